@@ -0,0 +1,57 @@
+// Command catalog-docs renders a team's catalog into a zipped static HTML site.
+//
+//	go run ./cmd/catalog-docs -team 1 -out catalog.zip
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"go-data-catalog/internal/config"
+	"go-data-catalog/internal/docsite"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+func main() {
+	teamID := flag.Int("team", 0, "team id to render")
+	out := flag.String("out", "catalog-docs.zip", "output zip path")
+	flag.Parse()
+	if *teamID <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	db, err := postgres.NewDB(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	cat, err := docsite.Load(context.Background(),
+		postgres.NewTeamRepository(db),
+		postgres.NewArtifactRepository(db),
+		postgres.NewArtifactFieldRepository(db),
+		postgres.NewContactRepository(db),
+		postgres.NewLineageRepository(db),
+		*teamID,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := docsite.Build(f, cat); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %s: %d artifacts, %d contacts", *out, len(cat.Artifacts), len(cat.Contacts))
+}
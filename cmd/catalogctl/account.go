@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// cmdPassword runs the forgotten-password flow: "forgot" emails a reset
+// link, "reset" sets the new password with the token from that link.
+func cmdPassword(c *cli, args []string) error {
+	action, args, err := subcommand(args, "forgot", "reset")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "password "+action)
+	email := fs.String("email", os.Getenv("CATALOG_EMAIL"), "account email (forgot)")
+	token := fs.String("token", "", "token from the reset link, the part after #reset_password= (reset)")
+	fromStdin := fs.Bool("password-stdin", false, "read the new password from stdin (reset)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("password %s takes no arguments", action)
+	}
+
+	if action == "forgot" {
+		if *email == "" {
+			return usagef("-email (or CATALOG_EMAIL) is required")
+		}
+		if err := c.api.ForgotPassword(c.ctx, *email); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stderr, "If the account exists and has a password, a reset link is on its way")
+		return nil
+	}
+	if *token == "" {
+		return usagef("-token is required")
+	}
+	password := os.Getenv("CATALOG_PASSWORD")
+	if *fromStdin {
+		if password, err = readLine(bufio.NewReader(os.Stdin)); err != nil {
+			return fmt.Errorf("read password: %w", err)
+		}
+	}
+	if password == "" {
+		return usagef("password is required: use -password-stdin or CATALOG_PASSWORD")
+	}
+	if err := c.api.ResetPassword(c.ctx, *token, password); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stderr, "Password changed; all sessions were ended. Log in with the new password.")
+	return nil
+}
+
+// cmdVerifyEmail confirms an email address, or with -resend asks for a new
+// verification link.
+func cmdVerifyEmail(c *cli, args []string) error {
+	fs := newFlagSet(c, "verify-email")
+	resend := fs.Bool("resend", false, "send a new verification email instead")
+	email := fs.String("email", os.Getenv("CATALOG_EMAIL"), "account email (-resend)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *resend {
+		if len(rest) > 0 {
+			return usagef("verify-email -resend takes no arguments")
+		}
+		if *email == "" {
+			return usagef("-email (or CATALOG_EMAIL) is required")
+		}
+		if err := c.api.ResendVerification(c.ctx, *email); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stderr, "If the address needs verification, a new link is on its way")
+		return nil
+	}
+	if len(rest) != 1 {
+		return usagef("usage: verify-email TOKEN")
+	}
+	if err := c.api.VerifyEmail(c.ctx, rest[0]); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stderr, "Email address verified")
+	return nil
+}
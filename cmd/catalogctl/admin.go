@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"go-data-catalog/pkg/client"
+)
+
+func printUsers(c *cli, users []client.User) error {
+	rows := make([][]string, 0, len(users))
+	for _, u := range users {
+		status := "active"
+		if !u.IsActive {
+			status = "inactive"
+		}
+		rows = append(rows, []string{strconv.Itoa(u.ID), u.Email, u.Name, u.SystemRole, status})
+	}
+	return c.print(users, []string{"ID", "EMAIL", "NAME", "ROLE", "STATUS"}, rows)
+}
+
+func cmdUsers(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "get", "deactivate", "activate", "role", "reset-2fa", "unlock", "logins")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "users "+action)
+	search := fs.String("search", "", "email or name filter (list)")
+	status := fs.String("status", "", "active or inactive (list)")
+	role := fs.String("role", "", "system role: user or admin (role)")
+	limit := fs.Int("limit", 0, "number of attempts to show, default 50 (logins)")
+	org := fs.Int("org", 0, "organization whose users to manage (all but role)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *org != 0 && action == "role" {
+		return usagef("system roles are changed by system admins only; drop -org")
+	}
+	if *org == 0 && action != "role" {
+		return usagef("-org is required: users are managed by the admins of their organization")
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	if action == "list" {
+		f := client.UserFilter{Search: *search}
+		switch *status {
+		case "":
+		case "active", "inactive":
+			active := *status == "active"
+			f.Active = &active
+		default:
+			return usagef("-status must be active or inactive")
+		}
+		users, err := c.api.ListOrgUsers(c.ctx, *org, f)
+		if err != nil {
+			return err
+		}
+		return printUsers(c, users)
+	}
+
+	id, err := parseID(rest, "user")
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "reset-2fa":
+		if err := c.api.ResetOrgUserTwoFactor(c.ctx, *org, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "Two-factor authentication of user %d turned off\n", id)
+		return nil
+	case "unlock":
+		if err := c.api.UnlockOrgUser(c.ctx, *org, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "Failed logins of user %d cleared\n", id)
+		return nil
+	case "logins":
+		h, err := c.api.OrgLoginHistory(c.ctx, *org, id, client.ListOptions{Limit: *limit})
+		if err != nil {
+			return err
+		}
+		if h.LockedUntil != nil && c.output == "table" {
+			fmt.Fprintf(c.stderr, "Locked until %s\n", h.LockedUntil.Local().Format("2006-01-02 15:04:05"))
+		}
+		rows := make([][]string, 0, len(h.Attempts))
+		for _, a := range h.Attempts {
+			rows = append(rows, []string{a.CreatedAt.Local().Format("2006-01-02 15:04:05"), a.Method, a.Result, a.Login, a.IP})
+		}
+		return c.print(h, []string{"TIME", "METHOD", "RESULT", "LOGIN", "IP"}, rows)
+	}
+	var u *client.User
+	switch action {
+	case "get":
+		u, err = c.api.GetOrgUser(c.ctx, *org, id)
+	case "deactivate":
+		u, err = c.api.DeactivateOrgUser(c.ctx, *org, id)
+	case "activate":
+		u, err = c.api.ActivateOrgUser(c.ctx, *org, id)
+	default: // role
+		if *role != "user" && *role != "admin" {
+			return usagef("-role must be user or admin")
+		}
+		u, err = c.api.SetSystemRole(c.ctx, id, *role)
+	}
+	if err != nil {
+		return err
+	}
+	return printUsers(c, []client.User{*u})
+}
+
+func printSnapshots(c *cli, items []client.TeamSnapshot) error {
+	rows := make([][]string, 0, len(items))
+	for _, s := range items {
+		restored := "-"
+		if s.RestoredTeamID != nil {
+			restored = "as team " + strconv.Itoa(*s.RestoredTeamID)
+		} else if s.RestoredAt != nil {
+			restored = "yes"
+		}
+		rows = append(rows, []string{strconv.Itoa(s.ID), strconv.Itoa(s.TeamID), s.TeamName, s.CreatedAt.Format("2006-01-02 15:04"), restored})
+	}
+	return c.print(items, []string{"ID", "TEAM ID", "TEAM", "DELETED", "RESTORED"}, rows)
+}
+
+func cmdTeamSnapshots(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "get", "restore")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "team-snapshots "+action)
+	name := fs.String("name", "", "restore under another team name (restore)")
+	limit := fs.Int("limit", 0, "number of snapshots to show (list)")
+	org := fs.Int("org", 0, "organization of the deleted teams (required)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *org == 0 {
+		return usagef("-org is required")
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	if action == "list" {
+		items, err := c.api.ListOrgTeamSnapshots(c.ctx, *org, client.ListOptions{Limit: *limit})
+		if err != nil {
+			return err
+		}
+		return printSnapshots(c, items)
+	}
+
+	id, err := parseID(rest, "snapshot")
+	if err != nil {
+		return err
+	}
+	if action == "get" {
+		s, err := c.api.GetOrgTeamSnapshot(c.ctx, *org, id)
+		if err != nil {
+			return err
+		}
+		if c.output == "table" && s.Data != nil {
+			fmt.Fprintf(c.stderr, "%d members, %d contacts, %d artifacts, %d fields\n",
+				len(s.Data.Members), len(s.Data.Contacts), len(s.Data.Artifacts), len(s.Data.Fields))
+		}
+		return printSnapshots(c, []client.TeamSnapshot{*s})
+	}
+	t, err := c.api.RestoreOrgTeamSnapshot(c.ctx, *org, id, *name)
+	if err != nil {
+		return err
+	}
+	return printTeams(c, []client.Team{*t})
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-data-catalog/pkg/client"
+)
+
+// credentialsPath is where tokens are cached, keyed by server URL.
+func credentialsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "catalogctl", "credentials.json"), nil
+}
+
+type credential struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// UnmarshalJSON also accepts a bare token string, the format of older versions.
+func (cr *credential) UnmarshalJSON(data []byte) error {
+	var token string
+	if json.Unmarshal(data, &token) == nil {
+		*cr = credential{Token: token}
+		return nil
+	}
+	type plain credential
+	return json.Unmarshal(data, (*plain)(cr))
+}
+
+func readCredentials() map[string]credential {
+	creds := map[string]credential{}
+	path, err := credentialsPath()
+	if err != nil {
+		return creds
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return creds
+	}
+	_ = json.Unmarshal(data, &creds)
+	return creds
+}
+
+func writeCredentials(creds map[string]credential) error {
+	path, err := credentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func loadCredential(server string) credential {
+	return readCredentials()[strings.TrimRight(server, "/")]
+}
+
+func saveCredential(server string, cr credential) error {
+	creds := readCredentials()
+	key := strings.TrimRight(server, "/")
+	if cr.Token == "" && cr.RefreshToken == "" {
+		delete(creds, key)
+	} else {
+		creds[key] = cr
+	}
+	return writeCredentials(creds)
+}
+
+func cmdLogin(c *cli, args []string) error {
+	fs := newFlagSet(c, "login")
+	email := fs.String("email", os.Getenv("CATALOG_EMAIL"), "account email")
+	fromStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	code := fs.String("code", os.Getenv("CATALOG_OTP"), "two-factor code, if enabled (default: prompt)")
+	noCache := fs.Bool("print-token", false, "print the token instead of caching it")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("login takes no arguments")
+	}
+	if *email == "" {
+		return usagef("-email (or CATALOG_EMAIL) is required")
+	}
+	password := os.Getenv("CATALOG_PASSWORD")
+	stdin := bufio.NewReader(os.Stdin)
+	if *fromStdin {
+		if password, err = readLine(stdin); err != nil {
+			return fmt.Errorf("read password: %w", err)
+		}
+	}
+	if password == "" {
+		return usagef("password is required: use -password-stdin or CATALOG_PASSWORD")
+	}
+	res, err := c.api.Login(c.ctx, *email, password)
+	if err != nil {
+		return err
+	}
+	if res.MFARequired {
+		if *code == "" {
+			fmt.Fprint(c.stderr, "Two-factor code: ")
+			if *code, err = readLine(stdin); err != nil {
+				return fmt.Errorf("read code: %w", err)
+			}
+		}
+		if res, err = c.api.LoginTwoFactor(c.ctx, res.MFAToken, *code); err != nil {
+			return err
+		}
+	}
+	if *noCache {
+		fmt.Fprintln(c.stdout, res.Token)
+		return nil
+	}
+	if err := saveCredential(c.server, credential{Token: res.Token, RefreshToken: res.RefreshToken}); err != nil {
+		return fmt.Errorf("cache token: %w", err)
+	}
+	fmt.Fprintf(c.stderr, "Logged in to %s as %s\n", c.server, res.User.Email)
+	return nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func cmdLogout(c *cli, args []string) error {
+	fs := newFlagSet(c, "logout")
+	all := fs.Bool("all", false, "end all sessions of the account, on every device")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("logout takes no arguments")
+	}
+	if c.api.Token() != "" || c.api.RefreshToken() != "" {
+		if *all {
+			err = c.api.LogoutAll(c.ctx)
+		} else {
+			err = c.api.Logout(c.ctx)
+		}
+		// an already expired or revoked session is as good as logged out
+		if err != nil && !errors.Is(err, client.ErrUnauthorized) {
+			return err
+		}
+	}
+	return saveCredential(c.server, credential{})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"go-data-catalog/pkg/client"
+)
+
+func printCertifications(c *cli, items []client.Certification) error {
+	rows := make([][]string, 0, len(items))
+	for _, cert := range items {
+		rows = append(rows, []string{strconv.Itoa(cert.ArtifactID), cert.ArtifactName, cert.State, cert.CertifiedByEmail,
+			cert.CertifiedAt.Format("2006-01-02"), cert.ExpiresAt.Format("2006-01-02"), cert.ReviewReason, truncate(cert.Note, 40)})
+	}
+	return c.print(items, []string{"ARTIFACT_ID", "ARTIFACT", "STATE", "CERTIFIED_BY", "CERTIFIED", "EXPIRES", "REVIEW", "NOTE"}, rows)
+}
+
+// cmdCertify certifies artifacts of the -team team as trusted sources and
+// lists the certifications to review or renew.
+func cmdCertify(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "get", "set", "remove")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "certify "+action)
+	state := fs.String("state", "", "state filter (list): certified, needs_review, expired or empty for all")
+	note := fs.String("note", "", "why the artifact can be trusted (set)")
+	expires := fs.String("expires", "", "expiry date YYYY-MM-DD, default in 180 days (set)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().AddDate(0, 0, 180)
+	if *expires != "" {
+		if expiresAt, err = time.ParseInLocation("2006-01-02", *expires, time.Local); err != nil {
+			return usagef("invalid -expires %q: want YYYY-MM-DD", *expires)
+		}
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	if action == "list" {
+		items, err := c.api.Certifications(c.ctx, teamID, *state)
+		if err != nil {
+			return err
+		}
+		return printCertifications(c, items)
+	}
+	id, err := parseID(rest, "artifact")
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "get":
+		cert, err := c.api.Certification(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		return printCertifications(c, []client.Certification{*cert})
+	case "set":
+		cert, err := c.api.Certify(c.ctx, teamID, id, *note, expiresAt)
+		if err != nil {
+			return err
+		}
+		return printCertifications(c, []client.Certification{*cert})
+	default: // remove
+		return c.api.Uncertify(c.ctx, teamID, id)
+	}
+}
@@ -0,0 +1,789 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-data-catalog/pkg/client"
+)
+
+func printTeams(c *cli, teams []client.Team) error {
+	rows := make([][]string, 0, len(teams))
+	for _, t := range teams {
+		rows = append(rows, []string{strconv.Itoa(t.ID), t.Name, t.Visibility, t.JoinPolicy, truncate(t.Description, 60)})
+	}
+	return c.print(teams, []string{"ID", "NAME", "VISIBILITY", "JOIN", "DESCRIPTION"}, rows)
+}
+
+func cmdTeams(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "mine", "create", "join", "require-2fa", "leave", "transfer", "permissions", "settings", "update", "archive", "unarchive", "delete")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "teams "+action)
+	search := fs.String("search", "", "name filter (list)")
+	name := fs.String("name", "", "team name (create, update)")
+	description := fs.String("description", "", "team description (create, update)")
+	org := fs.Int("org", 0, "organization id; needed if you belong to several (create)")
+	confirm := fs.String("confirm", "", "the team name, to confirm deletion (delete)")
+	off := fs.Bool("off", false, "lift the requirement (require-2fa)")
+	to := fs.Int("to", 0, "user id of the new owner (transfer)")
+	message := fs.String("message", "", "message to the team admins (join)")
+	visibility := fs.String("visibility", "", "public, discoverable or hidden (settings)")
+	joinPolicy := fs.String("join-policy", "", "open, approval or invite_only (settings)")
+	publicCatalog := fs.Bool("public-catalog", false, "let non-members read the artifacts (settings)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		teams, err := c.api.SearchTeams(c.ctx, *search)
+		if err != nil {
+			return err
+		}
+		return printTeams(c, teams)
+	case "mine":
+		teams, err := c.api.MyTeams(c.ctx)
+		if err != nil {
+			return err
+		}
+		return printTeams(c, teams)
+	case "create":
+		if *name == "" {
+			return usagef("-name is required")
+		}
+		t, err := c.api.CreateOrgTeam(c.ctx, *org, *name, *description)
+		if err != nil {
+			return err
+		}
+		return printTeams(c, []client.Team{*t})
+	case "require-2fa":
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		t, err := c.api.SetTeamRequire2FA(c.ctx, teamID, !*off)
+		if err != nil {
+			return err
+		}
+		return printTeams(c, []client.Team{*t})
+	case "settings":
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		t, err := currentTeam(c, teamID)
+		if err != nil {
+			return err
+		}
+		settings := client.TeamSettings{Visibility: t.Visibility, JoinPolicy: t.JoinPolicy, PublicCatalog: t.PublicCatalog}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "visibility":
+				settings.Visibility = *visibility
+			case "join-policy":
+				settings.JoinPolicy = *joinPolicy
+			case "public-catalog":
+				settings.PublicCatalog = *publicCatalog
+			}
+		})
+		t, err = c.api.UpdateTeamSettings(c.ctx, teamID, settings)
+		if err != nil {
+			return err
+		}
+		return printTeams(c, []client.Team{*t})
+	case "update":
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		t, err := currentTeam(c, teamID)
+		if err != nil {
+			return err
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name":
+				t.Name = *name
+			case "description":
+				t.Description = *description
+			}
+		})
+		t, err = c.api.UpdateTeam(c.ctx, teamID, t.Name, t.Description)
+		if err != nil {
+			return err
+		}
+		return printTeams(c, []client.Team{*t})
+	case "archive", "unarchive":
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		archive := c.api.ArchiveTeam
+		if action == "unarchive" {
+			archive = c.api.UnarchiveTeam
+		}
+		t, err := archive(c.ctx, teamID)
+		if err != nil {
+			return err
+		}
+		return printTeams(c, []client.Team{*t})
+	case "delete":
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		if *confirm == "" {
+			return usagef("-confirm with the team name is required")
+		}
+		s, err := c.api.DeleteTeam(c.ctx, teamID, *confirm)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "Team %d deleted; snapshot %d keeps a copy\n", teamID, s.ID)
+		return nil
+	case "leave":
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		if err := c.api.LeaveTeam(c.ctx, teamID); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "Left team %d\n", teamID)
+		return nil
+	case "transfer":
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		if *to <= 0 {
+			return usagef("-to is required")
+		}
+		m, err := c.api.TransferOwnership(c.ctx, teamID, *to)
+		if err != nil {
+			return err
+		}
+		return printMembers(c, []client.TeamMember{*m})
+	case "permissions":
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		p, err := c.api.MyPermissions(c.ctx, teamID)
+		if err != nil {
+			return err
+		}
+		resources := make([]string, 0, len(p.Permissions))
+		for r := range p.Permissions {
+			resources = append(resources, r)
+		}
+		sort.Strings(resources)
+		rows := make([][]string, 0, len(resources))
+		for _, r := range resources {
+			rows = append(rows, []string{p.Role, r, strings.Join(p.Permissions[r], ",")})
+		}
+		return c.print(p, []string{"ROLE", "RESOURCE", "ACTIONS"}, rows)
+	default: // join
+		teamID, err := parseID(rest, "team")
+		if err != nil {
+			return err
+		}
+		jr, err := c.api.RequestJoin(c.ctx, teamID, *message)
+		if err != nil {
+			return err
+		}
+		return printRequests(c, []client.JoinRequest{*jr})
+	}
+}
+
+// currentTeam reads the team, so that flags left out of "teams settings" and
+// "teams update" keep their values.
+func currentTeam(c *cli, teamID int) (*client.Team, error) {
+	teams, err := c.api.MyTeams(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range teams {
+		if t.ID == teamID {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("you are not a member of team %d", teamID)
+}
+
+func printRequests(c *cli, items []client.JoinRequest) error {
+	rows := make([][]string, 0, len(items))
+	for _, r := range items {
+		team, user := strconv.Itoa(r.TeamID), strconv.Itoa(r.UserID)
+		if r.TeamName != "" {
+			team += " " + r.TeamName
+		}
+		if r.UserEmail != "" {
+			user += " " + r.UserEmail
+		}
+		note := r.Message
+		if r.DecisionComment != "" {
+			note = r.DecisionComment
+		}
+		rows = append(rows, []string{strconv.Itoa(r.ID), team, user, r.Status, r.CreatedAt.Format("2006-01-02 15:04"), truncate(note, 60)})
+	}
+	return c.print(items, []string{"ID", "TEAM", "USER", "STATUS", "CREATED", "MESSAGE"}, rows)
+}
+
+// cmdRequests manages join requests of the -team team; mine and cancel work
+// on the current user's own requests.
+func cmdRequests(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "approve", "reject", "mine", "cancel")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "requests "+action)
+	status := fs.String("status", "pending", "status filter (list, mine): pending, approved, rejected, cancelled or empty for all")
+	comment := fs.String("comment", "", "comment to the requester (approve, reject)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	teamID := 0
+	if action != "mine" && action != "cancel" {
+		if teamID, err = c.requireTeam(); err != nil {
+			return err
+		}
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	switch action {
+	case "list", "mine":
+		var items []client.JoinRequest
+		if action == "mine" {
+			items, err = c.api.MyJoinRequests(c.ctx, *status)
+		} else {
+			items, err = c.api.ListJoinRequests(c.ctx, teamID, *status)
+		}
+		if err != nil {
+			return err
+		}
+		return printRequests(c, items)
+	}
+	id, err := parseID(rest, "request")
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "approve":
+		return c.api.ApproveJoinRequest(c.ctx, teamID, id, *comment)
+	case "reject":
+		return c.api.RejectJoinRequest(c.ctx, teamID, id, *comment)
+	default: // cancel
+		return c.api.CancelJoinRequest(c.ctx, id)
+	}
+}
+
+func printMembers(c *cli, items []client.TeamMember) error {
+	rows := make([][]string, 0, len(items))
+	for _, m := range items {
+		name := m.Email
+		if m.ServiceAccount {
+			name = m.Name + " (service account)"
+		}
+		rows = append(rows, []string{strconv.Itoa(m.UserID), name, m.Role, m.Status, m.JoinedAt.Format("2006-01-02")})
+	}
+	return c.print(items, []string{"USER", "EMAIL", "ROLE", "STATUS", "JOINED"}, rows)
+}
+
+// cmdMembers lists and manages the members of the -team team.
+func cmdMembers(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "role", "deactivate", "activate", "remove")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "members "+action)
+	status := fs.String("status", "", "status filter (list): active, inactive or empty for all")
+	role := fs.String("role", "", "new role: admin, member or viewer (role)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	if action == "list" {
+		items, err := c.api.ListMembers(c.ctx, teamID, *status)
+		if err != nil {
+			return err
+		}
+		return printMembers(c, items)
+	}
+	userID, err := parseID(rest, "user")
+	if err != nil {
+		return err
+	}
+	var m *client.TeamMember
+	switch action {
+	case "role":
+		if *role != "admin" && *role != "member" && *role != "viewer" {
+			return usagef("-role must be admin, member or viewer")
+		}
+		m, err = c.api.SetMemberRole(c.ctx, teamID, userID, *role)
+	case "deactivate", "activate":
+		m, err = c.api.SetMemberActive(c.ctx, teamID, userID, action == "activate")
+	default: // remove
+		if err := c.api.RemoveMember(c.ctx, teamID, userID); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "User %d removed from team %d\n", userID, teamID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return printMembers(c, []client.TeamMember{*m})
+}
+
+func printArtifacts(c *cli, items []client.Artifact) error {
+	rows := make([][]string, 0, len(items))
+	for _, a := range items {
+		dev := ""
+		if a.DeveloperID > 0 {
+			dev = strconv.Itoa(a.DeveloperID)
+		}
+		rows = append(rows, []string{strconv.Itoa(a.ID), a.Name, a.Type, a.ProjectName, dev, a.Status, a.Certification, truncate(a.Description, 50)})
+	}
+	return c.print(items, []string{"ID", "NAME", "TYPE", "PROJECT", "DEVELOPER", "STATUS", "CERTIFICATION", "DESCRIPTION"}, rows)
+}
+
+func cmdArtifacts(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "get", "create", "update", "delete", "render", "status", "deprecated")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "artifacts "+action)
+	var a client.Artifact
+	var st client.ArtifactStatus
+	fs.StringVar(&a.Name, "name", "", "artifact name")
+	fs.StringVar(&a.Type, "type", "table", "table, view, procedure, function, index, dataset, api or file")
+	fs.StringVar(&a.ProjectName, "project", "", "project name")
+	fs.StringVar(&a.Description, "description", "", "description")
+	fs.IntVar(&a.DeveloperID, "developer", 0, "developer contact id")
+	format := fs.String("format", "postgres-ddl", "render format: postgres-ddl, clickhouse-ddl, go-struct, json-schema or avro")
+	fs.StringVar(&a.Status, "status", "", "draft or active (create); draft, active, deprecated or retired (status)")
+	fs.StringVar(&st.Reason, "reason", "", "why the artifact is deprecated (status)")
+	replacement := fs.Int("replacement", 0, "id of the artifact replacing it (status)")
+	date := fs.String("date", "", "deprecation date YYYY-MM-DD, default today (status)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if action == "status" {
+		if st.Status = a.Status; st.Status == "" {
+			return usagef("artifacts status requires -status")
+		}
+		if *replacement > 0 {
+			st.ReplacementID = replacement
+		}
+		if *date != "" {
+			d, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+			if err != nil {
+				return usagef("invalid -date %q: want YYYY-MM-DD", *date)
+			}
+			st.DeprecatedAt = &d
+		}
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		items, err := c.api.ListArtifacts(c.ctx, teamID, nil)
+		if err != nil {
+			return err
+		}
+		return printArtifacts(c, items)
+	case "create":
+		created, err := c.api.CreateArtifact(c.ctx, teamID, a)
+		if err != nil {
+			return err
+		}
+		return printArtifacts(c, []client.Artifact{*created})
+	case "deprecated":
+		items, err := c.api.DeprecatedReport(c.ctx, teamID)
+		if err != nil {
+			return err
+		}
+		return printDeprecatedReport(c, items)
+	}
+
+	id, err := parseID(rest, "artifact")
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "get":
+		got, err := c.api.GetArtifact(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		return printArtifacts(c, []client.Artifact{*got})
+	case "update":
+		// start from the stored artifact so that only given flags change
+		cur, err := c.api.GetArtifact(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name":
+				cur.Name = a.Name
+			case "type":
+				cur.Type = a.Type
+			case "project":
+				cur.ProjectName = a.ProjectName
+			case "description":
+				cur.Description = a.Description
+			case "developer":
+				cur.DeveloperID = a.DeveloperID
+			}
+		})
+		updated, err := c.api.UpdateArtifact(c.ctx, teamID, id, *cur)
+		if err != nil {
+			return err
+		}
+		return printArtifacts(c, []client.Artifact{*updated})
+	case "delete":
+		return c.api.DeleteArtifact(c.ctx, teamID, id)
+	case "status":
+		updated, err := c.api.SetArtifactStatus(c.ctx, teamID, id, st)
+		if err != nil {
+			return err
+		}
+		return printArtifacts(c, []client.Artifact{*updated})
+	default: // render
+		res, err := c.api.RenderArtifact(c.ctx, teamID, id, *format)
+		if err != nil {
+			return err
+		}
+		if c.output != "table" {
+			return c.print(res, nil, nil)
+		}
+		for _, w := range res.Warnings {
+			fmt.Fprintln(c.stderr, "warning:", w)
+		}
+		_, err = fmt.Fprint(c.stdout, res.Content)
+		return err
+	}
+}
+
+func printFields(c *cli, items []client.ArtifactField) error {
+	rows := make([][]string, 0, len(items))
+	for _, f := range items {
+		rows = append(rows, []string{strconv.Itoa(f.ID), strconv.Itoa(f.ArtifactID), f.FieldName, f.DataType, yesNo(f.IsPK), truncate(f.Description, 50)})
+	}
+	return c.print(items, []string{"ID", "ARTIFACT", "NAME", "TYPE", "PK", "DESCRIPTION"}, rows)
+}
+
+func cmdFields(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "get", "create", "update", "delete")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "fields "+action)
+	var f client.ArtifactField
+	artifactID := fs.Int("artifact", 0, "artifact id (list, create)")
+	fs.StringVar(&f.FieldName, "name", "", "field name")
+	fs.StringVar(&f.DataType, "type", "", "data type, e.g. bigint or varchar(255)")
+	fs.StringVar(&f.Description, "description", "", "description")
+	fs.BoolVar(&f.IsPK, "pk", false, "part of the primary key")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list", "create":
+		if *artifactID <= 0 {
+			return usagef("-artifact is required")
+		}
+		if action == "list" {
+			items, err := c.api.ListFields(c.ctx, teamID, *artifactID)
+			if err != nil {
+				return err
+			}
+			return printFields(c, items)
+		}
+		created, err := c.api.CreateField(c.ctx, teamID, *artifactID, f)
+		if err != nil {
+			return err
+		}
+		return printFields(c, []client.ArtifactField{*created})
+	}
+
+	id, err := parseID(rest, "field")
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "get":
+		got, err := c.api.GetField(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		return printFields(c, []client.ArtifactField{*got})
+	case "update":
+		cur, err := c.api.GetField(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		fs.Visit(func(fl *flag.Flag) {
+			switch fl.Name {
+			case "name":
+				cur.FieldName = f.FieldName
+			case "type":
+				cur.DataType = f.DataType
+			case "description":
+				cur.Description = f.Description
+			case "pk":
+				cur.IsPK = f.IsPK
+			}
+		})
+		updated, err := c.api.UpdateField(c.ctx, teamID, id, *cur)
+		if err != nil {
+			return err
+		}
+		return printFields(c, []client.ArtifactField{*updated})
+	default: // delete
+		return c.api.DeleteField(c.ctx, teamID, id)
+	}
+}
+
+func printContacts(c *cli, items []client.Contact) error {
+	rows := make([][]string, 0, len(items))
+	for _, ct := range items {
+		user := "-"
+		if ct.UserID != nil {
+			user = ct.UserEmail
+		}
+		channels := make([]string, 0, len(ct.Channels))
+		for _, ch := range ct.Channels {
+			channels = append(channels, ch.Kind+":"+ch.Value)
+		}
+		rows = append(rows, []string{strconv.Itoa(ct.ID), ct.Name, ct.Department, strings.Join(channels, " "), user, truncate(ct.Availability, 40)})
+	}
+	return c.print(items, []string{"ID", "NAME", "DEPARTMENT", "CHANNELS", "USER", "AVAILABILITY"}, rows)
+}
+
+// channelFlags are the contact flags that set channels of one kind.
+var channelFlags = []string{client.ChannelEmail, client.ChannelPhone, client.ChannelTelegram, client.ChannelSlack, client.ChannelMattermost}
+
+func cmdContacts(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "get", "create", "update", "delete", "reassign", "vcard")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "contacts "+action)
+	var ct client.Contact
+	fs.StringVar(&ct.Name, "name", "", "contact name")
+	fs.StringVar(&ct.Department, "department", "", "department or team label; filter for list")
+	fs.StringVar(&ct.Availability, "availability", "", "on-call or availability note")
+	fs.StringVar(&ct.AvatarURL, "avatar", "", "avatar image URL")
+	channels := map[string]*string{}
+	for _, kind := range channelFlags {
+		channels[kind] = fs.String(kind, "", kind+" channels, comma-separated; empty to remove")
+	}
+	userID := fs.Int("user", 0, "id of the team member's account the contact stands for, 0 to unlink")
+	to := fs.Int("to", 0, "contact that takes over the artifacts (reassign)")
+	query := fs.String("q", "", "search by name, department, channel or email (list)")
+	out := fs.String("f", "-", "output file, - for stdout (vcard)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *userID > 0 {
+		ct.UserID = userID
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	// setChannels replaces the channels of the kinds given on the command line
+	setChannels := func(cur []client.ContactChannel) []client.ContactChannel {
+		res := []client.ContactChannel{}
+		given := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) {
+			if _, ok := channels[f.Name]; ok {
+				given[f.Name] = true
+			}
+		})
+		for _, ch := range cur {
+			if !given[ch.Kind] {
+				res = append(res, ch)
+			}
+		}
+		for _, kind := range channelFlags {
+			if !given[kind] {
+				continue
+			}
+			for _, v := range strings.Split(*channels[kind], ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					res = append(res, client.ContactChannel{Kind: kind, Value: v})
+				}
+			}
+		}
+		return res
+	}
+
+	switch action {
+	case "list":
+		items, err := c.api.SearchContacts(c.ctx, teamID, *query, ct.Department, nil)
+		if err != nil {
+			return err
+		}
+		return printContacts(c, items)
+	case "create":
+		ct.Channels = setChannels(nil)
+		created, err := c.api.CreateContact(c.ctx, teamID, ct)
+		if err != nil {
+			return err
+		}
+		return printContacts(c, []client.Contact{*created})
+	case "vcard":
+		var data []byte
+		if len(rest) == 0 {
+			data, err = c.api.DirectoryVCard(c.ctx, teamID)
+		} else {
+			id, perr := parseID(rest, "contact")
+			if perr != nil {
+				return perr
+			}
+			data, err = c.api.ContactVCard(c.ctx, teamID, id)
+		}
+		if err != nil {
+			return err
+		}
+		if *out == "-" {
+			_, err = c.stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(*out, data, 0o644)
+	}
+
+	id, err := parseID(rest, "contact")
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "get":
+		got, err := c.api.GetContact(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		return printContacts(c, []client.Contact{*got})
+	case "update":
+		cur, err := c.api.GetContact(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name":
+				cur.Name = ct.Name
+			case "department":
+				cur.Department = ct.Department
+			case "availability":
+				cur.Availability = ct.Availability
+			case "avatar":
+				cur.AvatarURL = ct.AvatarURL
+			case "user":
+				cur.UserID = ct.UserID
+			}
+		})
+		cur.Channels = setChannels(cur.Channels)
+		updated, err := c.api.UpdateContact(c.ctx, teamID, id, *cur)
+		if err != nil {
+			return err
+		}
+		return printContacts(c, []client.Contact{*updated})
+	case "reassign":
+		if *to <= 0 {
+			return usagef("-to is required")
+		}
+		n, err := c.api.ReassignContact(c.ctx, teamID, id, *to)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "%d artifacts handed over to contact %d\n", n, *to)
+		return nil
+	default: // delete
+		return c.api.DeleteContact(c.ctx, teamID, id)
+	}
+}
+
+// cmdSearch matches the query against artifact names, descriptions and field
+// names of the team and of artifacts shared with it.
+func cmdSearch(c *cli, args []string) error {
+	fs := newFlagSet(c, "search")
+	withFields := fs.Bool("fields", true, "also show field matches")
+	limit := fs.Int("limit", 0, "maximum number of hits, 1-200 (default 50)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usagef("search QUERY")
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	all, err := c.api.Search(c.ctx, teamID, strings.Join(rest, " "), *limit)
+	if err != nil {
+		return err
+	}
+	hits := make([]client.SearchHit, 0, len(all))
+	rows := make([][]string, 0, len(all))
+	for _, h := range all {
+		if h.Match == "field" && !*withFields {
+			continue
+		}
+		team := "-"
+		if h.Shared {
+			team = h.TeamName
+		}
+		hits = append(hits, h)
+		rows = append(rows, []string{strconv.Itoa(h.ArtifactID), h.Artifact, h.ProjectName, team, h.Status, yesNo(h.Certified), h.Match, h.Field})
+	}
+	return c.print(hits, []string{"ARTIFACT_ID", "ARTIFACT", "PROJECT", "SHARED_BY", "STATUS", "CERTIFIED", "MATCH", "FIELD"}, rows)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"go-data-catalog/pkg/client"
+)
+
+func printInvitations(c *cli, items []client.Invitation) error {
+	rows := make([][]string, 0, len(items))
+	for _, inv := range items {
+		email, uses := "-", strconv.Itoa(inv.UseCount)+"/∞"
+		if inv.Email != nil {
+			email = *inv.Email
+		}
+		if inv.MaxUses != nil {
+			uses = strconv.Itoa(inv.UseCount) + "/" + strconv.Itoa(*inv.MaxUses)
+		}
+		rows = append(rows, []string{strconv.Itoa(inv.ID), inv.Role, email, uses, inv.Status, inv.ExpiresAt.Format("2006-01-02 15:04")})
+	}
+	return c.print(items, []string{"ID", "ROLE", "EMAIL", "USES", "STATUS", "EXPIRES"}, rows)
+}
+
+// cmdInvitations manages invitations of the -team team; accept works with
+// any team's invitation token.
+func cmdInvitations(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "create", "revoke", "accept")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "invitations "+action)
+	status := fs.String("status", "active", "status filter (list): active, used, expired, revoked or empty for all")
+	role := fs.String("role", "member", "team role: admin, member or viewer (create)")
+	email := fs.String("email", "", "only this user may accept; the link is emailed (create)")
+	maxUses := fs.Int("max-uses", 1, "how many people may join with the link, 0 for unlimited (create)")
+	days := fs.Int("expires-in", 0, "lifetime in days, 1-90 (create; default 7)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	teamID := 0
+	if action != "accept" {
+		if teamID, err = c.requireTeam(); err != nil {
+			return err
+		}
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		items, err := c.api.ListInvitations(c.ctx, teamID, *status)
+		if err != nil {
+			return err
+		}
+		return printInvitations(c, items)
+	case "create":
+		inv, err := c.api.CreateInvitation(c.ctx, teamID, client.InvitationRequest{Role: *role, Email: *email, MaxUses: maxUses, ExpiresInDays: *days})
+		if err != nil {
+			return err
+		}
+		if c.output != "table" {
+			return c.print(inv, nil, nil)
+		}
+		fmt.Fprintln(c.stderr, "Invitation created; the link will not be shown again:")
+		fmt.Fprintln(c.stdout, inv.URL)
+		return nil
+	case "revoke":
+		id, err := parseID(rest, "invitation")
+		if err != nil {
+			return err
+		}
+		return c.api.RevokeInvitation(c.ctx, teamID, id)
+	default: // accept
+		if len(rest) != 1 {
+			return usagef("usage: invitations accept TOKEN")
+		}
+		res, err := c.api.AcceptInvitation(c.ctx, rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "Joined team %d (%s) as %s\n", res.Team.ID, res.Team.Name, res.Role)
+		return nil
+	}
+}
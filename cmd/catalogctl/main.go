@@ -0,0 +1,274 @@
+// Command catalogctl is a command-line client for the catalog API.
+//
+//	catalogctl login -email me@example.com
+//	catalogctl -team 1 artifacts list
+//	catalogctl -team 1 -o yaml export > catalog.yaml
+//	catalogctl -team 1 import -f catalog.yaml
+//
+// Settings can also come from the environment: CATALOG_SERVER, CATALOG_TOKEN,
+// CATALOG_TEAM, CATALOG_EMAIL and CATALOG_PASSWORD (the last two let CI log in
+// without a cached token).
+//
+// Exit codes: 0 success, 1 other error, 2 usage error, 3 authentication or
+// permission error, 4 not found, 5 invalid request, 6 server error.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"go-data-catalog/pkg/client"
+)
+
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitAuth
+	exitNotFound
+	exitInvalid
+	exitServer
+	// exitThrottled means the server rate-limited the request; retry later
+	exitThrottled
+)
+
+// usageError is returned for bad command-line input.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// cli carries global options shared by every command.
+type cli struct {
+	server string
+	token  string
+	team   int
+	output string
+	stdout io.Writer
+	stderr io.Writer
+	ctx    context.Context
+	api    *client.Client
+}
+
+type command struct {
+	usage string
+	run   func(c *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"login":            {"login [-email E] [-password-stdin] [-code C]\tlog in and cache the token", cmdLogin},
+	"logout":           {"logout [-all]\tend the session and forget the cached tokens", cmdLogout},
+	"password":         {"password forgot|reset\treset a forgotten password via email", cmdPassword},
+	"verify-email":     {"verify-email TOKEN | -resend\tconfirm the account email", cmdVerifyEmail},
+	"teams":            {"teams list|mine|create|update|join|leave|transfer|require-2fa|settings|permissions|archive|unarchive|delete\tfind, create, join, leave and delete teams", cmdTeams},
+	"members":          {"members list|role|deactivate|activate|remove\tmanage team members", cmdMembers},
+	"requests":         {"requests list|approve|reject|mine|cancel\tmanage join requests to a team or your own", cmdRequests},
+	"invitations":      {"invitations list|create|revoke|accept\tinvite people to a team and join with an invitation", cmdInvitations},
+	"artifacts":        {"artifacts list|get|create|update|delete|render|status|deprecated\tmanage artifacts, deprecate them and list deprecated ones still in use", cmdArtifacts},
+	"fields":           {"fields list|get|create|update|delete\tmanage artifact fields", cmdFields},
+	"contacts":         {"contacts list|get|create|update|delete|reassign|vcard\tmanage and search contacts, hand their artifacts over, export vCards", cmdContacts},
+	"owners":           {"owners list|set|mine\tartifact owners and the artifacts you own", cmdOwners},
+	"certify":          {"certify list|get|set|remove\tcertify artifacts as trusted sources and list those to review", cmdCertify},
+	"search":           {"search QUERY\tsearch artifacts and fields of the team and those shared with it", cmdSearch},
+	"shares":           {"shares list|create|revoke|shared|get\tshare artifacts with other teams and browse shared ones", cmdShares},
+	"lineage":          {"lineage list|add|remove\tshow and edit where an artifact comes from", cmdLineage},
+	"notifications":    {"notifications list|read|read-all\tin-app notifications", cmdNotifications},
+	"tokens":           {"tokens list|create|revoke [-account ID]\tmanage personal or service account API tokens", cmdTokens},
+	"service-accounts": {"service-accounts list|create|deactivate\tmanage team service accounts (team admins)", cmdServiceAccounts},
+	"users":            {"users list|get|deactivate|activate|role|reset-2fa|unlock|logins -org ID\tmanage user accounts (org admins; role: system admins)", cmdUsers},
+	"team-snapshots":   {"team-snapshots list|get|restore -org ID\trestore deleted teams (org admins)", cmdTeamSnapshots},
+	"orgs":             {"orgs list|all|get|create|update|members|add-member|add-admin|set-role|remove-member|types|add-type|remove-type|search|usage\torganizations, their members, artifact types and usage", cmdOrgs},
+	"2fa":              {"2fa status|enroll|confirm|disable|recovery-codes\tmanage two-factor authentication", cmdTwoFactor},
+	"export":           {"export [-f FILE]\tdump the team catalog as YAML or JSON", cmdExport},
+	"import":           {"import -f FILE [-prune] [-dry-run]\tcreate or update artifacts from a catalog file", cmdImport},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr, ctx: context.Background()}
+	fs := flag.NewFlagSet("catalogctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.server, "server", envOr("CATALOG_SERVER", "http://localhost:8080"), "catalog server URL")
+	fs.StringVar(&c.token, "token", os.Getenv("CATALOG_TOKEN"), "bearer token (default: cached by login)")
+	fs.IntVar(&c.team, "team", envInt("CATALOG_TEAM"), "team id for team-scoped commands")
+	fs.StringVar(&c.output, "o", "table", "output format: table, json or yaml")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: catalogctl [flags] COMMAND [args]\n\nCommands:")
+		names := make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		tw := tabwriter.NewWriter(stderr, 0, 0, 3, ' ', 0)
+		for _, n := range names {
+			fmt.Fprintln(tw, "  "+commands[n].usage)
+		}
+		tw.Flush()
+		fmt.Fprintln(stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	switch c.output {
+	case "table", "json", "yaml":
+	default:
+		fmt.Fprintf(stderr, "catalogctl: unknown output format %q\n", c.output)
+		return exitUsage
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "catalogctl: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return exitUsage
+	}
+	var cached credential
+	if c.token == "" {
+		cached = loadCredential(c.server)
+		c.token = cached.Token
+	}
+	c.api = client.New(c.server,
+		client.WithToken(c.token),
+		client.WithRefreshToken(cached.RefreshToken),
+		client.WithCredentials(os.Getenv("CATALOG_EMAIL"), os.Getenv("CATALOG_PASSWORD")),
+	)
+
+	err := cmd.run(c, fs.Args()[1:])
+	// keep the cache in sync when the client refreshed its tokens
+	if fs.Arg(0) != "login" && cached.Token != "" && c.api.RefreshToken() != "" && c.api.RefreshToken() != cached.RefreshToken {
+		if err := saveCredential(c.server, credential{Token: c.api.Token(), RefreshToken: c.api.RefreshToken()}); err != nil {
+			fmt.Fprintln(stderr, "catalogctl: cache token:", err)
+		}
+	}
+	if err == nil {
+		return exitOK
+	}
+	fmt.Fprintln(stderr, "catalogctl:", err)
+	return exitCode(err)
+}
+
+func exitCode(err error) int {
+	var ue *usageError
+	if errors.As(err, &ue) {
+		return exitUsage
+	}
+	switch {
+	case errors.Is(err, client.ErrUnauthorized), errors.Is(err, client.ErrForbidden):
+		return exitAuth
+	case errors.Is(err, client.ErrTooManyRequests):
+		return exitThrottled
+	case errors.Is(err, client.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, client.ErrServer):
+		return exitServer
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return exitInvalid
+	}
+	return exitError
+}
+
+// requireTeam returns the -team value or a usage error.
+func (c *cli) requireTeam() (int, error) {
+	if c.team <= 0 {
+		return 0, usagef("-team (or CATALOG_TEAM) is required")
+	}
+	return c.team, nil
+}
+
+// errNotLoggedIn is reported before any request when there is no way to authenticate.
+var errNotLoggedIn = fmt.Errorf("not logged in; run catalogctl login or set CATALOG_TOKEN: %w", client.ErrUnauthorized)
+
+// ensureAuth fails early when there is neither a token nor
+// CATALOG_EMAIL/CATALOG_PASSWORD for the client to log in with.
+func (c *cli) ensureAuth() error {
+	if c.api.Token() != "" || c.api.RefreshToken() != "" || (os.Getenv("CATALOG_EMAIL") != "" && os.Getenv("CATALOG_PASSWORD") != "") {
+		return nil
+	}
+	return errNotLoggedIn
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(key string) int {
+	n, _ := strconv.Atoi(os.Getenv(key))
+	return n
+}
+
+// parseID parses a positional numeric id argument.
+func parseID(args []string, what string) (int, error) {
+	if len(args) != 1 {
+		return 0, usagef("expected exactly one %s id", what)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, usagef("invalid %s id %q", what, args[0])
+	}
+	return id, nil
+}
+
+// subcommand splits "artifacts list ..." into the action and its args.
+func subcommand(args []string, actions ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, usagef("expected one of: %s", strings.Join(actions, ", "))
+	}
+	for _, a := range actions {
+		if args[0] == a {
+			return a, args[1:], nil
+		}
+	}
+	return "", nil, usagef("unknown action %q, expected one of: %s", args[0], strings.Join(actions, ", "))
+}
+
+// newFlagSet returns a flag set for a subcommand. Global -team and -o are
+// accepted there too so they can follow the subcommand name.
+func newFlagSet(c *cli, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.IntVar(&c.team, "team", c.team, "team id")
+	fs.StringVar(&c.output, "o", c.output, "output format: table, json or yaml")
+	return fs
+}
+
+// parseFlags parses fs, allowing flags and positional arguments to be
+// interleaved, and returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, &usageError{msg: err.Error()}
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
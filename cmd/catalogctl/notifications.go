@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"go-data-catalog/pkg/client"
+)
+
+func cmdNotifications(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "read", "read-all")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "notifications "+action)
+	unread := fs.Bool("unread", false, "only unread notifications (list)")
+	limit := fs.Int("limit", 20, "page size (list)")
+	offset := fs.Int("offset", 0, "number of notifications to skip (list)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	switch action {
+	case "list":
+		res, err := c.api.Notifications(c.ctx, *unread, client.ListOptions{Limit: *limit, Offset: *offset})
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(res.Notifications))
+		for _, n := range res.Notifications {
+			state := "unread"
+			if n.ReadAt != nil {
+				state = "read"
+			}
+			rows = append(rows, []string{strconv.Itoa(n.ID), n.CreatedAt.Format("2006-01-02 15:04"), state, n.Message})
+		}
+		if c.output == "table" {
+			fmt.Fprintf(c.stderr, "%d unread\n", res.Unread)
+		}
+		return c.print(res, []string{"ID", "CREATED", "STATE", "MESSAGE"}, rows)
+	case "read":
+		id, err := parseID(rest, "notification")
+		if err != nil {
+			return err
+		}
+		return c.api.MarkNotificationRead(c.ctx, id)
+	default: // read-all
+		return c.api.MarkAllNotificationsRead(c.ctx)
+	}
+}
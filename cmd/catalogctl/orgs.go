@@ -0,0 +1,234 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-data-catalog/pkg/client"
+)
+
+func printOrgs(c *cli, items []client.Organization) error {
+	rows := make([][]string, 0, len(items))
+	for _, o := range items {
+		role := o.Role
+		if role == "" {
+			role = "-"
+		}
+		rows = append(rows, []string{strconv.Itoa(o.ID), o.Name, role, strings.Join(o.AllowedAuthMethods, ","), yesNo(o.AutoJoin), truncate(o.Description, 50)})
+	}
+	return c.print(items, []string{"ID", "NAME", "ROLE", "SIGN_IN", "AUTO_JOIN", "DESCRIPTION"}, rows)
+}
+
+func printOrgMembers(c *cli, items []client.OrgMember) error {
+	rows := make([][]string, 0, len(items))
+	for _, m := range items {
+		rows = append(rows, []string{strconv.Itoa(m.UserID), m.Email, m.Name, m.Role, yesNo(m.IsActive), m.JoinedAt.Format("2006-01-02")})
+	}
+	return c.print(items, []string{"USER_ID", "EMAIL", "NAME", "ROLE", "ACTIVE", "JOINED"}, rows)
+}
+
+// orgAndArg splits "ORG_ID ARG" positional arguments.
+func orgAndArg(args []string, what string) (int, string, error) {
+	if len(args) != 2 {
+		return 0, "", usagef("expected an organization id and a %s", what)
+	}
+	orgID, err := parseID(args[:1], "organization")
+	return orgID, args[1], err
+}
+
+// cmdOrgs manages organizations. Most actions take the organization id as
+// the first argument; changes need the org admin role, and all, create and
+// add-admin the system admin role.
+func cmdOrgs(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "all", "get", "create", "update", "members", "add-member", "add-admin", "set-role", "remove-member", "types", "add-type", "remove-type", "search", "usage")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "orgs "+action)
+	name := fs.String("name", "", "organization name (create, update) or artifact type (add-type)")
+	description := fs.String("description", "", "description (create, update, add-type)")
+	auth := fs.String("auth", "", "allowed sign-in methods, comma-separated: password,ldap,oidc (create, update)")
+	autoJoin := fs.Bool("auto-join", false, "new accounts join the organization (create, update)")
+	email := fs.String("email", "", "user email (add-member, add-admin)")
+	role := fs.String("role", "", "admin or member (add-member, set-role)")
+	limit := fs.Int("limit", 0, "maximum number of hits, 1-200 (search)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	var methods []string
+	if *auth != "" {
+		methods = strings.Split(*auth, ",")
+	}
+
+	switch action {
+	case "list":
+		items, err := c.api.ListOrgs(c.ctx)
+		if err != nil {
+			return err
+		}
+		return printOrgs(c, items)
+	case "all":
+		items, err := c.api.ListAllOrgs(c.ctx)
+		if err != nil {
+			return err
+		}
+		return printOrgs(c, items)
+	case "create":
+		if *name == "" {
+			return usagef("-name is required")
+		}
+		o, err := c.api.CreateOrg(c.ctx, client.OrgSettings{Name: *name, Description: *description, AllowedAuthMethods: methods, AutoJoin: *autoJoin})
+		if err != nil {
+			return err
+		}
+		return printOrgs(c, []client.Organization{*o})
+	case "set-role", "remove-member":
+		orgID, arg, err := orgAndArg(rest, "user id")
+		if err != nil {
+			return err
+		}
+		userID, err := parseID([]string{arg}, "user")
+		if err != nil {
+			return err
+		}
+		if action == "remove-member" {
+			if err := c.api.RemoveOrgMember(c.ctx, orgID, userID); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.stderr, "User %d removed from organization %d and its teams\n", userID, orgID)
+			return nil
+		}
+		if *role == "" {
+			return usagef("-role is required")
+		}
+		m, err := c.api.SetOrgMemberRole(c.ctx, orgID, userID, *role)
+		if err != nil {
+			return err
+		}
+		return printOrgMembers(c, []client.OrgMember{*m})
+	case "remove-type":
+		orgID, typeName, err := orgAndArg(rest, "type name")
+		if err != nil {
+			return err
+		}
+		return c.api.RemoveArtifactType(c.ctx, orgID, typeName)
+	case "search":
+		if len(rest) < 2 {
+			return usagef("orgs search ORG_ID QUERY")
+		}
+		orgID, err := parseID(rest[:1], "organization")
+		if err != nil {
+			return err
+		}
+		hits, err := c.api.SearchOrg(c.ctx, orgID, strings.Join(rest[1:], " "), *limit)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(hits))
+		for _, h := range hits {
+			rows = append(rows, []string{strconv.Itoa(h.ArtifactID), h.Artifact, h.ProjectName, h.TeamName, h.Status, yesNo(h.Certified), h.Match, h.Field})
+		}
+		return c.print(hits, []string{"ARTIFACT_ID", "ARTIFACT", "PROJECT", "TEAM", "STATUS", "CERTIFIED", "MATCH", "FIELD"}, rows)
+	}
+
+	orgID, err := parseID(rest, "organization")
+	if err != nil {
+		return err
+	}
+	switch action {
+	case "get":
+		o, err := c.api.GetOrg(c.ctx, orgID)
+		if err != nil {
+			return err
+		}
+		return printOrgs(c, []client.Organization{*o})
+	case "update":
+		o, err := c.api.GetOrg(c.ctx, orgID)
+		if err != nil {
+			return err
+		}
+		s := client.OrgSettings{Name: o.Name, Description: o.Description, AllowedAuthMethods: o.AllowedAuthMethods, AutoJoin: o.AutoJoin}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name":
+				s.Name = *name
+			case "description":
+				s.Description = *description
+			case "auth":
+				s.AllowedAuthMethods = methods
+			case "auto-join":
+				s.AutoJoin = *autoJoin
+			}
+		})
+		o, err = c.api.UpdateOrg(c.ctx, orgID, s)
+		if err != nil {
+			return err
+		}
+		return printOrgs(c, []client.Organization{*o})
+	case "members":
+		items, err := c.api.OrgMembers(c.ctx, orgID)
+		if err != nil {
+			return err
+		}
+		return printOrgMembers(c, items)
+	case "add-member":
+		if *email == "" {
+			return usagef("-email is required")
+		}
+		m, err := c.api.AddOrgMember(c.ctx, orgID, *email, *role)
+		if err != nil {
+			return err
+		}
+		return printOrgMembers(c, []client.OrgMember{*m})
+	case "add-admin":
+		if *email == "" {
+			return usagef("-email is required")
+		}
+		m, err := c.api.AppointOrgAdmin(c.ctx, orgID, *email)
+		if err != nil {
+			return err
+		}
+		return printOrgMembers(c, []client.OrgMember{*m})
+	case "types", "add-type":
+		if action == "add-type" {
+			if *name == "" {
+				return usagef("-name is required")
+			}
+			if _, err := c.api.AddArtifactType(c.ctx, orgID, *name, *description); err != nil {
+				return err
+			}
+		}
+		items, err := c.api.ArtifactTypes(c.ctx, orgID)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(items))
+		for _, t := range items {
+			rows = append(rows, []string{t.Name, strconv.Itoa(t.InUse), t.Description})
+		}
+		return c.print(items, []string{"TYPE", "IN_USE", "DESCRIPTION"}, rows)
+	default: // usage
+		u, err := c.api.OrgUsage(c.ctx, orgID)
+		if err != nil {
+			return err
+		}
+		if c.output == "table" {
+			fmt.Fprintf(c.stderr, "%d teams, %d users (%d active in 30 days), %d artifacts, %d fields\n", u.Teams, u.Users, u.ActiveUsers, u.Artifacts, u.Fields)
+		}
+		rows := make([][]string, 0, len(u.PerTeam))
+		for _, t := range u.PerTeam {
+			last := "-"
+			if t.LastArtifactAt != nil {
+				last = t.LastArtifactAt.Local().Format("2006-01-02")
+			}
+			rows = append(rows, []string{strconv.Itoa(t.TeamID), t.TeamName, yesNo(t.Archived), strconv.Itoa(t.Members), strconv.Itoa(t.Artifacts), strconv.Itoa(t.Fields), strconv.Itoa(t.Contacts), strconv.Itoa(t.Shares), last})
+		}
+		return c.print(u, []string{"TEAM_ID", "TEAM", "ARCHIVED", "MEMBERS", "ARTIFACTS", "FIELDS", "CONTACTS", "SHARES", "LAST_ARTIFACT"}, rows)
+	}
+}
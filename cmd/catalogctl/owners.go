@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"go-data-catalog/pkg/client"
+)
+
+// ownerRoleNames accepts short role names on the command line.
+var ownerRoleNames = map[string]string{
+	"technical":           client.OwnerTechnical,
+	"business":            client.OwnerBusiness,
+	"steward":             client.OwnerSteward,
+	client.OwnerTechnical: client.OwnerTechnical,
+	client.OwnerBusiness:  client.OwnerBusiness,
+	client.OwnerSteward:   client.OwnerSteward,
+}
+
+func printOwners(c *cli, items []client.ArtifactOwner) error {
+	rows := make([][]string, 0, len(items))
+	for _, o := range items {
+		rows = append(rows, []string{strconv.Itoa(o.ContactID), o.ContactName, o.Role})
+	}
+	return c.print(items, []string{"CONTACT_ID", "NAME", "ROLE"}, rows)
+}
+
+// parseOwners reads CONTACT_ID:ROLE arguments.
+func parseOwners(args []string) ([]client.ArtifactOwner, error) {
+	owners := make([]client.ArtifactOwner, 0, len(args))
+	for _, arg := range args {
+		id, role, ok := strings.Cut(arg, ":")
+		if !ok {
+			role = "technical"
+		}
+		contactID, err := parseID([]string{id}, "contact")
+		if err != nil {
+			return nil, err
+		}
+		full, ok := ownerRoleNames[role]
+		if !ok {
+			return nil, usagef("unknown owner role %q: technical, business or steward", role)
+		}
+		owners = append(owners, client.ArtifactOwner{ContactID: contactID, Role: full})
+	}
+	return owners, nil
+}
+
+// cmdOwners shows and edits the owners of an artifact and lists the
+// artifacts owned by the current user.
+func cmdOwners(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "set", "mine")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "owners "+action)
+	role := fs.String("role", "", "only artifacts where you have this role: technical, business or steward (mine)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if action == "mine" {
+		full := ""
+		if *role != "" {
+			var ok bool
+			if full, ok = ownerRoleNames[*role]; !ok {
+				return usagef("unknown owner role %q: technical, business or steward", *role)
+			}
+		}
+		if err := c.ensureAuth(); err != nil {
+			return err
+		}
+		items, err := c.api.MyArtifacts(c.ctx, full, nil)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(items))
+		for _, a := range items {
+			rows = append(rows, []string{strconv.Itoa(a.TeamID), a.TeamName, strconv.Itoa(a.ID), a.Name, a.ProjectName, strings.Join(a.Roles, ",")})
+		}
+		return c.print(items, []string{"TEAM_ID", "TEAM", "ARTIFACT_ID", "ARTIFACT", "PROJECT", "ROLES"}, rows)
+	}
+
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usagef("usage: owners %s ARTIFACT_ID", action)
+	}
+	id, err := parseID(rest[:1], "artifact")
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	if action == "list" {
+		items, err := c.api.ArtifactOwners(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		return printOwners(c, items)
+	}
+	owners, err := parseOwners(rest[1:])
+	if err != nil {
+		return err
+	}
+	items, err := c.api.SetArtifactOwners(c.ctx, teamID, id, owners)
+	if err != nil {
+		return err
+	}
+	return printOwners(c, items)
+}
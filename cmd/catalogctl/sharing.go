@@ -0,0 +1,189 @@
+package main
+
+import (
+	"strconv"
+
+	"go-data-catalog/pkg/client"
+)
+
+func printShares(c *cli, items []client.ArtifactShare) error {
+	rows := make([][]string, 0, len(items))
+	for _, s := range items {
+		target, status := "all teams", "active"
+		if s.TargetTeamID != nil {
+			target = strconv.Itoa(*s.TargetTeamID) + " " + s.TargetTeamName
+		}
+		if s.RevokedAt != nil {
+			status = "revoked"
+		}
+		rows = append(rows, []string{strconv.Itoa(s.ID), strconv.Itoa(s.ArtifactID), s.ArtifactName, target, status, s.CreatedAt.Format("2006-01-02 15:04")})
+	}
+	return c.print(items, []string{"ID", "ARTIFACT_ID", "ARTIFACT", "SHARED_WITH", "STATUS", "CREATED"}, rows)
+}
+
+func printSharedArtifacts(c *cli, items []client.SharedArtifact) error {
+	rows := make([][]string, 0, len(items))
+	for _, a := range items {
+		rows = append(rows, []string{strconv.Itoa(a.ID), a.Name, a.Type, a.ProjectName, a.TeamName, truncate(a.Description, 50)})
+	}
+	return c.print(items, []string{"ID", "NAME", "TYPE", "PROJECT", "TEAM", "DESCRIPTION"}, rows)
+}
+
+// cmdShares publishes artifacts of the -team team to other teams and lists
+// the artifacts other teams share with it.
+func cmdShares(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "create", "revoke", "shared", "get")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "shares "+action)
+	status := fs.String("status", "active", "status filter (list): active, revoked or empty for all")
+	target := fs.Int("target", 0, "team to share with, 0 for all teams (create)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		items, err := c.api.ListShares(c.ctx, teamID, *status)
+		if err != nil {
+			return err
+		}
+		return printShares(c, items)
+	case "create":
+		id, err := parseID(rest, "artifact")
+		if err != nil {
+			return err
+		}
+		s, err := c.api.ShareArtifact(c.ctx, teamID, id, *target)
+		if err != nil {
+			return err
+		}
+		return printShares(c, []client.ArtifactShare{*s})
+	case "revoke":
+		id, err := parseID(rest, "share")
+		if err != nil {
+			return err
+		}
+		return c.api.RevokeShare(c.ctx, teamID, id)
+	case "shared":
+		items, err := c.api.SharedArtifacts(c.ctx, teamID)
+		if err != nil {
+			return err
+		}
+		return printSharedArtifacts(c, items)
+	default: // get
+		id, err := parseID(rest, "artifact")
+		if err != nil {
+			return err
+		}
+		a, err := c.api.GetSharedArtifact(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		if c.output != "table" {
+			return c.print(a, nil, nil)
+		}
+		if err := printSharedArtifacts(c, []client.SharedArtifact{*a}); err != nil {
+			return err
+		}
+		return printFields(c, a.Fields)
+	}
+}
+
+// printDeprecatedReport prints one row per consumer of a deprecated artifact.
+func printDeprecatedReport(c *cli, items []client.DeprecatedInUse) error {
+	var rows [][]string
+	for _, a := range items {
+		since, replacement := "", ""
+		if a.DeprecatedAt != nil {
+			since = a.DeprecatedAt.Format("2006-01-02")
+		}
+		if a.ReplacementID != nil {
+			replacement = strconv.Itoa(*a.ReplacementID)
+		}
+		for _, e := range a.Consumers {
+			rows = append(rows, []string{strconv.Itoa(a.ID), a.Name, a.Status, since, replacement, e.DownstreamTeamName, strconv.Itoa(e.DownstreamID), e.DownstreamName})
+		}
+	}
+	return c.print(items, []string{"ID", "ARTIFACT", "STATUS", "SINCE", "REPLACEMENT", "CONSUMER_TEAM", "CONSUMER_ID", "CONSUMER"}, rows)
+}
+
+func printLineage(c *cli, l *client.Lineage) error {
+	rows := make([][]string, 0, len(l.Upstream)+len(l.Downstream))
+	for _, e := range l.Upstream {
+		id := "-"
+		if e.UpstreamID != nil {
+			id = strconv.Itoa(*e.UpstreamID)
+		}
+		status := e.UpstreamStatus
+		if e.UpstreamLifecycle != "" && e.UpstreamLifecycle != client.StatusActive {
+			status += ", " + e.UpstreamLifecycle
+		}
+		rows = append(rows, []string{strconv.Itoa(e.ID), "upstream", id, e.UpstreamName, e.UpstreamTeamName, status})
+	}
+	for _, e := range l.Downstream {
+		rows = append(rows, []string{strconv.Itoa(e.ID), "downstream", strconv.Itoa(e.DownstreamID), e.DownstreamName, e.DownstreamTeamName, ""})
+	}
+	return c.print(l, []string{"EDGE", "DIRECTION", "ARTIFACT_ID", "ARTIFACT", "TEAM", "STATUS"}, rows)
+}
+
+// cmdLineage shows and edits where an artifact of the -team team comes from.
+func cmdLineage(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "add", "remove")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "lineage "+action)
+	upstream := fs.Int("upstream", 0, "source artifact id, of this team or shared with it (add)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return usagef("usage: lineage %s ARTIFACT_ID", action)
+	}
+	id, err := parseID(rest[:1], "artifact")
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		l, err := c.api.Lineage(c.ctx, teamID, id)
+		if err != nil {
+			return err
+		}
+		return printLineage(c, l)
+	case "add":
+		if *upstream <= 0 {
+			return usagef("-upstream is required")
+		}
+		e, err := c.api.AddLineage(c.ctx, teamID, id, *upstream)
+		if err != nil {
+			return err
+		}
+		return printLineage(c, &client.Lineage{Upstream: []client.LineageEdge{*e}})
+	default: // remove
+		edgeID, err := parseID(rest[1:], "lineage edge")
+		if err != nil {
+			return err
+		}
+		return c.api.DeleteLineage(c.ctx, teamID, id, edgeID)
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/goccy/go-yaml"
+
+	"go-data-catalog/pkg/client"
+)
+
+// catalogFile is the export/import document. Developers are referenced by
+// contact name rather than id so that files can move between teams.
+type catalogFile struct {
+	Contacts  []fileContact  `json:"contacts,omitempty"`
+	Artifacts []fileArtifact `json:"artifacts"`
+}
+
+type fileContact struct {
+	Name     string `json:"name"`
+	Telegram string `json:"telegram_contact,omitempty"`
+}
+
+type fileArtifact struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Project     string      `json:"project_name"`
+	Description string      `json:"description,omitempty"`
+	Developer   string      `json:"developer,omitempty"`
+	Fields      []fileField `json:"fields,omitempty"`
+}
+
+type fileField struct {
+	Name        string `json:"name"`
+	DataType    string `json:"data_type"`
+	IsPK        bool   `json:"is_pk,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// change is one create/update/delete performed (or planned) by import.
+type change struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+}
+
+func cmdExport(c *cli, args []string) error {
+	fs := newFlagSet(c, "export")
+	out := fs.String("f", "-", "output file, - for stdout")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("export takes no arguments")
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	contacts, err := c.api.ListContacts(c.ctx, teamID, nil)
+	if err != nil {
+		return err
+	}
+	names := map[int]string{}
+	doc := catalogFile{Artifacts: []fileArtifact{}}
+	for _, ct := range contacts {
+		names[ct.ID] = ct.Name
+		doc.Contacts = append(doc.Contacts, fileContact{Name: ct.Name, Telegram: ct.TelegramContact})
+	}
+	for a, err := range c.api.Artifacts(c.ctx, teamID) {
+		if err != nil {
+			return err
+		}
+		fields, err := c.api.ListFields(c.ctx, teamID, a.ID)
+		if err != nil {
+			return err
+		}
+		fa := fileArtifact{Name: a.Name, Type: a.Type, Project: a.ProjectName, Description: a.Description, Developer: names[a.DeveloperID]}
+		for _, f := range fields {
+			fa.Fields = append(fa.Fields, fileField{Name: f.FieldName, DataType: f.DataType, IsPK: f.IsPK, Description: f.Description})
+		}
+		doc.Artifacts = append(doc.Artifacts, fa)
+	}
+	sort.Slice(doc.Artifacts, func(i, j int) bool {
+		if doc.Artifacts[i].Project != doc.Artifacts[j].Project {
+			return doc.Artifacts[i].Project < doc.Artifacts[j].Project
+		}
+		return doc.Artifacts[i].Name < doc.Artifacts[j].Name
+	})
+
+	w := c.stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	// a catalog file has no table form; default to YAML
+	saved, saveOut := c.stdout, c.output
+	defer func() { c.stdout, c.output = saved, saveOut }()
+	c.stdout = w
+	if c.output == "table" {
+		c.output = "yaml"
+	}
+	return c.print(doc, nil, nil)
+}
+
+func readCatalogFile(path string) (*catalogFile, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML, so one decoder handles both
+	var doc catalogFile
+	if err := yaml.UnmarshalWithOptions(data, &doc, yaml.Strict()); err != nil {
+		return nil, usagef("parse %s: %v", path, err)
+	}
+	for i, a := range doc.Artifacts {
+		if a.Name == "" || a.Project == "" {
+			return nil, usagef("artifact #%d: name and project_name are required", i+1)
+		}
+	}
+	return &doc, nil
+}
+
+func cmdImport(c *cli, args []string) error {
+	fs := newFlagSet(c, "import")
+	in := fs.String("f", "", "catalog file (JSON or YAML), - for stdin")
+	prune := fs.Bool("prune", false, "delete fields of imported artifacts that are not in the file")
+	dryRun := fs.Bool("dry-run", false, "print the changes without applying them")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *in == "" || len(rest) > 0 {
+		return usagef("import -f FILE")
+	}
+	doc, err := readCatalogFile(*in)
+	if err != nil {
+		return err
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	changes := []change{}
+	record := func(action, kind, name string) { changes = append(changes, change{action, kind, name}) }
+
+	contacts, err := c.api.ListContacts(c.ctx, teamID, nil)
+	if err != nil {
+		return err
+	}
+	contactIDs := map[string]int{}
+	for _, ct := range contacts {
+		contactIDs[ct.Name] = ct.ID
+	}
+	for _, fc := range doc.Contacts {
+		want := client.Contact{Name: fc.Name, TelegramContact: fc.Telegram}
+		id, ok := contactIDs[fc.Name]
+		if !ok {
+			record("create", "contact", fc.Name)
+			if !*dryRun {
+				created, err := c.api.CreateContact(c.ctx, teamID, want)
+				if err != nil {
+					return fmt.Errorf("contact %q: %w", fc.Name, err)
+				}
+				contactIDs[fc.Name] = created.ID
+			}
+			continue
+		}
+		for _, ct := range contacts {
+			if ct.ID == id && ct.TelegramContact != fc.Telegram {
+				record("update", "contact", fc.Name)
+				if !*dryRun {
+					if _, err := c.api.UpdateContact(c.ctx, teamID, id, want); err != nil {
+						return fmt.Errorf("contact %q: %w", fc.Name, err)
+					}
+				}
+			}
+		}
+	}
+
+	arts, err := c.api.ListArtifacts(c.ctx, teamID, nil)
+	if err != nil {
+		return err
+	}
+	type key struct{ project, name string }
+	existing := map[key]client.Artifact{}
+	for _, a := range arts {
+		existing[key{a.ProjectName, a.Name}] = a
+	}
+	for _, fa := range doc.Artifacts {
+		label := fa.Project + "/" + fa.Name
+		want := client.Artifact{Name: fa.Name, Type: fa.Type, ProjectName: fa.Project, Description: fa.Description}
+		if fa.Developer != "" {
+			id, ok := contactIDs[fa.Developer]
+			if !ok && !*dryRun {
+				return usagef("artifact %s: unknown developer %q", label, fa.Developer)
+			}
+			want.DeveloperID = id
+		}
+		cur, ok := existing[key{fa.Project, fa.Name}]
+		var curFields []client.ArtifactField
+		switch {
+		case !ok:
+			record("create", "artifact", label)
+			if *dryRun {
+				for _, ff := range fa.Fields {
+					record("create", "field", label+"."+ff.Name)
+				}
+				continue
+			}
+			created, err := c.api.CreateArtifact(c.ctx, teamID, want)
+			if err != nil {
+				return fmt.Errorf("artifact %s: %w", label, err)
+			}
+			cur = *created
+		default:
+			if cur.Type != want.Type || cur.Description != want.Description || (fa.Developer != "" && cur.DeveloperID != want.DeveloperID) {
+				if fa.Developer == "" {
+					want.DeveloperID = cur.DeveloperID
+				}
+				record("update", "artifact", label)
+				if !*dryRun {
+					if _, err := c.api.UpdateArtifact(c.ctx, teamID, cur.ID, want); err != nil {
+						return fmt.Errorf("artifact %s: %w", label, err)
+					}
+				}
+			}
+			if curFields, err = c.api.ListFields(c.ctx, teamID, cur.ID); err != nil {
+				return err
+			}
+		}
+
+		byName := map[string]client.ArtifactField{}
+		for _, f := range curFields {
+			byName[f.FieldName] = f
+		}
+		seen := map[string]bool{}
+		for _, ff := range fa.Fields {
+			seen[ff.Name] = true
+			wantField := client.ArtifactField{FieldName: ff.Name, DataType: ff.DataType, IsPK: ff.IsPK, Description: ff.Description}
+			f, ok := byName[ff.Name]
+			switch {
+			case !ok:
+				record("create", "field", label+"."+ff.Name)
+				if !*dryRun {
+					if _, err := c.api.CreateField(c.ctx, teamID, cur.ID, wantField); err != nil {
+						return fmt.Errorf("field %s.%s: %w", label, ff.Name, err)
+					}
+				}
+			case f.DataType != ff.DataType || f.IsPK != ff.IsPK || f.Description != ff.Description:
+				record("update", "field", label+"."+ff.Name)
+				if !*dryRun {
+					if _, err := c.api.UpdateField(c.ctx, teamID, f.ID, wantField); err != nil {
+						return fmt.Errorf("field %s.%s: %w", label, ff.Name, err)
+					}
+				}
+			}
+		}
+		if *prune {
+			for _, f := range curFields {
+				if seen[f.FieldName] {
+					continue
+				}
+				record("delete", "field", label+"."+f.FieldName)
+				if !*dryRun {
+					if err := c.api.DeleteField(c.ctx, teamID, f.ID); err != nil {
+						return fmt.Errorf("field %s.%s: %w", label, f.FieldName, err)
+					}
+				}
+			}
+		}
+	}
+
+	rows := make([][]string, 0, len(changes))
+	for _, ch := range changes {
+		rows = append(rows, []string{ch.Action, ch.Kind, ch.Name})
+	}
+	return c.print(changes, []string{"ACTION", "KIND", "NAME"}, rows)
+}
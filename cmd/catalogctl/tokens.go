@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-data-catalog/pkg/client"
+)
+
+func printTokens(c *cli, tokens []client.APIToken) error {
+	rows := make([][]string, 0, len(tokens))
+	for _, t := range tokens {
+		lastUsed := "never"
+		if t.LastUsedAt != nil {
+			lastUsed = t.LastUsedAt.Format("2006-01-02 15:04") + " " + t.LastUsedIP
+		}
+		status := "active"
+		if t.RevokedAt != nil {
+			status = "revoked"
+		}
+		rows = append(rows, []string{strconv.Itoa(t.ID), t.Name, t.Prefix + "…", strings.Join(t.Scopes, ","), t.ExpiresAt.Format("2006-01-02"), lastUsed, status})
+	}
+	return c.print(tokens, []string{"ID", "NAME", "PREFIX", "SCOPES", "EXPIRES", "LAST USED", "STATUS"}, rows)
+}
+
+// cmdTokens manages personal access tokens, or the tokens of a team service
+// account with -account.
+func cmdTokens(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "create", "revoke")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "tokens "+action)
+	account := fs.Int("account", 0, "service account id (requires -team)")
+	name := fs.String("name", "", "token name (create)")
+	scopes := fs.String("scopes", "read", "comma-separated scopes: read, write (create)")
+	days := fs.Int("expires-in", 0, "lifetime in days, 1-365 (create; default 90)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	teamID := 0
+	if *account > 0 {
+		if teamID, err = c.requireTeam(); err != nil {
+			return err
+		}
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		var tokens []client.APIToken
+		if *account > 0 {
+			tokens, err = c.api.ListServiceAccountTokens(c.ctx, teamID, *account)
+		} else {
+			tokens, err = c.api.ListTokens(c.ctx)
+		}
+		if err != nil {
+			return err
+		}
+		return printTokens(c, tokens)
+	case "create":
+		if *name == "" {
+			return usagef("-name is required")
+		}
+		req := client.TokenRequest{Name: *name, Scopes: strings.Split(*scopes, ","), ExpiresInDays: *days}
+		var t *client.CreatedToken
+		if *account > 0 {
+			t, err = c.api.CreateServiceAccountToken(c.ctx, teamID, *account, req)
+		} else {
+			t, err = c.api.CreateToken(c.ctx, req)
+		}
+		if err != nil {
+			return err
+		}
+		if c.output != "table" {
+			return c.print(t, nil, nil)
+		}
+		fmt.Fprintln(c.stderr, "Token created; it will not be shown again:")
+		fmt.Fprintln(c.stdout, t.Token)
+		return nil
+	default: // revoke
+		id, err := parseID(rest, "token")
+		if err != nil {
+			return err
+		}
+		if *account > 0 {
+			return c.api.RevokeServiceAccountToken(c.ctx, teamID, *account, id)
+		}
+		return c.api.RevokeToken(c.ctx, id)
+	}
+}
+
+func printServiceAccounts(c *cli, items []client.ServiceAccount) error {
+	rows := make([][]string, 0, len(items))
+	for _, sa := range items {
+		status := "active"
+		if !sa.IsActive {
+			status = "inactive"
+		}
+		rows = append(rows, []string{strconv.Itoa(sa.ID), sa.Name, sa.Role, status, sa.CreatedAt.Format("2006-01-02 15:04")})
+	}
+	return c.print(items, []string{"ID", "NAME", "ROLE", "STATUS", "CREATED"}, rows)
+}
+
+func cmdServiceAccounts(c *cli, args []string) error {
+	action, args, err := subcommand(args, "list", "create", "deactivate")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "service-accounts "+action)
+	name := fs.String("name", "", "account name (create)")
+	role := fs.String("role", "member", "team role: admin, member or viewer (create)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	teamID, err := c.requireTeam()
+	if err != nil {
+		return err
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+	switch action {
+	case "list":
+		items, err := c.api.ListServiceAccounts(c.ctx, teamID)
+		if err != nil {
+			return err
+		}
+		return printServiceAccounts(c, items)
+	case "create":
+		if *name == "" {
+			return usagef("-name is required")
+		}
+		sa, err := c.api.CreateServiceAccount(c.ctx, teamID, *name, *role)
+		if err != nil {
+			return err
+		}
+		return printServiceAccounts(c, []client.ServiceAccount{*sa})
+	default: // deactivate
+		id, err := parseID(rest, "service account")
+		if err != nil {
+			return err
+		}
+		return c.api.DeactivateServiceAccount(c.ctx, teamID, id)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cmdTwoFactor manages TOTP two-factor authentication of the current user.
+func cmdTwoFactor(c *cli, args []string) error {
+	action, args, err := subcommand(args, "status", "enroll", "confirm", "disable", "recovery-codes")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(c, "2fa "+action)
+	code := fs.String("code", "", "code from the authenticator app, or a recovery code (confirm, disable, recovery-codes)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("2fa %s takes no arguments", action)
+	}
+	if action != "status" && action != "enroll" && *code == "" {
+		return usagef("-code is required")
+	}
+	if err := c.ensureAuth(); err != nil {
+		return err
+	}
+
+	switch action {
+	case "status":
+		s, err := c.api.TwoFactorStatus(c.ctx)
+		if err != nil {
+			return err
+		}
+		enabled := "disabled"
+		if s.Enabled {
+			enabled = "enabled"
+		}
+		return c.print(s, []string{"2FA", "RECOVERY CODES LEFT"}, [][]string{{enabled, fmt.Sprint(s.RecoveryCodesLeft)}})
+	case "enroll":
+		e, err := c.api.EnrollTwoFactor(c.ctx)
+		if err != nil {
+			return err
+		}
+		if c.output != "table" {
+			return c.print(e, nil, nil)
+		}
+		fmt.Fprintf(c.stdout, "Secret: %s\nURI:    %s\n", e.Secret, e.OTPAuthURI)
+		fmt.Fprintln(c.stderr, "Add the secret to your authenticator app, then run: catalogctl 2fa confirm -code CODE")
+		return nil
+	case "disable":
+		if err := c.api.DisableTwoFactor(c.ctx, *code); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stderr, "Two-factor authentication disabled")
+		return nil
+	}
+
+	var codes []string
+	if action == "confirm" {
+		codes, err = c.api.ConfirmTwoFactor(c.ctx, *code)
+	} else {
+		codes, err = c.api.RegenerateRecoveryCodes(c.ctx, *code)
+	}
+	if err != nil {
+		return err
+	}
+	if c.output != "table" {
+		return c.print(map[string][]string{"recovery_codes": codes}, nil, nil)
+	}
+	fmt.Fprintln(c.stdout, strings.Join(codes, "\n"))
+	fmt.Fprintln(c.stderr, "Store these recovery codes safely; each works once and they are not shown again")
+	return nil
+}
@@ -0,0 +1,53 @@
+// Command mock-ldap is a minimal in-memory LDAP server for local development
+// and manual testing of the catalog's LDAP login. It understands simple
+// bind, subtree search with and/or/not/equality/presence filters, and
+// unbind; nothing else. Users live under ou=people and carry memberOf
+// values for groups under ou=groups. Tests run the same server in-process,
+// see internal/sso/ssotest.
+//
+//	go run ./cmd/mock-ldap -addr :3389 -user 'alice:secret:alice@example.com:Alice:data-eng,analysts'
+//	LDAP_URL=ldap://localhost:3389 LDAP_BASE_DN=dc=example,dc=com \
+//	LDAP_BIND_DN=cn=admin,dc=example,dc=com LDAP_BIND_PASSWORD=admin go run ./cmd/server
+//
+// Do not use it anywhere real: passwords are kept and compared in clear text.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"strings"
+
+	"go-data-catalog/internal/sso/ssotest"
+)
+
+type userFlags []string
+
+func (u *userFlags) String() string     { return strings.Join(*u, " ") }
+func (u *userFlags) Set(v string) error { *u = append(*u, v); return nil }
+
+func main() {
+	addr := flag.String("addr", ":3389", "listen address")
+	base := flag.String("base", "dc=example,dc=com", "base DN")
+	bindPassword := flag.String("bind-password", "admin", "password of cn=admin,<base>")
+	var users userFlags
+	flag.Var(&users, "user", "uid:password:mail:name:group1,group2 (repeatable)")
+	flag.Parse()
+	if len(users) == 0 {
+		users = userFlags{"alice:secret:alice@example.com:Alice Example:data-eng", "bob:secret:bob@example.com:Bob Example:analysts"}
+	}
+
+	d := ssotest.NewDirectory(*base, *bindPassword)
+	for _, u := range users {
+		if err := d.AddUser(u); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("mock LDAP server for %s listening on %s (%d users)", *base, *addr, len(users))
+	log.Fatal(d.Serve(ln))
+}
@@ -0,0 +1,39 @@
+// Command mock-oidc is a minimal OpenID Connect provider for local
+// development and manual testing of the catalog's SSO login. It supports
+// discovery, the authorization code flow with PKCE (S256) and RS256 ID
+// tokens. Every login is approved; the login page lets you choose the email,
+// name and groups to assert. Tests run the same provider in-process, see
+// internal/sso/ssotest.
+//
+//	go run ./cmd/mock-oidc -addr :9000 -groups data-eng
+//	OIDC_ISSUER_URL=http://localhost:9000 OIDC_CLIENT_ID=catalog \
+//	OIDC_REDIRECT_URL=http://localhost:8080/api/v1/auth/oidc/callback go run ./cmd/server
+//
+// Do not use it anywhere real: it authenticates anybody as anyone.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"go-data-catalog/internal/sso/ssotest"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL as seen by the catalog server")
+	clientID := flag.String("client-id", "catalog", "accepted client id")
+	email := flag.String("email", "dev@example.com", "default email on the login page")
+	name := flag.String("name", "Dev User", "default name on the login page")
+	groups := flag.String("groups", "", "default comma-separated groups on the login page")
+	flag.Parse()
+
+	p, err := ssotest.NewOIDCProvider(*issuer, *clientID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p.DefaultEmail, p.DefaultName, p.DefaultGroups = *email, *name, *groups
+	log.Printf("mock OIDC provider %s listening on %s", p.Issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, p))
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/config"
+	"go-data-catalog/internal/handlers"
+	"go-data-catalog/internal/mail"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/openapi"
+	"go-data-catalog/internal/password"
+	"go-data-catalog/internal/repository/postgres"
+	"go-data-catalog/internal/sso"
+	"go-data-catalog/internal/throttle"
+)
+
+// newRouter wires repositories, handlers and routes. It does not touch the
+// database, so tests build it without one.
+func newRouter(cfg *config.Config, db *postgres.DB) (*gin.Engine, error) {
+	// Инициализация репозиториев
+	artifactRepo := postgres.NewArtifactRepository(db)
+	contactRepo := postgres.NewContactRepository(db)
+	artifactFieldRepo := postgres.NewArtifactFieldRepository(db)
+	userRepo := postgres.NewUserRepository(db)
+	teamRepo := postgres.NewTeamRepository(db)
+	memberRepo := postgres.NewTeamMemberRepository(db)
+	joinReqRepo := postgres.NewJoinRequestRepository(db)
+	sessionRepo := postgres.NewSessionRepository(db)
+	apiTokenRepo := postgres.NewAPITokenRepository(db)
+	serviceAccountRepo := postgres.NewServiceAccountRepository(db)
+	identityRepo := postgres.NewIdentityRepository(db)
+	twoFactorRepo := postgres.NewTwoFactorRepository(db)
+	userTokenRepo := postgres.NewUserTokenRepository(db)
+	loginAttemptRepo := postgres.NewLoginAttemptRepository(db)
+	invitationRepo := postgres.NewTeamInvitationRepository(db)
+	notificationRepo := postgres.NewNotificationRepository(db)
+	snapshotRepo := postgres.NewTeamSnapshotRepository(db)
+	shareRepo := postgres.NewShareRepository(db)
+	lineageRepo := postgres.NewLineageRepository(db)
+	orgRepo := postgres.NewOrgRepository(db)
+
+	// Outgoing mail and password policy
+	mailer, err := mail.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail configuration: %w", err)
+	}
+	passwordPolicy, err := password.Load(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password policy: %w", err)
+	}
+
+	// Login brute-force protection
+	var throttleStore throttle.Store
+	switch cfg.LoginThrottleStore {
+	case "postgres":
+		throttleStore = postgres.NewLoginThrottleRepository(db)
+	case "memory":
+		throttleStore = throttle.NewMemoryStore()
+	default:
+		return nil, errors.New("invalid LOGIN_THROTTLE_STORE: want postgres or memory")
+	}
+	loginLimiter := throttle.New(cfg, throttleStore)
+
+	// Single sign-on
+	oidcProvider, err := sso.NewOIDC(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC configuration: %w", err)
+	}
+	oidcGroups, err := sso.ParseGroupMapping(cfg.OIDCGroupMapping)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC_GROUP_MAPPING: %w", err)
+	}
+
+	// Directories consulted by password login after local accounts
+	var passwordBackends []sso.PasswordBackend
+	ldapAuth, err := sso.NewLDAP(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP configuration: %w", err)
+	}
+	if ldapAuth != nil {
+		ldapGroups, err := sso.ParseGroupMapping(cfg.LDAPGroupMapping)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LDAP_GROUP_MAPPING: %w", err)
+		}
+		passwordBackends = append(passwordBackends, sso.PasswordBackend{Name: "LDAP", Authenticator: ldapAuth, Groups: ldapGroups, AllowSignup: cfg.LDAPAllowSignup, TrustEmail: cfg.LDAPTrustEmail})
+	}
+
+	// Инициализация handlers
+	artifactHandler := handlers.NewArtifactHandler(artifactRepo, orgRepo)
+	contactHandler := handlers.NewContactHandler(contactRepo, memberRepo, artifactRepo, teamRepo)
+	artifactFieldHandler := handlers.NewArtifactFieldHandler(artifactFieldRepo, artifactRepo)
+	authHandler := handlers.NewAuthHandler(userRepo, sessionRepo, identityRepo, memberRepo, twoFactorRepo, userTokenRepo, passwordBackends, mailer, passwordPolicy, loginLimiter, loginAttemptRepo, orgRepo, cfg)
+	ssoHandler := handlers.NewSSOHandler(authHandler, oidcProvider, oidcGroups, cfg)
+	usersHandler := handlers.NewUsersHandler(userRepo, orgRepo, sessionRepo, twoFactorRepo, loginAttemptRepo, loginLimiter)
+	twoFactorHandler := handlers.NewTwoFactorHandler(userRepo, twoFactorRepo)
+	tokensHandler := handlers.NewTokensHandler(apiTokenRepo, serviceAccountRepo)
+	teamsHandler := handlers.NewTeamsHandler(teamRepo, memberRepo, joinReqRepo, twoFactorRepo, userRepo, notificationRepo, orgRepo)
+	notificationsHandler := handlers.NewNotificationsHandler(notificationRepo)
+	teamLifecycleHandler := handlers.NewTeamLifecycleHandler(teamRepo, snapshotRepo)
+	sharingHandler := handlers.NewSharingHandler(shareRepo, lineageRepo, artifactRepo, artifactFieldRepo, teamRepo)
+	lifecycleHandler := handlers.NewArtifactLifecycleHandler(artifactRepo, lineageRepo, notificationRepo, teamRepo)
+	orgsHandler := handlers.NewOrgsHandler(orgRepo, artifactRepo)
+	invitationsHandler := handlers.NewInvitationsHandler(invitationRepo, teamRepo, mailer, cfg)
+	docsHandler := handlers.NewDocsHandler(teamRepo, artifactRepo, artifactFieldRepo, contactRepo, lineageRepo)
+	apiSpec := handlers.APISpec()
+	openapiHandler := handlers.NewOpenAPIHandler(apiSpec)
+
+	// Настройка роутера
+	r := gin.New() // Используем New вместо Default чтобы сами настроить middleware
+
+	// Добавляем наши middleware
+	// client IPs for login throttling; X-Forwarded-For only from known proxies
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	r.Use(gin.Recovery()) // Восстановление после паники
+	r.Use(middleware.LoggerMiddleware())
+	r.Use(middleware.ErrorHandlerMiddleware())
+	r.Use(middleware.CORSMiddleware())
+
+	// Health check
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "OK"})
+	})
+
+	// Static files (frontend)
+	r.Static("/static", "./web/static")
+	r.GET("/", func(c *gin.Context) {
+		c.File("./web/static/index.html")
+	})
+
+	// Public auth
+	v1 := r.Group("/api/v1")
+	{
+		v1.POST("/auth/register", authHandler.Register)
+		v1.POST("/auth/login", authHandler.Login)
+		v1.POST("/auth/login/2fa", authHandler.LoginTwoFactor)
+		v1.POST("/auth/refresh", authHandler.Refresh)
+		v1.POST("/auth/verify-email", authHandler.VerifyEmail)
+		v1.POST("/auth/verify-email/resend", authHandler.ResendVerification)
+		v1.POST("/auth/password/forgot", authHandler.ForgotPassword)
+		v1.POST("/auth/password/reset", authHandler.ResetPassword)
+		v1.GET("/auth/providers", ssoHandler.Providers)
+		v1.GET("/auth/oidc/login", ssoHandler.OIDCLogin)
+		v1.GET("/auth/oidc/callback", ssoHandler.OIDCCallback)
+		v1.GET("/openapi.json", openapiHandler.Spec)
+		v1.GET("/docs", openapiHandler.Docs)
+	}
+
+	// Authenticated routes
+	v1auth := r.Group("/api/v1")
+	v1auth.Use(middleware.AuthMiddleware(cfg, sessionRepo, apiTokenRepo))
+	{
+		v1auth.POST("/auth/logout", authHandler.Logout)
+		v1auth.POST("/auth/logout-all", authHandler.LogoutAll)
+
+		// teams discovery/creation
+		v1auth.GET("/teams", teamsHandler.Search)
+		v1auth.POST("/teams", middleware.RequireHuman(), teamsHandler.CreateTeam)
+		v1auth.POST("/teams/:teamId/join", middleware.RequireHuman(), teamsHandler.RequestJoin)
+		v1auth.GET("/me/teams", teamsHandler.MyTeams)
+		v1auth.POST("/invitations/accept", middleware.RequireHuman(), invitationsHandler.Accept)
+		v1auth.GET("/me/join-requests", teamsHandler.MyJoinRequests)
+		v1auth.GET("/me/artifacts", artifactHandler.MyArtifacts)
+		v1auth.POST("/me/join-requests/:id/cancel", teamsHandler.CancelJoinRequest)
+
+		// in-app notifications of the current user
+		v1auth.GET("/me/notifications", notificationsHandler.List)
+		v1auth.POST("/me/notifications/:id/read", notificationsHandler.MarkRead)
+		v1auth.POST("/me/notifications/read-all", notificationsHandler.MarkAllRead)
+
+		// personal access tokens; managing them needs an interactive login
+		myTokens := v1auth.Group("/me/tokens")
+		myTokens.Use(middleware.RequireSession())
+		{
+			myTokens.GET("", tokensHandler.ListMine)
+			myTokens.POST("", tokensHandler.CreateMine)
+			myTokens.DELETE("/:id", tokensHandler.RevokeMine)
+		}
+
+		// directory accounts of the current user; linking needs an interactive login
+		v1auth.POST("/me/identities/ldap", middleware.RequireSession(), authHandler.LinkDirectory)
+
+		// two-factor authentication of the current user
+		my2FA := v1auth.Group("/me/2fa")
+		my2FA.Use(middleware.RequireSession())
+		{
+			my2FA.GET("", twoFactorHandler.Status)
+			my2FA.POST("/enroll", twoFactorHandler.Enroll)
+			my2FA.POST("/confirm", twoFactorHandler.Confirm)
+			my2FA.POST("/disable", twoFactorHandler.Disable)
+			my2FA.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+		}
+
+		// system administration (system_role=admin) is limited to managing
+		// organizations and system roles; users and teams are managed by
+		// org admins under /orgs/:orgId
+		sysAdmin := v1auth.Group("/admin")
+		sysAdmin.Use(middleware.RequireSystemRole("admin"))
+		{
+			sysAdmin.GET("/orgs", orgsHandler.ListAll)
+			sysAdmin.PUT("/orgs/:orgId/admins", orgsHandler.AppointAdmin)
+			sysAdmin.PUT("/users/:id/role", usersHandler.SetRole)
+		}
+
+		// organizations; org admins manage their organization and its users
+		v1auth.GET("/orgs", orgsHandler.List)
+		v1auth.POST("/orgs", middleware.RequireSystemRole("admin"), orgsHandler.Create)
+		org := v1auth.Group("/orgs/:orgId")
+		org.Use(middleware.OrgMembershipMiddleware(orgRepo))
+		{
+			org.GET("", orgsHandler.Get)
+			org.GET("/members", orgsHandler.ListMembers)
+			org.GET("/artifact-types", orgsHandler.ListArtifactTypes)
+			org.GET("/search", orgsHandler.Search)
+
+			orgAdmin := org.Group("")
+			orgAdmin.Use(middleware.RequireOrgAdmin())
+			{
+				orgAdmin.PUT("", orgsHandler.Update)
+				orgAdmin.POST("/members", orgsHandler.AddMember)
+				orgAdmin.PUT("/members/:userId", orgsHandler.SetMemberRole)
+				orgAdmin.DELETE("/members/:userId", orgsHandler.RemoveMember)
+				orgAdmin.POST("/artifact-types", orgsHandler.AddArtifactType)
+				orgAdmin.DELETE("/artifact-types/:name", orgsHandler.RemoveArtifactType)
+				orgAdmin.GET("/usage", orgsHandler.Usage)
+				orgAdmin.GET("/users", usersHandler.List)
+				orgAdmin.GET("/users/:id", usersHandler.Get)
+				orgAdmin.POST("/users/:id/deactivate", usersHandler.Deactivate)
+				orgAdmin.POST("/users/:id/activate", usersHandler.Activate)
+				orgAdmin.DELETE("/users/:id/2fa", usersHandler.ResetTwoFactor)
+				orgAdmin.GET("/users/:id/login-attempts", usersHandler.LoginAttempts)
+				orgAdmin.POST("/users/:id/unlock", usersHandler.Unlock)
+				orgAdmin.GET("/team-snapshots", teamLifecycleHandler.ListSnapshots)
+				orgAdmin.GET("/team-snapshots/:id", teamLifecycleHandler.GetSnapshot)
+				orgAdmin.POST("/team-snapshots/:id/restore", teamLifecycleHandler.RestoreSnapshot)
+				orgAdmin.PUT("/teams/:teamId/owner", middleware.RequireSession(), teamsHandler.ReassignOwner)
+			}
+		}
+
+		// team-scoped routes; access.Routes decides which roles may use each
+		team := v1auth.Group("/teams/:teamId")
+		team.Use(middleware.TeamMembershipMiddleware(memberRepo, teamRepo, orgRepo), middleware.TeamPermissions())
+		{
+			team.GET("/me/permissions", teamsHandler.MyPermissions)
+
+			// rename, archive and delete (owner)
+			team.PUT("", teamLifecycleHandler.Update)
+			team.POST("/archive", teamLifecycleHandler.Archive)
+			team.POST("/unarchive", teamLifecycleHandler.Unarchive)
+			team.DELETE("", middleware.RequireSession(), teamLifecycleHandler.Delete)
+
+			// join requests
+			team.GET("/requests", teamsHandler.ListRequests)
+			team.POST("/requests/:id/:action", teamsHandler.DecideRequest) // action=approve|reject
+
+			// membership management; the owner is changed only by transfer
+			team.PUT("/members/:userId/role", middleware.RequireHuman(), teamsHandler.SetMemberRole)
+			team.PUT("/members/:userId/status", middleware.RequireHuman(), teamsHandler.SetMemberStatus)
+			team.DELETE("/members/:userId", middleware.RequireHuman(), teamsHandler.RemoveMember)
+
+			// invitations
+			team.GET("/invitations", invitationsHandler.List)
+			team.POST("/invitations", middleware.RequireHuman(), invitationsHandler.Create)
+			team.DELETE("/invitations/:id", middleware.RequireHuman(), invitationsHandler.Revoke)
+
+			// service accounts and their tokens
+			serviceAccounts := team.Group("/service-accounts")
+			serviceAccounts.Use(middleware.RequireSession())
+			{
+				serviceAccounts.GET("", tokensHandler.ListServiceAccounts)
+				serviceAccounts.POST("", tokensHandler.CreateServiceAccount)
+				serviceAccounts.DELETE("/:id", tokensHandler.DeactivateServiceAccount)
+				serviceAccounts.GET("/:id/tokens", tokensHandler.ListServiceAccountTokens)
+				serviceAccounts.POST("/:id/tokens", tokensHandler.CreateServiceAccountToken)
+				serviceAccounts.DELETE("/:id/tokens/:tokenId", tokensHandler.RevokeServiceAccountToken)
+			}
+
+			// team security settings
+			team.PUT("/require-2fa", middleware.RequireSession(), teamsHandler.SetRequire2FA)
+			team.PUT("/settings", middleware.RequireSession(), teamsHandler.UpdateSettings)
+
+			// membership
+			team.GET("/members", teamsHandler.ListMembers)
+			team.POST("/leave", middleware.RequireHuman(), teamsHandler.Leave)
+			team.POST("/transfer-ownership", middleware.RequireSession(), teamsHandler.TransferOwnership)
+
+			// static documentation site (zip)
+			team.GET("/docs.zip", docsHandler.DownloadSite)
+
+			// catalog search, including artifacts shared with the team
+			team.GET("/search", sharingHandler.Search)
+
+			// sharing artifacts with other teams
+			team.GET("/shares", sharingHandler.ListShares)
+			team.DELETE("/shares/:id", sharingHandler.RevokeShare)
+			team.GET("/shared-artifacts", sharingHandler.ListShared)
+			team.GET("/shared-artifacts/:id", sharingHandler.GetShared)
+
+			// deprecated artifacts that other artifacts still derive from
+			team.GET("/reports/deprecated", lifecycleHandler.DeprecatedReport)
+
+			// certified artifacts, those to review first
+			team.GET("/certifications", artifactHandler.ListCertifications)
+
+			// artifacts
+			artifacts := team.Group("/artifacts")
+			{
+				artifacts.GET("", artifactHandler.GetArtifacts)
+				artifacts.GET("/:id", artifactHandler.GetArtifactByID)
+				artifacts.POST("", artifactHandler.CreateArtifact)
+				artifacts.PUT("/:id", artifactHandler.UpdateArtifact)
+				artifacts.DELETE("/:id", artifactHandler.DeleteArtifact)
+				artifacts.GET("/:id/render", artifactFieldHandler.RenderArtifact) // format=postgres-ddl|clickhouse-ddl|go-struct|json-schema|avro
+				artifacts.POST("/:id/shares", sharingHandler.Share)
+				artifacts.GET("/:id/lineage", sharingHandler.Lineage)
+				artifacts.POST("/:id/lineage", sharingHandler.AddLineage)
+				artifacts.DELETE("/:id/lineage/:edgeId", sharingHandler.DeleteLineage)
+				artifacts.GET("/:id/owners", artifactHandler.ListOwners)
+				artifacts.PUT("/:id/owners", artifactHandler.SetOwners)
+				artifacts.PUT("/:id/status", lifecycleHandler.SetStatus)
+				artifacts.GET("/:id/certification", artifactHandler.GetCertification)
+				artifacts.PUT("/:id/certification", artifactHandler.Certify)
+				artifacts.DELETE("/:id/certification", artifactHandler.Uncertify)
+				// artifact fields
+				artifactFields := artifacts.Group("/:id/fields")
+				{
+					artifactFields.GET("", artifactFieldHandler.GetFieldsByArtifact)
+					artifactFields.POST("", artifactFieldHandler.CreateField)
+				}
+			}
+
+			// contacts
+			team.GET("/contacts.vcf", contactHandler.DirectoryVCard)
+			contacts := team.Group("/contacts")
+			{
+				contacts.GET("", contactHandler.GetContacts)
+				contacts.GET("/:id", contactHandler.GetContactByID)
+				contacts.POST("", contactHandler.CreateContact)
+				contacts.PUT("/:id", contactHandler.UpdateContact)
+				contacts.DELETE("/:id", contactHandler.DeleteContact)
+				contacts.POST("/:id/reassign", contactHandler.Reassign)
+				contacts.GET("/:id/vcard", contactHandler.VCard)
+			}
+
+			// fields by id
+			fields := team.Group("/fields")
+			{
+				fields.GET("/:id", artifactFieldHandler.GetFieldByID)
+				fields.PUT("/:id", artifactFieldHandler.UpdateField)
+				fields.DELETE("/:id", artifactFieldHandler.DeleteField)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// verifyAPISpec checks that every API route has an entry in
+// handlers.APISpec and that every entry has a route.
+func verifyAPISpec(spec *openapi.Spec, registered gin.RoutesInfo) error {
+	var routes []openapi.Route
+	for _, ri := range registered {
+		// frontend and static files are not part of the API
+		if ri.Path == "/" || strings.HasPrefix(ri.Path, "/static/") {
+			continue
+		}
+		routes = append(routes, openapi.Route{Method: ri.Method, Path: ri.Path})
+	}
+	var problems []string
+	for _, r := range spec.Missing(routes) {
+		problems = append(problems, fmt.Sprintf("route %s %s is not documented in handlers.APISpec", r.Method, r.Path))
+	}
+	for _, op := range spec.Stale(routes) {
+		problems = append(problems, fmt.Sprintf("documented operation %s %s is not registered", op.Method, op.Path))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// verifyTeamPermissions checks that every team route has a permission in
+// access.Routes and that every entry has a route.
+func verifyTeamPermissions(registered gin.RoutesInfo) error {
+	var routes []string
+	for _, ri := range registered {
+		routes = append(routes, ri.Method+" "+ri.Path)
+	}
+	missing, stale := access.Check(routes)
+	var problems []string
+	for _, r := range missing {
+		problems = append(problems, fmt.Sprintf("team route %s has no entry in access.Routes", r))
+	}
+	for _, r := range stale {
+		problems = append(problems, fmt.Sprintf("access.Routes entry %s is not registered", r))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/gin-gonic/gin"
+
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/config"
+	"go-data-catalog/internal/handlers"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+// testRouter builds the server's router with SSO disabled and without a
+// database; handlers are never called.
+func testRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	for k, v := range map[string]string{
+		"DB_HOST": "localhost", "DB_PORT": "5432", "DB_USER": "test", "DB_PASSWORD": "test", "DB_NAME": "test",
+		"SERVER_PORT": "8080", "JWT_SECRET": "test", "TOKEN_TTL": "15",
+		"OIDC_ISSUER_URL": "", "LDAP_URL": "", "LOGIN_THROTTLE_STORE": "memory", "MAIL_DRIVER": "log",
+	} {
+		t.Setenv(k, v)
+	}
+	var cfg config.Config
+	if err := env.Parse(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	r, err := newRouter(&cfg, &postgres.DB{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestAPISpecCoversRoutes(t *testing.T) {
+	r := testRouter(t)
+	if err := verifyAPISpec(handlers.APISpec(), r.Routes()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTeamRoutesHavePermissions(t *testing.T) {
+	r := testRouter(t)
+	if err := verifyTeamPermissions(r.Routes()); err != nil {
+		t.Error(err)
+	}
+}
+
+// roles are the team roles from most to least privileged; "" is a request
+// that got no team role at all.
+var roles = []string{access.RoleOwner, access.RoleAdmin, access.RoleMember, access.RoleViewer, access.RoleGuest, ""}
+
+// teamRoutes lists every team route under access.TeamPrefix with the least
+// privileged role allowed to use it; the roles after it are denied.
+var teamRoutes = []struct {
+	method, path, least string
+}{
+	{"PUT", "", access.RoleOwner},
+	{"DELETE", "", access.RoleOwner},
+	{"POST", "/archive", access.RoleOwner},
+	{"POST", "/unarchive", access.RoleOwner},
+	{"PUT", "/settings", access.RoleOwner},
+	{"PUT", "/require-2fa", access.RoleOwner},
+	{"POST", "/transfer-ownership", access.RoleOwner},
+	{"POST", "/leave", access.RoleViewer},
+	{"GET", "/me/permissions", access.RoleGuest},
+	{"GET", "/docs.zip", access.RoleViewer},
+
+	{"GET", "/members", access.RoleViewer},
+	{"PUT", "/members/:userId/role", access.RoleAdmin},
+	{"PUT", "/members/:userId/status", access.RoleAdmin},
+	{"DELETE", "/members/:userId", access.RoleAdmin},
+	{"GET", "/requests", access.RoleAdmin},
+	{"POST", "/requests/:id/:action", access.RoleAdmin},
+	{"GET", "/invitations", access.RoleAdmin},
+	{"POST", "/invitations", access.RoleAdmin},
+	{"DELETE", "/invitations/:id", access.RoleAdmin},
+
+	{"GET", "/service-accounts", access.RoleAdmin},
+	{"POST", "/service-accounts", access.RoleAdmin},
+	{"DELETE", "/service-accounts/:id", access.RoleAdmin},
+	{"GET", "/service-accounts/:id/tokens", access.RoleAdmin},
+	{"POST", "/service-accounts/:id/tokens", access.RoleAdmin},
+	{"DELETE", "/service-accounts/:id/tokens/:tokenId", access.RoleAdmin},
+
+	{"GET", "/artifacts", access.RoleGuest},
+	{"POST", "/artifacts", access.RoleMember},
+	{"GET", "/artifacts/:id", access.RoleGuest},
+	{"PUT", "/artifacts/:id", access.RoleMember},
+	{"DELETE", "/artifacts/:id", access.RoleMember},
+	{"GET", "/artifacts/:id/render", access.RoleGuest},
+	{"GET", "/artifacts/:id/owners", access.RoleViewer},
+	{"PUT", "/artifacts/:id/owners", access.RoleMember},
+	{"PUT", "/artifacts/:id/status", access.RoleMember},
+	{"GET", "/reports/deprecated", access.RoleGuest},
+	{"GET", "/artifacts/:id/certification", access.RoleGuest},
+	{"PUT", "/artifacts/:id/certification", access.RoleMember},
+	{"DELETE", "/artifacts/:id/certification", access.RoleMember},
+	{"GET", "/certifications", access.RoleGuest},
+
+	{"GET", "/search", access.RoleGuest},
+	{"GET", "/shares", access.RoleViewer},
+	{"POST", "/artifacts/:id/shares", access.RoleAdmin},
+	{"DELETE", "/shares/:id", access.RoleAdmin},
+	{"GET", "/shared-artifacts", access.RoleViewer},
+	{"GET", "/shared-artifacts/:id", access.RoleViewer},
+	{"GET", "/artifacts/:id/lineage", access.RoleViewer},
+	{"POST", "/artifacts/:id/lineage", access.RoleMember},
+	{"DELETE", "/artifacts/:id/lineage/:edgeId", access.RoleMember},
+
+	{"GET", "/artifacts/:id/fields", access.RoleGuest},
+	{"POST", "/artifacts/:id/fields", access.RoleMember},
+	{"GET", "/fields/:id", access.RoleGuest},
+	{"PUT", "/fields/:id", access.RoleMember},
+	{"DELETE", "/fields/:id", access.RoleMember},
+
+	{"GET", "/contacts", access.RoleViewer},
+	{"POST", "/contacts", access.RoleMember},
+	{"GET", "/contacts/:id", access.RoleViewer},
+	{"PUT", "/contacts/:id", access.RoleMember},
+	{"DELETE", "/contacts/:id", access.RoleMember},
+	{"POST", "/contacts/:id/reassign", access.RoleMember},
+	{"GET", "/contacts/:id/vcard", access.RoleViewer},
+	{"GET", "/contacts.vcf", access.RoleViewer},
+}
+
+// TestTeamRoutePermissions sends a request as every role to each team route
+// of the router through middleware.TeamPermissions.
+func TestTeamRoutePermissions(t *testing.T) {
+	want := map[string]string{}
+	for _, tr := range teamRoutes {
+		want[tr.method+" "+access.TeamPrefix+tr.path] = tr.least
+	}
+
+	// the membership middleware needs a database; set the role from a header
+	probe := gin.New()
+	setRole := func(c *gin.Context) {
+		if role := c.GetHeader("X-Team-Role"); role != "" {
+			c.Set(middleware.CtxTeamRole, role)
+		}
+	}
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	var routes gin.RoutesInfo
+	for _, ri := range testRouter(t).Routes() {
+		if ri.Path != access.TeamPrefix && !strings.HasPrefix(ri.Path, access.TeamPrefix+"/") || ri.Path == access.TeamPrefix+"/join" {
+			continue
+		}
+		probe.Handle(ri.Method, ri.Path, setRole, middleware.TeamPermissions(), ok)
+		routes = append(routes, ri)
+	}
+
+	for _, ri := range routes {
+		key := ri.Method + " " + ri.Path
+		least, listed := want[key]
+		if !listed {
+			t.Errorf("%s is missing from teamRoutes", key)
+			continue
+		}
+		delete(want, key)
+		t.Run(key, func(t *testing.T) {
+			allowed := true
+			for _, role := range roles {
+				req := httptest.NewRequest(ri.Method, concretePath(ri.Path), nil)
+				req.Header.Set("X-Team-Role", role)
+				w := httptest.NewRecorder()
+				probe.ServeHTTP(w, req)
+				if got := w.Code == http.StatusNoContent; got != allowed {
+					t.Errorf("role %q: allowed=%v, want %v (status %d)", role, got, allowed, w.Code)
+				}
+				if role == least {
+					allowed = false
+				}
+			}
+		})
+	}
+	for key := range want {
+		t.Errorf("teamRoutes entry %s is not registered", key)
+	}
+}
+
+// concretePath fills the path parameters of a route with 1.
+func concretePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			parts[i] = "1"
+		}
+	}
+	return strings.Join(parts, "/")
+}
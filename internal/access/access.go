@@ -0,0 +1,152 @@
+// Package access holds the permission matrix of team roles: which role may
+// perform which action on which resource of a team.
+package access
+
+import "sort"
+
+// Team roles, from most to least privileged.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+	// RoleGuest is given to non-members browsing a team with a public
+	// catalog; it is never stored in team_members.
+	RoleGuest = "guest"
+)
+
+// Resource is a kind of team data.
+type Resource string
+
+const (
+	Team            Resource = "team"
+	Members         Resource = "members"
+	JoinRequests    Resource = "join_requests"
+	Invitations     Resource = "invitations"
+	ServiceAccounts Resource = "service_accounts"
+	Artifacts       Resource = "artifacts"
+	Fields          Resource = "fields"
+	Contacts        Resource = "contacts"
+	// Docs is the generated documentation site, which includes contacts.
+	Docs Resource = "docs"
+	// Shares covers publishing artifacts to other teams and reading the
+	// artifacts other teams share.
+	Shares  Resource = "shares"
+	Lineage Resource = "lineage"
+	// Certifications mark trusted artifacts. Update lets a member certify
+	// the artifacts they are a data steward of; Manage covers all artifacts.
+	Certifications Resource = "certifications"
+)
+
+// Action is what is done with a resource.
+type Action string
+
+const (
+	Read   Action = "read"
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+	// Manage covers administration that is not plain editing: deciding join
+	// requests, inviting and changing members, issuing service account tokens.
+	Manage Action = "manage"
+	// Leave and Transfer apply to the team only.
+	Leave    Action = "leave"
+	Transfer Action = "transfer"
+)
+
+// Permission is one action on one resource.
+type Permission struct {
+	Resource Resource
+	Action   Action
+}
+
+var (
+	everyone = []string{RoleOwner, RoleAdmin, RoleMember, RoleViewer}
+	editors  = []string{RoleOwner, RoleAdmin, RoleMember}
+	admins   = []string{RoleOwner, RoleAdmin}
+	owner    = []string{RoleOwner}
+	// readers may browse the catalog; guests see artifacts and fields only.
+	readers = []string{RoleOwner, RoleAdmin, RoleMember, RoleViewer, RoleGuest}
+)
+
+// matrix lists the roles allowed for each permission. Anything not listed is
+// denied.
+var matrix = map[Permission][]string{
+	{Team, Read}:     readers,
+	{Team, Update}:   owner,
+	{Team, Leave}:    everyone,
+	{Team, Transfer}: owner,
+	{Team, Delete}:   owner,
+
+	{Members, Read}:   everyone,
+	{Members, Manage}: admins,
+
+	{JoinRequests, Read}:   admins,
+	{JoinRequests, Manage}: admins,
+
+	{Invitations, Read}:   admins,
+	{Invitations, Manage}: admins,
+
+	{ServiceAccounts, Read}:   admins,
+	{ServiceAccounts, Manage}: admins,
+
+	{Artifacts, Read}:   readers,
+	{Artifacts, Create}: editors,
+	{Artifacts, Update}: editors,
+	{Artifacts, Delete}: editors,
+
+	{Fields, Read}:   readers,
+	{Fields, Create}: editors,
+	{Fields, Update}: editors,
+	{Fields, Delete}: editors,
+
+	{Contacts, Read}:   everyone,
+	{Contacts, Create}: editors,
+	{Contacts, Update}: editors,
+	{Contacts, Delete}: editors,
+
+	{Docs, Read}: everyone,
+
+	{Shares, Read}:   everyone,
+	{Shares, Manage}: admins,
+
+	{Lineage, Read}:   everyone,
+	{Lineage, Create}: editors,
+	{Lineage, Delete}: editors,
+
+	{Certifications, Read}:   readers,
+	{Certifications, Update}: editors,
+	{Certifications, Manage}: admins,
+}
+
+// Allowed reports whether the team role may perform the action.
+func Allowed(role string, p Permission) bool {
+	for _, r := range matrix[p] {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedArchived reports whether the permission may be used in an archived
+// team. Archived teams are read-only except for the team itself, so that it
+// can be unarchived, deleted or left.
+func AllowedArchived(p Permission) bool {
+	return p.Action == Read || p.Resource == Team
+}
+
+// For returns the actions the role may perform, by resource. Resources the
+// role cannot touch at all are left out.
+func For(role string, archived bool) map[Resource][]Action {
+	res := map[Resource][]Action{}
+	for p := range matrix {
+		if Allowed(role, p) && (!archived || AllowedArchived(p)) {
+			res[p.Resource] = append(res[p.Resource], p.Action)
+		}
+	}
+	for _, actions := range res {
+		sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	}
+	return res
+}
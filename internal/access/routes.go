@@ -0,0 +1,113 @@
+package access
+
+import "strings"
+
+// TeamPrefix is the path prefix of team-scoped routes.
+const TeamPrefix = "/api/v1/teams/:teamId"
+
+// Routes maps every team-scoped route ("METHOD path") to the permission it
+// needs. The cmd/server tests fail if a team route is missing here, and the
+// permission middleware denies routes it does not know.
+var Routes = map[string]Permission{
+	"PUT " + TeamPrefix:                          {Team, Update},
+	"DELETE " + TeamPrefix:                       {Team, Delete},
+	"POST " + TeamPrefix + "/archive":            {Team, Update},
+	"POST " + TeamPrefix + "/unarchive":          {Team, Update},
+	"GET " + TeamPrefix + "/me/permissions":      {Team, Read},
+	"PUT " + TeamPrefix + "/settings":            {Team, Update},
+	"PUT " + TeamPrefix + "/require-2fa":         {Team, Update},
+	"POST " + TeamPrefix + "/leave":              {Team, Leave},
+	"POST " + TeamPrefix + "/transfer-ownership": {Team, Transfer},
+	"GET " + TeamPrefix + "/docs.zip":            {Docs, Read},
+
+	"GET " + TeamPrefix + "/members":                {Members, Read},
+	"PUT " + TeamPrefix + "/members/:userId/role":   {Members, Manage},
+	"PUT " + TeamPrefix + "/members/:userId/status": {Members, Manage},
+	"DELETE " + TeamPrefix + "/members/:userId":     {Members, Manage},
+	"GET " + TeamPrefix + "/requests":               {JoinRequests, Read},
+	"POST " + TeamPrefix + "/requests/:id/:action":  {JoinRequests, Manage},
+	"GET " + TeamPrefix + "/invitations":            {Invitations, Read},
+	"POST " + TeamPrefix + "/invitations":           {Invitations, Manage},
+	"DELETE " + TeamPrefix + "/invitations/:id":     {Invitations, Manage},
+
+	"GET " + TeamPrefix + "/service-accounts":                        {ServiceAccounts, Read},
+	"POST " + TeamPrefix + "/service-accounts":                       {ServiceAccounts, Manage},
+	"DELETE " + TeamPrefix + "/service-accounts/:id":                 {ServiceAccounts, Manage},
+	"GET " + TeamPrefix + "/service-accounts/:id/tokens":             {ServiceAccounts, Read},
+	"POST " + TeamPrefix + "/service-accounts/:id/tokens":            {ServiceAccounts, Manage},
+	"DELETE " + TeamPrefix + "/service-accounts/:id/tokens/:tokenId": {ServiceAccounts, Manage},
+
+	"GET " + TeamPrefix + "/artifacts":                      {Artifacts, Read},
+	"GET " + TeamPrefix + "/artifacts/:id":                  {Artifacts, Read},
+	"GET " + TeamPrefix + "/artifacts/:id/render":           {Artifacts, Read},
+	"POST " + TeamPrefix + "/artifacts":                     {Artifacts, Create},
+	"PUT " + TeamPrefix + "/artifacts/:id":                  {Artifacts, Update},
+	"DELETE " + TeamPrefix + "/artifacts/:id":               {Artifacts, Delete},
+	"GET " + TeamPrefix + "/artifacts/:id/owners":           {Contacts, Read},
+	"PUT " + TeamPrefix + "/artifacts/:id/owners":           {Artifacts, Update},
+	"PUT " + TeamPrefix + "/artifacts/:id/status":           {Artifacts, Update},
+	"GET " + TeamPrefix + "/reports/deprecated":             {Artifacts, Read},
+	"GET " + TeamPrefix + "/artifacts/:id/certification":    {Certifications, Read},
+	"PUT " + TeamPrefix + "/artifacts/:id/certification":    {Certifications, Update},
+	"DELETE " + TeamPrefix + "/artifacts/:id/certification": {Certifications, Update},
+	"GET " + TeamPrefix + "/certifications":                 {Certifications, Read},
+
+	"GET " + TeamPrefix + "/search":                           {Artifacts, Read},
+	"GET " + TeamPrefix + "/shares":                           {Shares, Read},
+	"POST " + TeamPrefix + "/artifacts/:id/shares":            {Shares, Manage},
+	"DELETE " + TeamPrefix + "/shares/:id":                    {Shares, Manage},
+	"GET " + TeamPrefix + "/shared-artifacts":                 {Shares, Read},
+	"GET " + TeamPrefix + "/shared-artifacts/:id":             {Shares, Read},
+	"GET " + TeamPrefix + "/artifacts/:id/lineage":            {Lineage, Read},
+	"POST " + TeamPrefix + "/artifacts/:id/lineage":           {Lineage, Create},
+	"DELETE " + TeamPrefix + "/artifacts/:id/lineage/:edgeId": {Lineage, Delete},
+
+	"GET " + TeamPrefix + "/artifacts/:id/fields":  {Fields, Read},
+	"POST " + TeamPrefix + "/artifacts/:id/fields": {Fields, Create},
+	"GET " + TeamPrefix + "/fields/:id":            {Fields, Read},
+	"PUT " + TeamPrefix + "/fields/:id":            {Fields, Update},
+	"DELETE " + TeamPrefix + "/fields/:id":         {Fields, Delete},
+
+	"GET " + TeamPrefix + "/contacts":               {Contacts, Read},
+	"GET " + TeamPrefix + "/contacts/:id":           {Contacts, Read},
+	"POST " + TeamPrefix + "/contacts":              {Contacts, Create},
+	"PUT " + TeamPrefix + "/contacts/:id":           {Contacts, Update},
+	"DELETE " + TeamPrefix + "/contacts/:id":        {Contacts, Delete},
+	"POST " + TeamPrefix + "/contacts/:id/reassign": {Artifacts, Update},
+	"GET " + TeamPrefix + "/contacts/:id/vcard":     {Contacts, Read},
+	"GET " + TeamPrefix + "/contacts.vcf":           {Contacts, Read},
+}
+
+// nonMember lists routes under TeamPrefix that are open to users outside the
+// team and so have no entry in Routes.
+var nonMember = map[string]bool{
+	"POST " + TeamPrefix + "/join": true,
+}
+
+// Route returns the permission of a registered route.
+func Route(method, path string) (Permission, bool) {
+	p, ok := Routes[method+" "+path]
+	return p, ok
+}
+
+// Check compares Routes with the registered routes ("METHOD path") and
+// returns team routes without a permission and entries without a route.
+func Check(registered []string) (missing, stale []string) {
+	seen := map[string]bool{}
+	for _, r := range registered {
+		seen[r] = true
+		_, path, _ := strings.Cut(r, " ")
+		if path != TeamPrefix && !strings.HasPrefix(path, TeamPrefix+"/") || nonMember[r] {
+			continue
+		}
+		if _, ok := Routes[r]; !ok {
+			missing = append(missing, r)
+		}
+	}
+	for r := range Routes {
+		if !seen[r] {
+			stale = append(stale, r)
+		}
+	}
+	return missing, stale
+}
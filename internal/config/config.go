@@ -18,7 +18,70 @@ type Config struct {
 	ServerPort string `env:"SERVER_PORT,required"`
 
 	JWTSecret  string `env:"JWT_SECRET,required"`
-	TokenTTL   int    `env:"TOKEN_TTL,required"` // minutes
+	TokenTTL   int    `env:"TOKEN_TTL,required"` // minutes, access token
+	RefreshTokenTTL int `env:"REFRESH_TOKEN_TTL" envDefault:"43200"` // minutes, refresh token (30 days)
+
+	// OpenID Connect single sign-on; disabled when OIDC_ISSUER_URL is empty
+	OIDCIssuerURL    string   `env:"OIDC_ISSUER_URL"`
+	OIDCClientID     string   `env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string   `env:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL  string   `env:"OIDC_REDIRECT_URL"` // e.g. https://catalog.example.com/api/v1/auth/oidc/callback
+	OIDCScopes       []string `env:"OIDC_SCOPES" envSeparator:"," envDefault:"openid,email,profile"`
+	OIDCGroupsClaim  string   `env:"OIDC_GROUPS_CLAIM" envDefault:"groups"`
+	OIDCGroupMapping string   `env:"OIDC_GROUP_MAPPING"` // "idp-group=teamId:role,..."
+	OIDCAllowSignup  bool     `env:"OIDC_ALLOW_SIGNUP" envDefault:"true"`
+	OIDCPostLoginURL string   `env:"OIDC_POST_LOGIN_URL" envDefault:"/"` // browser is sent here with tokens in the URL fragment
+
+	// LDAP / Active Directory password login; disabled when LDAP_URL is empty
+	LDAPURL            string `env:"LDAP_URL"` // ldap://host:389 or ldaps://host:636
+	LDAPStartTLS       bool   `env:"LDAP_START_TLS"`
+	LDAPBindDN         string `env:"LDAP_BIND_DN"` // service account used to look users up; empty for anonymous search
+	LDAPBindPassword   string `env:"LDAP_BIND_PASSWORD"`
+	LDAPBaseDN         string `env:"LDAP_BASE_DN"`
+	LDAPUserFilter     string `env:"LDAP_USER_FILTER" envDefault:"(|(mail={login})(uid={login}))"` // AD: (|(mail={login})(sAMAccountName={login}))
+	LDAPEmailAttribute string `env:"LDAP_EMAIL_ATTRIBUTE" envDefault:"mail"`
+	LDAPNameAttribute  string `env:"LDAP_NAME_ATTRIBUTE" envDefault:"cn"`
+	LDAPGroupAttribute string `env:"LDAP_GROUP_ATTRIBUTE" envDefault:"memberOf"`
+	LDAPGroupMapping   string `env:"LDAP_GROUP_MAPPING"` // "group-cn=teamId:role,..."
+	LDAPAllowSignup    bool   `env:"LDAP_ALLOW_SIGNUP" envDefault:"true"`
+	LDAPTrustEmail     bool   `env:"LDAP_TRUST_EMAIL"` // link to local accounts by email; only if users cannot edit their mail attribute
+
+	// Public URL of the web UI; links in emails point here
+	AppURL string `env:"APP_URL" envDefault:"http://localhost:8080/"`
+
+	// Outgoing mail: MAIL_DRIVER is smtp, file (one .eml per message in MAIL_FILE_DIR) or log
+	MailDriver      string `env:"MAIL_DRIVER" envDefault:"log"`
+	MailFrom        string `env:"MAIL_FROM" envDefault:"Go Data Catalog <noreply@localhost>"`
+	MailFileDir     string `env:"MAIL_FILE_DIR" envDefault:"mail"`
+	SMTPHost        string `env:"SMTP_HOST"`
+	SMTPPort        int    `env:"SMTP_PORT" envDefault:"587"`
+	SMTPUsername    string `env:"SMTP_USERNAME"`
+	SMTPPassword    string `env:"SMTP_PASSWORD"`
+	SMTPImplicitTLS bool   `env:"SMTP_IMPLICIT_TLS"` // TLS from the first byte, usually port 465
+
+	// Password policy and account emails
+	PasswordMinLength        int    `env:"PASSWORD_MIN_LENGTH" envDefault:"8"`
+	PasswordBreachedList     string `env:"PASSWORD_BREACHED_LIST"` // file of passwords or SHA-1 hashes, one per line
+	RequireEmailVerification bool   `env:"REQUIRE_EMAIL_VERIFICATION"`
+	EmailVerificationTTL     int    `env:"EMAIL_VERIFICATION_TTL" envDefault:"2880"` // minutes (48 hours)
+	PasswordResetTTL         int    `env:"PASSWORD_RESET_TTL" envDefault:"60"`       // minutes
+
+	// Brute-force protection of password and 2FA login. Failures are counted
+	// per account and per client IP; past the free attempts each failure
+	// doubles the delay, and the lockout threshold blocks for a while.
+	LoginThrottleStore     string `env:"LOGIN_THROTTLE_STORE" envDefault:"postgres"` // postgres (shared by all instances) or memory
+	LoginFreeAttempts      int    `env:"LOGIN_FREE_ATTEMPTS" envDefault:"5"`
+	LoginLockoutAttempts   int    `env:"LOGIN_LOCKOUT_ATTEMPTS" envDefault:"10"`
+	LoginLockoutDuration   int    `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15"` // minutes
+	LoginIPFreeAttempts    int    `env:"LOGIN_IP_FREE_ATTEMPTS" envDefault:"20"`
+	LoginIPLockoutAttempts int    `env:"LOGIN_IP_LOCKOUT_ATTEMPTS" envDefault:"100"`
+	LoginBackoffBase       int    `env:"LOGIN_BACKOFF_BASE" envDefault:"1"`      // seconds
+	LoginBackoffMax        int    `env:"LOGIN_BACKOFF_MAX" envDefault:"300"`     // seconds
+	LoginFailureWindow     int    `env:"LOGIN_FAILURE_WINDOW" envDefault:"60"`   // minutes without failures that reset the counters
+
+	// Proxies whose X-Forwarded-For is believed when taking the client IP;
+	// empty means the connection address is used
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:","`
 }
 
 func Load() *Config {
@@ -0,0 +1,44 @@
+(function () {
+  const input = document.getElementById('search');
+  const list = document.getElementById('search-results');
+  const root = document.body.dataset.root || '';
+  const index = window.CATALOG_SEARCH_INDEX || [];
+  const kinds = { project: 'проект', artifact: 'артефакт', contact: 'контакт', term: 'термин' };
+
+  function search(q) {
+    const terms = q.toLowerCase().split(/\s+/).filter(Boolean);
+    if (!terms.length) return [];
+    const scored = [];
+    for (const e of index) {
+      const title = e.t.toLowerCase();
+      const hay = (title + ' ' + (e.p || '') + ' ' + (e.d || '')).toLowerCase();
+      if (!terms.every(t => hay.includes(t))) continue;
+      // title hits first, then exact-prefix title hits
+      let score = terms.filter(t => title.includes(t)).length * 2;
+      if (title.startsWith(terms[0])) score += 1;
+      scored.push([score, e]);
+    }
+    scored.sort((a, b) => b[0] - a[0] || a[1].t.localeCompare(b[1].t));
+    return scored.slice(0, 30).map(s => s[1]);
+  }
+
+  input.addEventListener('input', () => {
+    const results = search(input.value);
+    list.innerHTML = '';
+    for (const e of results) {
+      const li = document.createElement('li');
+      const a = document.createElement('a');
+      a.href = root + e.u;
+      a.textContent = e.t;
+      const small = document.createElement('small');
+      small.textContent = kinds[e.k] + (e.p ? ' · ' + e.p : '');
+      a.appendChild(small);
+      li.appendChild(a);
+      list.appendChild(li);
+    }
+    list.classList.toggle('hidden', results.length === 0);
+  });
+  input.addEventListener('keydown', ev => {
+    if (ev.key === 'Escape') { input.value = ''; list.classList.add('hidden'); }
+  });
+})();
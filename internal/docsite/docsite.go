@@ -0,0 +1,384 @@
+// Package docsite renders a team's catalog into a self-contained static HTML
+// site packaged as a zip archive, so it can be published on a wiki or file
+// share for people without a catalog account.
+package docsite
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+//go:embed templates/*.html assets/*
+var files embed.FS
+
+var tmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"contact": func(contacts map[int]models.Contact, id int) *models.Contact {
+		if c, ok := contacts[id]; ok {
+			return &c
+		}
+		return nil
+	},
+	"ownerRole": func(role string) string {
+		return ownerRoleLabels[role]
+	},
+	"statusLabel": func(status string) string {
+		return statusLabels[status]
+	},
+	"channel": func(ch models.ContactChannel) template.HTML {
+		v := template.HTMLEscapeString(ch.Value)
+		switch ch.Kind {
+		case models.ChannelEmail:
+			return template.HTML(`<a href="mailto:` + v + `">` + v + `</a>`)
+		case models.ChannelPhone:
+			return template.HTML(`<a href="tel:` + template.HTMLEscapeString(strings.ReplaceAll(ch.Value, " ", "")) + `">` + v + `</a>`)
+		case models.ChannelTelegram:
+			return template.HTML(`<a href="https://t.me/` + template.HTMLEscapeString(strings.TrimPrefix(ch.Value, "@")) + `">` + v + `</a>`)
+		}
+		return template.HTML(channelLabels[ch.Kind] + " " + v)
+	},
+}).ParseFS(files, "templates/*.html"))
+
+var ownerRoleLabels = map[string]string{
+	models.OwnerTechnical: "Технический владелец",
+	models.OwnerBusiness:  "Бизнес-владелец",
+	models.OwnerSteward:   "Стюард данных",
+}
+
+var statusLabels = map[string]string{
+	models.StatusDraft:      "черновик",
+	models.StatusDeprecated: "устарел",
+	models.StatusRetired:    "выведен из эксплуатации",
+}
+
+var channelLabels = map[string]string{
+	models.ChannelSlack:      "Slack",
+	models.ChannelMattermost: "Mattermost",
+}
+
+// Catalog is everything the site is rendered from.
+type Catalog struct {
+	Team        models.Team
+	Artifacts   []models.Artifact
+	Fields      map[int][]models.ArtifactField
+	Contacts    []models.Contact
+	Owners      map[int][]models.ArtifactOwner
+	Lineage     []models.LineageEdge // edges into and out of the team's artifacts
+	GeneratedAt time.Time
+}
+
+// Load reads a team's catalog from the database.
+func Load(ctx context.Context, teams *postgres.TeamRepository, artifacts *postgres.ArtifactRepository, fields *postgres.ArtifactFieldRepository, contacts *postgres.ContactRepository, lineage *postgres.LineageRepository, teamID int) (*Catalog, error) {
+	team, err := teams.GetByID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("load team: %w", err)
+	}
+	arts, err := artifacts.GetAllArtifacts(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("load artifacts: %w", err)
+	}
+	fs, err := fields.GetFieldsByTeamID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("load fields: %w", err)
+	}
+	cs, err := contacts.GetAllContacts(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("load contacts: %w", err)
+	}
+	owners, err := artifacts.OwnersByTeam(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("load owners: %w", err)
+	}
+	edges, err := lineage.ByTeam(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("load lineage: %w", err)
+	}
+	return &Catalog{Team: *team, Artifacts: arts, Fields: fs, Contacts: cs, Owners: owners, Lineage: edges, GeneratedAt: time.Now()}, nil
+}
+
+type project struct {
+	Name      string
+	Slug      string
+	Artifacts []models.Artifact
+}
+
+type page struct {
+	Root        string // relative path from the page to the site root
+	Title       string
+	Team        models.Team
+	GeneratedAt time.Time
+	Projects    []project
+	Project     *project
+	Artifact    *models.Artifact
+	Fields      []models.ArtifactField
+	Owners      []models.ArtifactOwner
+	Replacement *models.Artifact // the replacement of a deprecated artifact, if in this catalog
+	Upstream    []lineageLink
+	Downstream  []lineageLink
+	Contacts    []models.Contact
+	ContactByID map[int]models.Contact
+	Glossary    []term
+}
+
+// lineageLink is the other end of a lineage edge as shown on an artifact
+// page. Href is set for artifacts of this catalog only.
+type lineageLink struct {
+	Name   string
+	Team   string // empty for the team's own artifacts
+	Href   string
+	Status string // lifecycle label of an available upstream
+	Note   string // why the artifact is not available any more
+}
+
+var edgeNotes = map[string]string{
+	models.UpstreamUnshared: "больше не доступен: владелец закрыл доступ",
+	models.UpstreamDeleted:  "удалён",
+}
+
+// term is a glossary entry: a field name with its descriptions and the
+// artifacts that use it.
+type term struct {
+	Name         string
+	Anchor       string
+	Descriptions []string
+	Usages       []termUsage
+}
+
+type termUsage struct {
+	Artifact models.Artifact
+	DataType string
+}
+
+type searchEntry struct {
+	Title   string `json:"t"`
+	Kind    string `json:"k"`
+	Project string `json:"p,omitempty"`
+	Text    string `json:"d,omitempty"`
+	URL     string `json:"u"`
+}
+
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(s string) string {
+	slug := strings.Trim(slugRe.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if slug == "" {
+		slug = "project"
+	}
+	return slug
+}
+
+func groupProjects(arts []models.Artifact) []project {
+	byName := map[string]*project{}
+	var names []string
+	for _, a := range arts {
+		p, ok := byName[a.ProjectName]
+		if !ok {
+			p = &project{Name: a.ProjectName}
+			byName[a.ProjectName] = p
+			names = append(names, a.ProjectName)
+		}
+		p.Artifacts = append(p.Artifacts, a)
+	}
+	sort.Strings(names)
+	used := map[string]int{}
+	res := make([]project, 0, len(names))
+	for _, n := range names {
+		p := byName[n]
+		p.Slug = slugify(n)
+		if used[p.Slug]++; used[p.Slug] > 1 {
+			p.Slug = fmt.Sprintf("%s-%d", p.Slug, used[p.Slug])
+		}
+		sort.Slice(p.Artifacts, func(i, j int) bool { return p.Artifacts[i].Name < p.Artifacts[j].Name })
+		res = append(res, *p)
+	}
+	return res
+}
+
+// Build writes the zipped static site for cat to w.
+func Build(w io.Writer, cat *Catalog) error {
+	zw := zip.NewWriter(w)
+	contactByID := make(map[int]models.Contact, len(cat.Contacts))
+	for _, c := range cat.Contacts {
+		contactByID[c.ID] = c
+	}
+	artifactByID := make(map[int]*models.Artifact, len(cat.Artifacts))
+	for i := range cat.Artifacts {
+		artifactByID[cat.Artifacts[i].ID] = &cat.Artifacts[i]
+	}
+	upstream, downstream := lineageLinks(cat, artifactByID)
+	projects := groupProjects(cat.Artifacts)
+	base := page{Team: cat.Team, GeneratedAt: cat.GeneratedAt, Projects: projects, Contacts: cat.Contacts, ContactByID: contactByID}
+
+	render := func(name, tmplName string, p page) error {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, tmplName, p); err != nil {
+			return fmt.Errorf("render %s: %w", name, err)
+		}
+		return writeFile(zw, name, buf.Bytes(), cat.GeneratedAt)
+	}
+
+	idx := base
+	idx.Root, idx.Title = "", cat.Team.Name
+	if err := render("index.html", "index.html", idx); err != nil {
+		return err
+	}
+	cp := base
+	cp.Root, cp.Title = "", "Контакты"
+	if err := render("contacts.html", "contacts.html", cp); err != nil {
+		return err
+	}
+	gp := base
+	gp.Root, gp.Title, gp.Glossary = "", "Глоссарий", glossary(cat)
+	if err := render("glossary.html", "glossary.html", gp); err != nil {
+		return err
+	}
+
+	var index []searchEntry
+	for i := range projects {
+		p := base
+		p.Root, p.Title, p.Project = "../", projects[i].Name, &projects[i]
+		if err := render("projects/"+projects[i].Slug+".html", "project.html", p); err != nil {
+			return err
+		}
+		index = append(index, searchEntry{Title: projects[i].Name, Kind: "project", URL: "projects/" + projects[i].Slug + ".html"})
+		for j := range projects[i].Artifacts {
+			a := &projects[i].Artifacts[j]
+			ap := base
+			ap.Root, ap.Title, ap.Project, ap.Artifact, ap.Fields, ap.Owners = "../", a.Name, &projects[i], a, cat.Fields[a.ID], cat.Owners[a.ID]
+			if a.ReplacementID != nil {
+				ap.Replacement = artifactByID[*a.ReplacementID]
+			}
+			ap.Upstream, ap.Downstream = upstream[a.ID], downstream[a.ID]
+			url := fmt.Sprintf("artifacts/%d.html", a.ID)
+			if err := render(url, "artifact.html", ap); err != nil {
+				return err
+			}
+			text := []string{a.Type, a.Description}
+			for _, f := range cat.Fields[a.ID] {
+				text = append(text, f.FieldName, f.Description)
+			}
+			index = append(index, searchEntry{Title: a.Name, Kind: "artifact", Project: a.ProjectName, Text: strings.Join(text, " "), URL: url})
+		}
+	}
+	for _, c := range cat.Contacts {
+		text := []string{c.Department}
+		for _, ch := range c.Channels {
+			text = append(text, ch.Value)
+		}
+		index = append(index, searchEntry{Title: c.Name, Kind: "contact", Text: strings.Join(text, " "), URL: fmt.Sprintf("contacts.html#contact-%d", c.ID)})
+	}
+	for _, t := range gp.Glossary {
+		index = append(index, searchEntry{Title: t.Name, Kind: "term", Text: strings.Join(t.Descriptions, " "), URL: "glossary.html#" + t.Anchor})
+	}
+
+	// A script rather than a .json file so that search also works when the
+	// site is opened straight from disk (file:// forbids fetch).
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(zw, "search-index.js", append(append([]byte("window.CATALOG_SEARCH_INDEX = "), data...), ";\n"...), cat.GeneratedAt); err != nil {
+		return err
+	}
+	for _, asset := range []string{"style.css", "search.js"} {
+		b, err := files.ReadFile("assets/" + asset)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(zw, asset, b, cat.GeneratedAt); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// lineageLinks returns, by artifact id, where the team's artifacts come from
+// and which artifacts are derived from them.
+func lineageLinks(cat *Catalog, artifactByID map[int]*models.Artifact) (upstream, downstream map[int][]lineageLink) {
+	upstream, downstream = map[int][]lineageLink{}, map[int][]lineageLink{}
+	for _, e := range cat.Lineage {
+		if _, ok := artifactByID[e.DownstreamID]; ok {
+			l := lineageLink{Name: e.UpstreamName, Note: edgeNotes[e.UpstreamStatus], Status: statusLabels[e.UpstreamLifecycle]}
+			if e.UpstreamTeamID == nil || *e.UpstreamTeamID != cat.Team.ID {
+				l.Team = e.UpstreamTeamName
+			} else if e.UpstreamStatus == models.UpstreamAvailable && e.UpstreamID != nil {
+				l.Href = fmt.Sprintf("%d.html", *e.UpstreamID)
+			}
+			upstream[e.DownstreamID] = append(upstream[e.DownstreamID], l)
+		}
+		if e.UpstreamID != nil && e.UpstreamStatus != models.UpstreamDeleted {
+			if _, ok := artifactByID[*e.UpstreamID]; ok {
+				l := lineageLink{Name: e.DownstreamName, Note: edgeNotes[e.UpstreamStatus]}
+				if e.DownstreamTeamID == cat.Team.ID {
+					l.Href = fmt.Sprintf("%d.html", e.DownstreamID)
+				} else {
+					l.Team = e.DownstreamTeamName
+				}
+				downstream[*e.UpstreamID] = append(downstream[*e.UpstreamID], l)
+			}
+		}
+	}
+	return upstream, downstream
+}
+
+// glossary gathers the field names of the catalog, case-insensitively, with
+// their descriptions and the artifacts that use them.
+func glossary(cat *Catalog) []term {
+	byKey := map[string]*term{}
+	seen := map[string]map[string]bool{}
+	for _, a := range cat.Artifacts {
+		for _, f := range cat.Fields[a.ID] {
+			key := strings.ToLower(f.FieldName)
+			t, ok := byKey[key]
+			if !ok {
+				t = &term{Name: f.FieldName}
+				byKey[key], seen[key] = t, map[string]bool{}
+			}
+			if d := strings.TrimSpace(f.Description); d != "" && !seen[key][d] {
+				seen[key][d] = true
+				t.Descriptions = append(t.Descriptions, d)
+			}
+			t.Usages = append(t.Usages, termUsage{Artifact: a, DataType: f.DataType})
+		}
+	}
+	res := make([]term, 0, len(byKey))
+	for _, t := range byKey {
+		sort.Slice(t.Usages, func(i, j int) bool { return t.Usages[i].Artifact.Name < t.Usages[j].Artifact.Name })
+		res = append(res, *t)
+	}
+	sort.Slice(res, func(i, j int) bool { return strings.ToLower(res[i].Name) < strings.ToLower(res[j].Name) })
+	used := map[string]int{}
+	for i := range res {
+		slug := strings.Trim(slugRe.ReplaceAllString(strings.ToLower(res[i].Name), "-"), "-")
+		if slug == "" {
+			slug = "x"
+		}
+		anchor := "term-" + slug
+		if used[anchor]++; used[anchor] > 1 {
+			anchor = fmt.Sprintf("%s-%d", anchor, used[anchor])
+		}
+		res[i].Anchor = anchor
+	}
+	return res
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte, mod time.Time) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: mod})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,92 @@
+package docsite
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go-data-catalog/internal/models"
+)
+
+// build renders cat and returns the files of the zip by name.
+func build(t *testing.T, cat *Catalog) map[string]string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Build(&buf, cat); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func TestBuildLineageAndGlossary(t *testing.T) {
+	id := func(n int) *int { return &n }
+	cat := &Catalog{
+		Team: models.Team{ID: 1, Name: "Data"},
+		Artifacts: []models.Artifact{
+			{ID: 10, Name: "orders", Type: "table", ProjectName: "sales", Status: models.StatusActive},
+			{ID: 11, Name: "orders_daily", Type: "view", ProjectName: "sales", Status: models.StatusActive},
+		},
+		Fields: map[int][]models.ArtifactField{
+			10: {{FieldName: "order_id", DataType: "bigint", Description: "Order number"}},
+			11: {{FieldName: "ORDER_ID", DataType: "bigint"}, {FieldName: "day", DataType: "date"}},
+		},
+		Contacts: []models.Contact{{ID: 5, Name: "Anna", AvatarURL: "https://cdn.example.com/anna.png"}},
+		Lineage: []models.LineageEdge{
+			// within the team
+			{ID: 1, UpstreamID: id(10), UpstreamName: "orders", UpstreamTeamID: id(1), UpstreamTeamName: "Data", UpstreamStatus: models.UpstreamAvailable, UpstreamLifecycle: models.StatusActive,
+				DownstreamID: 11, DownstreamName: "orders_daily", DownstreamTeamID: 1, DownstreamTeamName: "Data"},
+			// another team's artifact that is no longer shared with this team
+			{ID: 2, UpstreamID: id(20), UpstreamName: "customers", UpstreamTeamID: id(2), UpstreamTeamName: "CRM", UpstreamStatus: models.UpstreamUnshared,
+				DownstreamID: 11, DownstreamName: "orders_daily", DownstreamTeamID: 1, DownstreamTeamName: "Data"},
+			// another team derives from this team's artifact
+			{ID: 3, UpstreamID: id(10), UpstreamName: "orders", UpstreamTeamID: id(1), UpstreamTeamName: "Data", UpstreamStatus: models.UpstreamAvailable, UpstreamLifecycle: models.StatusActive,
+				DownstreamID: 30, DownstreamName: "revenue", DownstreamTeamID: 3, DownstreamTeamName: "Finance"},
+		},
+		GeneratedAt: time.Now(),
+	}
+	files := build(t, cat)
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"artifacts/11.html", []string{`<a href="10.html">orders</a>`, `customers <span class="muted">(CRM)</span>`, "больше не доступен"}},
+		{"artifacts/10.html", []string{`<a href="11.html">orders_daily</a>`, `revenue <span class="muted">(Finance)</span>`}},
+		{"glossary.html", []string{`id="term-order-id"`, "Order number", `<a href="artifacts/10.html">orders</a>`, `<a href="artifacts/11.html">orders_daily</a>`}},
+		{"search-index.js", []string{`"k":"term"`}},
+	}
+	for _, tt := range tests {
+		for _, want := range tt.want {
+			if !strings.Contains(files[tt.file], want) {
+				t.Errorf("%s lacks %s", tt.file, want)
+			}
+		}
+	}
+
+	// the site must work offline: nothing is loaded from other hosts
+	for name, data := range files {
+		if strings.Contains(data, "<img") || strings.Contains(data, "cdn.example.com") {
+			t.Errorf("%s loads an external image", name)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"go-data-catalog/internal/mail"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+const (
+	errEmailNotConfirmed = "email address is not verified"
+	mailSendTimeout      = time.Minute
+)
+
+// verificationPendingResponse is returned by register instead of tokens when
+// REQUIRE_EMAIL_VERIFICATION is on.
+type verificationPendingResponse struct {
+	VerificationRequired bool        `json:"verification_required"`
+	User                 models.User `json:"user"`
+}
+
+type emailTokenRequest struct {
+	Token string `json:"token" binding:"required,max=100"`
+}
+
+type emailRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required,max=100"`
+	Password string `json:"password" binding:"required,max=100"`
+}
+
+// POST /auth/verify-email confirms the address with the emailed token.
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req emailTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	_, err := h.tokens.VerifyEmail(c.Request.Context(), postgres.HashToken(req.Token))
+	if errors.Is(err, postgres.ErrInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify email"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /auth/verify-email/resend sends a new verification link. The answer
+// is the same whether or not the address belongs to an unverified account.
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req emailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	u, err := h.users.GetByEmail(c.Request.Context(), req.Email)
+	if err == nil && !u.EmailVerified && u.IsActive && u.ServiceTeamID == nil {
+		h.sendVerificationEmail(u)
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// POST /auth/password/forgot emails a password reset link. Only accounts
+// with a local password get one; the answer never reveals which do.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req emailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	u, err := h.users.GetByEmail(c.Request.Context(), req.Email)
+	if err == nil && u.IsActive && u.ServiceTeamID == nil && hasLocalPassword(u) {
+		h.sendUserToken(u, postgres.TokenResetPassword, time.Duration(h.cfg.PasswordResetTTL)*time.Minute,
+			"Reset your password", "#reset_password=",
+			"Someone asked to reset the password of your Go Data Catalog account.\n"+
+				"If it was you, open the link below to choose a new password:")
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// POST /auth/password/reset sets a new password with the emailed token and
+// logs the user out everywhere.
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	ctx := c.Request.Context()
+	tokenHash := postgres.HashToken(req.Token)
+	userID, err := h.tokens.UserID(ctx, postgres.TokenResetPassword, tokenHash)
+	if errors.Is(err, postgres.ErrInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+		return
+	}
+	u, err := h.users.GetByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+		return
+	}
+	if err := h.passwords.Check(req.Password, u.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+		return
+	}
+	// consuming the token is what counts: a concurrent reset with the same
+	// token loses here
+	_, err = h.tokens.ResetPassword(ctx, tokenHash, string(hash))
+	if errors.Is(err, postgres.ErrInvalidUserToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) sendVerificationEmail(u *models.User) {
+	h.sendUserToken(u, postgres.TokenVerifyEmail, time.Duration(h.cfg.EmailVerificationTTL)*time.Minute,
+		"Confirm your email address", "#verify_email=",
+		"Welcome to Go Data Catalog!\nOpen the link below to confirm your email address:")
+}
+
+// sendUserToken creates a single-use token and emails a link with it to the
+// user. Sending happens in the background so the response time does not
+// depend on the mail server or tell whether an email was sent.
+func (h *AuthHandler) sendUserToken(u *models.User, purpose string, ttl time.Duration, subject, fragment, intro string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		token, err := newRefreshToken()
+		if err != nil {
+			log.Printf("%s token: %v", purpose, err)
+			return
+		}
+		expires := time.Now().Add(ttl)
+		if err := h.tokens.Create(ctx, u.ID, purpose, postgres.HashToken(token), expires); err != nil {
+			log.Printf("%s token for user %d: %v", purpose, u.ID, err)
+			return
+		}
+		link := strings.TrimSuffix(h.cfg.AppURL, "/") + "/" + fragment + token
+		body := intro + "\n\n" + link + "\n\n" +
+			"The link works once and expires at " + expires.UTC().Format("2006-01-02 15:04 MST") + ".\n" +
+			"If you did not ask for this email, you can ignore it.\n"
+		if err := h.mailer.Send(ctx, mail.Message{To: u.Email, Subject: subject, Body: body}); err != nil {
+			log.Printf("%s email to user %d: %v", purpose, u.ID, err)
+		}
+	}()
+}
+
+// hasLocalPassword is false for accounts created through SSO or a directory,
+// which store a placeholder instead of a bcrypt hash.
+func hasLocalPassword(u *models.User) bool {
+	return strings.HasPrefix(u.PasswordHash, "$2")
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+// certificationStates are the states of a certification.
+var certificationStates = []string{models.CertCertified, models.CertNeedsReview, models.CertExpired}
+
+// certifyRequest certifies an artifact, or renews its certification.
+type certifyRequest struct {
+	Note      string    `json:"note" binding:"max=1000"`
+	ExpiresAt time.Time `json:"expires_at" binding:"required"`
+}
+
+// GET /api/v1/teams/:teamId/artifacts/:id/certification
+func (h *ArtifactHandler) GetCertification(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	cert, err := h.repo.Certification(c.Request.Context(), c.GetInt(middleware.CtxTeamID), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "the artifact is not certified"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load certification"})
+		return
+	}
+	c.JSON(http.StatusOK, cert)
+}
+
+// PUT /api/v1/teams/:teamId/artifacts/:id/certification certifies the
+// artifact until expires_at; certifying again renews the certification and
+// clears a pending review.
+func (h *ArtifactHandler) Certify(c *gin.Context) {
+	id, ok := h.certifiableArtifact(c)
+	if !ok {
+		return
+	}
+	var req certifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+	cert, err := h.repo.Certify(c.Request.Context(), c.GetInt(middleware.CtxTeamID), id, c.GetInt(middleware.CtxUserID), req.Note, req.ExpiresAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+	case errors.Is(err, postgres.ErrNotCertifiable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to certify artifact"})
+	default:
+		c.JSON(http.StatusOK, cert)
+	}
+}
+
+// DELETE /api/v1/teams/:teamId/artifacts/:id/certification
+func (h *ArtifactHandler) Uncertify(c *gin.Context) {
+	id, ok := h.certifiableArtifact(c)
+	if !ok {
+		return
+	}
+	err := h.repo.Uncertify(c.Request.Context(), c.GetInt(middleware.CtxTeamID), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "the artifact is not certified"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove certification"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v1/teams/:teamId/certifications?state= lists the team's
+// certifications, those to review or renew first.
+func (h *ArtifactHandler) ListCertifications(c *gin.Context) {
+	state := c.Query("state")
+	if state != "" && !slices.Contains(certificationStates, state) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state"})
+		return
+	}
+	items, err := h.repo.Certifications(c.Request.Context(), c.GetInt(middleware.CtxTeamID), state)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list certifications"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// certifiableArtifact reads :id and checks that the artifact is the team's
+// and that the caller is a team admin or one of its data stewards.
+func (h *ArtifactHandler) certifiableArtifact(c *gin.Context) (int, bool) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return 0, false
+	}
+	teamID := c.GetInt(middleware.CtxTeamID)
+	exists, err := h.repo.Exists(c.Request.Context(), teamID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load artifact"})
+		return 0, false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+		return 0, false
+	}
+	if access.Allowed(c.GetString(middleware.CtxTeamRole), access.Permission{Resource: access.Certifications, Action: access.Manage}) {
+		return id, true
+	}
+	steward, err := h.repo.IsSteward(c.Request.Context(), teamID, id, c.GetInt(middleware.CtxUserID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+		return 0, false
+	}
+	if !steward {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only team admins and data stewards of the artifact may certify it"})
+		return 0, false
+	}
+	return id, true
+}
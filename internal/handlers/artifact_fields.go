@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/render"
 	"go-data-catalog/internal/repository/postgres"
 
 	"github.com/gin-gonic/gin"
@@ -140,6 +142,39 @@ func (h *ArtifactFieldHandler) DeleteField(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Field deleted successfully"})
 }
 
+// RenderArtifact renders the artifact's fields as DDL, a Go struct, JSON Schema or Avro.
+// GET /teams/:teamId/artifacts/:id/render?format=postgres-ddl|clickhouse-ddl|go-struct|json-schema|avro
+func (h *ArtifactFieldHandler) RenderArtifact(c *gin.Context) {
+	teamID, ok := h.teamID(c); if !ok { return }
+	artifactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid artifact_id"})
+		return
+	}
+
+	artifact, err := h.artifactRepo.GetArtifactByID(c.Request.Context(), teamID, artifactID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+		return
+	}
+	fields, err := h.repo.GetFieldsByArtifactID(c.Request.Context(), artifactID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := render.Render(c.Query("format"), artifact, fields)
+	if errors.Is(err, render.ErrUnknownFormat) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown format", "formats": render.Formats})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
 func (h *ArtifactFieldHandler) artifactExists(c *gin.Context, teamID, artifactID int) (bool, error) {
 	return h.artifactRepo.Exists(c.Request.Context(), teamID, artifactID)
 }
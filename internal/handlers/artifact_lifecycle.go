@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+// ArtifactLifecycleHandler moves artifacts through draft, active,
+// deprecated and retired, warns downstream teams and reports deprecated
+// artifacts still in use.
+type ArtifactLifecycleHandler struct {
+	artifacts *postgres.ArtifactRepository
+	lineage   *postgres.LineageRepository
+	notify    *postgres.NotificationRepository
+	teams     *postgres.TeamRepository
+}
+
+func NewArtifactLifecycleHandler(artifacts *postgres.ArtifactRepository, lineage *postgres.LineageRepository, notify *postgres.NotificationRepository, teams *postgres.TeamRepository) *ArtifactLifecycleHandler {
+	return &ArtifactLifecycleHandler{artifacts: artifacts, lineage: lineage, notify: notify, teams: teams}
+}
+
+// PUT /api/v1/teams/:teamId/artifacts/:id/status. Deprecating or retiring
+// an artifact notifies the teams of the draft and active artifacts derived
+// from it.
+func (h *ArtifactLifecycleHandler) SetStatus(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req models.ArtifactStatus
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	teamID := c.GetInt(middleware.CtxTeamID)
+	a, prev, err := h.artifacts.SetStatus(c.Request.Context(), teamID, id, req)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+		return
+	case errors.Is(err, postgres.ErrUnknownReplacement), errors.Is(err, postgres.ErrReplacementInactive), errors.Is(err, postgres.ErrReasonRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change status"})
+		return
+	}
+	if a.Status != prev && (a.Status == models.StatusDeprecated || a.Status == models.StatusRetired) {
+		h.warnDownstream(c, a)
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// warnDownstream notifies consumers of a newly deprecated or retired
+// artifact. Failures are logged: the status change itself succeeded.
+func (h *ArtifactLifecycleHandler) warnDownstream(c *gin.Context, a *models.Artifact) {
+	teamName := fmt.Sprint(a.TeamID)
+	if t, err := h.teams.GetByID(c.Request.Context(), a.TeamID); err == nil {
+		teamName = t.Name
+	}
+	msg := fmt.Sprintf("Upstream artifact %s of %s is %s as of %s", a.Name, teamName, a.Status, a.DeprecatedAt.Format("2006-01-02"))
+	if a.DeprecationReason != "" {
+		msg += ": " + a.DeprecationReason
+	}
+	msg += "."
+	if a.ReplacementID != nil {
+		msg += fmt.Sprintf(" Replacement: artifact #%d.", *a.ReplacementID)
+	}
+	if _, err := h.notify.NotifyDownstream(c.Request.Context(), a.ID, postgres.NotifyUpstreamDeprecated, msg); err != nil {
+		log.Printf("artifact %d deprecation notifications: %v", a.ID, err)
+	}
+}
+
+// GET /api/v1/teams/:teamId/reports/deprecated lists the team's deprecated
+// and retired artifacts that draft or active artifacts are still derived
+// from, with those consumers.
+func (h *ArtifactLifecycleHandler) DeprecatedReport(c *gin.Context) {
+	teamID := c.GetInt(middleware.CtxTeamID)
+	res, err := h.lineage.DeprecatedConsumers(c.Request.Context(), teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build report"})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+// ownerRoles are the roles of artifact owners.
+var ownerRoles = []string{models.OwnerTechnical, models.OwnerBusiness, models.OwnerSteward}
+
+// setOwnersRequest replaces the owners of an artifact.
+type setOwnersRequest struct {
+	Owners []models.ArtifactOwner `json:"owners" binding:"max=50,dive"`
+}
+
+// GET /api/v1/teams/:teamId/artifacts/:id/owners
+func (h *ArtifactHandler) ListOwners(c *gin.Context) {
+	teamID, ok := h.teamID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	if _, err := h.repo.GetArtifactByID(c.Request.Context(), teamID, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+		return
+	}
+	owners, err := h.repo.Owners(c.Request.Context(), teamID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list owners"})
+		return
+	}
+	c.JSON(http.StatusOK, owners)
+}
+
+// PUT /api/v1/teams/:teamId/artifacts/:id/owners replaces the owner list.
+// Owners must be contacts of the team; the first technical owner becomes the
+// artifact's developer_id.
+func (h *ArtifactHandler) SetOwners(c *gin.Context) {
+	teamID, ok := h.teamID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	var req setOwnersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !h.stewardsUnchangedOrAllowed(c, teamID, id, req.Owners) {
+		return
+	}
+	owners, err := h.repo.SetOwners(c.Request.Context(), teamID, id, req.Owners)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+	case errors.Is(err, postgres.ErrUnknownContact):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set owners"})
+	default:
+		c.JSON(http.StatusOK, owners)
+	}
+}
+
+// stewardsUnchangedOrAllowed lets only team admins change who the data
+// stewards of an artifact are: stewards may certify it.
+func (h *ArtifactHandler) stewardsUnchangedOrAllowed(c *gin.Context, teamID, id int, owners []models.ArtifactOwner) bool {
+	if access.Allowed(c.GetString(middleware.CtxTeamRole), access.Permission{Resource: access.Certifications, Action: access.Manage}) {
+		return true
+	}
+	current, err := h.repo.Owners(c.Request.Context(), teamID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list owners"})
+		return false
+	}
+	if !slices.Equal(stewards(current), stewards(owners)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only team admins may change the data stewards of an artifact"})
+		return false
+	}
+	return true
+}
+
+// stewards returns the sorted contact ids of the data stewards.
+func stewards(owners []models.ArtifactOwner) []int {
+	var ids []int
+	for _, o := range owners {
+		if o.Role == models.OwnerSteward && !slices.Contains(ids, o.ContactID) {
+			ids = append(ids, o.ContactID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// GET /api/v1/me/artifacts lists artifacts the current user owns through
+// contacts linked to their account.
+func (h *ArtifactHandler) MyArtifacts(c *gin.Context) {
+	role := c.Query("role")
+	if role != "" && !slices.Contains(ownerRoles, role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role"})
+		return
+	}
+	limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	items, err := h.repo.MyArtifacts(c.Request.Context(), c.GetInt(middleware.CtxUserID), role, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list artifacts"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"go-data-catalog/internal/middleware"
 	"go-data-catalog/internal/models"
 	"go-data-catalog/internal/repository/postgres"
 
@@ -11,10 +12,26 @@ import (
 
 type ArtifactHandler struct {
 	repo *postgres.ArtifactRepository
+	orgs *postgres.OrgRepository
 }
 
-func NewArtifactHandler(repo *postgres.ArtifactRepository) *ArtifactHandler {
-	return &ArtifactHandler{repo: repo}
+func NewArtifactHandler(repo *postgres.ArtifactRepository, orgs *postgres.OrgRepository) *ArtifactHandler {
+	return &ArtifactHandler{repo: repo, orgs: orgs}
+}
+
+// knownType checks the artifact type against the registry of the team's
+// organization.
+func (h *ArtifactHandler) knownType(c *gin.Context, t string) bool {
+	ok, err := h.orgs.HasArtifactType(c.Request.Context(), c.GetInt(middleware.CtxOrgID), t)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check artifact type"})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown artifact type " + t + "; see the organization's artifact types"})
+		return false
+	}
+	return true
 }
 
 func (h *ArtifactHandler) teamID(c *gin.Context) (int, bool) {
@@ -29,7 +46,8 @@ func (h *ArtifactHandler) teamID(c *gin.Context) (int, bool) {
 
 func (h *ArtifactHandler) GetArtifacts(c *gin.Context) {
 	teamID, ok := h.teamID(c); if !ok { return }
-	artifacts, err := h.repo.GetAllArtifacts(c.Request.Context(), teamID)
+	limit, offset, ok := pageParams(c); if !ok { return }
+	artifacts, err := h.repo.GetArtifactsPage(c.Request.Context(), teamID, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -45,6 +63,11 @@ func (h *ArtifactHandler) CreateArtifact(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
+	if !h.knownType(c, artifact.Type) { return }
+	// deprecation goes through PUT .../status
+	if artifact.Status != "" && artifact.Status != models.StatusDraft && artifact.Status != models.StatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a new artifact is draft or active"}); return
+	}
 	
 	if err := h.repo.CreateArtifact(c.Request.Context(), teamID, &artifact); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -86,6 +109,7 @@ func (h *ArtifactHandler) UpdateArtifact(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
+	if !h.knownType(c, artifact.Type) { return }
 	
 	if err := h.repo.UpdateArtifact(c.Request.Context(), teamID, id, &artifact); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1,6 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
@@ -9,33 +15,84 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"go-data-catalog/internal/config"
+	"go-data-catalog/internal/mail"
+	"go-data-catalog/internal/middleware"
 	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/password"
 	"go-data-catalog/internal/repository/postgres"
+	"go-data-catalog/internal/sso"
+	"go-data-catalog/internal/throttle"
 )
 
 type AuthHandler struct {
-	users *postgres.UserRepository
-	cfg   *config.Config
+	users      *postgres.UserRepository
+	sessions   *postgres.SessionRepository
+	identities *postgres.IdentityRepository
+	members    *postgres.TeamMemberRepository
+	twoFactor  *postgres.TwoFactorRepository
+	tokens     *postgres.UserTokenRepository
+	backends   []sso.PasswordBackend // directories tried after local accounts
+	mailer     mail.Sender
+	passwords  *password.Policy
+	limiter    *throttle.Limiter
+	attempts   *postgres.LoginAttemptRepository
+	orgs       *postgres.OrgRepository
+	cfg        *config.Config
+}
+
+const mfaTokenTTL = 5 * time.Minute
+
+// mfaClaims identify a user who passed the first login step. The token has
+// no session, so AuthMiddleware does not accept it.
+type mfaClaims struct {
+	UserID  int    `json:"user_id"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
 }
 
 type registerRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6,max=100"`
+	Password string `json:"password" binding:"required,max=100"` // checked by the password policy
 	Name     string `json:"name" binding:"omitempty,max=255"`
 }
 
+// Email may also be a directory login (e.g. an LDAP uid).
 type loginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
+	Email    string `json:"email" binding:"required,max=255"`
+	Password string `json:"password" binding:"required"`
+}
+
+type linkDirectoryRequest struct {
+	Login    string `json:"login" binding:"required,max=255"`
 	Password string `json:"password" binding:"required"`
 }
 
+type loginTwoFactorRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,max=32"` // TOTP or recovery code
+}
+
+// mfaChallengeResponse is returned by login instead of tokens when the user
+// has two-factor authentication enabled.
+type mfaChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 type authResponse struct {
-	Token string       `json:"token"`
-	User  models.User  `json:"user"`
+	Token        string      `json:"token"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	RefreshToken string      `json:"refresh_token"`
+	User         models.User `json:"user"`
 }
 
-func NewAuthHandler(users *postgres.UserRepository, cfg *config.Config) *AuthHandler {
-	return &AuthHandler{users: users, cfg: cfg}
+func NewAuthHandler(users *postgres.UserRepository, sessions *postgres.SessionRepository, identities *postgres.IdentityRepository, members *postgres.TeamMemberRepository, twoFactor *postgres.TwoFactorRepository, tokens *postgres.UserTokenRepository, backends []sso.PasswordBackend, mailer mail.Sender, passwords *password.Policy, limiter *throttle.Limiter, attempts *postgres.LoginAttemptRepository, orgs *postgres.OrgRepository, cfg *config.Config) *AuthHandler {
+	return &AuthHandler{users: users, sessions: sessions, identities: identities, members: members, twoFactor: twoFactor, tokens: tokens, backends: backends, mailer: mailer, passwords: passwords, limiter: limiter, attempts: attempts, orgs: orgs, cfg: cfg}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -44,6 +101,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
 		return
 	}
+	if err := h.passwords.Check(req.Password, req.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
@@ -54,10 +115,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "email already exists or invalid"})
 		return
 	}
-	token, err := h.issueToken(u)
+	h.joinOrgs(c.Request.Context(), u)
+	h.sendVerificationEmail(u)
+	if h.cfg.RequireEmailVerification {
+		// no session until the address is confirmed
+		u.PasswordHash = ""
+		c.JSON(http.StatusCreated, verificationPendingResponse{VerificationRequired: true, User: *u})
+		return
+	}
+	res, err := h.startSession(c, u)
 	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"}); return }
-	u.PasswordHash = ""
-	c.JSON(http.StatusCreated, authResponse{Token: token, User: *u})
+	c.JSON(http.StatusCreated, res)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -66,23 +134,247 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
 		return
 	}
+	if h.throttled(c, req.Email, "password") {
+		return
+	}
 	u, err := h.users.GetByEmail(c.Request.Context(), req.Email)
-	if err != nil { c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"}); return }
-	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)); err != nil { c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"}); return }
-	token, err := h.issueToken(u)
+	var userID *int // for the login history, when the login names an account
+	if err == nil {
+		userID = &u.ID
+	}
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)) != nil {
+		// not a local account or not its password: try the directories
+		if u, err = h.loginDirectory(c.Request.Context(), req.Email, req.Password); err == nil {
+			userID = &u.ID
+		}
+	} else if u.IsActive {
+		err = h.authMethodAllowed(c.Request.Context(), u, models.AuthPassword)
+	}
+	var le loginError
+	switch {
+	case errors.Is(err, sso.ErrInvalidCredentials):
+		h.loginFailed(c, req.Email, userID, "password", attemptInvalidCredentials)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	case errors.As(err, &le):
+		h.recordAttempt(c, req.Email, userID, "password", attemptRejected)
+		c.JSON(http.StatusForbidden, gin.H{"error": le.Error()})
+		return
+	case err != nil:
+		log.Printf("login: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "login failed"})
+		return
+	}
+	if !u.IsActive {
+		h.recordAttempt(c, req.Email, userID, "password", attemptDeactivated)
+		c.JSON(http.StatusForbidden, gin.H{"error": "account is deactivated"})
+		return
+	}
+	if h.cfg.RequireEmailVerification && !u.EmailVerified {
+		h.recordAttempt(c, req.Email, userID, "password", attemptEmailNotVerified)
+		c.JSON(http.StatusForbidden, gin.H{"error": errEmailNotConfirmed})
+		return
+	}
+	challenge, err := h.mfaChallenge(c.Request.Context(), u)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "login failed"}); return }
+	if challenge != nil {
+		// failures are kept until the second step succeeds, so a known
+		// password does not reset the limit on code guessing
+		h.recordAttempt(c, req.Email, userID, "password", attemptMFARequired)
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+	res, err := h.startSession(c, u)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"}); return }
+	h.loginSucceeded(c, req.Email, u, "password")
+	c.JSON(http.StatusOK, res)
+}
+
+// POST /auth/login/2fa completes a login with a TOTP or recovery code.
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req loginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	claims := &mfaClaims{}
+	token, err := jwt.ParseWithClaims(req.MFAToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(h.cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid || claims.Purpose != "2fa" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "login expired, please sign in again"})
+		return
+	}
+	u, err := h.users.GetByID(c.Request.Context(), claims.UserID)
+	if err != nil || !u.IsActive { c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"}); return }
+	if h.throttled(c, u.Email, "2fa") {
+		return
+	}
+	ok, err := verifySecondFactor(c.Request.Context(), h.twoFactor, u.ID, req.Code)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check code"}); return }
+	if !ok {
+		h.loginFailed(c, u.Email, &u.ID, "2fa", attemptInvalidCode)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
+		return
+	}
+	res, err := h.startSession(c, u)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"}); return }
+	h.loginSucceeded(c, u.Email, u, "2fa")
+	c.JSON(http.StatusOK, res)
+}
+
+// POST /auth/refresh exchanges a refresh token for a new access/refresh pair.
+// Each refresh token works once; reusing one revokes its whole session.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	refresh, err := newRefreshToken()
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"}); return }
+	expires := time.Now().Add(time.Duration(h.cfg.RefreshTokenTTL) * time.Minute)
+	sessionID, userID, err := h.sessions.Rotate(c.Request.Context(), postgres.HashToken(req.RefreshToken), postgres.HashToken(refresh), expires)
+	switch {
+	case errors.Is(err, postgres.ErrRefreshTokenReused), errors.Is(err, postgres.ErrInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token"})
+		return
+	}
+	u, err := h.users.GetByID(c.Request.Context(), userID)
+	if err != nil || !u.IsActive { c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"}); return }
+	token, tokenExpires, err := h.issueToken(u, sessionID)
 	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"}); return }
 	u.PasswordHash = ""
-	c.JSON(http.StatusOK, authResponse{Token: token, User: *u})
+	c.JSON(http.StatusOK, authResponse{Token: token, ExpiresAt: tokenExpires, RefreshToken: refresh, User: *u})
 }
 
-func (h *AuthHandler) issueToken(u *models.User) (string, error) {
+// POST /auth/logout revokes the current session.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID := c.GetInt(middleware.CtxUserID)
+	if err := h.sessions.Revoke(c.Request.Context(), c.GetString(middleware.CtxSessionID), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log out"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /auth/logout-all revokes every session of the current user.
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	if err := h.sessions.RevokeAllForUser(c.Request.Context(), c.GetInt(middleware.CtxUserID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log out"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loginDirectory checks the password against the configured directories in
+// order. A directory that cannot be reached is skipped.
+func (h *AuthHandler) loginDirectory(ctx context.Context, login, password string) (*models.User, error) {
+	for _, b := range h.backends {
+		id, err := b.Authenticator.Authenticate(ctx, login, password)
+		if errors.Is(err, sso.ErrInvalidCredentials) {
+			continue
+		}
+		if err != nil {
+			log.Printf("%s login: %v", b.Name, err)
+			continue
+		}
+		return h.loginExternal(ctx, id, models.AuthLDAP, b.Groups, b.AllowSignup, b.TrustEmail)
+	}
+	return nil, sso.ErrInvalidCredentials
+}
+
+// joinOrgs adds a new account to the organizations that accept everyone.
+func (h *AuthHandler) joinOrgs(ctx context.Context, u *models.User) {
+	if err := h.orgs.JoinAutoJoin(ctx, u.ID); err != nil {
+		log.Printf("join organizations: user %d: %v", u.ID, err)
+	}
+}
+
+// authMethodAllowed applies the sign-in methods of the user's organizations,
+// to system admins as well.
+func (h *AuthHandler) authMethodAllowed(ctx context.Context, u *models.User, method string) error {
+	ok, err := h.orgs.AuthMethodAllowed(ctx, u.ID, method)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errMethodNotAllowed
+	}
+	return nil
+}
+
+// mfaChallenge returns nil if u can log in with the first factor alone.
+func (h *AuthHandler) mfaChallenge(ctx context.Context, u *models.User) (*mfaChallengeResponse, error) {
+	enabled, err := h.twoFactor.IsEnabled(ctx, u.ID)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	expires := time.Now().Add(mfaTokenTTL)
+	claims := mfaClaims{
+		UserID:           u.ID,
+		Purpose:          "2fa",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expires), IssuedAt: jwt.NewNumericDate(time.Now())},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h.cfg.JWTSecret))
+	if err != nil {
+		return nil, err
+	}
+	return &mfaChallengeResponse{MFARequired: true, MFAToken: token, ExpiresAt: expires}, nil
+}
+
+// startSession creates a session for u and returns its first token pair.
+func (h *AuthHandler) startSession(c *gin.Context, u *models.User) (*authResponse, error) {
+	sessionID, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(time.Duration(h.cfg.RefreshTokenTTL) * time.Minute)
+	if err := h.sessions.Create(c.Request.Context(), sessionID, u.ID, postgres.HashToken(refresh), expires); err != nil {
+		return nil, err
+	}
+	token, tokenExpires, err := h.issueToken(u, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	u.PasswordHash = ""
+	return &authResponse{Token: token, ExpiresAt: tokenExpires, RefreshToken: refresh, User: *u}, nil
+}
+
+func (h *AuthHandler) issueToken(u *models.User, sessionID string) (string, time.Time, error) {
 	expires := time.Now().Add(time.Duration(h.cfg.TokenTTL) * time.Minute)
 	claims := jwt.MapClaims{
 		"user_id": u.ID,
 		"system_role": u.SystemRole,
+		"sid": sessionID,
 		"exp": expires.Unix(),
 		"iat": time.Now().Unix(),
 	}
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return t.SignedString([]byte(h.cfg.JWTSecret))
+	signed, err := t.SignedString([]byte(h.cfg.JWTSecret))
+	return signed, expires, err
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
+
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+// stewardTest is a team with an admin, a member and an artifact, served with
+// the caller taken from the X-User-ID and X-Team-Role headers.
+type stewardTest struct {
+	router   *gin.Engine
+	teamID   int
+	admin    *models.User
+	member   *models.User
+	artifact *models.Artifact
+	contacts *postgres.ContactRepository
+}
+
+func newStewardTest(t *testing.T, db *postgres.DB) *stewardTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	s := &stewardTest{
+		admin:    createLocalUser(t, db, "admin@example.com", "admin-pass"),
+		member:   createLocalUser(t, db, "member@example.com", "member-pass"),
+		contacts: postgres.NewContactRepository(db),
+	}
+	team := &models.Team{OrgID: 1, Name: "Data Engineering", CreatedBy: s.admin.ID}
+	if err := postgres.NewTeamRepository(db).CreateTeam(ctx, team); err != nil {
+		t.Fatal(err)
+	}
+	s.teamID = team.ID
+	members := postgres.NewTeamMemberRepository(db)
+	for _, m := range []struct {
+		user *models.User
+		role string
+	}{{s.admin, access.RoleAdmin}, {s.member, access.RoleMember}} {
+		if err := members.EnsureRole(ctx, team.ID, m.user.ID, m.role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	artifacts := postgres.NewArtifactRepository(db)
+	s.artifact = &models.Artifact{Name: "orders", Type: "table", ProjectName: "warehouse"}
+	if err := artifacts.CreateArtifact(ctx, team.ID, s.artifact); err != nil {
+		t.Fatal(err)
+	}
+
+	ah := NewArtifactHandler(artifacts, postgres.NewOrgRepository(db))
+	ch := NewContactHandler(s.contacts, members, artifacts, postgres.NewTeamRepository(db))
+	s.router = gin.New()
+	// stands in for AuthMiddleware and TeamMembershipMiddleware
+	g := s.router.Group("/api/v1/teams/:teamId", func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.GetHeader("X-User-ID"))
+		c.Set(middleware.CtxUserID, id)
+		c.Set(middleware.CtxTeamID, s.teamID)
+		c.Set(middleware.CtxTeamRole, c.GetHeader("X-Team-Role"))
+	})
+	g.POST("/contacts", ch.CreateContact)
+	g.PUT("/artifacts/:id/owners", ah.SetOwners)
+	g.PUT("/artifacts/:id/certification", ah.Certify)
+	return s
+}
+
+func (s *stewardTest) do(t *testing.T, method, path string, user *models.User, role string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, "/api/v1/teams/"+strconv.Itoa(s.teamID)+path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", strconv.Itoa(user.ID))
+	req.Header.Set("X-Team-Role", role)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// createContact creates a contact linked to userID as the given caller.
+func (s *stewardTest) createContact(t *testing.T, caller *models.User, role, name string, userID int) *httptest.ResponseRecorder {
+	return s.do(t, http.MethodPost, "/contacts", caller, role, models.Contact{Name: name, UserID: &userID})
+}
+
+func (s *stewardTest) setSteward(t *testing.T, caller *models.User, role string, contactID int) *httptest.ResponseRecorder {
+	owners := setOwnersRequest{Owners: []models.ArtifactOwner{{ContactID: contactID, Role: models.OwnerSteward}}}
+	return s.do(t, http.MethodPut, "/artifacts/"+strconv.Itoa(s.artifact.ID)+"/owners", caller, role, owners)
+}
+
+func (s *stewardTest) certify(t *testing.T, caller *models.User, role string) *httptest.ResponseRecorder {
+	req := certifyRequest{Note: "reviewed", ExpiresAt: time.Now().Add(30 * 24 * time.Hour)}
+	return s.do(t, http.MethodPut, "/artifacts/"+strconv.Itoa(s.artifact.ID)+"/certification", caller, role, req)
+}
+
+func contactID(t *testing.T, w *httptest.ResponseRecorder) int {
+	t.Helper()
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create contact: %d %s", w.Code, w.Body)
+	}
+	var contact models.Contact
+	if err := json.Unmarshal(w.Body.Bytes(), &contact); err != nil {
+		t.Fatal(err)
+	}
+	return contact.ID
+}
+
+func TestMemberCannotMakeThemselvesSteward(t *testing.T) {
+	db := testDB(t)
+	s := newStewardTest(t, db)
+
+	if w := s.createContact(t, s.member, access.RoleMember, "Admin", s.admin.ID); w.Code != http.StatusForbidden {
+		t.Errorf("member links another user's account: %d %s", w.Code, w.Body)
+	}
+	own := contactID(t, s.createContact(t, s.member, access.RoleMember, "Member", s.member.ID))
+	if w := s.setSteward(t, s.member, access.RoleMember, own); w.Code != http.StatusForbidden {
+		t.Fatalf("member appoints themselves steward: %d %s", w.Code, w.Body)
+	}
+	if w := s.certify(t, s.member, access.RoleMember); w.Code != http.StatusForbidden {
+		t.Errorf("member certifies: %d %s", w.Code, w.Body)
+	}
+
+	// a steward appointed by an admin may certify
+	if w := s.setSteward(t, s.admin, access.RoleAdmin, own); w.Code != http.StatusOK {
+		t.Fatalf("admin appoints steward: %d %s", w.Code, w.Body)
+	}
+	if w := s.certify(t, s.member, access.RoleMember); w.Code != http.StatusOK {
+		t.Errorf("steward certifies: %d %s", w.Code, w.Body)
+	}
+	// and may not step down or appoint others without an admin
+	if w := s.setSteward(t, s.member, access.RoleMember, contactID(t, s.createContact(t, s.admin, access.RoleAdmin, "Admin", s.admin.ID))); w.Code != http.StatusForbidden {
+		t.Errorf("steward replaces themselves: %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/vcard"
+)
+
+var (
+	phoneRe      = regexp.MustCompile(`^\+?[0-9][0-9 ()-]*[0-9]$`)
+	telegramRe   = regexp.MustCompile(`^@[A-Za-z0-9_]{3,32}$`)
+	slackRe      = regexp.MustCompile(`^(@[A-Za-z0-9][A-Za-z0-9._-]{0,79}|[UW][A-Z0-9]{8,11})$`)
+	mattermostRe = regexp.MustCompile(`^@[a-z][a-z0-9._-]{2,21}$`)
+)
+
+// normalizeChannel checks the format of a channel value and returns it in
+// its canonical form: a bare email address, @handles for messengers.
+func normalizeChannel(ch models.ContactChannel) (models.ContactChannel, error) {
+	v := strings.TrimSpace(ch.Value)
+	switch ch.Kind {
+	case models.ChannelEmail:
+		addr, err := mail.ParseAddress(v)
+		if err != nil || addr.Name != "" {
+			return ch, errors.New("invalid email address")
+		}
+		v = addr.Address
+	case models.ChannelPhone:
+		digits := 0
+		for _, r := range v {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		if !phoneRe.MatchString(v) || digits < 7 || digits > 15 {
+			return ch, errors.New("invalid phone number; use digits with an optional leading +")
+		}
+	case models.ChannelTelegram:
+		v = strings.TrimPrefix(strings.TrimPrefix(v, "https://t.me/"), "t.me/")
+		if !strings.HasPrefix(v, "@") {
+			v = "@" + v
+		}
+		if !telegramRe.MatchString(v) {
+			return ch, errors.New("invalid Telegram username")
+		}
+	case models.ChannelSlack:
+		if !strings.HasPrefix(v, "@") && !slackRe.MatchString(v) {
+			v = "@" + v
+		}
+		if !slackRe.MatchString(v) {
+			return ch, errors.New("invalid Slack handle or member ID")
+		}
+	case models.ChannelMattermost:
+		if !strings.HasPrefix(v, "@") {
+			v = "@" + v
+		}
+		v = strings.ToLower(v)
+		if !mattermostRe.MatchString(v) {
+			return ch, errors.New("invalid Mattermost username")
+		}
+	default:
+		return ch, errors.New("unknown channel kind")
+	}
+	return models.ContactChannel{Kind: ch.Kind, Value: v}, nil
+}
+
+// prepareChannels validates the channels of a contact being saved (id 0 on
+// creation). Requests without channels keep working with telegram_contact
+// alone: it replaces the Telegram channels and other channels are kept.
+// telegram_contact is then set to the first Telegram channel.
+func (h *ContactHandler) prepareChannels(c *gin.Context, teamID, id int, contact *models.Contact) bool {
+	channels := contact.Channels
+	if channels == nil {
+		channels = []models.ContactChannel{}
+		if contact.TelegramContact != "" {
+			channels = append(channels, models.ContactChannel{Kind: models.ChannelTelegram, Value: contact.TelegramContact})
+		}
+		if id != 0 {
+			cur, err := h.repo.GetContactByID(c.Request.Context(), teamID, id)
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
+				return false
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load contact"})
+				return false
+			}
+			for _, ch := range cur.Channels {
+				if ch.Kind != models.ChannelTelegram {
+					channels = append(channels, ch)
+				}
+			}
+		}
+	}
+
+	seen := map[models.ContactChannel]bool{}
+	contact.Channels = make([]models.ContactChannel, 0, len(channels))
+	contact.TelegramContact = ""
+	for i, ch := range channels {
+		ch, err := normalizeChannel(ch)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("channels[%d]: %s", i, err)})
+			return false
+		}
+		if seen[ch] {
+			continue
+		}
+		seen[ch] = true
+		contact.Channels = append(contact.Channels, ch)
+		if ch.Kind == models.ChannelTelegram && contact.TelegramContact == "" {
+			contact.TelegramContact = ch.Value
+		}
+	}
+	return true
+}
+
+// GET /api/v1/teams/:teamId/contacts/:id/vcard
+func (h *ContactHandler) VCard(c *gin.Context) {
+	teamID, ok := h.teamID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	contact, err := h.repo.GetContactByID(c.Request.Context(), teamID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
+		return
+	}
+	h.writeVCard(c, teamID, fmt.Sprintf("contact-%d.vcf", id), []models.Contact{*contact})
+}
+
+// GET /api/v1/teams/:teamId/contacts.vcf exports the whole team directory.
+func (h *ContactHandler) DirectoryVCard(c *gin.Context) {
+	teamID, ok := h.teamID(c)
+	if !ok {
+		return
+	}
+	contacts, err := h.repo.GetAllContacts(c.Request.Context(), teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load contacts"})
+		return
+	}
+	h.writeVCard(c, teamID, fmt.Sprintf("team-%d-contacts.vcf", teamID), contacts)
+}
+
+func (h *ContactHandler) writeVCard(c *gin.Context, teamID int, filename string, contacts []models.Contact) {
+	team, err := h.teams.GetByID(c.Request.Context(), teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load team"})
+		return
+	}
+	var buf bytes.Buffer
+	if err := vcard.Write(&buf, team.Name, contacts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build vCard"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, vcard.ContentType, buf.Bytes())
+}
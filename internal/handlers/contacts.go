@@ -1,20 +1,74 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/middleware"
 	"go-data-catalog/internal/models"
 	"go-data-catalog/internal/repository/postgres"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type ContactHandler struct {
-	repo *postgres.ContactRepository
+	repo      *postgres.ContactRepository
+	members   *postgres.TeamMemberRepository
+	artifacts *postgres.ArtifactRepository
+	teams     *postgres.TeamRepository
 }
 
-func NewContactHandler(repo *postgres.ContactRepository) *ContactHandler {
-	return &ContactHandler{repo: repo}
+// reassignRequest names the contact that takes over the artifacts.
+type reassignRequest struct {
+	ToContactID int `json:"to_contact_id" binding:"required,min=1"`
+}
+
+type reassignResponse struct {
+	Reassigned int `json:"reassigned"` // artifacts whose ownership moved
+}
+
+func NewContactHandler(repo *postgres.ContactRepository, members *postgres.TeamMemberRepository, artifacts *postgres.ArtifactRepository, teams *postgres.TeamRepository) *ContactHandler {
+	return &ContactHandler{repo: repo, members: members, artifacts: artifacts, teams: teams}
+}
+
+// linkedUserOK checks that a linked account is an active member of the team.
+// Linking an account other than the caller's needs certifications:manage,
+// since a linked contact may act as data steward; previous is the account
+// the contact is already linked to, if any, and may be kept.
+func (h *ContactHandler) linkedUserOK(c *gin.Context, teamID int, contact *models.Contact, previous *int) bool {
+	if contact.UserID == nil {
+		return true
+	}
+	unchanged := previous != nil && *previous == *contact.UserID
+	if !unchanged && *contact.UserID != c.GetInt(middleware.CtxUserID) &&
+		!access.Allowed(c.GetString(middleware.CtxTeamRole), access.Permission{Resource: access.Certifications, Action: access.Manage}) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only team admins may link a contact to another user's account"})
+		return false
+	}
+	ok, err := h.members.IsMember(c.Request.Context(), teamID, *contact.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check membership"})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id must be an active member of the team"})
+		return false
+	}
+	return true
+}
+
+// saveFailed reports an error of CreateContact or UpdateContact.
+func saveFailed(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrContactUserTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
 
 func (h *ContactHandler) teamID(c *gin.Context) (int, bool) {
@@ -29,7 +83,8 @@ func (h *ContactHandler) teamID(c *gin.Context) (int, bool) {
 
 func (h *ContactHandler) GetContacts(c *gin.Context) {
 	teamID, ok := h.teamID(c); if !ok { return }
-	contacts, err := h.repo.GetAllContacts(c.Request.Context(), teamID)
+	limit, offset, ok := pageParams(c); if !ok { return }
+	contacts, err := h.repo.GetContactsPage(c.Request.Context(), teamID, c.Query("q"), c.Query("department"), limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -63,9 +118,11 @@ func (h *ContactHandler) CreateContact(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
+	if !h.linkedUserOK(c, teamID, &contact, nil) { return }
+	if !h.prepareChannels(c, teamID, 0, &contact) { return }
 	
 	if err := h.repo.CreateContact(c.Request.Context(), teamID, &contact); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		saveFailed(c, err)
 		return
 	}
 	
@@ -86,9 +143,16 @@ func (h *ContactHandler) UpdateContact(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
+	current, err := h.repo.GetContactByID(c.Request.Context(), teamID, id)
+	if err != nil {
+		saveFailed(c, err)
+		return
+	}
+	if !h.linkedUserOK(c, teamID, &contact, current.UserID) { return }
+	if !h.prepareChannels(c, teamID, id, &contact) { return }
 	
 	if err := h.repo.UpdateContact(c.Request.Context(), teamID, id, &contact); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		saveFailed(c, err)
 		return
 	}
 	
@@ -111,3 +175,33 @@ func (h *ContactHandler) DeleteContact(c *gin.Context) {
 	
 	c.JSON(http.StatusOK, gin.H{"message": "Contact deleted successfully"})
 }
+
+// POST /api/v1/teams/:teamId/contacts/:id/reassign moves all artifacts owned
+// by a departing contact to another contact of the team, keeping the roles.
+func (h *ContactHandler) Reassign(c *gin.Context) {
+	teamID, ok := h.teamID(c); if !ok { return }
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	var req reassignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if req.ToContactID == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to_contact_id must be another contact"})
+		return
+	}
+	n, err := h.artifacts.ReassignContact(c.Request.Context(), teamID, id, req.ToContactID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reassign artifacts"})
+		return
+	}
+	c.JSON(http.StatusOK, reassignResponse{Reassigned: n})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-data-catalog/internal/docsite"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+type DocsHandler struct {
+	teams     *postgres.TeamRepository
+	artifacts *postgres.ArtifactRepository
+	fields    *postgres.ArtifactFieldRepository
+	contacts  *postgres.ContactRepository
+	lineage   *postgres.LineageRepository
+}
+
+func NewDocsHandler(teams *postgres.TeamRepository, artifacts *postgres.ArtifactRepository, fields *postgres.ArtifactFieldRepository, contacts *postgres.ContactRepository, lineage *postgres.LineageRepository) *DocsHandler {
+	return &DocsHandler{teams: teams, artifacts: artifacts, fields: fields, contacts: contacts, lineage: lineage}
+}
+
+// GET /api/v1/teams/:teamId/docs.zip
+func (h *DocsHandler) DownloadSite(c *gin.Context) {
+	teamID := c.GetInt(middleware.CtxTeamID)
+	cat, err := docsite.Load(c.Request.Context(), h.teams, h.artifacts, h.fields, h.contacts, h.lineage, teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// build into memory first so that a rendering error still yields a JSON response
+	var buf bytes.Buffer
+	if err := docsite.Build(&buf, cat); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="catalog-team-%d.zip"`, teamID))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
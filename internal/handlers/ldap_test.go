@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+	"go-data-catalog/internal/sso"
+	"go-data-catalog/internal/sso/ssotest"
+)
+
+const testBaseDN = "dc=example,dc=com"
+
+// ldapTest runs password login and directory linking against the mock LDAP
+// server, which knows alice (password "secret", groups data-eng and
+// analysts).
+type ldapTest struct {
+	db       *postgres.DB
+	ldap     *sso.LDAP
+	router   *gin.Engine
+	provider string
+}
+
+func newLDAPTest(t *testing.T, db *postgres.DB, trustEmail bool, groups sso.GroupMapping) *ldapTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	d := ssotest.NewDirectory(testBaseDN, "admin")
+	if err := d.AddUser("alice:secret:alice@example.com:Alice Example:data-eng,analysts"); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go d.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	cfg := testConfig()
+	cfg.LDAPURL = "ldap://" + ln.Addr().String()
+	cfg.LDAPBindDN = "cn=admin," + testBaseDN
+	cfg.LDAPBindPassword = "admin"
+	cfg.LDAPBaseDN = testBaseDN
+	cfg.LDAPUserFilter = "(|(mail={login})(uid={login}))"
+	cfg.LDAPEmailAttribute = "mail"
+	cfg.LDAPNameAttribute = "cn"
+	cfg.LDAPGroupAttribute = "memberOf"
+	l, err := sso.NewLDAP(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestAuthHandler(db, cfg)
+	h.backends = []sso.PasswordBackend{{Name: "LDAP", Authenticator: l, Groups: groups, AllowSignup: true, TrustEmail: trustEmail}}
+
+	r := gin.New()
+	r.POST("/api/v1/auth/login", h.Login)
+	// stands in for AuthMiddleware
+	r.POST("/api/v1/me/identities/ldap", func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.GetHeader("X-User-ID"))
+		c.Set(middleware.CtxUserID, id)
+	}, h.LinkDirectory)
+	return &ldapTest{db: db, ldap: l, router: r, provider: l.Provider()}
+}
+
+func (l *ldapTest) post(t *testing.T, path string, userID int, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != 0 {
+		req.Header.Set("X-User-ID", strconv.Itoa(userID))
+	}
+	w := httptest.NewRecorder()
+	l.router.ServeHTTP(w, req)
+	return w
+}
+
+func (l *ldapTest) login(t *testing.T, login, password string) *httptest.ResponseRecorder {
+	return l.post(t, "/api/v1/auth/login", 0, loginRequest{Email: login, Password: password})
+}
+
+func (l *ldapTest) link(t *testing.T, userID int, login, password string) *httptest.ResponseRecorder {
+	return l.post(t, "/api/v1/me/identities/ldap", userID, linkDirectoryRequest{Login: login, Password: password})
+}
+
+// linkedUser returns the user alice's directory account is linked to, or 0.
+func (l *ldapTest) linkedUser(t *testing.T) int {
+	t.Helper()
+	id, err := postgres.NewIdentityRepository(l.db).LinkedUserID(context.Background(), l.provider, "uid=alice,ou=people,"+testBaseDN)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func loggedInAs(t *testing.T, w *httptest.ResponseRecorder) *models.User {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", w.Code, w.Body)
+	}
+	var res authResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil || res.Token == "" {
+		t.Fatalf("login response %s: %v", w.Body, err)
+	}
+	return &res.User
+}
+
+// createLocalUser creates an account with a local password.
+func createLocalUser(t *testing.T, db *postgres.DB, email, password string) *models.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &models.User{Email: email, PasswordHash: string(hash), Name: email, SystemRole: "user", IsActive: true, EmailVerified: true}
+	if err := postgres.NewUserRepository(db).CreateUser(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestLDAPLoginSignsUpWithGroups(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	owner := createLocalUser(t, db, "owner@example.com", "owner-pass")
+	teams := postgres.NewTeamRepository(db)
+	var teamIDs []int
+	for _, name := range []string{"Data Engineering", "Analytics"} {
+		team := &models.Team{OrgID: 1, Name: name, CreatedBy: owner.ID}
+		if err := teams.CreateTeam(ctx, team); err != nil {
+			t.Fatal(err)
+		}
+		teamIDs = append(teamIDs, team.ID)
+	}
+	groups := sso.GroupMapping{
+		"data-eng": {{TeamID: teamIDs[0], Role: "member"}},
+		"analysts": {{TeamID: teamIDs[0], Role: "viewer"}, {TeamID: teamIDs[1], Role: "admin"}},
+	}
+
+	for _, trust := range []bool{false, true} {
+		t.Run("trust email "+strconv.FormatBool(trust), func(t *testing.T) {
+			l := newLDAPTest(t, db, trust, groups)
+			u := loggedInAs(t, l.login(t, "alice", "secret"))
+			if u.Email != "alice@example.com" || u.EmailVerified != trust {
+				t.Errorf("signed up %+v", u)
+			}
+			if got := l.linkedUser(t); got != u.ID {
+				t.Errorf("directory account linked to %d, want %d", got, u.ID)
+			}
+			members := postgres.NewTeamMemberRepository(db)
+			for i, want := range []string{"member", "admin"} {
+				if role, err := members.GetUserRole(ctx, teamIDs[i], u.ID); err != nil || role != want {
+					t.Errorf("team %d: role %q (%v), want %q", teamIDs[i], role, err, want)
+				}
+			}
+			if _, err := db.Pool.Exec(ctx, "DELETE FROM users WHERE id = $1", u.ID); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLDAPLoginDoesNotLinkByEmail(t *testing.T) {
+	db := testDB(t)
+	l := newLDAPTest(t, db, false, nil)
+	local := createLocalUser(t, db, "alice@example.com", "local-pass")
+
+	for _, login := range []string{"alice", "alice@example.com"} {
+		w := l.login(t, login, "secret")
+		if w.Code != http.StatusForbidden || !bytes.Contains(w.Body.Bytes(), []byte(errLinkRequired.Error())) {
+			t.Errorf("%s: %d %s, want 403 %q", login, w.Code, w.Body, errLinkRequired)
+		}
+	}
+	if got := l.linkedUser(t); got != 0 {
+		t.Errorf("directory account linked to %d", got)
+	}
+	// the local password still works
+	if u := loggedInAs(t, l.login(t, "alice@example.com", "local-pass")); u.ID != local.ID {
+		t.Errorf("logged in as %d, want %d", u.ID, local.ID)
+	}
+}
+
+func TestLDAPLoginLinksByEmailWhenTrusted(t *testing.T) {
+	db := testDB(t)
+	l := newLDAPTest(t, db, true, nil)
+	local := createLocalUser(t, db, "alice@example.com", "local-pass")
+
+	if u := loggedInAs(t, l.login(t, "alice", "secret")); u.ID != local.ID {
+		t.Errorf("logged in as %d, want %d", u.ID, local.ID)
+	}
+	if got := l.linkedUser(t); got != local.ID {
+		t.Errorf("directory account linked to %d, want %d", got, local.ID)
+	}
+}
+
+func TestLinkDirectory(t *testing.T) {
+	db := testDB(t)
+	l := newLDAPTest(t, db, false, nil)
+	// the local account may have another address than the directory
+	local := createLocalUser(t, db, "a.example@example.org", "local-pass")
+	other := createLocalUser(t, db, "mallory@example.com", "other-pass")
+
+	if w := l.link(t, local.ID, "alice", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password: %d %s", w.Code, w.Body)
+	}
+	if got := l.linkedUser(t); got != 0 {
+		t.Fatalf("linked to %d after a wrong password", got)
+	}
+	if w := l.link(t, local.ID, "alice", "secret"); w.Code != http.StatusNoContent {
+		t.Fatalf("link: %d %s", w.Code, w.Body)
+	}
+	if got := l.linkedUser(t); got != local.ID {
+		t.Errorf("directory account linked to %d, want %d", got, local.ID)
+	}
+	// linking again is a no-op
+	if w := l.link(t, local.ID, "alice@example.com", "secret"); w.Code != http.StatusNoContent {
+		t.Errorf("relink: %d %s", w.Code, w.Body)
+	}
+	if w := l.link(t, other.ID, "alice", "secret"); w.Code != http.StatusConflict {
+		t.Errorf("link to another user: %d %s", w.Code, w.Body)
+	}
+	if u := loggedInAs(t, l.login(t, "alice", "secret")); u.ID != local.ID {
+		t.Errorf("directory login as %d, want %d", u.ID, local.ID)
+	}
+}
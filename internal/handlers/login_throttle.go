@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-data-catalog/internal/models"
+)
+
+// Results recorded in the login history besides "ok".
+const (
+	attemptInvalidCredentials = "invalid_credentials"
+	attemptInvalidCode        = "invalid_code"
+	attemptThrottled          = "throttled"
+	attemptMFARequired        = "mfa_required"
+	attemptRejected           = "rejected"
+	attemptDeactivated        = "deactivated"
+	attemptEmailNotVerified   = "email_not_verified"
+)
+
+// throttled answers 429 and returns true while the login or the client IP
+// has to wait after failed attempts. Credentials are not checked then, so
+// guessing during the wait gains nothing.
+func (h *AuthHandler) throttled(c *gin.Context, login, method string) bool {
+	wait, err := h.limiter.Wait(c.Request.Context(), login, c.ClientIP())
+	if err != nil {
+		log.Printf("login throttle: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "login failed"})
+		return true
+	}
+	if wait <= 0 {
+		return false
+	}
+	h.recordAttempt(c, login, nil, method, attemptThrottled)
+	secs := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(secs))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed login attempts, try again later", "retry_after": secs})
+	return true
+}
+
+// loginFailed counts a wrong password or code against the login and the IP.
+func (h *AuthHandler) loginFailed(c *gin.Context, login string, userID *int, method, result string) {
+	if err := h.limiter.Failed(c.Request.Context(), login, c.ClientIP()); err != nil {
+		log.Printf("login throttle: %v", err)
+	}
+	h.recordAttempt(c, login, userID, method, result)
+}
+
+// loginSucceeded clears the failures of the login (and of the account email,
+// if the user logged in with a directory login) and records the success.
+func (h *AuthHandler) loginSucceeded(c *gin.Context, login string, u *models.User, method string) {
+	ctx := c.Request.Context()
+	for _, l := range []string{login, u.Email} {
+		if err := h.limiter.Succeeded(ctx, l); err != nil {
+			log.Printf("login throttle: %v", err)
+		}
+		if strings.EqualFold(login, u.Email) {
+			break
+		}
+	}
+	h.recordAttempt(c, login, &u.ID, method, "ok")
+}
+
+func (h *AuthHandler) recordAttempt(c *gin.Context, login string, userID *int, method, result string) {
+	a := &models.LoginAttempt{
+		UserID:    userID,
+		Login:     login,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Method:    method,
+		Result:    result,
+	}
+	if err := h.attempts.Record(c.Request.Context(), a); err != nil {
+		log.Printf("login history: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+// NotificationsHandler serves the current user's in-app notifications.
+type NotificationsHandler struct {
+	notify *postgres.NotificationRepository
+}
+
+type notificationsResponse struct {
+	Unread        int                   `json:"unread"`
+	Notifications []models.Notification `json:"notifications"`
+}
+
+func NewNotificationsHandler(notify *postgres.NotificationRepository) *NotificationsHandler {
+	return &NotificationsHandler{notify: notify}
+}
+
+// GET /me/notifications?unread=true&limit=&offset=
+func (h *NotificationsHandler) List(c *gin.Context) {
+	limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	userID := c.GetInt(middleware.CtxUserID)
+	items, err := h.notify.ListForUser(c.Request.Context(), userID, c.Query("unread") == "true", limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load notifications"})
+		return
+	}
+	unread, err := h.notify.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, notificationsResponse{Unread: unread, Notifications: items})
+}
+
+// POST /me/notifications/:id/read
+func (h *NotificationsHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	err = h.notify.MarkRead(c.Request.Context(), c.GetInt(middleware.CtxUserID), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notification"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /me/notifications/read-all
+func (h *NotificationsHandler) MarkAllRead(c *gin.Context) {
+	if err := h.notify.MarkAllRead(c.Request.Context(), c.GetInt(middleware.CtxUserID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notifications"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
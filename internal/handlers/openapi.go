@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/openapi"
+	"go-data-catalog/internal/render"
+)
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+const tokenDescription = "The token secret is returned only in this response. Use it as a bearer token. Tokens with only the read scope may use GET requests only. Token management itself requires an interactive login."
+
+var joinRequestStatuses = []string{"pending", "approved", "rejected", "cancelled"}
+
+var pageQuery = []openapi.Param{
+	{Name: "limit", Type: "integer", Description: "page size (max 500); omit to get the whole list"},
+	{Name: "offset", Type: "integer", Description: "number of items to skip"},
+}
+
+// APISpec documents every route registered in cmd/server. The server refuses
+// to start if a route is missing here, so new routes must be added together
+// with their entry.
+func APISpec() *openapi.Spec {
+	const (
+		bad       = http.StatusBadRequest
+		forbidden = http.StatusForbidden
+		notFound  = http.StatusNotFound
+		internal  = http.StatusInternalServerError
+		throttled = http.StatusTooManyRequests
+	)
+	return &openapi.Spec{
+		Title:   "Go Data Catalog API",
+		Version: "1.0.0",
+		Ops: []openapi.Operation{
+			{Method: "GET", Path: "/health", Tag: "system", Summary: "Health check", Public: true, Response: statusResponse{}},
+			{Method: "GET", Path: "/api/v1/openapi.json", Tag: "system", Summary: "This OpenAPI document", Public: true, Response: map[string]any{}},
+			{Method: "GET", Path: "/api/v1/docs", Tag: "system", Summary: "Interactive API documentation", Public: true, ContentType: "text/html"},
+
+			{Method: "POST", Path: "/api/v1/auth/register", Tag: "auth", Summary: "Register a user", Description: "The password must satisfy the password policy (PASSWORD_MIN_LENGTH, breached-password list). A verification email is sent; with REQUIRE_EMAIL_VERIFICATION the response is {verification_required: true, user} and no tokens.", Public: true, Request: registerRequest{}, Status: http.StatusCreated, Response: authResponse{}, Errors: []int{bad}},
+			{Method: "POST", Path: "/api/v1/auth/login", Tag: "auth", Summary: "Log in", Description: "Returns 403 for deactivated accounts and, with REQUIRE_EMAIL_VERIFICATION, unverified emails. Also 403 when none of the user's organizations allows password sign-in (directory accounts: ldap), and when a directory account has the email of an existing account it is not linked to (see POST /api/v1/me/identities/ldap). With two-factor authentication enabled the response is {mfa_required: true, mfa_token, expires_at} instead; finish with POST /api/v1/auth/login/2fa. After repeated failures the account or the client IP has to wait: 429 with Retry-After.", Public: true, Request: loginRequest{}, Response: authResponse{}, Errors: []int{bad, http.StatusUnauthorized, forbidden, throttled}},
+			{Method: "POST", Path: "/api/v1/auth/login/2fa", Tag: "auth", Summary: "Finish a login with a TOTP or recovery code", Description: "Wrong codes count as failed logins of the account, like wrong passwords.", Public: true, Request: loginTwoFactorRequest{}, Response: authResponse{}, Errors: []int{bad, http.StatusUnauthorized, throttled}},
+			{Method: "POST", Path: "/api/v1/auth/refresh", Tag: "auth", Summary: "Exchange a refresh token for a new token pair", Description: "Refresh tokens are single-use. Presenting a refresh token that was already exchanged revokes the whole session.", Public: true, Request: refreshRequest{}, Response: authResponse{}, Errors: []int{bad, http.StatusUnauthorized}},
+			{Method: "POST", Path: "/api/v1/auth/verify-email", Tag: "auth", Summary: "Confirm an email address with the emailed token", Public: true, Request: emailTokenRequest{}, Status: http.StatusNoContent, Errors: []int{bad, internal}},
+			{Method: "POST", Path: "/api/v1/auth/verify-email/resend", Tag: "auth", Summary: "Send a new verification email", Description: "Always 202, whether or not the address belongs to an unverified account.", Public: true, Request: emailRequest{}, Status: http.StatusAccepted, Errors: []int{bad}},
+			{Method: "POST", Path: "/api/v1/auth/password/forgot", Tag: "auth", Summary: "Email a password reset link", Description: "Always 202. Only accounts with a local password get an email; SSO and directory accounts reset their password at the provider.", Public: true, Request: emailRequest{}, Status: http.StatusAccepted, Errors: []int{bad}},
+			{Method: "POST", Path: "/api/v1/auth/password/reset", Tag: "auth", Summary: "Set a new password with the emailed token", Description: "Revokes all sessions of the user.", Public: true, Request: resetPasswordRequest{}, Status: http.StatusNoContent, Errors: []int{bad, internal}},
+			{Method: "GET", Path: "/api/v1/auth/providers", Tag: "auth", Summary: "Available login methods", Public: true, Response: providersResponse{}},
+			{Method: "GET", Path: "/api/v1/auth/oidc/login", Tag: "auth", Summary: "Start OpenID Connect login", Description: "Redirects the browser to the identity provider (authorization code flow with PKCE).", Public: true, Status: http.StatusFound, Errors: []int{notFound, http.StatusBadGateway}},
+			{Method: "GET", Path: "/api/v1/auth/oidc/callback", Tag: "auth", Summary: "OpenID Connect redirect target", Description: "Finishes the login and redirects to OIDC_POST_LOGIN_URL. The URL fragment holds token, refresh_token and expires_at; mfa_token when a second factor is needed; or error.", Public: true, Query: []openapi.Param{{Name: "code"}, {Name: "state"}, {Name: "error"}}, Status: http.StatusFound, Errors: []int{notFound}},
+			{Method: "POST", Path: "/api/v1/auth/logout", Tag: "auth", Summary: "Revoke the current session", Status: http.StatusNoContent, Errors: []int{internal}},
+			{Method: "POST", Path: "/api/v1/auth/logout-all", Tag: "auth", Summary: "Revoke all sessions of the current user", Status: http.StatusNoContent, Errors: []int{internal}},
+
+			{Method: "GET", Path: "/api/v1/teams", Tag: "teams", Summary: "Search teams by name", Description: "Only teams of the user's organizations are searched. Public teams match a substring of the name, discoverable teams only their exact name (case-insensitive). The user's own teams are always found; hidden teams never are.", Query: []openapi.Param{{Name: "search", Description: "substring of the team name"}}, Response: []models.Team{}, Errors: []int{internal}},
+			{Method: "POST", Path: "/api/v1/teams", Tag: "teams", Summary: "Create a team; the creator becomes its owner", Description: "org_id may be omitted when the user belongs to a single organization. Team names are unique across organizations.", Request: createTeamRequest{}, Status: http.StatusCreated, Response: models.Team{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/join", Tag: "teams", Summary: "Request to join a team", Description: "The body is optional. Teams with join_policy open approve the request at once, invite_only and archived teams refuse it (403) and hidden teams and teams of other organizations are not found (404). A user has at most one pending request per team (409). The team's owners and admins get an in-app notification.", Request: joinRequestBody{}, Status: http.StatusCreated, Response: models.JoinRequest{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict}},
+			{Method: "GET", Path: "/api/v1/me/join-requests", Tag: "teams", Summary: "Join requests of the current user", Query: []openapi.Param{{Name: "status", Enum: joinRequestStatuses}}, Response: []models.JoinRequest{}, Errors: []int{bad, internal}},
+			{Method: "POST", Path: "/api/v1/me/join-requests/:id/cancel", Tag: "teams", Summary: "Cancel my pending join request", Status: http.StatusNoContent, Errors: []int{bad, notFound, http.StatusConflict, internal}},
+			{Method: "GET", Path: "/api/v1/me/artifacts", Tag: "owners", Summary: "Artifacts the current user owns", Description: "Artifacts of the user's teams owned by contacts linked to the user's account, with the team name and the user's roles.", Query: append([]openapi.Param{{Name: "role", Enum: ownerRoles}}, pageQuery...), Response: []models.MyArtifact{}, Errors: []int{bad, internal}},
+			{Method: "GET", Path: "/api/v1/me/teams", Tag: "teams", Summary: "Teams of the current user", Response: []models.Team{}, Errors: []int{internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/requests", Tag: "teams", Summary: "List join requests (owner/admin)", Query: []openapi.Param{{Name: "status", Enum: joinRequestStatuses}}, Response: []models.JoinRequest{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/requests/:id/:action", Tag: "teams", Summary: "Approve or reject a join request (owner/admin)", Description: "The body with a comment for the requester is optional. Only pending requests can be decided (409). Approving a user whose membership a team admin deactivated is refused (409): reactivate the member instead. The requester gets an in-app notification.", PathEnums: map[string][]string{"action": {"approve", "reject"}}, Request: joinDecisionBody{}, Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/invitations", Tag: "teams", Summary: "Invite someone to the team (owner/admin)", Description: "Returns the invitation link; its token is shown only in this response. With email the invitation is single-use, only that user (with a verified email) may accept it, and the link is sent by email. Without email max_uses limits the number of people who may join (default 1, 0 for unlimited). Only the owner invites admins. Expires after expires_in_days (default 7).", Request: createInvitationRequest{}, Status: http.StatusCreated, Response: createdInvitationResponse{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/invitations", Tag: "teams", Summary: "List invitations (owner/admin)", Query: []openapi.Param{{Name: "status", Enum: []string{"active", "used", "expired", "revoked"}}}, Response: []models.TeamInvitation{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/invitations/:id", Tag: "teams", Summary: "Revoke an invitation (owner/admin)", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/invitations/accept", Tag: "teams", Summary: "Join a team with an invitation token", Description: "Adds the current user with the invitation's role and closes their pending join requests to the team.", Request: acceptInvitationRequest{}, Response: acceptInvitationResponse{}, Errors: []int{bad, forbidden, http.StatusConflict, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/me/permissions", Tag: "teams", Summary: "Actions the current user may perform in the team", Description: "Resources: team, members, join_requests, invitations, service_accounts, artifacts, fields, contacts, docs, shares, lineage, certifications. Actions: read, create, update, delete, manage, leave, transfer. Viewers may only read; members also edit artifacts, fields and contacts; admins manage members, join requests, invitations and service accounts; the owner changes team settings and transfers ownership. certifications:update is granted to members but, without certifications:manage, only for artifacts they are a data steward of. Only admins link a contact to another user's account or change the data stewards of an artifact. Non-members of a team with a public catalog get the guest role and may read the team, artifacts and fields. In an archived team only reading and actions on the team itself are listed. Team routes return 403 for anything not listed.", Response: permissionsResponse{}, Errors: []int{forbidden}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/members", Tag: "teams", Summary: "List team members with user details", Query: []openapi.Param{{Name: "status", Enum: []string{"active", "inactive"}}}, Response: []models.TeamMemberDetail{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/members/:userId/role", Tag: "teams", Summary: "Change a member's role (owner/admin)", Description: "Only the owner grants or revokes admin. The owner role changes only through transfer-ownership.", Request: setMemberRoleRequest{}, Response: models.TeamMemberDetail{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/members/:userId/status", Tag: "teams", Summary: "Deactivate or reactivate a member (owner/admin)", Description: "Inactive members keep their role but have no access to the team.", Request: setMemberStatusRequest{}, Response: models.TeamMemberDetail{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/members/:userId", Tag: "teams", Summary: "Remove a member (owner/admin)", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, http.StatusConflict}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/leave", Tag: "teams", Summary: "Leave the team", Description: "The last owner must transfer ownership first (409).", Status: http.StatusNoContent, Errors: []int{forbidden, http.StatusConflict}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/transfer-ownership", Tag: "teams", Summary: "Make another active member the owner (owner)", Description: "The previous owner stays in the team as admin.", Request: transferOwnershipRequest{}, Response: models.TeamMemberDetail{}, Errors: []int{bad, forbidden, http.StatusConflict}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/require-2fa", Tag: "teams", Summary: "Require two-factor authentication from team members (owner)", Description: "Members without 2FA get 403 on all team routes until they enable it. Service accounts are exempt.", Request: require2FARequest{}, Response: models.Team{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId", Tag: "teams", Summary: "Rename a team or change its description (owner)", Request: updateTeamRequest{}, Response: models.Team{}, Errors: []int{bad, forbidden, http.StatusConflict, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/archive", Tag: "teams", Summary: "Archive a team (owner)", Description: "An archived team is read-only: only reading and the team's own settings, unarchiving, leaving and deleting are allowed (403 otherwise). Search finds it only for its members, and it cannot be joined.", Response: models.Team{}, Errors: []int{forbidden, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/unarchive", Tag: "teams", Summary: "Bring an archived team back (owner)", Response: models.Team{}, Errors: []int{forbidden, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId", Tag: "teams", Summary: "Delete a team with all its data (owner)", Description: "confirm_name must repeat the team name. A full snapshot of the team is saved first; an org admin can restore it. Not allowed with an API token.", Request: deleteTeamRequest{}, Response: models.TeamSnapshot{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/settings", Tag: "teams", Summary: "Change team visibility, join policy and public catalog (owner)", Description: "visibility: public (found by search), discoverable (found by its exact name) or hidden (404 to non-members). join_policy: open, approval or invite_only. public_catalog lets any logged-in user read the artifacts and fields of a public team.", Request: teamSettingsRequest{}, Response: models.Team{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/docs.zip", Tag: "teams", Summary: "Static documentation site of the team catalog", ContentType: "application/zip", Errors: []int{forbidden, internal}},
+
+			{Method: "GET", Path: "/api/v1/me/notifications", Tag: "notifications", Summary: "In-app notifications of the current user, newest first", Query: append([]openapi.Param{{Name: "unread", Enum: []string{"true"}, Description: "only unread notifications"}}, pageQuery...), Response: notificationsResponse{}, Errors: []int{bad, internal}},
+			{Method: "POST", Path: "/api/v1/me/notifications/:id/read", Tag: "notifications", Summary: "Mark a notification as read", Status: http.StatusNoContent, Errors: []int{bad, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/me/notifications/read-all", Tag: "notifications", Summary: "Mark all notifications as read", Status: http.StatusNoContent, Errors: []int{internal}},
+
+			{Method: "POST", Path: "/api/v1/me/identities/ldap", Tag: "auth", Summary: "Link a directory account to the current user", Description: "Checks the directory login and password like LDAP login does. Afterwards the directory account logs in to this user. Without LDAP_TRUST_EMAIL this is the only way to use a directory account whose email belongs to an existing account. 409 if the directory account is linked to another user. Failures count as failed logins of the directory login.", Request: linkDirectoryRequest{}, Status: http.StatusNoContent, Errors: []int{bad, http.StatusUnauthorized, forbidden, notFound, http.StatusConflict, throttled, internal}},
+
+			{Method: "GET", Path: "/api/v1/me/2fa", Tag: "2fa", Summary: "Two-factor authentication status", Response: twoFactorStatusResponse{}, Errors: []int{forbidden, internal}},
+			{Method: "POST", Path: "/api/v1/me/2fa/enroll", Tag: "2fa", Summary: "Start TOTP enrollment", Description: "Returns a new secret with its otpauth:// URI and QR code. 2FA is enabled only after POST /api/v1/me/2fa/confirm.", Response: twoFactorEnrollResponse{}, Errors: []int{forbidden, http.StatusConflict, internal}},
+			{Method: "POST", Path: "/api/v1/me/2fa/confirm", Tag: "2fa", Summary: "Enable 2FA with the first TOTP code", Description: "Returns one-time recovery codes; they are shown only once.", Request: twoFactorCodeRequest{}, Response: recoveryCodesResponse{}, Errors: []int{bad, forbidden, http.StatusConflict, internal}},
+			{Method: "POST", Path: "/api/v1/me/2fa/disable", Tag: "2fa", Summary: "Disable 2FA", Request: twoFactorCodeRequest{}, Status: http.StatusNoContent, Errors: []int{bad, http.StatusUnauthorized, forbidden, internal}},
+			{Method: "POST", Path: "/api/v1/me/2fa/recovery-codes", Tag: "2fa", Summary: "Replace all recovery codes", Request: twoFactorCodeRequest{}, Response: recoveryCodesResponse{}, Errors: []int{bad, http.StatusUnauthorized, forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/me/tokens", Tag: "tokens", Summary: "List personal access tokens", Response: []models.APIToken{}, Errors: []int{forbidden, internal}},
+			{Method: "POST", Path: "/api/v1/me/tokens", Tag: "tokens", Summary: "Create a personal access token", Description: tokenDescription, Request: createTokenRequest{}, Status: http.StatusCreated, Response: createdTokenResponse{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "DELETE", Path: "/api/v1/me/tokens/:id", Tag: "tokens", Summary: "Revoke a personal access token", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/service-accounts", Tag: "tokens", Summary: "List service accounts of the team (owner/admin)", Response: []models.ServiceAccount{}, Errors: []int{forbidden, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/service-accounts", Tag: "tokens", Summary: "Create a service account with a team role (owner/admin)", Request: createServiceAccountRequest{}, Status: http.StatusCreated, Response: models.ServiceAccount{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/service-accounts/:id", Tag: "tokens", Summary: "Deactivate a service account and revoke its tokens (owner/admin)", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/service-accounts/:id/tokens", Tag: "tokens", Summary: "List tokens of a service account (owner/admin)", Response: []models.APIToken{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/service-accounts/:id/tokens", Tag: "tokens", Summary: "Create a token for a service account (owner/admin)", Description: tokenDescription, Request: createTokenRequest{}, Status: http.StatusCreated, Response: createdTokenResponse{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/service-accounts/:id/tokens/:tokenId", Tag: "tokens", Summary: "Revoke a service account token (owner/admin)", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+
+			{Method: "GET", Path: "/api/v1/admin/orgs", Tag: "admin", Summary: "List every organization (system admin)", Description: "role is the caller's role where they are a member. System admins manage organizations and system roles only; they act in an organization through its membership.", Response: []models.Organization{}, Errors: []int{forbidden, internal}},
+			{Method: "PUT", Path: "/api/v1/admin/orgs/:orgId/admins", Tag: "admin", Summary: "Make a user an admin of an organization (system admin)", Description: "Adds the user with the email to the organization if needed.", Request: appointOrgAdminRequest{}, Response: models.OrgMember{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "PUT", Path: "/api/v1/admin/users/:id/role", Tag: "admin", Summary: "Change the system role of a user (system admin)", Request: setSystemRoleRequest{}, Response: models.User{}, Errors: []int{bad, forbidden, notFound, internal}},
+
+			{Method: "GET", Path: "/api/v1/orgs", Tag: "organizations", Summary: "Organizations of the current user with their role", Response: []models.Organization{}, Errors: []int{internal}},
+			{Method: "POST", Path: "/api/v1/orgs", Tag: "organizations", Summary: "Create an organization (system admin)", Description: "The creator becomes its admin. The organization starts with the default artifact types. allowed_auth_methods defaults to all methods.", Request: orgRequest{}, Status: http.StatusCreated, Response: models.Organization{}, Errors: []int{bad, forbidden, http.StatusConflict, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId", Tag: "organizations", Summary: "Get an organization", Description: "Organizations the user does not belong to are not found (404).", Response: models.Organization{}, Errors: []int{bad, notFound, internal}},
+			{Method: "PUT", Path: "/api/v1/orgs/:orgId", Tag: "organizations", Summary: "Change the name and settings of an organization (org admin)", Description: "allowed_auth_methods lists password, ldap and oidc; a user may sign in with a method allowed by at least one of their organizations. System admins may always sign in. auto_join adds new accounts to the organization.", Request: orgRequest{}, Response: models.Organization{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/members", Tag: "organizations", Summary: "Members of an organization", Response: []models.OrgMember{}, Errors: []int{bad, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/orgs/:orgId/members", Tag: "organizations", Summary: "Add an existing user to an organization (org admin)", Description: "Joining a team of the organization also makes the user a member.", Request: addOrgMemberRequest{}, Status: http.StatusCreated, Response: models.OrgMember{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "PUT", Path: "/api/v1/orgs/:orgId/members/:userId", Tag: "organizations", Summary: "Change the role of a member (org admin)", Description: "The last admin cannot be demoted (409).", Request: setOrgRoleRequest{}, Response: models.OrgMember{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "DELETE", Path: "/api/v1/orgs/:orgId/members/:userId", Tag: "organizations", Summary: "Remove a member from an organization (org admin)", Description: "Also removes the user from the organization's teams. Owners of teams and the last admin cannot be removed (409).", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/artifact-types", Tag: "organizations", Summary: "Artifact types the teams of the organization may use", Description: "in_use counts the artifacts of each type.", Response: []models.ArtifactType{}, Errors: []int{bad, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/orgs/:orgId/artifact-types", Tag: "organizations", Summary: "Add an artifact type (org admin)", Description: "Names are stored in lower case.", Request: artifactTypeRequest{}, Status: http.StatusCreated, Response: models.ArtifactType{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "DELETE", Path: "/api/v1/orgs/:orgId/artifact-types/:name", Tag: "organizations", Summary: "Remove an unused artifact type (org admin)", Description: "Types used by artifacts cannot be removed (409).", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/search", Tag: "organizations", Summary: "Search artifacts and fields across the teams of the organization", Description: "Members find artifacts of their teams and of public teams with a public catalog (shared: true for teams they are not in); org admins search every team. Ranking as in team search.", Query: []openapi.Param{{Name: "q", Required: true}, {Name: "limit", Description: "default 50, at most 200"}}, Response: []models.SearchHit{}, Errors: []int{bad, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/usage", Tag: "organizations", Summary: "Usage statistics of the organization and its teams (org admin)", Response: models.OrgUsage{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/users", Tag: "organizations", Summary: "List the users of an organization (org admin)", Query: append([]openapi.Param{{Name: "search", Description: "substring of email or name"}, {Name: "active", Enum: []string{"true", "false"}}}, pageQuery...), Response: []models.User{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/users/:id", Tag: "organizations", Summary: "Get a user of an organization (org admin)", Response: models.User{}, Errors: []int{bad, forbidden, notFound}},
+			{Method: "POST", Path: "/api/v1/orgs/:orgId/users/:id/deactivate", Tag: "organizations", Summary: "Deactivate a user of an organization (org admin)", Description: "Blocks login and revokes all sessions. Team memberships are kept but grant no access while the user is inactive. Only users all of whose organizations the caller administers can be changed, and system admins only by system admins (403).", Response: models.User{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/orgs/:orgId/users/:id/activate", Tag: "organizations", Summary: "Reactivate a user of an organization (org admin)", Description: "Same restrictions as deactivation.", Response: models.User{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "DELETE", Path: "/api/v1/orgs/:orgId/users/:id/2fa", Tag: "organizations", Summary: "Turn off two-factor authentication of a user of an organization (org admin)", Description: "Same restrictions as deactivation.", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/users/:id/login-attempts", Tag: "organizations", Summary: "Login history of a user of an organization (org admin)", Description: "Newest first, 50 by default. locked_until is set while failed logins block the account.", Query: pageQuery, Response: loginHistoryResponse{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/orgs/:orgId/users/:id/unlock", Tag: "organizations", Summary: "Clear failed logins of a user of an organization (org admin)", Description: "Same restrictions as deactivation.", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/team-snapshots", Tag: "organizations", Summary: "Snapshots of deleted teams of an organization (org admin)", Query: pageQuery, Response: []models.TeamSnapshot{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/orgs/:orgId/team-snapshots/:id", Tag: "organizations", Summary: "A snapshot of a deleted team of an organization (org admin)", Response: models.TeamSnapshot{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/orgs/:orgId/team-snapshots/:id/restore", Tag: "organizations", Summary: "Restore a deleted team of an organization (org admin)", Description: "Creates a new team with new ids for contacts, artifacts, fields, shares and lineage edges; contacts stay linked to accounts that still exist, certifications keep their expiry and review flag, and edges of other teams point to the restored artifacts again. Members whose accounts were deleted are skipped; if no active owner remains, the admin becomes the owner. Service accounts are not restored. The body is optional: name restores the team under another name when the original is taken (409). A snapshot can be restored once (409).", Request: restoreTeamRequest{}, Status: http.StatusCreated, Response: models.Team{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "PUT", Path: "/api/v1/orgs/:orgId/teams/:teamId/owner", Tag: "organizations", Summary: "Reassign the owner of a team of an organization (org admin)", Description: "For teams whose owner is gone, e.g. deactivated. user_id may be any active user of the organization; it becomes a member if needed, and previous owners become admins. Service accounts cannot own a team (400).", Request: transferOwnershipRequest{}, Response: models.TeamMemberDetail{}, Errors: []int{bad, forbidden, notFound, internal}},
+
+			{Method: "GET", Path: "/api/v1/teams/:teamId/artifacts", Tag: "artifacts", Summary: "List artifacts", Query: pageQuery, Response: []models.Artifact{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/artifacts", Tag: "artifacts", Summary: "Create an artifact", Description: "type must be one of the organization's artifact types (400 otherwise). status is draft or active (default).", Request: models.Artifact{}, Status: http.StatusCreated, Response: models.Artifact{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/artifacts/:id", Tag: "artifacts", Summary: "Get an artifact", Response: models.Artifact{}, Errors: []int{bad, forbidden, notFound}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/artifacts/:id", Tag: "artifacts", Summary: "Update an artifact", Description: "type must be one of the organization's artifact types (400 otherwise). The status and deprecation fields are ignored; see PUT .../status.", Request: models.Artifact{}, Response: models.Artifact{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/artifacts/:id", Tag: "artifacts", Summary: "Delete an artifact", Response: messageResponse{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/artifacts/:id/status", Tag: "lifecycle", Summary: "Change the lifecycle status of an artifact", Description: "Statuses: draft, active, deprecated, retired. Deprecating requires a reason; deprecated_at defaults to now, and omitted deprecation fields keep their value. The replacement must be a draft or active artifact of the team or one shared with it (400 otherwise). Back to draft or active the deprecation fields are cleared. When an artifact becomes deprecated or retired, the owners and admins of teams with draft or active artifacts derived from it, and the users linked to those artifacts' owners, get an in-app notification.", Request: models.ArtifactStatus{}, Response: models.Artifact{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/reports/deprecated", Tag: "lifecycle", Summary: "Deprecated artifacts still in use", Description: "The team's deprecated and retired artifacts that draft or active artifacts of any team are derived from, with those lineage edges as consumers, oldest deprecation first.", Response: []models.DeprecatedInUse{}, Errors: []int{forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/artifacts/:id/certification", Tag: "certification", Summary: "Certification of an artifact", Description: "state is certified, needs_review (the fields or the description changed after certification; review_reason says what changed first) or expired. 404 if the artifact is not certified.", Response: models.Certification{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/artifacts/:id/certification", Tag: "certification", Summary: "Certify an artifact as a trusted source (team admin or data steward)", Description: "Team owners and admins certify any artifact, other members only those they are a data steward of through a linked contact (403 otherwise). Only active artifacts can be certified (409). expires_at must be in the future. Certifying again renews the certification and clears a pending review. Certified artifacts rank first in search; deprecating or retiring an artifact removes its certification.", Request: certifyRequest{}, Response: models.Certification{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/artifacts/:id/certification", Tag: "certification", Summary: "Remove the certification of an artifact (team admin or data steward)", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/certifications", Tag: "certification", Summary: "Certifications of the team's artifacts", Description: "Those needing review or expired come first, then by expiry.", Query: []openapi.Param{{Name: "state", Enum: certificationStates}}, Response: []models.Certification{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/artifacts/:id/render", Tag: "artifacts", Summary: "Render the artifact as DDL, a Go struct, JSON Schema or Avro", Query: []openapi.Param{{Name: "format", Enum: render.Formats, Required: true}}, Response: render.Result{}, Errors: []int{bad, forbidden, notFound, internal}},
+
+			{Method: "GET", Path: "/api/v1/teams/:teamId/artifacts/:id/owners", Tag: "owners", Summary: "Owners of an artifact", Description: "Roles: technical_owner, business_owner, data_steward. Technical owners come first.", Response: []models.ArtifactOwner{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/artifacts/:id/owners", Tag: "owners", Summary: "Replace the owners of an artifact", Description: "Owners must be contacts of the team (400 otherwise); a contact may hold several roles. The order of the list is kept within a role; the first technical owner is also returned as the artifact's developer_id.", Request: setOwnersRequest{}, Response: []models.ArtifactOwner{}, Errors: []int{bad, forbidden, notFound, internal}},
+
+			{Method: "GET", Path: "/api/v1/teams/:teamId/search", Tag: "artifacts", Summary: "Search artifacts and fields of the team and artifacts shared with it", Description: "Case-insensitive substring search over artifact names, descriptions and field names and descriptions. Name matches come first, then descriptions, then fields; within each, certified artifacts (certified: true) first and deprecated or retired ones last, then the team's own artifacts before shared ones (shared: true). Guests search the team's own artifacts only.", Query: []openapi.Param{{Name: "q", Required: true}, {Name: "limit", Description: "default 50, at most 200"}}, Response: []models.SearchHit{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/artifacts/:id/shares", Tag: "sharing", Summary: "Share an artifact read-only with another team or all teams (owner/admin)", Description: "Without target_team_id (or without a body) the artifact is shared with every team.", Request: createShareRequest{}, Status: http.StatusCreated, Response: models.ArtifactShare{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/shares", Tag: "sharing", Summary: "Artifacts the team shares with other teams", Query: []openapi.Param{{Name: "status", Enum: []string{"active", "revoked"}}}, Response: []models.ArtifactShare{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/shares/:id", Tag: "sharing", Summary: "Stop sharing (owner/admin)", Description: "Lineage edges of other teams to the artifact remain with upstream_status unshared.", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/shared-artifacts", Tag: "sharing", Summary: "Artifacts of other teams shared with this team", Response: []models.SharedArtifact{}, Errors: []int{forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/shared-artifacts/:id", Tag: "sharing", Summary: "A shared artifact with its fields (read-only)", Response: models.SharedArtifact{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/artifacts/:id/lineage", Tag: "lineage", Summary: "Sources of the artifact and artifacts of any team derived from it", Description: "upstream_status is available, unshared (the owning team stopped sharing it; upstream_name is the name it had) or deleted. upstream_lifecycle is the lifecycle status of an available upstream.", Response: models.Lineage{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/artifacts/:id/lineage", Tag: "lineage", Summary: "Mark the artifact as derived from another one", Description: "The upstream must be an artifact of the team or one shared with it (404 otherwise).", Request: addLineageRequest{}, Status: http.StatusCreated, Response: models.LineageEdge{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/artifacts/:id/lineage/:edgeId", Tag: "lineage", Summary: "Remove a lineage edge into the artifact", Status: http.StatusNoContent, Errors: []int{bad, forbidden, notFound, internal}},
+
+			{Method: "GET", Path: "/api/v1/teams/:teamId/artifacts/:id/fields", Tag: "fields", Summary: "List fields of an artifact", Response: []models.ArtifactField{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/artifacts/:id/fields", Tag: "fields", Summary: "Add a field to an artifact", Request: models.ArtifactField{}, Status: http.StatusCreated, Response: models.ArtifactField{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/fields/:id", Tag: "fields", Summary: "Get a field", Response: models.ArtifactField{}, Errors: []int{bad, forbidden, notFound}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/fields/:id", Tag: "fields", Summary: "Update a field", Request: models.ArtifactField{}, Response: models.ArtifactField{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/fields/:id", Tag: "fields", Summary: "Delete a field", Response: messageResponse{}, Errors: []int{bad, forbidden, internal}},
+
+			{Method: "GET", Path: "/api/v1/teams/:teamId/contacts", Tag: "contacts", Summary: "List or search contacts", Query: append([]openapi.Param{{Name: "q", Description: "substring of the name, department, availability note, a channel or the linked account's email"}, {Name: "department", Description: "exact department, ignoring case"}}, pageQuery...), Response: []models.Contact{}, Errors: []int{bad, forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/contacts.vcf", Tag: "contacts", Summary: "The team's contact directory as vCard 3.0", ContentType: "text/vcard", Errors: []int{forbidden, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/contacts/:id/vcard", Tag: "contacts", Summary: "A contact as vCard 3.0", ContentType: "text/vcard", Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/contacts", Tag: "contacts", Summary: "Create a contact", Description: "user_id links the contact to the account of an active team member (400 otherwise); an account is linked to one contact per team (409). Channels are checked by kind: email address, phone number of 7-15 digits, @username for Telegram and Mattermost, @handle or member ID for Slack (400 otherwise); handles get the leading @. Without channels, telegram_contact becomes the Telegram channel; otherwise it is the first Telegram channel.", Request: models.Contact{}, Status: http.StatusCreated, Response: models.Contact{}, Errors: []int{bad, forbidden, http.StatusConflict, internal}},
+			{Method: "GET", Path: "/api/v1/teams/:teamId/contacts/:id", Tag: "contacts", Summary: "Get a contact", Response: models.Contact{}, Errors: []int{bad, forbidden, notFound}},
+			{Method: "PUT", Path: "/api/v1/teams/:teamId/contacts/:id", Tag: "contacts", Summary: "Update a contact", Description: "As creation; omit user_id to unlink the account. Without channels in the body only the Telegram channel is replaced by telegram_contact.", Request: models.Contact{}, Response: models.Contact{}, Errors: []int{bad, forbidden, notFound, http.StatusConflict, internal}},
+			{Method: "POST", Path: "/api/v1/teams/:teamId/contacts/:id/reassign", Tag: "owners", Summary: "Hand all artifacts of a contact over to another contact", Description: "Every owner role of the contact, and developer_id, moves to to_contact_id, e.g. when someone leaves. Returns the number of artifacts concerned.", Request: reassignRequest{}, Response: reassignResponse{}, Errors: []int{bad, forbidden, notFound, internal}},
+			{Method: "DELETE", Path: "/api/v1/teams/:teamId/contacts/:id", Tag: "contacts", Summary: "Delete a contact", Response: messageResponse{}, Errors: []int{bad, forbidden, internal}},
+		},
+	}
+}
+
+type OpenAPIHandler struct {
+	doc  []byte
+	page []byte
+}
+
+func NewOpenAPIHandler(spec *openapi.Spec) *OpenAPIHandler {
+	doc, err := json.Marshal(spec.Document())
+	if err != nil {
+		panic(err)
+	}
+	return &OpenAPIHandler{doc: doc, page: openapi.DocsPage("/api/v1/openapi.json")}
+}
+
+// GET /api/v1/openapi.json
+func (h *OpenAPIHandler) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.doc)
+}
+
+// GET /api/v1/docs
+func (h *OpenAPIHandler) Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", h.page)
+}
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+// OrgsHandler serves organizations: their settings, members, artifact type
+// registry, search across their teams and the usage dashboard.
+type OrgsHandler struct {
+	orgs      *postgres.OrgRepository
+	artifacts *postgres.ArtifactRepository
+}
+
+// orgRequest creates an organization or replaces its settings. Without
+// AllowedAuthMethods every method is allowed.
+type orgRequest struct {
+	Name               string   `json:"name" binding:"required,min=2,max=255"`
+	Description        string   `json:"description" binding:"max=1000"`
+	AllowedAuthMethods []string `json:"allowed_auth_methods" binding:"omitempty,min=1,dive,oneof=password ldap oidc"`
+	AutoJoin           bool     `json:"auto_join"`
+}
+
+type addOrgMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=admin member"`
+}
+
+type appointOrgAdminRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type setOrgRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member"`
+}
+
+type artifactTypeRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+func NewOrgsHandler(orgs *postgres.OrgRepository, artifacts *postgres.ArtifactRepository) *OrgsHandler {
+	return &OrgsHandler{orgs: orgs, artifacts: artifacts}
+}
+
+// GET /api/v1/orgs lists the caller's organizations.
+func (h *OrgsHandler) List(c *gin.Context) {
+	items, err := h.orgs.ListForUser(c.Request.Context(), c.GetInt(middleware.CtxUserID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list organizations"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GET /api/v1/admin/orgs (system admin) lists every organization, with the
+// caller's role where they are a member.
+func (h *OrgsHandler) ListAll(c *gin.Context) {
+	items, err := h.orgs.ListAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list organizations"})
+		return
+	}
+	mine, err := h.orgs.ListForUser(c.Request.Context(), c.GetInt(middleware.CtxUserID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list organizations"})
+		return
+	}
+	roles := map[int]string{}
+	for _, o := range mine {
+		roles[o.ID] = o.Role
+	}
+	for i := range items {
+		items[i].Role = roles[items[i].ID]
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// PUT /api/v1/admin/orgs/:orgId/admins (system admin) makes an existing user
+// an admin of the organization, e.g. when it has lost its admins. System
+// admins act in an organization only through such a membership.
+func (h *OrgsHandler) AppointAdmin(c *gin.Context) {
+	orgID, ok := pathID(c, "orgId")
+	if !ok {
+		return
+	}
+	var req appointOrgAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if _, err := h.orgs.Get(c.Request.Context(), orgID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load organization"})
+		}
+		return
+	}
+	m, err := h.orgs.MakeAdmin(c.Request.Context(), orgID, strings.TrimSpace(req.Email))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to appoint admin"})
+	default:
+		c.JSON(http.StatusOK, m)
+	}
+}
+
+// POST /api/v1/orgs (system admin); the creator becomes its admin.
+func (h *OrgsHandler) Create(c *gin.Context) {
+	var req orgRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	o := req.org()
+	err := h.orgs.Create(c.Request.Context(), &o, c.GetInt(middleware.CtxUserID))
+	if errors.Is(err, postgres.ErrOrgNameTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create organization"})
+		return
+	}
+	c.JSON(http.StatusCreated, o)
+}
+
+// GET /api/v1/orgs/:orgId
+func (h *OrgsHandler) Get(c *gin.Context) {
+	o, err := h.orgs.Get(c.Request.Context(), c.GetInt(middleware.CtxOrgID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load organization"})
+		return
+	}
+	o.Role = c.GetString(middleware.CtxOrgRole)
+	c.JSON(http.StatusOK, o)
+}
+
+// PUT /api/v1/orgs/:orgId (org admin) replaces the name and settings.
+func (h *OrgsHandler) Update(c *gin.Context) {
+	var req orgRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	o := req.org()
+	o.ID = c.GetInt(middleware.CtxOrgID)
+	if o.AllowedAuthMethods == nil {
+		o.AllowedAuthMethods = []string{models.AuthPassword, models.AuthLDAP, models.AuthOIDC}
+	}
+	err := h.orgs.Update(c.Request.Context(), &o)
+	if errors.Is(err, postgres.ErrOrgNameTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update organization"})
+		return
+	}
+	o.Role = c.GetString(middleware.CtxOrgRole)
+	c.JSON(http.StatusOK, o)
+}
+
+// GET /api/v1/orgs/:orgId/members
+func (h *OrgsHandler) ListMembers(c *gin.Context) {
+	items, err := h.orgs.ListMembers(c.Request.Context(), c.GetInt(middleware.CtxOrgID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list members"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// POST /api/v1/orgs/:orgId/members (org admin) adds an existing user by email.
+func (h *OrgsHandler) AddMember(c *gin.Context) {
+	var req addOrgMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if req.Role == "" {
+		req.Role = "member"
+	}
+	m, err := h.orgs.AddMember(c.Request.Context(), c.GetInt(middleware.CtxOrgID), strings.TrimSpace(req.Email), req.Role)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, postgres.ErrAlreadyOrgMember):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add member"})
+	default:
+		c.JSON(http.StatusCreated, m)
+	}
+}
+
+// PUT /api/v1/orgs/:orgId/members/:userId (org admin)
+func (h *OrgsHandler) SetMemberRole(c *gin.Context) {
+	userID, ok := pathID(c, "userId")
+	if !ok {
+		return
+	}
+	var req setOrgRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	orgID := c.GetInt(middleware.CtxOrgID)
+	if !h.memberResult(c, h.orgs.SetRole(c.Request.Context(), orgID, userID, req.Role)) {
+		return
+	}
+	m, err := h.orgs.GetMember(c.Request.Context(), orgID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load member"})
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
+
+// DELETE /api/v1/orgs/:orgId/members/:userId (org admin) also removes the
+// user from the organization's teams.
+func (h *OrgsHandler) RemoveMember(c *gin.Context) {
+	userID, ok := pathID(c, "userId")
+	if !ok {
+		return
+	}
+	if h.memberResult(c, h.orgs.RemoveMember(c.Request.Context(), c.GetInt(middleware.CtxOrgID), userID)) {
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (h *OrgsHandler) memberResult(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+	case errors.Is(err, postgres.ErrLastOrgAdmin), errors.Is(err, postgres.ErrOwnsTeams):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update member"})
+	default:
+		return true
+	}
+	return false
+}
+
+// GET /api/v1/orgs/:orgId/artifact-types
+func (h *OrgsHandler) ListArtifactTypes(c *gin.Context) {
+	items, err := h.orgs.ArtifactTypes(c.Request.Context(), c.GetInt(middleware.CtxOrgID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list artifact types"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// POST /api/v1/orgs/:orgId/artifact-types (org admin)
+func (h *OrgsHandler) AddArtifactType(c *gin.Context) {
+	var req artifactTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	t := models.ArtifactType{Name: strings.ToLower(strings.TrimSpace(req.Name)), Description: req.Description}
+	if t.Name == "" || strings.ContainsAny(t.Name, " /") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type name"})
+		return
+	}
+	err := h.orgs.AddArtifactType(c.Request.Context(), c.GetInt(middleware.CtxOrgID), t)
+	if errors.Is(err, postgres.ErrTypeExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add artifact type"})
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+// DELETE /api/v1/orgs/:orgId/artifact-types/:name (org admin); only unused
+// types can be removed.
+func (h *OrgsHandler) RemoveArtifactType(c *gin.Context) {
+	err := h.orgs.RemoveArtifactType(c.Request.Context(), c.GetInt(middleware.CtxOrgID), c.Param("name"))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "artifact type not found"})
+	case errors.Is(err, postgres.ErrTypeInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove artifact type"})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// GET /api/v1/orgs/:orgId/search?q=&limit= searches the artifacts of the
+// organization's teams the caller may read: their own teams and public
+// catalogs, or every team for org admins.
+func (h *OrgsHandler) Search(c *gin.Context) {
+	q, limit, ok := searchParams(c)
+	if !ok {
+		return
+	}
+	all := c.GetString(middleware.CtxOrgRole) == "admin"
+	hits, err := h.artifacts.SearchOrg(c.Request.Context(), c.GetInt(middleware.CtxOrgID), c.GetInt(middleware.CtxUserID), all, q, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "search failed"})
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
+
+// GET /api/v1/orgs/:orgId/usage (org admin)
+func (h *OrgsHandler) Usage(c *gin.Context) {
+	u, err := h.orgs.Usage(c.Request.Context(), c.GetInt(middleware.CtxOrgID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+func (r orgRequest) org() models.Organization {
+	return models.Organization{
+		Name:               strings.TrimSpace(r.Name),
+		Description:        r.Description,
+		AllowedAuthMethods: r.AllowedAuthMethods,
+		AutoJoin:           r.AutoJoin,
+	}
+}
+
+// orgOf resolves the organization of a new team: the requested one, which
+// the user must belong to, or the user's only organization.
+func orgOf(c *gin.Context, orgs *postgres.OrgRepository, requested int) (int, bool) {
+	userID := c.GetInt(middleware.CtxUserID)
+	if requested != 0 {
+		ok, err := orgs.IsMember(c.Request.Context(), requested, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load organization"})
+			return 0, false
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return 0, false
+		}
+		return requested, true
+	}
+	mine, err := orgs.ListForUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load organization"})
+		return 0, false
+	}
+	if len(mine) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "org_id is required: you belong to " + strconv.Itoa(len(mine)) + " organizations"})
+		return 0, false
+	}
+	return mine[0].ID, true
+}
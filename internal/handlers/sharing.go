@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// SharingHandler publishes artifacts to other teams, serves the artifacts
+// shared with a team, lineage between artifacts and the catalog search.
+type SharingHandler struct {
+	shares    *postgres.ShareRepository
+	lineage   *postgres.LineageRepository
+	artifacts *postgres.ArtifactRepository
+	fields    *postgres.ArtifactFieldRepository
+	teams     *postgres.TeamRepository
+}
+
+// createShareRequest: TargetTeamID omitted shares with all teams of the
+// organization.
+type createShareRequest struct {
+	TargetTeamID *int `json:"target_team_id" binding:"omitempty,min=1"`
+}
+
+type addLineageRequest struct {
+	UpstreamID int `json:"upstream_id" binding:"required,min=1"`
+}
+
+func NewSharingHandler(shares *postgres.ShareRepository, lineage *postgres.LineageRepository, artifacts *postgres.ArtifactRepository, fields *postgres.ArtifactFieldRepository, teams *postgres.TeamRepository) *SharingHandler {
+	return &SharingHandler{shares: shares, lineage: lineage, artifacts: artifacts, fields: fields, teams: teams}
+}
+
+// POST /api/v1/teams/:teamId/artifacts/:id/shares (owner/admin)
+func (h *SharingHandler) Share(c *gin.Context) {
+	artifactID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req createShareRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+			return
+		}
+	}
+	teamID := c.GetInt(middleware.CtxTeamID)
+	if req.TargetTeamID != nil {
+		if *req.TargetTeamID == teamID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "an artifact cannot be shared with its own team"})
+			return
+		}
+		// teams of other organizations look as if they did not exist
+		t, err := h.teams.GetByID(c.Request.Context(), *req.TargetTeamID)
+		if errors.Is(err, pgx.ErrNoRows) || err == nil && t.OrgID != c.GetInt(middleware.CtxOrgID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "target team not found"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to share artifact"})
+			return
+		}
+	}
+	userID := c.GetInt(middleware.CtxUserID)
+	s := models.ArtifactShare{ArtifactID: artifactID, TeamID: teamID, TargetTeamID: req.TargetTeamID, CreatedBy: &userID}
+	err := h.shares.Create(c.Request.Context(), &s)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+	case errors.Is(err, postgres.ErrAlreadyShared):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to share artifact"})
+	default:
+		c.JSON(http.StatusCreated, s)
+	}
+}
+
+// GET /api/v1/teams/:teamId/shares?status=active|revoked
+func (h *SharingHandler) ListShares(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != "active" && status != "revoked" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	items, err := h.shares.ListByTeam(c.Request.Context(), c.GetInt(middleware.CtxTeamID), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list shares"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// DELETE /api/v1/teams/:teamId/shares/:id (owner/admin)
+func (h *SharingHandler) RevokeShare(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	err := h.shares.Revoke(c.Request.Context(), c.GetInt(middleware.CtxTeamID), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke share"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v1/teams/:teamId/shared-artifacts
+func (h *SharingHandler) ListShared(c *gin.Context) {
+	items, err := h.shares.ListSharedWith(c.Request.Context(), c.GetInt(middleware.CtxTeamID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list shared artifacts"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GET /api/v1/teams/:teamId/shared-artifacts/:id returns the artifact with
+// its fields.
+func (h *SharingHandler) GetShared(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	sa, err := h.shares.GetSharedWith(c.Request.Context(), c.GetInt(middleware.CtxTeamID), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "the artifact is not shared with this team"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load artifact"})
+		return
+	}
+	if sa.Fields, err = h.fields.GetFieldsByArtifactID(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load artifact"})
+		return
+	}
+	c.JSON(http.StatusOK, sa)
+}
+
+// GET /api/v1/teams/:teamId/artifacts/:id/lineage
+func (h *SharingHandler) Lineage(c *gin.Context) {
+	artifactID, ok := h.ownArtifact(c)
+	if !ok {
+		return
+	}
+	l, err := h.lineage.ForArtifact(c.Request.Context(), artifactID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load lineage"})
+		return
+	}
+	c.JSON(http.StatusOK, l)
+}
+
+// POST /api/v1/teams/:teamId/artifacts/:id/lineage marks the artifact as
+// derived from an artifact of the team or one shared with it.
+func (h *SharingHandler) AddLineage(c *gin.Context) {
+	artifactID, ok := h.ownArtifact(c)
+	if !ok {
+		return
+	}
+	var req addLineageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if req.UpstreamID == artifactID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "an artifact cannot be its own upstream"})
+		return
+	}
+	e, err := h.lineage.Add(c.Request.Context(), c.GetInt(middleware.CtxTeamID), artifactID, req.UpstreamID, c.GetInt(middleware.CtxUserID))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "upstream artifact not found or not shared with this team"})
+	case errors.Is(err, postgres.ErrEdgeExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add lineage"})
+	default:
+		c.JSON(http.StatusCreated, e)
+	}
+}
+
+// DELETE /api/v1/teams/:teamId/artifacts/:id/lineage/:edgeId
+func (h *SharingHandler) DeleteLineage(c *gin.Context) {
+	artifactID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	edgeID, ok := pathID(c, "edgeId")
+	if !ok {
+		return
+	}
+	err := h.lineage.Delete(c.Request.Context(), c.GetInt(middleware.CtxTeamID), artifactID, edgeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "lineage edge not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete lineage"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v1/teams/:teamId/search?q=&limit= searches the team's artifacts
+// and fields, and the artifacts shared with the team unless the caller is a
+// guest.
+func (h *SharingHandler) Search(c *gin.Context) {
+	q, limit, ok := searchParams(c)
+	if !ok {
+		return
+	}
+	shared := access.Allowed(c.GetString(middleware.CtxTeamRole), access.Permission{Resource: access.Shares, Action: access.Read})
+	hits, err := h.artifacts.Search(c.Request.Context(), c.GetInt(middleware.CtxTeamID), q, shared, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "search failed"})
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
+
+// searchParams reads the required ?q= and the optional ?limit=.
+func searchParams(c *gin.Context) (string, int, bool) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return "", 0, false
+	}
+	limit := defaultSearchLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return "", 0, false
+		}
+		limit = n
+	}
+	return q, limit, true
+}
+
+// ownArtifact reads :id and checks that the artifact belongs to the team.
+func (h *SharingHandler) ownArtifact(c *gin.Context) (int, bool) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return 0, false
+	}
+	exists, err := h.artifacts.Exists(c.Request.Context(), c.GetInt(middleware.CtxTeamID), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load artifact"})
+		return 0, false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+		return 0, false
+	}
+	return id, true
+}
+
+func pathID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/oauth2"
+
+	"go-data-catalog/internal/config"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/sso"
+)
+
+const (
+	oidcFlowCookie = "oidc_flow"
+	oidcFlowTTL    = 10 * time.Minute
+)
+
+// loginError is an external login failure that is safe to show to the user;
+// other errors are logged and reported as "login failed".
+type loginError string
+
+func (e loginError) Error() string { return string(e) }
+
+const (
+	errEmailNotVerified = loginError("the identity provider did not confirm the email address")
+	errSignupDisabled   = loginError("no account with this email; ask an administrator to create one")
+	errDeactivated      = loginError("account is deactivated")
+	errFlowExpired      = loginError("login session expired, please try again")
+	errProviderRejected = loginError("identity provider rejected the login")
+	errMethodNotAllowed = loginError("your organization does not allow this sign-in method")
+	errLinkRequired     = loginError("an account with this email already exists; sign in to it and link your directory account in the profile")
+)
+
+// SSOHandler logs users in through external identity providers.
+type SSOHandler struct {
+	auth         *AuthHandler
+	oidc         *sso.OIDC // nil when OIDC is not configured
+	groups       sso.GroupMapping
+	allowSignup  bool
+	postLoginURL string
+	secret       []byte
+}
+
+type providersResponse struct {
+	Password bool `json:"password"`
+	OIDC     bool `json:"oidc"`
+	LDAP     bool `json:"ldap"` // password login also accepts directory accounts
+}
+
+// oidcFlow is kept in a signed cookie between the redirect to the provider
+// and the callback.
+type oidcFlow struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Expires  int64  `json:"exp"`
+}
+
+func NewSSOHandler(auth *AuthHandler, oidc *sso.OIDC, groups sso.GroupMapping, cfg *config.Config) *SSOHandler {
+	return &SSOHandler{
+		auth:         auth,
+		oidc:         oidc,
+		groups:       groups,
+		allowSignup:  cfg.OIDCAllowSignup,
+		postLoginURL: cfg.OIDCPostLoginURL,
+		secret:       []byte(cfg.JWTSecret),
+	}
+}
+
+// GET /auth/providers lists the available login methods.
+func (h *SSOHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, providersResponse{Password: true, OIDC: h.oidc != nil, LDAP: len(h.auth.backends) > 0})
+}
+
+// GET /auth/oidc/login redirects the browser to the identity provider.
+func (h *SSOHandler) OIDCLogin(c *gin.Context) {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "OIDC login is not configured"})
+		return
+	}
+	state, err1 := randomString(16)
+	nonce, err2 := randomString(16)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start login"})
+		return
+	}
+	flow := oidcFlow{State: state, Nonce: nonce, Verifier: oauth2.GenerateVerifier(), Expires: time.Now().Add(oidcFlowTTL).Unix()}
+	target, err := h.oidc.AuthCodeURL(c.Request.Context(), flow.State, flow.Nonce, flow.Verifier)
+	if err != nil {
+		log.Printf("OIDC login: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider is unavailable"})
+		return
+	}
+	h.setFlowCookie(c, h.sealFlow(flow), int(oidcFlowTTL.Seconds()))
+	c.Redirect(http.StatusFound, target)
+}
+
+// GET /auth/oidc/callback finishes the login and sends the browser to
+// OIDC_POST_LOGIN_URL with the token pair, a 2FA challenge or an error in the
+// URL fragment.
+func (h *SSOHandler) OIDCCallback(c *gin.Context) {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "OIDC login is not configured"})
+		return
+	}
+	cookie, _ := c.Cookie(oidcFlowCookie)
+	h.setFlowCookie(c, "", -1)
+	if e := c.Query("error"); e != "" {
+		h.finish(c, nil, loginError("identity provider: "+e))
+		return
+	}
+	flow, ok := h.openFlow(cookie)
+	if !ok || !hmac.Equal([]byte(flow.State), []byte(c.Query("state"))) {
+		h.finish(c, nil, errFlowExpired)
+		return
+	}
+	id, err := h.oidc.Exchange(c.Request.Context(), c.Query("code"), flow.Verifier, flow.Nonce)
+	if err != nil {
+		log.Printf("OIDC callback: %v", err)
+		h.finish(c, nil, errProviderRejected)
+		return
+	}
+	u, err := h.auth.loginExternal(c.Request.Context(), id, models.AuthOIDC, h.groups, h.allowSignup, true)
+	if err != nil {
+		h.finish(c, nil, err)
+		return
+	}
+	challenge, err := h.auth.mfaChallenge(c.Request.Context(), u)
+	if err != nil {
+		h.finish(c, nil, err)
+		return
+	}
+	if challenge != nil {
+		// the web UI asks for the code and calls POST /auth/login/2fa
+		h.redirect(c, url.Values{"mfa_token": {challenge.MFAToken}})
+		return
+	}
+	res, err := h.auth.startSession(c, u)
+	if err == nil {
+		h.auth.loginSucceeded(c, u.Email, u, "oidc")
+	}
+	h.finish(c, res, err)
+}
+
+func (h *SSOHandler) finish(c *gin.Context, res *authResponse, err error) {
+	v := url.Values{}
+	var le loginError
+	switch {
+	case errors.As(err, &le):
+		v.Set("error", le.Error())
+	case err != nil:
+		log.Printf("SSO login: %v", err)
+		v.Set("error", "login failed")
+	default:
+		v.Set("token", res.Token)
+		v.Set("refresh_token", res.RefreshToken)
+		v.Set("expires_at", res.ExpiresAt.Format(time.RFC3339))
+	}
+	h.redirect(c, v)
+}
+
+func (h *SSOHandler) redirect(c *gin.Context, fragment url.Values) {
+	c.Redirect(http.StatusFound, h.postLoginURL+"#"+fragment.Encode())
+}
+
+// loginExternal finds or creates the user for an external identity: by an
+// existing link, else by verified email. An existing account is linked by
+// email only if linkByEmail is set, i.e. the provider is trusted to assert
+// addresses. Group memberships are synced on every login; teams are only
+// added or upgraded, never removed.
+func (h *AuthHandler) loginExternal(ctx context.Context, id *sso.Identity, method string, groups sso.GroupMapping, allowSignup, linkByEmail bool) (*models.User, error) {
+	var u *models.User
+	userID, err := h.identities.FindUserID(ctx, id.Provider, id.Subject)
+	switch {
+	case err == nil:
+		if u, err = h.users.GetByID(ctx, userID); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		if id.Email == "" || !id.EmailVerified {
+			return nil, errEmailNotVerified
+		}
+		u, err = h.users.GetByEmail(ctx, id.Email)
+		if errors.Is(err, pgx.ErrNoRows) {
+			if !allowSignup {
+				return nil, errSignupDisabled
+			}
+			// no local password: the account can only log in through the
+			// provider; an untrusted address still has to be confirmed
+			u = &models.User{Email: id.Email, PasswordHash: "!", Name: id.Name, SystemRole: "user", IsActive: true, EmailVerified: linkByEmail}
+			if err = h.users.CreateUser(ctx, u); err == nil {
+				h.joinOrgs(ctx, u)
+				if !u.EmailVerified {
+					h.sendVerificationEmail(u)
+				}
+			}
+		} else if err == nil && !linkByEmail {
+			return nil, errLinkRequired
+		} else if err == nil && !u.EmailVerified && u.ServiceTeamID == nil {
+			err = h.users.ClaimUnverified(ctx, u.ID)
+			u.EmailVerified = true
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := h.identities.Link(ctx, u.ID, id.Provider, id.Subject, id.Email); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	if !u.IsActive {
+		return nil, errDeactivated
+	}
+	if err := h.authMethodAllowed(ctx, u, method); err != nil {
+		return nil, err
+	}
+	for teamID, role := range groups.Resolve(id.Groups) {
+		if err := h.members.EnsureRole(ctx, teamID, u.ID, role); err != nil {
+			log.Printf("SSO group sync: team %d, user %d: %v", teamID, u.ID, err)
+		}
+	}
+	return u, nil
+}
+
+// POST /me/identities/ldap links a directory account to the current user,
+// who proves it with the directory password. Directories that do not trust
+// email addresses are linked to existing accounts only this way.
+func (h *AuthHandler) LinkDirectory(c *gin.Context) {
+	var req linkDirectoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if len(h.backends) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "LDAP login is not configured"})
+		return
+	}
+	if h.throttled(c, req.Login, "link") {
+		return
+	}
+	ctx := c.Request.Context()
+	userID := c.GetInt(middleware.CtxUserID)
+	var id *sso.Identity
+	for _, b := range h.backends {
+		var err error
+		id, err = b.Authenticator.Authenticate(ctx, req.Login, req.Password)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, sso.ErrInvalidCredentials) {
+			log.Printf("%s link: %v", b.Name, err)
+		}
+	}
+	if id == nil {
+		h.loginFailed(c, req.Login, &userID, "link", attemptInvalidCredentials)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+	linked, err := h.identities.LinkedUserID(ctx, id.Provider, id.Subject)
+	switch {
+	case err == nil && linked != userID:
+		c.JSON(http.StatusConflict, gin.H{"error": "this directory account is linked to another user"})
+		return
+	case err != nil && !errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to link account"})
+		return
+	}
+	if err := h.identities.Link(ctx, userID, id.Provider, id.Subject, id.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to link account"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SSOHandler) setFlowCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, value, maxAge, "/api/v1/auth/oidc", "", c.Request.TLS != nil, true)
+}
+
+func (h *SSOHandler) sealFlow(f oidcFlow) string {
+	payload, _ := json.Marshal(f)
+	p := base64.RawURLEncoding.EncodeToString(payload)
+	return p + "." + base64.RawURLEncoding.EncodeToString(h.mac(p))
+}
+
+func (h *SSOHandler) openFlow(cookie string) (oidcFlow, bool) {
+	var f oidcFlow
+	p, sig, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return f, false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, h.mac(p)) {
+		return f, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil || json.Unmarshal(payload, &f) != nil {
+		return f, false
+	}
+	return f, time.Now().Unix() < f.Expires
+}
+
+func (h *SSOHandler) mac(payload string) []byte {
+	m := hmac.New(sha256.New, h.secret)
+	m.Write([]byte("oidc-flow:" + payload))
+	return m.Sum(nil)
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+	"go-data-catalog/internal/sso"
+	"go-data-catalog/internal/sso/ssotest"
+)
+
+const (
+	testRedirectURL  = "http://catalog.test/api/v1/auth/oidc/callback"
+	testPostLoginURL = "http://catalog.test/login"
+)
+
+// oidcTest runs SSOHandler against the mock identity provider.
+type oidcTest struct {
+	idp    *ssotest.OIDCProvider
+	h      *SSOHandler
+	router *gin.Engine
+}
+
+// newOIDCTest needs db only for tests that get past the provider.
+func newOIDCTest(t *testing.T, db *postgres.DB, allowSignup bool) *oidcTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	idp, err := ssotest.NewOIDCProvider("", "catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(idp)
+	t.Cleanup(srv.Close)
+	idp.Issuer = srv.URL
+
+	cfg := testConfig()
+	cfg.OIDCIssuerURL = srv.URL
+	cfg.OIDCClientID = "catalog"
+	cfg.OIDCRedirectURL = testRedirectURL
+	cfg.OIDCScopes = []string{"openid", "email", "profile"}
+	cfg.OIDCGroupsClaim = "groups"
+	cfg.OIDCAllowSignup = allowSignup
+	cfg.OIDCPostLoginURL = testPostLoginURL
+	provider, err := sso.NewOIDC(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewSSOHandler(newTestAuthHandler(db, cfg), provider, nil, cfg)
+	r := gin.New()
+	r.GET("/api/v1/auth/oidc/login", h.OIDCLogin)
+	r.GET("/api/v1/auth/oidc/callback", h.OIDCCallback)
+	return &oidcTest{idp: idp, h: h, router: r}
+}
+
+// start begins a login and returns the flow cookie and the query of the
+// authorization request sent to the provider.
+func (o *oidcTest) start(t *testing.T) (*http.Cookie, url.Values) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	o.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("login: %d %s", w.Code, w.Body)
+	}
+	target, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(target.String(), o.idp.Issuer+"/authorize?") {
+		t.Fatalf("login redirects to %s", target)
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.Name == oidcFlowCookie {
+			return c, target.Query()
+		}
+	}
+	t.Fatal("login sets no flow cookie")
+	return nil, nil
+}
+
+// authorize signs in at the provider as email and returns the query the
+// provider sends back to the callback.
+func (o *oidcTest) authorize(t *testing.T, authQuery url.Values, email string) url.Values {
+	t.Helper()
+	form := url.Values{}
+	for k, v := range authQuery {
+		form[k] = v
+	}
+	form.Set("email", email)
+	form.Set("name", "Test User")
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.PostForm(o.idp.Issuer+"/authorize", form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: %s", resp.Status)
+	}
+	back, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(back.String(), testRedirectURL+"?") {
+		t.Fatalf("provider redirects to %s", back)
+	}
+	return back.Query()
+}
+
+// callback calls the callback endpoint and returns the URL fragment the
+// browser is sent to.
+func (o *oidcTest) callback(t *testing.T, query url.Values, cookie *http.Cookie) url.Values {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?"+query.Encode(), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	o.router.ServeHTTP(w, req)
+	if w.Code != http.StatusFound {
+		t.Fatalf("callback: %d %s", w.Code, w.Body)
+	}
+	target, fragment, _ := strings.Cut(w.Header().Get("Location"), "#")
+	if target != testPostLoginURL {
+		t.Fatalf("callback redirects to %s", target)
+	}
+	v, err := url.ParseQuery(fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func (o *oidcTest) login(t *testing.T, email string) url.Values {
+	t.Helper()
+	cookie, authQuery := o.start(t)
+	return o.callback(t, o.authorize(t, authQuery, email), cookie)
+}
+
+func TestOIDCLoginRedirectsToProvider(t *testing.T) {
+	o := newOIDCTest(t, &postgres.DB{}, true)
+	cookie, q := o.start(t)
+	for _, k := range []string{"state", "nonce", "code_challenge"} {
+		if q.Get(k) == "" {
+			t.Errorf("authorization request has no %s", k)
+		}
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("redirect_uri") != testRedirectURL || q.Get("client_id") != "catalog" {
+		t.Errorf("authorization request: %v", q)
+	}
+	if !cookie.HttpOnly || cookie.Path != "/api/v1/auth/oidc" {
+		t.Errorf("flow cookie: %+v", cookie)
+	}
+	flow, ok := o.h.openFlow(cookie.Value)
+	if !ok || flow.State != q.Get("state") || flow.Nonce != q.Get("nonce") {
+		t.Errorf("flow cookie does not match the request: %+v", flow)
+	}
+}
+
+func TestOIDCCallbackChecksState(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(o *oidcTest, q url.Values, c *http.Cookie) (url.Values, *http.Cookie)
+		want   string
+	}{
+		{"state mismatch", func(o *oidcTest, q url.Values, c *http.Cookie) (url.Values, *http.Cookie) {
+			q.Set("state", "forged")
+			return q, c
+		}, errFlowExpired.Error()},
+		{"no flow cookie", func(o *oidcTest, q url.Values, c *http.Cookie) (url.Values, *http.Cookie) {
+			return q, nil
+		}, errFlowExpired.Error()},
+		{"forged flow cookie", func(o *oidcTest, q url.Values, c *http.Cookie) (url.Values, *http.Cookie) {
+			c.Value = "f" + c.Value[1:]
+			return q, c
+		}, errFlowExpired.Error()},
+		{"expired flow", func(o *oidcTest, q url.Values, c *http.Cookie) (url.Values, *http.Cookie) {
+			flow, _ := o.h.openFlow(c.Value)
+			flow.Expires = time.Now().Add(-time.Second).Unix()
+			c.Value = o.h.sealFlow(flow)
+			return q, c
+		}, errFlowExpired.Error()},
+		{"provider error", func(o *oidcTest, q url.Values, c *http.Cookie) (url.Values, *http.Cookie) {
+			return url.Values{"error": {"access_denied"}, "state": q["state"]}, c
+		}, "identity provider: access_denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOIDCTest(t, &postgres.DB{}, true)
+			cookie, authQuery := o.start(t)
+			q, cookie := tt.tamper(o, o.authorize(t, authQuery, "alice@example.com"), cookie)
+			res := o.callback(t, q, cookie)
+			if res.Get("error") != tt.want || res.Get("token") != "" {
+				t.Errorf("got %v, want error %q", res, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackChecksNonce(t *testing.T) {
+	o := newOIDCTest(t, &postgres.DB{}, true)
+	cookie, authQuery := o.start(t)
+	// the provider signs the nonce it was asked for, the flow expects another
+	flow, _ := o.h.openFlow(cookie.Value)
+	flow.Nonce = "replayed"
+	cookie.Value = o.h.sealFlow(flow)
+	res := o.callback(t, o.authorize(t, authQuery, "alice@example.com"), cookie)
+	if res.Get("error") != errProviderRejected.Error() {
+		t.Errorf("got %v, want error %q", res, errProviderRejected)
+	}
+}
+
+func TestOIDCCallbackRejectsReusedCode(t *testing.T) {
+	o := newOIDCTest(t, &postgres.DB{}, true)
+	cookie, authQuery := o.start(t)
+	q := o.authorize(t, authQuery, "alice@example.com")
+	// redeem the code with a wrong nonce first, so no database is needed
+	flow, _ := o.h.openFlow(cookie.Value)
+	good := *cookie
+	flow.Nonce = "other"
+	cookie.Value = o.h.sealFlow(flow)
+	o.callback(t, q, cookie)
+	if res := o.callback(t, q, &good); res.Get("error") != errProviderRejected.Error() {
+		t.Errorf("got %v, want error %q", res, errProviderRejected)
+	}
+}
+
+func createTestUser(t *testing.T, db *postgres.DB, email string, verified bool) *models.User {
+	t.Helper()
+	u := &models.User{Email: email, PasswordHash: "!", Name: email, SystemRole: "user", IsActive: true, EmailVerified: verified}
+	if err := postgres.NewUserRepository(db).CreateUser(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestOIDCCallbackLinksByVerifiedEmail(t *testing.T) {
+	db := testDB(t)
+	o := newOIDCTest(t, db, false)
+	ctx := context.Background()
+	alice := createTestUser(t, db, "alice@example.com", true)
+	bob := createTestUser(t, db, "bob@example.com", false)
+
+	for _, u := range []*models.User{alice, bob} {
+		res := o.login(t, u.Email)
+		if res.Get("error") != "" || res.Get("token") == "" || res.Get("refresh_token") == "" {
+			t.Fatalf("login %s: %v", u.Email, res)
+		}
+		id, err := postgres.NewIdentityRepository(db).FindUserID(ctx, "oidc:"+o.idp.Issuer, "mock|"+u.Email)
+		if err != nil || id != u.ID {
+			t.Errorf("identity of %s links to user %d (%v), want %d", u.Email, id, err, u.ID)
+		}
+		// the second login goes through the link
+		if res := o.login(t, u.Email); res.Get("token") == "" {
+			t.Errorf("second login %s: %v", u.Email, res)
+		}
+	}
+	// the provider confirmed bob's address
+	if u, err := postgres.NewUserRepository(db).GetByID(ctx, bob.ID); err != nil || !u.EmailVerified {
+		t.Errorf("bob: verified %v, %v", u != nil && u.EmailVerified, err)
+	}
+}
+
+func TestOIDCCallbackSignup(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	closed := newOIDCTest(t, db, false)
+	if res := closed.login(t, "new@example.com"); res.Get("error") != errSignupDisabled.Error() {
+		t.Errorf("signup disabled: got %v", res)
+	}
+
+	open := newOIDCTest(t, db, true)
+	if res := open.login(t, "new@example.com"); res.Get("token") == "" {
+		t.Fatalf("signup: got %v", res)
+	}
+	u, err := postgres.NewUserRepository(db).GetByEmail(ctx, "new@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.PasswordHash != "!" || !u.EmailVerified {
+		t.Errorf("created user: %+v", u)
+	}
+}
+
+func TestOIDCCallbackDeactivated(t *testing.T) {
+	db := testDB(t)
+	o := newOIDCTest(t, db, false)
+	u := createTestUser(t, db, "alice@example.com", true)
+	if _, err := postgres.NewUserRepository(db).SetActive(context.Background(), u.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if res := o.login(t, u.Email); res.Get("error") != errDeactivated.Error() {
+		t.Errorf("got %v, want error %q", res, errDeactivated)
+	}
+}
+
+func TestOIDCCallbackTwoFactor(t *testing.T) {
+	db := testDB(t)
+	o := newOIDCTest(t, db, false)
+	ctx := context.Background()
+	u := createTestUser(t, db, "alice@example.com", true)
+	twoFactor := postgres.NewTwoFactorRepository(db)
+	if err := twoFactor.StartEnrollment(ctx, u.ID, "JBSWY3DPEHPK3PXP"); err != nil {
+		t.Fatal(err)
+	}
+	if err := twoFactor.Enable(ctx, u.ID, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	res := o.login(t, u.Email)
+	if res.Get("token") != "" || res.Get("error") != "" {
+		t.Fatalf("2FA user got %v", res)
+	}
+	var claims mfaClaims
+	_, err := jwt.ParseWithClaims(res.Get("mfa_token"), &claims, func(*jwt.Token) (any, error) {
+		return []byte(testConfig().JWTSecret), nil
+	})
+	if err != nil || claims.UserID != u.ID || claims.Purpose != "2fa" {
+		t.Errorf("mfa_token: %+v, %v", claims, err)
+	}
+}
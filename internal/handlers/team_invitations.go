@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/config"
+	"go-data-catalog/internal/mail"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+const defaultInvitationTTLDays = 7
+
+// InvitationsHandler manages team invitations and accepting them.
+type InvitationsHandler struct {
+	invites *postgres.TeamInvitationRepository
+	teams   *postgres.TeamRepository
+	mailer  mail.Sender
+	cfg     *config.Config
+}
+
+// MaxUses: omitted for a single-use invitation, 0 for a link that works any
+// number of times until it expires. Invitations bound to an email are always
+// single-use.
+type createInvitationRequest struct {
+	Role          string `json:"role" binding:"required,oneof=admin member viewer"`
+	Email         string `json:"email" binding:"omitempty,email,max=255"`
+	MaxUses       *int   `json:"max_uses" binding:"omitempty,min=0,max=10000"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1,max=90"`
+}
+
+// createdInvitationResponse carries the link token, which is never shown again.
+type createdInvitationResponse struct {
+	models.TeamInvitation
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type acceptInvitationRequest struct {
+	Token string `json:"token" binding:"required,max=100"`
+}
+
+type acceptInvitationResponse struct {
+	Team models.Team `json:"team"`
+	Role string      `json:"role"`
+}
+
+func NewInvitationsHandler(invites *postgres.TeamInvitationRepository, teams *postgres.TeamRepository, mailer mail.Sender, cfg *config.Config) *InvitationsHandler {
+	return &InvitationsHandler{invites: invites, teams: teams, mailer: mailer, cfg: cfg}
+}
+
+// POST /api/v1/teams/:teamId/invitations (owner/admin; only the owner
+// invites admins)
+func (h *InvitationsHandler) Create(c *gin.Context) {
+	var req createInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if req.Role == "admin" && c.GetString(middleware.CtxTeamRole) != "owner" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the owner can invite admins"})
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultInvitationTTLDays
+	}
+	userID := c.GetInt(middleware.CtxUserID)
+	inv := models.TeamInvitation{
+		TeamID:    c.GetInt(middleware.CtxTeamID),
+		Role:      req.Role,
+		ExpiresAt: time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour),
+		CreatedBy: &userID,
+	}
+	one := 1
+	switch {
+	case req.Email != "":
+		if req.MaxUses != nil && *req.MaxUses != 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "an invitation for an email address is single-use"})
+			return
+		}
+		email := strings.ToLower(req.Email)
+		inv.Email, inv.MaxUses = &email, &one
+	case req.MaxUses == nil:
+		inv.MaxUses = &one
+	case *req.MaxUses > 0:
+		inv.MaxUses = req.MaxUses
+	}
+	token, err := newRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+	if err := h.invites.Create(c.Request.Context(), &inv, postgres.HashToken(token)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+	link := strings.TrimSuffix(h.cfg.AppURL, "/") + "/#invite=" + token
+	if inv.Email != nil {
+		h.sendInvitation(inv, link)
+	}
+	c.JSON(http.StatusCreated, createdInvitationResponse{TeamInvitation: inv, Token: token, URL: link})
+}
+
+// GET /api/v1/teams/:teamId/invitations?status=active|used|expired|revoked
+func (h *InvitationsHandler) List(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", "active", "used", "expired", "revoked":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	items, err := h.invites.ListByTeam(c.Request.Context(), c.GetInt(middleware.CtxTeamID), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list invitations"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// DELETE /api/v1/teams/:teamId/invitations/:id
+func (h *InvitationsHandler) Revoke(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	err = h.invites.Revoke(c.Request.Context(), c.GetInt(middleware.CtxTeamID), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke invitation"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/v1/invitations/accept adds the current user to the team.
+func (h *InvitationsHandler) Accept(c *gin.Context) {
+	var req acceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	inv, err := h.invites.Accept(c.Request.Context(), postgres.HashToken(strings.TrimSpace(req.Token)), c.GetInt(middleware.CtxUserID))
+	switch {
+	case errors.Is(err, postgres.ErrInvalidInvitation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, postgres.ErrInvitationEmail), errors.Is(err, postgres.ErrInvitationUnverified), errors.Is(err, postgres.ErrMembershipInactive), errors.Is(err, postgres.ErrTeamArchived):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, postgres.ErrAlreadyMember):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to accept invitation"})
+		return
+	}
+	t, err := h.teams.GetByID(c.Request.Context(), inv.TeamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load team"})
+		return
+	}
+	c.JSON(http.StatusOK, acceptInvitationResponse{Team: *t, Role: inv.Role})
+}
+
+func (h *InvitationsHandler) sendInvitation(inv models.TeamInvitation, link string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		t, err := h.teams.GetByID(ctx, inv.TeamID)
+		if err != nil {
+			log.Printf("invitation %d: %v", inv.ID, err)
+			return
+		}
+		body := "You have been invited to join the team \"" + t.Name + "\" in the data catalog as " + inv.Role + ".\n\n" +
+			"Sign in or register with this email address and open the link:\n\n" + link + "\n\n" +
+			"The link works once and expires at " + inv.ExpiresAt.UTC().Format("2006-01-02 15:04 MST") + ".\n"
+		msg := mail.Message{To: *inv.Email, Subject: "Invitation to " + t.Name, Body: body}
+		if err := h.mailer.Send(ctx, msg); err != nil {
+			log.Printf("invitation email %d: %v", inv.ID, err)
+		}
+	}()
+}
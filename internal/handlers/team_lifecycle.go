@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+// TeamLifecycleHandler renames, archives and deletes teams, and lets system
+// and org admins restore deleted teams from their snapshots.
+type TeamLifecycleHandler struct {
+	teams     *postgres.TeamRepository
+	snapshots *postgres.TeamSnapshotRepository
+}
+
+type updateTeamRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=255"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+// deleteTeamRequest must repeat the team name exactly.
+type deleteTeamRequest struct {
+	ConfirmName string `json:"confirm_name" binding:"required"`
+}
+
+// restoreTeamRequest is optional; Name restores the team under another name
+// when the original one is taken.
+type restoreTeamRequest struct {
+	Name string `json:"name" binding:"omitempty,min=2,max=255"`
+}
+
+func NewTeamLifecycleHandler(teams *postgres.TeamRepository, snapshots *postgres.TeamSnapshotRepository) *TeamLifecycleHandler {
+	return &TeamLifecycleHandler{teams: teams, snapshots: snapshots}
+}
+
+// PUT /api/v1/teams/:teamId (owner)
+func (h *TeamLifecycleHandler) Update(c *gin.Context) {
+	var req updateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	teamID := c.GetInt(middleware.CtxTeamID)
+	err := h.teams.Update(c.Request.Context(), teamID, strings.TrimSpace(req.Name), req.Description)
+	if errors.Is(err, postgres.ErrTeamNameTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update team"})
+		return
+	}
+	h.respondTeam(c, teamID)
+}
+
+// POST /api/v1/teams/:teamId/archive (owner) makes the team read-only and
+// hides it from search.
+func (h *TeamLifecycleHandler) Archive(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+// POST /api/v1/teams/:teamId/unarchive (owner)
+func (h *TeamLifecycleHandler) Unarchive(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+func (h *TeamLifecycleHandler) setArchived(c *gin.Context, archived bool) {
+	teamID := c.GetInt(middleware.CtxTeamID)
+	if err := h.teams.SetArchived(c.Request.Context(), teamID, archived); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update team"})
+		return
+	}
+	h.respondTeam(c, teamID)
+}
+
+// DELETE /api/v1/teams/:teamId (owner) deletes the team and all its data
+// after saving a snapshot an org admin can restore.
+func (h *TeamLifecycleHandler) Delete(c *gin.Context) {
+	var req deleteTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "confirm_name is required"})
+		return
+	}
+	teamID := c.GetInt(middleware.CtxTeamID)
+	t, err := h.teams.GetByID(c.Request.Context(), teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load team"})
+		return
+	}
+	if strings.TrimSpace(req.ConfirmName) != t.Name {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "confirm_name does not match the team name"})
+		return
+	}
+	s, err := h.snapshots.DeleteTeam(c.Request.Context(), teamID, c.GetInt(middleware.CtxUserID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete team"})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// GET /api/v1/orgs/:orgId/team-snapshots?limit=&offset= lists snapshots of
+// the organization's teams
+func (h *TeamLifecycleHandler) ListSnapshots(c *gin.Context) {
+	limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	items, err := h.snapshots.List(c.Request.Context(), c.GetInt(middleware.CtxOrgID), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list snapshots"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GET /api/v1/orgs/:orgId/team-snapshots/:id returns the snapshot with its data.
+func (h *TeamLifecycleHandler) GetSnapshot(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	s, err := h.snapshots.Get(c.Request.Context(), id)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && !snapshotInScope(c, s.OrgID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "snapshot not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load snapshot"})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// POST /api/v1/orgs/:orgId/team-snapshots/:id/restore recreates the deleted team.
+func (h *TeamLifecycleHandler) RestoreSnapshot(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var req restoreTeamRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+			return
+		}
+	}
+	s, err := h.snapshots.Get(c.Request.Context(), id)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && !snapshotInScope(c, s.OrgID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "snapshot not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore team"})
+		return
+	}
+	t, err := h.snapshots.Restore(c.Request.Context(), id, c.GetInt(middleware.CtxUserID), strings.TrimSpace(req.Name))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "snapshot not found"})
+	case errors.Is(err, postgres.ErrSnapshotRestored), errors.Is(err, postgres.ErrTeamNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore team"})
+	default:
+		c.JSON(http.StatusCreated, t)
+	}
+}
+
+// snapshotInScope reports whether a snapshot of the organization belongs to
+// the organization of the route.
+func snapshotInScope(c *gin.Context, orgID int) bool {
+	return c.GetInt(middleware.CtxOrgID) == orgID
+}
+
+func (h *TeamLifecycleHandler) respondTeam(c *gin.Context, teamID int) {
+	t, err := h.teams.GetByID(c.Request.Context(), teamID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load team"})
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+type setMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member viewer"`
+}
+
+type setMemberStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active inactive"`
+}
+
+type transferOwnershipRequest struct {
+	UserID int `json:"user_id" binding:"required,min=1"`
+}
+
+// permissionsResponse lists the actions allowed to the caller, by resource.
+type permissionsResponse struct {
+	TeamID      int                                 `json:"team_id"`
+	OrgID       int                                 `json:"org_id"`
+	Role        string                              `json:"role"`
+	Archived    bool                                `json:"archived"`
+	Permissions map[access.Resource][]access.Action `json:"permissions"`
+}
+
+// GET /api/v1/teams/:teamId/me/permissions
+func (h *TeamsHandler) MyPermissions(c *gin.Context) {
+	role, archived := c.GetString(middleware.CtxTeamRole), c.GetBool(middleware.CtxTeamArchived)
+	c.JSON(http.StatusOK, permissionsResponse{
+		TeamID:      c.GetInt(middleware.CtxTeamID),
+		OrgID:       c.GetInt(middleware.CtxOrgID),
+		Role:        role,
+		Archived:    archived,
+		Permissions: access.For(role, archived),
+	})
+}
+
+// GET /api/v1/teams/:teamId/members?status=active|inactive
+func (h *TeamsHandler) ListMembers(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != "active" && status != "inactive" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status filter"})
+		return
+	}
+	members, err := h.members.ListMembers(c.Request.Context(), c.GetInt(middleware.CtxTeamID), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list members"})
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// PUT /api/v1/teams/:teamId/members/:userId/role (owner/admin). Only the
+// owner grants or takes away the admin role.
+func (h *TeamsHandler) SetMemberRole(c *gin.Context) {
+	var req setMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	m, ok := h.manageableMember(c, req.Role)
+	if !ok {
+		return
+	}
+	err := h.members.SetRole(c.Request.Context(), m.TeamID, m.UserID, req.Role)
+	h.respondMember(c, m.TeamID, m.UserID, err)
+}
+
+// PUT /api/v1/teams/:teamId/members/:userId/status (owner/admin). Inactive
+// members keep their role but lose access until reactivated.
+func (h *TeamsHandler) SetMemberStatus(c *gin.Context) {
+	var req setMemberStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	m, ok := h.manageableMember(c, "")
+	if !ok {
+		return
+	}
+	err := h.members.SetStatus(c.Request.Context(), m.TeamID, m.UserID, req.Status)
+	h.respondMember(c, m.TeamID, m.UserID, err)
+}
+
+// DELETE /api/v1/teams/:teamId/members/:userId (owner/admin)
+func (h *TeamsHandler) RemoveMember(c *gin.Context) {
+	m, ok := h.manageableMember(c, "")
+	if !ok {
+		return
+	}
+	if err := h.members.Remove(c.Request.Context(), m.TeamID, m.UserID); err != nil {
+		h.respondMember(c, m.TeamID, m.UserID, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/v1/teams/:teamId/leave. The last owner has to transfer
+// ownership first.
+func (h *TeamsHandler) Leave(c *gin.Context) {
+	err := h.members.Remove(c.Request.Context(), c.GetInt(middleware.CtxTeamID), c.GetInt(middleware.CtxUserID))
+	switch {
+	case errors.Is(err, postgres.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to leave the team"})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// POST /api/v1/teams/:teamId/transfer-ownership (owner). The new owner must
+// be an active member; the previous owner becomes an admin.
+func (h *TeamsHandler) TransferOwnership(c *gin.Context) {
+	var req transferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	teamID := c.GetInt(middleware.CtxTeamID)
+	actorID := c.GetInt(middleware.CtxUserID)
+	if req.UserID == actorID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "you already own this team"})
+		return
+	}
+	m, err := h.members.GetMember(c.Request.Context(), teamID, req.UserID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && m.Status != "active") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the new owner must be an active member of the team"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to transfer ownership"})
+		return
+	}
+	if m.ServiceAccount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a service account cannot own a team"})
+		return
+	}
+	err = h.members.TransferOwnership(c.Request.Context(), teamID, actorID, req.UserID)
+	h.respondMember(c, teamID, req.UserID, err)
+}
+
+// PUT /api/v1/orgs/:orgId/teams/:teamId/owner (org admin) makes an active
+// user of the organization the team owner, e.g. when the owner's account was
+// deactivated; previous owners become admins.
+func (h *TeamsHandler) ReassignOwner(c *gin.Context) {
+	teamID, err := strconv.Atoi(c.Param("teamId"))
+	if err != nil || teamID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team id"})
+		return
+	}
+	var req transferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	orgID := c.GetInt(middleware.CtxOrgID)
+	t, err := h.teams.GetByID(c.Request.Context(), teamID)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && t.OrgID != orgID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load team"})
+		return
+	}
+	inOrg, err := h.orgs.IsMember(c.Request.Context(), orgID, req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check membership"})
+		return
+	}
+	if !inOrg {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the new owner must be a member of the organization"})
+		return
+	}
+	err = h.members.ReassignOwner(c.Request.Context(), teamID, req.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the new owner must be an active user and not a service account"})
+		return
+	}
+	h.respondMember(c, teamID, req.UserID, err)
+}
+
+// manageableMember loads the :userId member and checks that the caller may
+// change it, and, if newRole is set, grant that role. Members cannot change
+// themselves (see Leave), the owner is changed only by transferring
+// ownership, and admins manage only members and viewers.
+func (h *TeamsHandler) manageableMember(c *gin.Context, newRole string) (*models.TeamMemberDetail, bool) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, false
+	}
+	if userID == c.GetInt(middleware.CtxUserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot change your own membership; use leave or transfer-ownership"})
+		return nil, false
+	}
+	m, err := h.members.GetMember(c.Request.Context(), c.GetInt(middleware.CtxTeamID), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load member"})
+		return nil, false
+	}
+	switch {
+	case m.ServiceAccount:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "manage service accounts under /service-accounts"})
+		return nil, false
+	case m.Role == "owner":
+		c.JSON(http.StatusForbidden, gin.H{"error": "the owner can only change by transferring ownership"})
+		return nil, false
+	case c.GetString(middleware.CtxTeamRole) != "owner" && (m.Role == "admin" || newRole == "admin"):
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the owner can manage admins"})
+		return nil, false
+	}
+	return m, true
+}
+
+// respondMember answers a membership change with the updated member.
+func (h *TeamsHandler) respondMember(c *gin.Context, teamID, userID int, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+		return
+	case errors.Is(err, postgres.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update member"})
+		return
+	}
+	m, err := h.members.GetMember(c.Request.Context(), teamID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load member"})
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+func TestReassignOwnerOfOwnerlessTeam(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	gin.SetMode(gin.TestMode)
+	owner := createLocalUser(t, db, "owner@example.com", "owner-pass")
+	member := createLocalUser(t, db, "member@example.com", "member-pass")
+	orgs := postgres.NewOrgRepository(db)
+	for _, u := range []*models.User{owner, member} {
+		if err := orgs.JoinAutoJoin(ctx, u.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	teams := postgres.NewTeamRepository(db)
+	team := &models.Team{OrgID: 1, Name: "Data Engineering", CreatedBy: owner.ID}
+	if err := teams.CreateTeam(ctx, team); err != nil {
+		t.Fatal(err)
+	}
+	members := postgres.NewTeamMemberRepository(db)
+	if err := members.AddOrUpdate(ctx, team.ID, owner.ID, "owner"); err != nil {
+		t.Fatal(err)
+	}
+	if err := members.AddOrUpdate(ctx, team.ID, member.ID, "member"); err != nil {
+		t.Fatal(err)
+	}
+
+	// an active owner cannot be demoted
+	if err := members.SetRole(ctx, team.ID, owner.ID, "admin"); !errors.Is(err, postgres.ErrLastOwner) {
+		t.Fatalf("demoting the only owner: %v, want ErrLastOwner", err)
+	}
+	if _, err := db.Pool.Exec(ctx, "UPDATE users SET is_active = false WHERE id = $1", owner.ID); err != nil {
+		t.Fatal(err)
+	}
+	// the team has no active owner now, which must not block other changes
+	if err := members.SetRole(ctx, team.ID, member.ID, "admin"); err != nil {
+		t.Fatalf("changing a role in an ownerless team: %v", err)
+	}
+
+	h := NewTeamsHandler(teams, members, nil, nil, nil, nil, orgs)
+	r := gin.New()
+	// stands in for OrgMembershipMiddleware and RequireOrgAdmin
+	r.PUT("/api/v1/orgs/:orgId/teams/:teamId/owner", func(c *gin.Context) {
+		c.Set(middleware.CtxOrgID, 1)
+		c.Set(middleware.CtxOrgRole, "admin")
+	}, h.ReassignOwner)
+	reassign := func(userID int) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(transferOwnershipRequest{UserID: userID})
+		path := "/api/v1/orgs/1/teams/" + strconv.Itoa(team.ID) + "/owner"
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := reassign(owner.ID); w.Code != http.StatusBadRequest {
+		t.Errorf("reassign to a deactivated user: %d %s", w.Code, w.Body)
+	}
+	if w := reassign(member.ID); w.Code != http.StatusOK {
+		t.Fatalf("reassign: %d %s", w.Code, w.Body)
+	}
+	for _, want := range []struct {
+		user *models.User
+		role string
+	}{{member, "owner"}, {owner, "admin"}} {
+		m, err := members.GetMember(ctx, team.ID, want.user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Role != want.role {
+			t.Errorf("%s has role %q, want %q", want.user.Email, m.Role, want.role)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+func TestRestoreTeamSnapshot(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	admin := createLocalUser(t, db, "admin@example.com", "admin-pass")
+	teams := postgres.NewTeamRepository(db)
+	artifacts := postgres.NewArtifactRepository(db)
+	shares := postgres.NewShareRepository(db)
+	lineage := postgres.NewLineageRepository(db)
+
+	deleted := models.Team{OrgID: 1, Name: "Data Engineering", CreatedBy: admin.ID}
+	other := models.Team{OrgID: 1, Name: "Analytics", CreatedBy: admin.ID}
+	for _, team := range []*models.Team{&deleted, &other} {
+		if err := teams.CreateTeam(ctx, team); err != nil {
+			t.Fatal(err)
+		}
+	}
+	artifact := func(team *models.Team, name string) *models.Artifact {
+		a := &models.Artifact{Name: name, Type: "table", ProjectName: "warehouse"}
+		if err := artifacts.CreateArtifact(ctx, team.ID, a); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	orders, customers, report := artifact(&deleted, "orders"), artifact(&other, "customers"), artifact(&other, "report")
+	for _, s := range []*models.ArtifactShare{
+		{ArtifactID: orders.ID, TeamID: deleted.ID, TargetTeamID: &other.ID, CreatedBy: &admin.ID},
+		{ArtifactID: customers.ID, TeamID: other.ID, TargetTeamID: &deleted.ID, CreatedBy: &admin.ID},
+	} {
+		if err := shares.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// orders is derived from customers, and report from orders
+	if _, err := lineage.Add(ctx, deleted.ID, orders.ID, customers.ID, admin.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lineage.Add(ctx, other.ID, report.ID, orders.ID, admin.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	if _, err := artifacts.Certify(ctx, deleted.ID, orders.ID, admin.ID, "reviewed", expires); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Pool.Exec(ctx, "SELECT flag_certification_review($1, 'field id changed')", orders.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots := postgres.NewTeamSnapshotRepository(db)
+	s, err := snapshots.DeleteTeam(ctx, deleted.ID, admin.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := snapshots.Restore(ctx, s.ID, admin.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := artifacts.GetArtifactsPage(ctx, restored.ID, 0, 0)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("restored artifacts %+v (%v)", list, err)
+	}
+	restoredOrders := list[0].ID
+
+	cert, err := artifacts.Certification(ctx, restored.ID, restoredOrders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Note != "reviewed" || !cert.ExpiresAt.Equal(expires) || cert.State != models.CertNeedsReview || cert.ReviewReason != "field id changed" {
+		t.Errorf("restored certification %+v", cert)
+	}
+
+	own, err := shares.ListByTeam(ctx, restored.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(own) != 1 || own[0].ArtifactID != restoredOrders || own[0].TargetTeamID == nil || *own[0].TargetTeamID != other.ID {
+		t.Errorf("shares of the restored team: %+v", own)
+	}
+	with, err := shares.ListSharedWith(ctx, restored.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(with) != 1 || with[0].ID != customers.ID {
+		t.Errorf("shared with the restored team: %+v", with)
+	}
+
+	edges, err := lineage.ByTeam(ctx, restored.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[[2]int]bool{{customers.ID, restoredOrders}: true, {restoredOrders, report.ID}: true}
+	for _, e := range edges {
+		if e.UpstreamID == nil || !want[[2]int{*e.UpstreamID, e.DownstreamID}] || e.UpstreamStatus != models.UpstreamAvailable {
+			t.Errorf("unexpected edge %+v", e)
+			continue
+		}
+		delete(want, [2]int{*e.UpstreamID, e.DownstreamID})
+	}
+	if len(want) != 0 {
+		t.Errorf("edges not restored: %v", want)
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 
 	"go-data-catalog/internal/middleware"
 	"go-data-catalog/internal/models"
@@ -15,19 +18,45 @@ type TeamsHandler struct {
 	teams      *postgres.TeamRepository
 	members    *postgres.TeamMemberRepository
 	joinReqs   *postgres.JoinRequestRepository
+	twoFactor  *postgres.TwoFactorRepository
+	users      *postgres.UserRepository
+	notify     *postgres.NotificationRepository
+	orgs       *postgres.OrgRepository
 }
 
 type createTeamRequest struct {
 	Name        string `json:"name" binding:"required,min=2,max=255"`
 	Description string `json:"description" binding:"omitempty,max=1000"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public discoverable hidden"`
+	JoinPolicy  string `json:"join_policy" binding:"omitempty,oneof=open approval invite_only"`
+	// OrgID may be omitted when the user belongs to a single organization.
+	OrgID       int    `json:"org_id"`
 }
 
-type joinDecisionRequest struct {
-	Action string `json:"action" binding:"required,oneof=approve reject"`
+// teamSettingsRequest replaces all access settings of a team. A public
+// catalog is only allowed for public teams.
+type teamSettingsRequest struct {
+	Visibility    string `json:"visibility" binding:"required,oneof=public discoverable hidden"`
+	JoinPolicy    string `json:"join_policy" binding:"required,oneof=open approval invite_only"`
+	PublicCatalog bool   `json:"public_catalog"`
 }
 
-func NewTeamsHandler(teams *postgres.TeamRepository, members *postgres.TeamMemberRepository, joinReqs *postgres.JoinRequestRepository) *TeamsHandler {
-	return &TeamsHandler{teams: teams, members: members, joinReqs: joinReqs}
+type require2FARequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+// joinRequestBody is the optional body of a join request.
+type joinRequestBody struct {
+	Message string `json:"message" binding:"max=1000"`
+}
+
+// joinDecisionBody is the optional body of a decision.
+type joinDecisionBody struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+func NewTeamsHandler(teams *postgres.TeamRepository, members *postgres.TeamMemberRepository, joinReqs *postgres.JoinRequestRepository, twoFactor *postgres.TwoFactorRepository, users *postgres.UserRepository, notify *postgres.NotificationRepository, orgs *postgres.OrgRepository) *TeamsHandler {
+	return &TeamsHandler{teams: teams, members: members, joinReqs: joinReqs, twoFactor: twoFactor, users: users, notify: notify, orgs: orgs}
 }
 
 // POST /api/v1/teams
@@ -35,60 +64,186 @@ func (h *TeamsHandler) CreateTeam(c *gin.Context) {
 	userID := c.GetInt(middleware.CtxUserID)
 	var req createTeamRequest
 	if err := c.ShouldBindJSON(&req); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"}); return }
-	t := &models.Team{Name: req.Name, Description: req.Description, CreatedBy: userID}
-	if err := h.teams.CreateTeam(c.Request.Context(), t); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "cannot create team"}); return }
-	_ = h.members.AddOrUpdate(c.Request.Context(), t.ID, userID, "owner")
+	orgID, ok := orgOf(c, h.orgs, req.OrgID)
+	if !ok { return }
+	t := &models.Team{OrgID: orgID, Name: req.Name, Description: req.Description, Visibility: req.Visibility, JoinPolicy: req.JoinPolicy, CreatedBy: userID}
+	err := h.teams.CreateTeam(c.Request.Context(), t)
+	if errors.Is(err, postgres.ErrTeamNameTaken) { c.JSON(http.StatusConflict, gin.H{"error": err.Error()}); return }
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "cannot create team"}); return }
+	if err := h.members.AddOrUpdate(c.Request.Context(), t.ID, userID, "owner"); err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to make you the team owner"}); return }
 	c.JSON(http.StatusCreated, t)
 }
 
-// GET /api/v1/teams?search=foo
+// GET /api/v1/teams?search=foo lists public teams of the user's organizations
+// by name, discoverable teams by their exact name and the user's own teams;
+// hidden teams are never listed.
 func (h *TeamsHandler) Search(c *gin.Context) {
 	q := c.Query("search")
-	res, err := h.teams.Search(c.Request.Context(), q, 20)
+	res, err := h.teams.Search(c.Request.Context(), q, c.GetInt(middleware.CtxUserID), 20)
 	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
 	c.JSON(http.StatusOK, res)
 }
 
-// POST /api/v1/teams/:teamId/join
+// POST /api/v1/teams/:teamId/join; the team's owners and admins are notified.
+// Open teams approve the request at once; invite-only teams refuse it.
 func (h *TeamsHandler) RequestJoin(c *gin.Context) {
 	userID := c.GetInt(middleware.CtxUserID)
-	teamID, _ := strconv.Atoi(c.Param("teamId"))
+	teamID, err := strconv.Atoi(c.Param("teamId"))
+	if err != nil || teamID <= 0 { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team id"}); return }
+	var req joinRequestBody
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"}); return }
+	}
+	t, err := h.teams.GetByID(c.Request.Context(), teamID)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && t.Visibility == "hidden" { c.JSON(http.StatusNotFound, gin.H{"error": "team not found"}); return }
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
+	// teams of other organizations are invisible
+	inOrg, err := h.orgs.IsMember(c.Request.Context(), t.OrgID, userID)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
+	if !inOrg { c.JSON(http.StatusNotFound, gin.H{"error": "team not found"}); return }
 	isMember, err := h.members.IsMember(c.Request.Context(), teamID, userID)
 	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
 	if isMember { c.JSON(http.StatusBadRequest, gin.H{"error": "already a member"}); return }
-	jr, err := h.joinReqs.Create(c.Request.Context(), teamID, userID)
+	if t.ArchivedAt != nil { c.JSON(http.StatusForbidden, gin.H{"error": postgres.ErrTeamArchived.Error()}); return }
+	if t.JoinPolicy == "invite_only" { c.JSON(http.StatusForbidden, gin.H{"error": "this team accepts members by invitation only"}); return }
+	if t.JoinPolicy == "open" {
+		// a suspended member must not reinstate themselves
+		if _, err := h.members.GetMember(c.Request.Context(), teamID, userID); err == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": postgres.ErrMembershipInactive.Error()}); return
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return
+		}
+	}
+	jr, err := h.joinReqs.Create(c.Request.Context(), teamID, userID, req.Message)
+	if errors.Is(err, postgres.ErrPendingRequest) { c.JSON(http.StatusConflict, gin.H{"error": err.Error()}); return }
 	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "cannot create request"}); return }
+	requester := strconv.Itoa(userID)
+	if u, err := h.users.GetByID(c.Request.Context(), userID); err == nil {
+		requester = u.Email
+	}
+	msg := requester + " asked to join " + t.Name
+	if t.JoinPolicy == "open" {
+		jr, err = h.joinReqs.Decide(c.Request.Context(), teamID, jr.ID, userID, "approved", "")
+		if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot join team"}); return }
+		msg = requester + " joined " + t.Name
+	}
+	if jr.Message != "" {
+		msg += ": " + jr.Message
+	}
+	if err := h.notify.NotifyTeamAdmins(c.Request.Context(), teamID, postgres.NotifyJoinRequest, jr.ID, msg); err != nil {
+		log.Printf("join request %d notification: %v", jr.ID, err)
+	}
 	c.JSON(http.StatusCreated, jr)
 }
 
 // GET /api/v1/teams/:teamId/requests?status=pending (owner/admin)
 func (h *TeamsHandler) ListRequests(c *gin.Context) {
-	teamID, _ := strconv.Atoi(c.Param("teamId"))
-	status := c.DefaultQuery("status", "")
-	items, err := h.joinReqs.ListByTeam(c.Request.Context(), teamID, status)
+	status, ok := joinRequestStatus(c)
+	if !ok { return }
+	items, err := h.joinReqs.ListByTeam(c.Request.Context(), c.GetInt(middleware.CtxTeamID), status)
 	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
 	c.JSON(http.StatusOK, items)
 }
 
-// POST /api/v1/teams/:teamId/requests/:id/approve or reject
+// POST /api/v1/teams/:teamId/requests/:id/approve or reject. Only pending
+// requests can be decided; the requester is notified.
 func (h *TeamsHandler) DecideRequest(c *gin.Context) {
-	teamID, _ := strconv.Atoi(c.Param("teamId"))
-	reqID, _ := strconv.Atoi(c.Param("id"))
+	teamID := c.GetInt(middleware.CtxTeamID)
+	reqID, err := strconv.Atoi(c.Param("id"))
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"}); return }
 	actorID := c.GetInt(middleware.CtxUserID)
 	action := c.Param("action") // expects "approve" or "reject"
 	if action != "approve" && action != "reject" { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid action"}); return }
-	jr, err := h.joinReqs.GetByID(c.Request.Context(), reqID)
-	if err != nil || jr.TeamID != teamID { c.JSON(http.StatusNotFound, gin.H{"error": "request not found"}); return }
+	var req joinDecisionBody
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"}); return }
+	}
 	status := map[string]string{"approve": "approved", "reject": "rejected"}[action]
-	if err := h.joinReqs.UpdateStatus(c.Request.Context(), reqID, actorID, status); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot update"}); return
+	jr, err := h.joinReqs.Decide(c.Request.Context(), teamID, reqID, actorID, status, req.Comment)
+	if errors.Is(err, pgx.ErrNoRows) { c.JSON(http.StatusNotFound, gin.H{"error": "request not found"}); return }
+	if errors.Is(err, postgres.ErrRequestProcessed) { c.JSON(http.StatusConflict, gin.H{"error": err.Error()}); return }
+	if errors.Is(err, postgres.ErrMembershipInactive) {
+		c.JSON(http.StatusConflict, gin.H{"error": "a team admin deactivated this user's membership; reactivate the member or reject the request"}); return
 	}
-	if status == "approved" {
-		_ = h.members.AddOrUpdate(c.Request.Context(), teamID, jr.UserID, "member")
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot update"}); return }
+	teamName := strconv.Itoa(teamID)
+	if t, err := h.teams.GetByID(c.Request.Context(), teamID); err == nil {
+		teamName = t.Name
+	}
+	msg := "Your request to join " + teamName + " was " + status
+	if jr.DecisionComment != "" {
+		msg += ": " + jr.DecisionComment
+	}
+	if err := h.notify.Create(c.Request.Context(), jr.UserID, postgres.NotifyJoinRequestDecided, teamID, jr.ID, msg); err != nil {
+		log.Printf("join request %d notification: %v", jr.ID, err)
 	}
 	c.Status(http.StatusNoContent)
 }
 
+// GET /api/v1/me/join-requests?status=pending
+func (h *TeamsHandler) MyJoinRequests(c *gin.Context) {
+	status, ok := joinRequestStatus(c)
+	if !ok { return }
+	items, err := h.joinReqs.ListForUser(c.Request.Context(), c.GetInt(middleware.CtxUserID), status)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
+	c.JSON(http.StatusOK, items)
+}
+
+// POST /api/v1/me/join-requests/:id/cancel withdraws a pending request.
+func (h *TeamsHandler) CancelJoinRequest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"}); return }
+	err = h.joinReqs.Cancel(c.Request.Context(), id, c.GetInt(middleware.CtxUserID))
+	if errors.Is(err, pgx.ErrNoRows) { c.JSON(http.StatusNotFound, gin.H{"error": "request not found"}); return }
+	if errors.Is(err, postgres.ErrRequestProcessed) { c.JSON(http.StatusConflict, gin.H{"error": err.Error()}); return }
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
+	c.Status(http.StatusNoContent)
+}
+
+func joinRequestStatus(c *gin.Context) (string, bool) {
+	status := c.Query("status")
+	switch status {
+	case "", "pending", "approved", "rejected", "cancelled":
+		return status, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+	return "", false
+}
+
+// PUT /api/v1/teams/:teamId/require-2fa (owner). The owner must have 2FA
+// enabled to turn the requirement on, so they do not lock themselves out.
+func (h *TeamsHandler) SetRequire2FA(c *gin.Context) {
+	var req require2FARequest
+	if err := c.ShouldBindJSON(&req); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"}); return }
+	teamID := c.GetInt(middleware.CtxTeamID)
+	if *req.Required {
+		enabled, err := h.twoFactor.IsEnabled(c.Request.Context(), c.GetInt(middleware.CtxUserID))
+		if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
+		if !enabled { c.JSON(http.StatusBadRequest, gin.H{"error": "enable two-factor authentication for your own account first"}); return }
+	}
+	if err := h.teams.SetRequire2FA(c.Request.Context(), teamID, *req.Required); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return
+	}
+	t, err := h.teams.GetByID(c.Request.Context(), teamID)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
+	c.JSON(http.StatusOK, t)
+}
+
+// PUT /api/v1/teams/:teamId/settings (owner) sets visibility, join policy and
+// the public catalog.
+func (h *TeamsHandler) UpdateSettings(c *gin.Context) {
+	var req teamSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"}); return }
+	if req.PublicCatalog && req.Visibility != "public" { c.JSON(http.StatusBadRequest, gin.H{"error": "only a public team can have a public catalog"}); return }
+	teamID := c.GetInt(middleware.CtxTeamID)
+	if err := h.teams.SetAccess(c.Request.Context(), teamID, req.Visibility, req.JoinPolicy, req.PublicCatalog); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return
+	}
+	t, err := h.teams.GetByID(c.Request.Context(), teamID)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}); return }
+	c.JSON(http.StatusOK, t)
+}
+
 // GET /api/v1/me/teams
 func (h *TeamsHandler) MyTeams(c *gin.Context) {
 	userID := c.GetInt(middleware.CtxUserID)
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+const defaultTokenTTLDays = 90
+
+// TokensHandler manages personal access tokens and team service accounts.
+type TokensHandler struct {
+	tokens   *postgres.APITokenRepository
+	accounts *postgres.ServiceAccountRepository
+}
+
+type createTokenRequest struct {
+	Name          string   `json:"name" binding:"required,min=1,max=255"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=read write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// createdTokenResponse carries the token secret, which is never shown again.
+type createdTokenResponse struct {
+	models.APIToken
+	Token string `json:"token"`
+}
+
+type createServiceAccountRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=255"`
+	Role string `json:"role" binding:"required,oneof=admin member viewer"`
+}
+
+func NewTokensHandler(tokens *postgres.APITokenRepository, accounts *postgres.ServiceAccountRepository) *TokensHandler {
+	return &TokensHandler{tokens: tokens, accounts: accounts}
+}
+
+// GET /me/tokens
+func (h *TokensHandler) ListMine(c *gin.Context) {
+	h.list(c, c.GetInt(middleware.CtxUserID))
+}
+
+// POST /me/tokens
+func (h *TokensHandler) CreateMine(c *gin.Context) {
+	h.create(c, c.GetInt(middleware.CtxUserID))
+}
+
+// DELETE /me/tokens/:id
+func (h *TokensHandler) RevokeMine(c *gin.Context) {
+	h.revoke(c, c.GetInt(middleware.CtxUserID), c.Param("id"))
+}
+
+// GET /teams/:teamId/service-accounts (owner/admin)
+func (h *TokensHandler) ListServiceAccounts(c *gin.Context) {
+	items, err := h.accounts.ListByTeam(c.Request.Context(), c.GetInt(middleware.CtxTeamID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list service accounts"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// POST /teams/:teamId/service-accounts (owner/admin)
+func (h *TokensHandler) CreateServiceAccount(c *gin.Context) {
+	var req createServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	sa, err := h.accounts.Create(c.Request.Context(), c.GetInt(middleware.CtxTeamID), req.Name, req.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create service account"})
+		return
+	}
+	c.JSON(http.StatusCreated, sa)
+}
+
+// DELETE /teams/:teamId/service-accounts/:id (owner/admin) deactivates the
+// account and revokes its tokens.
+func (h *TokensHandler) DeactivateServiceAccount(c *gin.Context) {
+	sa, ok := h.serviceAccount(c)
+	if !ok {
+		return
+	}
+	if err := h.accounts.Deactivate(c.Request.Context(), sa.TeamID, sa.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to deactivate service account"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /teams/:teamId/service-accounts/:id/tokens (owner/admin)
+func (h *TokensHandler) ListServiceAccountTokens(c *gin.Context) {
+	if sa, ok := h.serviceAccount(c); ok {
+		h.list(c, sa.ID)
+	}
+}
+
+// POST /teams/:teamId/service-accounts/:id/tokens (owner/admin)
+func (h *TokensHandler) CreateServiceAccountToken(c *gin.Context) {
+	sa, ok := h.serviceAccount(c)
+	if !ok {
+		return
+	}
+	if !sa.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "service account is deactivated"})
+		return
+	}
+	h.create(c, sa.ID)
+}
+
+// DELETE /teams/:teamId/service-accounts/:id/tokens/:tokenId (owner/admin)
+func (h *TokensHandler) RevokeServiceAccountToken(c *gin.Context) {
+	if sa, ok := h.serviceAccount(c); ok {
+		h.revoke(c, sa.ID, c.Param("tokenId"))
+	}
+}
+
+func (h *TokensHandler) serviceAccount(c *gin.Context) (*models.ServiceAccount, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return nil, false
+	}
+	sa, err := h.accounts.GetByID(c.Request.Context(), c.GetInt(middleware.CtxTeamID), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return nil, false
+	}
+	return sa, true
+}
+
+func (h *TokensHandler) list(c *gin.Context, userID int) {
+	items, err := h.tokens.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tokens"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *TokensHandler) create(c *gin.Context, userID int) {
+	var req createTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultTokenTTLDays
+	}
+	secret, err := newAPIToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create token"})
+		return
+	}
+	creator := c.GetInt(middleware.CtxUserID)
+	t := models.APIToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    secret[:len(middleware.APITokenPrefix)+8],
+		Scopes:    req.Scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, req.ExpiresInDays),
+		CreatedBy: &creator,
+	}
+	if err := h.tokens.Create(c.Request.Context(), &t, postgres.HashToken(secret)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create token"})
+		return
+	}
+	c.JSON(http.StatusCreated, createdTokenResponse{APIToken: t, Token: secret})
+}
+
+func (h *TokensHandler) revoke(c *gin.Context, userID int, idParam string) {
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	err = h.tokens.Revoke(c.Request.Context(), id, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke token"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func newAPIToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return middleware.APITokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
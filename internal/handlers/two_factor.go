@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"image/png"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/repository/postgres"
+)
+
+const (
+	totpIssuer        = "Go Data Catalog"
+	totpPeriod        = 30 // seconds
+	recoveryCodeCount = 10
+)
+
+// TwoFactorHandler manages TOTP enrollment of the current user.
+type TwoFactorHandler struct {
+	users     *postgres.UserRepository
+	twoFactor *postgres.TwoFactorRepository
+}
+
+type twoFactorStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+type twoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // PNG data URL of otpauth_uri
+}
+
+// Code is a TOTP code or, where allowed, a recovery code.
+type twoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func NewTwoFactorHandler(users *postgres.UserRepository, twoFactor *postgres.TwoFactorRepository) *TwoFactorHandler {
+	return &TwoFactorHandler{users: users, twoFactor: twoFactor}
+}
+
+// GET /me/2fa
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userID := c.GetInt(middleware.CtxUserID)
+	enabled, err := h.twoFactor.IsEnabled(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load 2FA status"})
+		return
+	}
+	res := twoFactorStatusResponse{Enabled: enabled}
+	if enabled {
+		if res.RecoveryCodesLeft, err = h.twoFactor.RecoveryCodesLeft(c.Request.Context(), userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load 2FA status"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// POST /me/2fa/enroll generates a new secret. 2FA is enabled only after the
+// first code is confirmed.
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	userID := c.GetInt(middleware.CtxUserID)
+	u, err := h.users.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start enrollment"})
+		return
+	}
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: totpIssuer, AccountName: u.Email, Period: totpPeriod})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start enrollment"})
+		return
+	}
+	err = h.twoFactor.StartEnrollment(c.Request.Context(), userID, key.Secret())
+	if errors.Is(err, postgres.ErrTwoFactorEnabled) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start enrollment"})
+		return
+	}
+	res := twoFactorEnrollResponse{Secret: key.Secret(), OTPAuthURI: key.URL()}
+	if img, err := key.Image(200, 200); err == nil {
+		var buf bytes.Buffer
+		if png.Encode(&buf, img) == nil {
+			res.QRCode = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// POST /me/2fa/confirm enables 2FA with the first TOTP code and returns the
+// recovery codes, which are shown only once.
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	userID := c.GetInt(middleware.CtxUserID)
+	t, err := h.twoFactor.Get(c.Request.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start enrollment first"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enable 2FA"})
+		return
+	}
+	if t.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": postgres.ErrTwoFactorEnabled.Error()})
+		return
+	}
+	step, ok := matchTOTP(t.Secret, normalizeCode(req.Code), time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enable 2FA"})
+		return
+	}
+	if err := h.twoFactor.Enable(c.Request.Context(), userID, step, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enable 2FA"})
+		return
+	}
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// POST /me/2fa/disable needs a current TOTP or recovery code.
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, ok := h.checkCode(c)
+	if !ok {
+		return
+	}
+	if err := h.twoFactor.Disable(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to disable 2FA"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /me/2fa/recovery-codes replaces all recovery codes; needs a current
+// TOTP or recovery code.
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := h.checkCode(c)
+	if !ok {
+		return
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create recovery codes"})
+		return
+	}
+	if err := h.twoFactor.ReplaceRecoveryCodes(c.Request.Context(), userID, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create recovery codes"})
+		return
+	}
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *TwoFactorHandler) checkCode(c *gin.Context) (int, bool) {
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return 0, false
+	}
+	userID := c.GetInt(middleware.CtxUserID)
+	enabled, err := h.twoFactor.IsEnabled(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check code"})
+		return 0, false
+	}
+	if !enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication is not enabled"})
+		return 0, false
+	}
+	ok, err := verifySecondFactor(c.Request.Context(), h.twoFactor, userID, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check code"})
+		return 0, false
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// verifySecondFactor accepts a TOTP code, each time step at most once, or an
+// unused recovery code, which is then used up.
+func verifySecondFactor(ctx context.Context, repo *postgres.TwoFactorRepository, userID int, code string) (bool, error) {
+	code = normalizeCode(code)
+	if isTOTPCode(code) {
+		t, err := repo.Get(ctx, userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil || !t.Enabled {
+			return false, err
+		}
+		step, ok := matchTOTP(t.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return repo.UseStep(ctx, userID, step)
+	}
+	return repo.UseRecoveryCode(ctx, userID, postgres.HashToken(code))
+}
+
+// matchTOTP checks the code against the current time step and its
+// neighbours (clock drift) and returns the matching step.
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	for _, drift := range []int64{0, -1, 1} {
+		t := now.Add(time.Duration(drift*totpPeriod) * time.Second)
+		if ok, _ := totp.ValidateCustom(code, secret, t, opts); ok {
+			return t.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+// normalizeCode drops spaces and dashes so "123 456" and "ABCDE-12345" work.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCodes returns codes formatted for display ("xxxxx-xxxxx") and
+// the hashes of their normalized form.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		s := hex.EncodeToString(b)
+		codes[i] = s[:5] + "-" + s[5:]
+		hashes[i] = postgres.HashToken(s)
+	}
+	return codes, hashes, nil
+}
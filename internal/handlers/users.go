@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/middleware"
+	"go-data-catalog/internal/models"
+	"go-data-catalog/internal/repository/postgres"
+	"go-data-catalog/internal/throttle"
+)
+
+// UsersHandler serves user management for org admins under
+// /orgs/:orgId/users: it sees the organization's members and changes only
+// accounts whose organizations the caller administers all. System admins
+// only change system roles, under /admin.
+type UsersHandler struct {
+	users     *postgres.UserRepository
+	orgs      *postgres.OrgRepository
+	sessions  *postgres.SessionRepository
+	twoFactor *postgres.TwoFactorRepository
+	attempts  *postgres.LoginAttemptRepository
+	limiter   *throttle.Limiter
+}
+
+// loginHistoryPageSize is the default limit; the history can be long.
+const loginHistoryPageSize = 50
+
+type loginHistoryResponse struct {
+	LockedUntil *time.Time            `json:"locked_until"` // set while failed logins block the account
+	Attempts    []models.LoginAttempt `json:"attempts"`
+}
+
+type setSystemRoleRequest struct {
+	SystemRole string `json:"system_role" binding:"required,oneof=user admin"`
+}
+
+func NewUsersHandler(users *postgres.UserRepository, orgs *postgres.OrgRepository, sessions *postgres.SessionRepository, twoFactor *postgres.TwoFactorRepository, attempts *postgres.LoginAttemptRepository, limiter *throttle.Limiter) *UsersHandler {
+	return &UsersHandler{users: users, orgs: orgs, sessions: sessions, twoFactor: twoFactor, attempts: attempts, limiter: limiter}
+}
+
+// GET /orgs/:orgId/users?search=&active=&limit=&offset=
+func (h *UsersHandler) List(c *gin.Context) {
+	limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	var active *bool
+	if v := c.Query("active"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid active filter"})
+			return
+		}
+		active = &b
+	}
+	users, err := h.users.List(c.Request.Context(), c.GetInt(middleware.CtxOrgID), c.Query("search"), active, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list users"})
+		return
+	}
+	if users == nil {
+		users = []models.User{}
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// GET /orgs/:orgId/users/:id
+func (h *UsersHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	if !h.inScope(c, id, false) {
+		return
+	}
+	u, err := h.users.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// POST /orgs/:orgId/users/:id/deactivate blocks login and ends all sessions. Team
+// memberships are kept but grant no access while the user is inactive.
+func (h *UsersHandler) Deactivate(c *gin.Context) {
+	id, ok := h.targetID(c)
+	if !ok {
+		return
+	}
+	u, err := h.users.SetActive(c.Request.Context(), id, false)
+	if !h.checkUpdate(c, err) {
+		return
+	}
+	if err := h.sessions.RevokeAllForUser(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// POST /orgs/:orgId/users/:id/activate
+func (h *UsersHandler) Activate(c *gin.Context) {
+	id, ok := h.targetID(c)
+	if !ok {
+		return
+	}
+	u, err := h.users.SetActive(c.Request.Context(), id, true)
+	if !h.checkUpdate(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// PUT /admin/users/:id/role
+func (h *UsersHandler) SetRole(c *gin.Context) {
+	id, ok := h.targetID(c)
+	if !ok {
+		return
+	}
+	var req setSystemRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	u, err := h.users.SetSystemRole(c.Request.Context(), id, req.SystemRole)
+	if !h.checkUpdate(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// DELETE /orgs/:orgId/users/:id/2fa turns off two-factor authentication for a user
+// who lost both the authenticator and the recovery codes.
+func (h *UsersHandler) ResetTwoFactor(c *gin.Context) {
+	id, ok := h.targetID(c)
+	if !ok {
+		return
+	}
+	if _, err := h.users.GetByID(c.Request.Context(), id); err != nil {
+		h.checkUpdate(c, err)
+		return
+	}
+	if err := h.twoFactor.Disable(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset 2FA"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /orgs/:orgId/users/:id/login-attempts?limit=&offset= returns the newest
+// login attempts and whether the account is locked.
+func (h *UsersHandler) LoginAttempts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	limit, offset, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	if limit == 0 {
+		limit = loginHistoryPageSize
+	}
+	if !h.inScope(c, id, false) {
+		return
+	}
+	u, err := h.users.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	attempts, err := h.attempts.ListForUser(c.Request.Context(), u.ID, u.Email, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load login history"})
+		return
+	}
+	res := loginHistoryResponse{Attempts: attempts}
+	if res.Attempts == nil {
+		res.Attempts = []models.LoginAttempt{}
+	}
+	until, err := h.limiter.LockedUntil(c.Request.Context(), u.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load login history"})
+		return
+	}
+	if !until.IsZero() {
+		res.LockedUntil = &until
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// POST /orgs/:orgId/users/:id/unlock clears failed logins and any lockout of the
+// account. Limits on the client IPs stay.
+func (h *UsersHandler) Unlock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	if !h.inScope(c, id, true) {
+		return
+	}
+	u, err := h.users.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.checkUpdate(c, err)
+		return
+	}
+	if err := h.limiter.Unlock(c.Request.Context(), u.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlock user"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// targetID parses :id and refuses changes to the caller's own account, so an
+// admin can't lock themselves (and possibly everyone) out.
+func (h *UsersHandler) targetID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, false
+	}
+	if id == c.GetInt(middleware.CtxUserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot change your own account"})
+		return 0, false
+	}
+	return id, h.inScope(c, id, true)
+}
+
+// inScope checks, on organization routes, that the user is a member and, to
+// change the account, that the caller is an admin of all the user's
+// organizations and, for a system admin, a system admin too.
+func (h *UsersHandler) inScope(c *gin.Context, id int, change bool) bool {
+	orgID := c.GetInt(middleware.CtxOrgID)
+	if orgID == 0 {
+		return true
+	}
+	ok, err := h.orgs.IsMember(c.Request.Context(), orgID, id)
+	if err == nil && ok && change {
+		if ok, err = h.orgs.AdminOfAll(c.Request.Context(), c.GetInt(middleware.CtxUserID), id); err == nil && !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "the user also belongs to organizations you are not an admin of"})
+			return false
+		}
+		var u *models.User
+		if u, err = h.users.GetByID(c.Request.Context(), id); err == nil && u.SystemRole == "admin" && c.GetString(middleware.CtxSysRole) != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "system admins are managed by system admins"})
+			return false
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load user"})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return false
+	}
+	return true
+}
+
+func (h *UsersHandler) checkUpdate(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+		return false
+	}
+	return true
+}
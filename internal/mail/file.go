@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File writes every message to its own .eml file in Dir. Useful for local
+// development and tests that need to read the links out of the messages.
+type File struct {
+	Dir  string
+	From string
+}
+
+func NewFile(dir, from string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("mail dir: %w", err)
+	}
+	return &File{Dir: dir, From: from}, nil
+}
+
+func (f *File) Send(ctx context.Context, m Message) error {
+	msg, err := format(f.From, m)
+	if err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix) + ".eml"
+	return os.WriteFile(filepath.Join(f.Dir, name), msg, 0o600)
+}
+
+// Log prints messages to the server log instead of sending them.
+type Log struct {
+	From string
+}
+
+func (l *Log) Send(ctx context.Context, m Message) error {
+	log.Printf("mail to %s: %s\n%s", m.To, m.Subject, m.Body)
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package mail sends notification emails. The driver is chosen with
+// MAIL_DRIVER: "smtp" for real delivery, "file" to write .eml files and
+// "log" (the default) to print messages to the server log.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"time"
+
+	"go-data-catalog/internal/config"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// New returns the sender configured by MAIL_DRIVER.
+func New(cfg *config.Config) (Sender, error) {
+	if _, err := mail.ParseAddress(cfg.MailFrom); err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required with MAIL_DRIVER=smtp")
+		}
+		return &SMTP{
+			Host:        cfg.SMTPHost,
+			Port:        cfg.SMTPPort,
+			Username:    cfg.SMTPUsername,
+			Password:    cfg.SMTPPassword,
+			ImplicitTLS: cfg.SMTPImplicitTLS,
+			From:        cfg.MailFrom,
+		}, nil
+	case "file":
+		return NewFile(cfg.MailFileDir, cfg.MailFrom)
+	case "log", "":
+		return &Log{From: cfg.MailFrom}, nil
+	}
+	return nil, fmt.Errorf("unknown MAIL_DRIVER %q: want smtp, file or log", cfg.MailDriver)
+}
+
+// format renders m as an RFC 5322 message with a quoted-printable UTF-8 body.
+func format(from string, m Message) ([]byte, error) {
+	var buf bytes.Buffer
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	toAddr, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+	fmt.Fprintf(&buf, "From: %s\r\n", fromAddr.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", toAddr.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain(fromAddr.Address))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write(bytes.ReplaceAll([]byte(m.Body), []byte("\n"), []byte("\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func domain(addr string) string {
+	for i := len(addr) - 1; i >= 0; i-- {
+		if addr[i] == '@' {
+			return addr[i+1:]
+		}
+	}
+	return "localhost"
+}
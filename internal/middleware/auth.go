@@ -1,24 +1,34 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
 	"go-data-catalog/internal/config"
+	"go-data-catalog/internal/repository/postgres"
 )
 
 // Claims for JWT
 type Claims struct {
 	UserID     int    `json:"user_id"`
 	SystemRole string `json:"system_role"`
+	SessionID  string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// AuthMiddleware validates JWT and sets user info into context
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// APITokenPrefix starts every personal access and service account token,
+// which tells them apart from JWTs.
+const APITokenPrefix = "dcp_"
+
+// AuthMiddleware validates JWT, checks that its session is not revoked and sets user info into context.
+// API tokens (APITokenPrefix) are accepted as well.
+func AuthMiddleware(cfg *config.Config, sessions *postgres.SessionRepository, apiTokens *postgres.APITokenRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		auth := c.GetHeader("Authorization")
 		if len(auth) < 8 || auth[:7] != "Bearer " {
@@ -26,6 +36,10 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 		tokenStr := auth[7:]
+		if strings.HasPrefix(tokenStr, APITokenPrefix) {
+			authenticateAPIToken(c, apiTokens, tokenStr)
+			return
+		}
 		token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 			return []byte(cfg.JWTSecret), nil
 		}, jwt.WithLeeway(5*time.Second))
@@ -38,8 +52,87 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
 			return
 		}
+		if claims.SessionID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+		// revoked sessions and deactivated users are rejected here; the
+		// system role is taken from the database, not the token
+		sysRole, ok, err := sessions.Validate(c.Request.Context(), claims.SessionID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check session"})
+			return
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session revoked or account deactivated"})
+			return
+		}
 		c.Set(CtxUserID, claims.UserID)
-		c.Set(CtxSysRole, claims.SystemRole)
+		c.Set(CtxSysRole, sysRole)
+		c.Set(CtxSessionID, claims.SessionID)
+		c.Next()
+	}
+}
+
+// authenticateAPIToken handles requests with an API token. Tokens without
+// the "write" scope may only read.
+func authenticateAPIToken(c *gin.Context, apiTokens *postgres.APITokenRepository, token string) {
+	id, err := apiTokens.Authenticate(c.Request.Context(), postgres.HashToken(token), c.ClientIP())
+	if errors.Is(err, postgres.ErrInvalidAPIToken) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check token"})
+		return
+	}
+	readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+	if !readOnly && !slices.Contains(id.Scopes, "write") {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "token has no write scope"})
+		return
+	}
+	c.Set(CtxUserID, id.UserID)
+	c.Set(CtxSysRole, id.SystemRole)
+	c.Set(CtxTokenID, id.TokenID)
+	if id.ServiceTeamID != 0 {
+		c.Set(CtxServiceTeamID, id.ServiceTeamID)
+	}
+	c.Next()
+}
+
+// RequireSession rejects API tokens: the route needs an interactive login.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(CtxSessionID) == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not allowed with an API token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireHuman rejects service accounts, which are confined to their team.
+func RequireHuman() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetInt(CtxServiceTeamID) != 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not allowed for service accounts"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSystemRole allows only users with one of the given system roles
+func RequireSystemRole(roles ...string) gin.HandlerFunc {
+	allowed := map[string]struct{}{}
+	for _, r := range roles {
+		allowed[r] = struct{}{}
+	}
+	return func(c *gin.Context) {
+		if _, ok := allowed[c.GetString(CtxSysRole)]; !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-data-catalog/internal/repository/postgres"
+)
+
+const (
+	CtxOrgID = "orgID"
+	// admin or member; system admins have only the role of their own membership
+	CtxOrgRole = "orgRole"
+)
+
+// OrgMembershipMiddleware admits members of the organization in path param
+// :orgId. Organizations of others look as if they did not exist.
+func OrgMembershipMiddleware(orgs *postgres.OrgRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID, err := strconv.Atoi(c.Param("orgId"))
+		if err != nil || orgID <= 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+			return
+		}
+		role, err := orgs.Role(c.Request.Context(), orgID, c.GetInt(CtxUserID))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check membership"})
+			return
+		}
+		if role == "" {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return
+		}
+		c.Set(CtxOrgID, orgID)
+		c.Set(CtxOrgRole, role)
+		c.Next()
+	}
+}
+
+// RequireOrgAdmin allows only admins of the organization of the request.
+func RequireOrgAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(CtxOrgRole) != "admin" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "organization admins only"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,23 +1,35 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go-data-catalog/internal/access"
+	"go-data-catalog/internal/models"
 	"go-data-catalog/internal/repository/postgres"
 )
 
 type ctxKey string
 
 const (
-	CtxUserID   = "userID"
-	CtxTeamID   = "teamID"
-	CtxTeamRole = "teamRole"
-	CtxSysRole  = "systemRole"
+	CtxUserID    = "userID"
+	CtxTeamID    = "teamID"
+	CtxTeamRole  = "teamRole"
+	CtxSysRole   = "systemRole"
+	CtxSessionID = "sessionID"
+	// set for API token requests only
+	CtxTokenID       = "apiTokenID"
+	CtxServiceTeamID = "serviceTeamID"
+	// true while the team of the request is archived
+	CtxTeamArchived = "teamArchived"
 )
 
-// TeamMembershipMiddleware ensures the authenticated user is a member of the team in path param :teamId
-func TeamMembershipMiddleware(membersRepo *postgres.TeamMemberRepository) gin.HandlerFunc {
+// TeamMembershipMiddleware ensures the authenticated user is a member of the team in path param :teamId.
+// Non-members get the guest role on teams with a public catalog; hidden teams
+// look as if they did not exist.
+func TeamMembershipMiddleware(membersRepo *postgres.TeamMemberRepository, teamsRepo *postgres.TeamRepository, orgsRepo *postgres.OrgRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userIDVal, exists := c.Get(CtxUserID)
 		if !exists {
@@ -32,9 +44,38 @@ func TeamMembershipMiddleware(membersRepo *postgres.TeamMemberRepository) gin.Ha
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid team id"})
 			return
 		}
+		t, err := teamsRepo.GetByID(c.Request.Context(), teamID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "team not found"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check membership"})
+			return
+		}
+		c.Set(CtxTeamArchived, t.ArchivedAt != nil)
+		c.Set(CtxOrgID, t.OrgID)
 		isMember, err := membersRepo.IsMember(c.Request.Context(), teamID, userID)
-		if err != nil || !isMember {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not a team member"})
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check membership"})
+			return
+		}
+		if !isMember {
+			inOrg, err := orgsRepo.IsMember(c.Request.Context(), t.OrgID, userID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check membership"})
+				return
+			}
+			nonMember(c, t, inOrg)
+			return
+		}
+		missing2FA, err := membersRepo.MissingRequired2FA(c.Request.Context(), teamID, userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check membership"})
+			return
+		}
+		if missing2FA {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "this team requires two-factor authentication; enable it at /api/v1/me/2fa"})
 			return
 		}
 		role, _ := membersRepo.GetUserRole(c.Request.Context(), teamID, userID)
@@ -44,19 +85,35 @@ func TeamMembershipMiddleware(membersRepo *postgres.TeamMemberRepository) gin.Ha
 	}
 }
 
-// RequireTeamRole allows only specified roles
-func RequireTeamRole(roles ...string) gin.HandlerFunc {
-	allowed := map[string]struct{}{}
-	for _, r := range roles { allowed[r] = struct{}{} }
+// nonMember lets a human non-member from the team's organization browse a
+// public catalog as a guest and refuses everything else; hidden teams and
+// teams of other organizations look as if they did not exist.
+func nonMember(c *gin.Context, t *models.Team, inOrg bool) {
+	if t.Visibility == "hidden" || !inOrg {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+	if _, service := c.Get(CtxServiceTeamID); service || !t.PublicCatalog {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not a team member"})
+		return
+	}
+	c.Set(CtxTeamID, t.ID)
+	c.Set(CtxTeamRole, access.RoleGuest)
+	c.Next()
+}
+
+// TeamPermissions checks the team role against the permission of the matched
+// route in access.Routes. Routes without an entry are denied, and so are
+// changes to an archived team.
+func TeamPermissions() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		val, ok := c.Get(CtxTeamRole)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		p, ok := access.Route(c.Request.Method, c.FullPath())
+		if !ok || !access.Allowed(c.GetString(CtxTeamRole), p) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "your team role does not allow this"})
 			return
 		}
-		role := val.(string)
-		if _, ok := allowed[role]; !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		if c.GetBool(CtxTeamArchived) && !access.AllowedArchived(p) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "the team is archived; unarchive it to make changes"})
 			return
 		}
 		c.Next()
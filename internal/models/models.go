@@ -3,24 +3,126 @@ package models
 import "time"
 
 type Contact struct {
-    ID              int       `json:"id"`
-    Name            string    `json:"name" binding:"required,min=2,max=255"`
-    TelegramContact string    `json:"telegram_contact" binding:"omitempty,min=3,max=100"`
-    TeamID          int       `json:"team_id"`
-    CreatedAt       time.Time `json:"created_at"`
+    ID              int              `json:"id"`
+    Name            string           `json:"name" binding:"required,min=2,max=255"`
+    TelegramContact string           `json:"telegram_contact" binding:"omitempty,min=3,max=100"` // the first Telegram channel
+    UserID          *int             `json:"user_id" binding:"omitempty,min=1"` // linked account, a member of the team
+    UserEmail       string           `json:"user_email,omitempty"`
+    Department      string           `json:"department" binding:"omitempty,max=255"`
+    Availability    string           `json:"availability" binding:"omitempty,max=500"` // on-call or availability note
+    AvatarURL       string           `json:"avatar_url" binding:"omitempty,http_url,max=500"`
+    // Channels are the ways to reach the contact; without them in a request
+    // telegram_contact is used as before.
+    Channels        []ContactChannel `json:"channels" binding:"max=20,dive"`
+    TeamID          int              `json:"team_id"`
+    CreatedAt       time.Time        `json:"created_at"`
 }
 
-type Artifact struct {
-    ID          int       `json:"id"`
-    Name        string    `json:"name" binding:"required,min=2,max=255"`
-    Type        string    `json:"type" binding:"required,oneof=table view procedure function index dataset api file"`
-    Description string    `json:"description" binding:"omitempty,max=1000"`
-    ProjectName string    `json:"project_name" binding:"required,min=2,max=255"`
-    DeveloperID int       `json:"developer_id" binding:"omitempty,min=1"`
-    TeamID      int       `json:"team_id"`
+// Contact channel kinds.
+const (
+    ChannelEmail      = "email"
+    ChannelPhone      = "phone"
+    ChannelTelegram   = "telegram"
+    ChannelSlack      = "slack"
+    ChannelMattermost = "mattermost"
+)
+
+// ContactChannel is one way to reach a contact.
+type ContactChannel struct {
+    Kind  string `json:"kind" binding:"required,oneof=email phone telegram slack mattermost"`
+    Value string `json:"value" binding:"required,max=255"`
+}
+
+// Roles of artifact owners.
+const (
+    OwnerTechnical = "technical_owner"
+    OwnerBusiness  = "business_owner"
+    OwnerSteward   = "data_steward"
+)
+
+// ArtifactOwner is a contact responsible for an artifact in one role.
+type ArtifactOwner struct {
+    ArtifactID  int       `json:"artifact_id"`
+    ContactID   int       `json:"contact_id" binding:"required,min=1"`
+    ContactName string    `json:"contact_name"`
+    UserID      *int      `json:"user_id,omitempty"` // the contact's linked account
+    Role        string    `json:"role" binding:"required,oneof=technical_owner business_owner data_steward"`
     CreatedAt   time.Time `json:"created_at"`
 }
 
+// MyArtifact is an artifact the current user owns through a contact linked
+// to their account.
+type MyArtifact struct {
+    Artifact
+    TeamName string   `json:"team_name"`
+    Roles    []string `json:"roles"`
+}
+
+// Artifact lifecycle statuses.
+const (
+    StatusDraft      = "draft"
+    StatusActive     = "active"
+    StatusDeprecated = "deprecated"
+    StatusRetired    = "retired"
+)
+
+// Artifact: Status may be set to draft or active on creation; it is changed
+// afterwards only through the status endpoint, like the deprecation fields,
+// which updates ignore.
+type Artifact struct {
+    ID                int        `json:"id"`
+    Name              string     `json:"name" binding:"required,min=2,max=255"`
+    Type              string     `json:"type" binding:"required,max=50"` // from the organization's artifact type registry
+    Description       string     `json:"description" binding:"omitempty,max=1000"`
+    ProjectName       string     `json:"project_name" binding:"required,min=2,max=255"`
+    DeveloperID       int        `json:"developer_id" binding:"omitempty,min=1"` // the first technical owner
+    TeamID            int        `json:"team_id"`
+    Status            string     `json:"status" binding:"omitempty,oneof=draft active deprecated retired"`
+    DeprecatedAt      *time.Time `json:"deprecated_at"`
+    ReplacementID     *int       `json:"replacement_id"`
+    DeprecationReason string     `json:"deprecation_reason"`
+    Certification     string     `json:"certification"` // certification state, empty if not certified; read-only
+    CreatedAt         time.Time  `json:"created_at"`
+}
+
+// ArtifactStatus changes the lifecycle status of an artifact. The
+// deprecation fields apply to deprecated and retired artifacts; omitted
+// ones keep their current value, and deprecated_at defaults to now.
+type ArtifactStatus struct {
+    Status        string     `json:"status" binding:"required,oneof=draft active deprecated retired"`
+    DeprecatedAt  *time.Time `json:"deprecated_at"`
+    ReplacementID *int       `json:"replacement_id" binding:"omitempty,min=1"`
+    Reason        string     `json:"reason" binding:"max=1000"`
+}
+
+// Certification states.
+const (
+    CertCertified   = "certified"
+    CertNeedsReview = "needs_review" // the fields or description changed since
+    CertExpired     = "expired"
+)
+
+// Certification marks an artifact as a trusted source until ExpiresAt.
+type Certification struct {
+    ArtifactID       int        `json:"artifact_id"`
+    ArtifactName     string     `json:"artifact_name"`
+    State            string     `json:"state"`
+    CertifiedBy      *int       `json:"certified_by"`
+    CertifiedByEmail string     `json:"certified_by_email,omitempty"`
+    CertifiedAt      time.Time  `json:"certified_at"`
+    Note             string     `json:"note"`
+    ExpiresAt        time.Time  `json:"expires_at"`
+    ReviewRequiredAt *time.Time `json:"review_required_at"`
+    ReviewReason     string     `json:"review_reason,omitempty"`
+}
+
+// DeprecatedInUse is a deprecated or retired artifact of the team that
+// artifacts still in draft or active status are derived from.
+type DeprecatedInUse struct {
+    Artifact
+    Consumers []LineageEdge `json:"consumers"`
+}
+
 type ArtifactField struct {
     ID          int       `json:"id"`
     ArtifactID  int       `json:"artifact_id"`
@@ -31,6 +133,74 @@ type ArtifactField struct {
     CreatedAt   time.Time `json:"created_at"`
 }
 
+// ArtifactShare publishes an artifact read-only to another team, or to all
+// teams when TargetTeamID is nil.
+type ArtifactShare struct {
+    ID             int        `json:"id"`
+    ArtifactID     int        `json:"artifact_id"`
+    ArtifactName   string     `json:"artifact_name"`
+    TeamID         int        `json:"team_id"`
+    TargetTeamID   *int       `json:"target_team_id"`
+    TargetTeamName string     `json:"target_team_name,omitempty"`
+    CreatedBy      *int       `json:"created_by"`
+    CreatedAt      time.Time  `json:"created_at"`
+    RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+// SharedArtifact is an artifact of another team shared with the caller's team.
+type SharedArtifact struct {
+    Artifact
+    TeamName string          `json:"team_name"`
+    Fields   []ArtifactField `json:"fields,omitempty"`
+}
+
+// Upstream states of a lineage edge, as seen by the downstream team.
+const (
+    UpstreamAvailable = "available" // the team's own artifact or shared with it
+    UpstreamUnshared  = "unshared"  // the owner no longer shares it
+    UpstreamDeleted   = "deleted"
+)
+
+// LineageEdge says that the downstream artifact is derived from the upstream
+// one. UpstreamName is the name when the edge was drawn if the upstream is no
+// longer available.
+type LineageEdge struct {
+    ID                 int       `json:"id"`
+    UpstreamID         *int      `json:"upstream_id"`
+    UpstreamName       string    `json:"upstream_name"`
+    UpstreamTeamID     *int      `json:"upstream_team_id"`
+    UpstreamTeamName   string    `json:"upstream_team_name"`
+    UpstreamStatus     string    `json:"upstream_status"`
+    UpstreamLifecycle  string    `json:"upstream_lifecycle,omitempty"` // status of an available upstream
+    DownstreamID       int       `json:"downstream_id"`
+    DownstreamName     string    `json:"downstream_name"`
+    DownstreamTeamID   int       `json:"downstream_team_id"`
+    DownstreamTeamName string    `json:"downstream_team_name"`
+    CreatedBy          *int      `json:"created_by"`
+    CreatedAt          time.Time `json:"created_at"`
+}
+
+// Lineage lists the edges into and out of one artifact.
+type Lineage struct {
+    Upstream   []LineageEdge `json:"upstream"`
+    Downstream []LineageEdge `json:"downstream"`
+}
+
+// SearchHit is an artifact, or a field of it, matching a catalog search.
+// Shared is set for artifacts of other teams.
+type SearchHit struct {
+    ArtifactID  int    `json:"artifact_id"`
+    Artifact    string `json:"artifact"`
+    ProjectName string `json:"project_name"`
+    TeamID      int    `json:"team_id"`
+    TeamName    string `json:"team_name"`
+    Shared      bool   `json:"shared"`
+    Status      string `json:"status"`
+    Certified   bool   `json:"certified"`
+    Match       string `json:"match"` // name, description or field
+    Field       string `json:"field,omitempty"`
+}
+
 // New auth/teams models
 
 type User struct {
@@ -40,15 +210,113 @@ type User struct {
     Name         string    `json:"name"`
     SystemRole   string    `json:"system_role"`
     IsActive     bool      `json:"is_active"`
+    EmailVerified bool     `json:"email_verified"`
+    ServiceTeamID *int     `json:"service_team_id,omitempty"` // set for team service accounts
     CreatedAt    time.Time `json:"created_at"`
 }
 
 type Team struct {
-    ID          int       `json:"id"`
-    Name        string    `json:"name" binding:"required,min=2,max=255"`
-    Description string    `json:"description"`
-    CreatedBy   int       `json:"created_by"`
-    CreatedAt   time.Time `json:"created_at"`
+    ID            int        `json:"id"`
+    OrgID         int        `json:"org_id"`
+    Name          string     `json:"name" binding:"required,min=2,max=255"`
+    Description   string     `json:"description"`
+    Require2FA    bool       `json:"require_2fa"`           // members must have two-factor authentication enabled
+    Visibility    string     `json:"visibility"`            // public, discoverable (found by exact name) or hidden
+    JoinPolicy    string     `json:"join_policy"`           // open, approval or invite_only
+    PublicCatalog bool       `json:"public_catalog"`        // non-members may read the artifacts
+    ArchivedAt    *time.Time `json:"archived_at,omitempty"` // archived teams are read-only
+    CreatedBy     int        `json:"created_by"`
+    CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Organization groups teams and users. Role is the caller's role in it when
+// listing the caller's organizations.
+type Organization struct {
+    ID                 int       `json:"id"`
+    Name               string    `json:"name"`
+    Description        string    `json:"description"`
+    AllowedAuthMethods []string  `json:"allowed_auth_methods"` // password, ldap, oidc
+    AutoJoin           bool      `json:"auto_join"`            // new accounts join automatically
+    Role               string    `json:"role,omitempty"`
+    CreatedAt          time.Time `json:"created_at"`
+}
+
+// Sign-in methods an organization may allow.
+const (
+    AuthPassword = "password"
+    AuthLDAP     = "ldap"
+    AuthOIDC     = "oidc"
+)
+
+// OrgMember is a user of an organization with their org role (admin or member).
+type OrgMember struct {
+    OrgID    int       `json:"org_id"`
+    UserID   int       `json:"user_id"`
+    Email    string    `json:"email"`
+    Name     string    `json:"name"`
+    Role     string    `json:"role"`
+    IsActive bool      `json:"is_active"`
+    JoinedAt time.Time `json:"joined_at"`
+}
+
+// ArtifactType is an entry of an organization's artifact type registry.
+type ArtifactType struct {
+    Name        string `json:"name"`
+    Description string `json:"description"`
+    InUse       int    `json:"in_use"` // artifacts of this type in the organization
+}
+
+// TeamUsage is one row of the organization usage dashboard.
+type TeamUsage struct {
+    TeamID         int        `json:"team_id"`
+    TeamName       string     `json:"team_name"`
+    Archived       bool       `json:"archived"`
+    Members        int        `json:"members"`
+    Artifacts      int        `json:"artifacts"`
+    Fields         int        `json:"fields"`
+    Contacts       int        `json:"contacts"`
+    Shares         int        `json:"shares"` // active shares of the team's artifacts
+    LastArtifactAt *time.Time `json:"last_artifact_at"`
+}
+
+// OrgUsage sums up an organization; ActiveUsers have used a session in the
+// last 30 days.
+type OrgUsage struct {
+    Teams       int         `json:"teams"`
+    Users       int         `json:"users"`
+    ActiveUsers int         `json:"active_users"`
+    Artifacts   int         `json:"artifacts"`
+    Fields      int         `json:"fields"`
+    PerTeam     []TeamUsage `json:"per_team"`
+}
+
+// TeamSnapshot is the export taken when a team is deleted. Data is filled in
+// only when a single snapshot is requested.
+type TeamSnapshot struct {
+    ID             int         `json:"id"`
+    TeamID         int         `json:"team_id"` // id of the deleted team
+    TeamName       string      `json:"team_name"`
+    OrgID          int         `json:"org_id"`
+    DeletedBy      *int        `json:"deleted_by"`
+    CreatedAt      time.Time   `json:"created_at"`
+    RestoredAt     *time.Time  `json:"restored_at,omitempty"`
+    RestoredTeamID *int        `json:"restored_team_id,omitempty"`
+    Data           *TeamExport `json:"data,omitempty"`
+}
+
+// TeamExport is the full content of a team. Service accounts and their
+// tokens are not part of it. Shares include those of other teams' artifacts
+// with this team, and lineage the edges of other teams from its artifacts.
+type TeamExport struct {
+    Team           Team            `json:"team"`
+    Members        []TeamMember    `json:"members"`
+    Contacts       []Contact       `json:"contacts"`
+    Artifacts      []Artifact      `json:"artifacts"`
+    Fields         []ArtifactField `json:"fields"`
+    Owners         []ArtifactOwner `json:"owners"`
+    Shares         []ArtifactShare `json:"shares"`
+    Lineage        []LineageEdge   `json:"lineage"`
+    Certifications []Certification `json:"certifications"`
 }
 
 type TeamMember struct {
@@ -59,12 +327,94 @@ type TeamMember struct {
     JoinedAt time.Time `json:"joined_at"`
 }
 
+// TeamMemberDetail is a membership with the member's user details.
+type TeamMemberDetail struct {
+    TeamMember
+    Email          string `json:"email"`
+    Name           string `json:"name"`
+    ServiceAccount bool   `json:"service_account"`
+}
+
+// JoinRequest status is pending, approved, rejected or cancelled. TeamName,
+// UserEmail and UserName are filled in by list queries.
 type JoinRequest struct {
-    ID          int        `json:"id"`
-    TeamID      int        `json:"team_id"`
-    UserID      int        `json:"user_id"`
-    Status      string     `json:"status"`
-    CreatedAt   time.Time  `json:"created_at"`
-    ProcessedBy *int       `json:"processed_by"`
-    ProcessedAt *time.Time `json:"processed_at"`
+    ID              int        `json:"id"`
+    TeamID          int        `json:"team_id"`
+    UserID          int        `json:"user_id"`
+    Status          string     `json:"status"`
+    Message         string     `json:"message"`
+    DecisionComment string     `json:"decision_comment"`
+    CreatedAt       time.Time  `json:"created_at"`
+    ProcessedBy     *int       `json:"processed_by"`
+    ProcessedAt     *time.Time `json:"processed_at"`
+    TeamName        string     `json:"team_name,omitempty"`
+    UserEmail       string     `json:"user_email,omitempty"`
+    UserName        string     `json:"user_name,omitempty"`
+}
+
+// Notification is an in-app message to a user.
+type Notification struct {
+    ID        int        `json:"id"`
+    Kind      string     `json:"kind"` // join_request, join_request_decided
+    TeamID    *int       `json:"team_id"`
+    SubjectID *int       `json:"subject_id"`
+    Message   string     `json:"message"`
+    ReadAt    *time.Time `json:"read_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+// TeamInvitation adds whoever accepts it to the team with Role. The link
+// token itself is returned only once, on creation. Status is computed:
+// active, used, expired or revoked.
+type TeamInvitation struct {
+    ID        int        `json:"id"`
+    TeamID    int        `json:"team_id"`
+    Role      string     `json:"role"`
+    Email     *string    `json:"email"`    // only this user may accept
+    MaxUses   *int       `json:"max_uses"` // nil: unlimited until expiry
+    UseCount  int        `json:"use_count"`
+    Status    string     `json:"status"`
+    ExpiresAt time.Time  `json:"expires_at"`
+    CreatedBy *int       `json:"created_by"`
+    CreatedAt time.Time  `json:"created_at"`
+    RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// APIToken is a personal access token or a service account token. The secret
+// itself is returned only once, on creation.
+type APIToken struct {
+    ID         int        `json:"id"`
+    UserID     int        `json:"user_id"`
+    Name       string     `json:"name"`
+    Prefix     string     `json:"prefix"`
+    Scopes     []string   `json:"scopes"`
+    ExpiresAt  time.Time  `json:"expires_at"`
+    CreatedBy  *int       `json:"created_by"`
+    CreatedAt  time.Time  `json:"created_at"`
+    LastUsedAt *time.Time `json:"last_used_at"`
+    LastUsedIP string     `json:"last_used_ip"`
+    RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// ServiceAccount is a team-owned non-human user.
+type ServiceAccount struct {
+    ID        int       `json:"id"`
+    TeamID    int       `json:"team_id"`
+    Name      string    `json:"name"`
+    Role      string    `json:"role"`
+    IsActive  bool      `json:"is_active"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginAttempt is one entry of the login history. UserID is nil when the
+// login matched no account.
+type LoginAttempt struct {
+    ID        int       `json:"id"`
+    UserID    *int      `json:"user_id"`
+    Login     string    `json:"login"`
+    IP        string    `json:"ip"`
+    UserAgent string    `json:"user_agent"`
+    Method    string    `json:"method"` // password, 2fa, oidc
+    Result    string    `json:"result"` // ok, invalid_credentials, invalid_code, throttled, ...
+    CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,390 @@
+// Package openapi builds an OpenAPI 3.1 document from a list of documented
+// operations, deriving JSON schemas from Go types and their gin binding tags.
+package openapi
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+//go:embed docs.html
+var assets embed.FS
+
+// DocsPage returns the bundled interactive documentation page. It loads the
+// spec from specURL.
+func DocsPage(specURL string) []byte {
+	b, _ := assets.ReadFile("docs.html")
+	return []byte(strings.ReplaceAll(string(b), "{{SPEC_URL}}", specURL))
+}
+
+// Param is a query parameter of an operation.
+type Param struct {
+	Name        string
+	Description string
+	Type        string // "string" or "integer"
+	Enum        []string
+	Required    bool
+}
+
+// Operation documents one route. Method and Path use gin syntax
+// (e.g. "/teams/:teamId"); path parameters are derived from Path.
+type Operation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tag         string
+	Public      bool // no bearer token required
+	Query       []Param
+	PathEnums   map[string][]string // enum values for string path params
+	Request     any                 // zero value of the JSON body type, nil if none
+	Status      int                 // success status, defaults to 200
+	Response    any                 // zero value of the JSON response type, nil for no body
+	ContentType string              // non-JSON success content type, e.g. application/zip
+	Errors      []int               // documented error statuses
+}
+
+// Route is a registered method+path pair, as in gin.RouteInfo.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Spec is a set of documented operations.
+type Spec struct {
+	Title   string
+	Version string
+	Ops     []Operation
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+func openapiPath(p string) string {
+	return ginParam.ReplaceAllString(p, "{$1}")
+}
+
+// Missing returns registered routes that have no documented operation.
+func (s *Spec) Missing(routes []Route) []Route {
+	documented := map[string]bool{}
+	for _, op := range s.Ops {
+		documented[op.Method+" "+op.Path] = true
+	}
+	var missing []Route
+	for _, r := range routes {
+		if !documented[r.Method+" "+r.Path] {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// Stale returns documented operations that are not registered.
+func (s *Spec) Stale(routes []Route) []Operation {
+	registered := map[string]bool{}
+	for _, r := range routes {
+		registered[r.Method+" "+r.Path] = true
+	}
+	var stale []Operation
+	for _, op := range s.Ops {
+		if !registered[op.Method+" "+op.Path] {
+			stale = append(stale, op)
+		}
+	}
+	return stale
+}
+
+// Document renders the OpenAPI 3.1 document.
+func (s *Spec) Document() map[string]any {
+	g := &schemaGen{components: map[string]any{}}
+	errSchema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"error": map[string]any{"type": "string"}},
+		"required":   []string{"error"},
+	}
+	g.components["Error"] = errSchema
+
+	paths := map[string]map[string]any{}
+	tags := map[string]bool{}
+	for _, op := range s.Ops {
+		p := openapiPath(op.Path)
+		if paths[p] == nil {
+			paths[p] = map[string]any{}
+		}
+		tags[op.Tag] = true
+		o := map[string]any{
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"tags":        []string{op.Tag},
+		}
+		if op.Description != "" {
+			o["description"] = op.Description
+		}
+		if op.Public {
+			o["security"] = []any{}
+		}
+
+		var params []any
+		for _, m := range ginParam.FindAllStringSubmatch(op.Path, -1) {
+			schema := map[string]any{"type": "integer", "minimum": 1}
+			if enum, ok := op.PathEnums[m[1]]; ok {
+				schema = map[string]any{"type": "string", "enum": enum}
+			}
+			params = append(params, map[string]any{"name": m[1], "in": "path", "required": true, "schema": schema})
+		}
+		for _, q := range op.Query {
+			schema := map[string]any{"type": q.Type}
+			if q.Type == "" {
+				schema["type"] = "string"
+			}
+			if len(q.Enum) > 0 {
+				schema["enum"] = q.Enum
+			}
+			param := map[string]any{"name": q.Name, "in": "query", "required": q.Required, "schema": schema}
+			if q.Description != "" {
+				param["description"] = q.Description
+			}
+			params = append(params, param)
+		}
+		if len(params) > 0 {
+			o["parameters"] = params
+		}
+
+		if op.Request != nil {
+			o["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Request))}},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ok := map[string]any{"description": http.StatusText(status)}
+		switch {
+		case op.ContentType != "":
+			ok["content"] = map[string]any{op.ContentType: map[string]any{"schema": map[string]any{"type": "string", "contentMediaType": op.ContentType}}}
+		case op.Response != nil:
+			ok["content"] = map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Response))}}
+		}
+		responses := map[string]any{strconv.Itoa(status): ok}
+		errs := op.Errors
+		if !op.Public {
+			errs = append([]int{http.StatusUnauthorized}, errs...)
+		}
+		for _, code := range errs {
+			responses[strconv.Itoa(code)] = map[string]any{
+				"description": http.StatusText(code),
+				"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}}},
+			}
+		}
+		o["responses"] = responses
+		paths[p][strings.ToLower(op.Method)] = o
+	}
+
+	tagList := make([]string, 0, len(tags))
+	for t := range tags {
+		tagList = append(tagList, t)
+	}
+	sort.Strings(tagList)
+	tagObjs := make([]any, len(tagList))
+	for i, t := range tagList {
+		tagObjs[i] = map[string]any{"name": t}
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info":    map[string]any{"title": s.Title, "version": s.Version},
+		"servers": []any{map[string]any{"url": "/"}},
+		"tags":    tagObjs,
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": g.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+		"security": []any{map[string]any{"bearerAuth": []string{}}},
+	}
+}
+
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, part := range strings.FieldsFunc(op.Path, func(r rune) bool { return r == '/' || r == '.' || r == '-' }) {
+		if part == "api" || part == "v1" {
+			continue
+		}
+		if part[0] == ':' || part[0] == '*' {
+			b.WriteString("By")
+			part = part[1:]
+		}
+		r := []rune(part)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	return b.String()
+}
+
+type schemaGen struct {
+	components map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema returns an inline schema or a $ref to a component for named structs.
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Pointer:
+		s := g.schema(t.Elem())
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []string{typ, "null"}
+			return s
+		}
+		return map[string]any{"oneOf": []any{s, map[string]any{"type": "null"}}}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		name := componentName(t)
+		if name == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = nil // guard against recursion
+			g.components[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case reflect.Interface:
+		return map[string]any{}
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+func componentName(t reflect.Type) string {
+	n := t.Name()
+	if n == "" {
+		return ""
+	}
+	r := []rune(n)
+	return string(unicode.ToUpper(r[0])) + string(r[1:])
+}
+
+func (g *schemaGen) structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		// embedded structs are flattened, as encoding/json does
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := g.structSchema(f.Type)
+			for k, v := range embedded["properties"].(map[string]any) {
+				props[k] = v
+			}
+			if req, ok := embedded["required"].([]string); ok {
+				required = append(required, req...)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s := g.schema(f.Type)
+		if applyBinding(s, f.Tag.Get("binding")) {
+			required = append(required, name)
+		}
+		props[name] = s
+	}
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// applyBinding maps gin/validator binding rules onto s and reports whether
+// the field is required.
+func applyBinding(s map[string]any, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	// rules after "dive" apply to the elements of a slice
+	if before, after, ok := strings.Cut(tag, ",dive"); ok {
+		if items, ok := s["items"].(map[string]any); ok {
+			applyBinding(items, strings.TrimPrefix(after, ","))
+		}
+		tag = before
+	}
+	required := false
+	isString := s["type"] == "string"
+	isArray := s["type"] == "array"
+	for _, rule := range strings.Split(tag, ",") {
+		key, val, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s["format"] = "email"
+		case "url":
+			s["format"] = "uri"
+		case "oneof":
+			s["enum"] = strings.Fields(val)
+		case "min", "max", "gt", "gte", "lt", "lte":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				continue
+			}
+			if isString || isArray {
+				unit := "Length"
+				if isArray {
+					unit = "Items"
+				}
+				if key == "min" || key == "gte" {
+					s["min"+unit] = n
+				} else if key == "max" || key == "lte" {
+					s["max"+unit] = n
+				}
+				continue
+			}
+			switch key {
+			case "min", "gte":
+				s["minimum"] = n
+			case "max", "lte":
+				s["maximum"] = n
+			case "gt":
+				s["exclusiveMinimum"] = n
+			case "lt":
+				s["exclusiveMaximum"] = n
+			}
+		}
+	}
+	return required
+}
@@ -0,0 +1,94 @@
+// Package password checks new passwords against the configured policy.
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"go-data-catalog/internal/config"
+)
+
+// maxBytes is the bcrypt input limit; longer passwords would be silently
+// truncated.
+const maxBytes = 72
+
+// Policy is a password policy. Its errors are meant to be shown to the user.
+type Policy struct {
+	MinLength int
+	breached  map[string]struct{} // upper-case hex SHA-1
+}
+
+// Load builds the policy from the config, reading the breached-password list
+// if one is configured.
+func Load(cfg *config.Config) (*Policy, error) {
+	p := &Policy{MinLength: cfg.PasswordMinLength}
+	if cfg.PasswordBreachedList != "" {
+		if err := p.loadBreached(cfg.PasswordBreachedList); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// loadBreached reads a list with one entry per line: either a plain password
+// or a SHA-1 hash in hex, optionally followed by ":count" as in the Have I
+// Been Pwned downloads. Empty lines and lines starting with # are skipped.
+func (p *Policy) loadBreached(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("breached password list: %w", err)
+	}
+	defer f.Close()
+	p.breached = map[string]struct{}{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1(hash) {
+			p.breached[strings.ToUpper(hash)] = struct{}{}
+		} else {
+			p.breached[sha1Hex(line)] = struct{}{}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("breached password list: %w", err)
+	}
+	return nil
+}
+
+// Check validates a new password for the account with the given email.
+func (p *Policy) Check(password, email string) error {
+	switch {
+	case utf8.RuneCountInString(password) < p.MinLength:
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	case len(password) > maxBytes:
+		return fmt.Errorf("password must be at most %d bytes long", maxBytes)
+	case email != "" && strings.EqualFold(password, email):
+		return errors.New("password must not be the same as the email")
+	}
+	if _, found := p.breached[sha1Hex(password)]; found {
+		return errors.New("this password appears in a list of breached passwords; choose another one")
+	}
+	return nil
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func isSHA1(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,363 @@
+// Package render turns a catalog artifact and its fields into ready-to-use
+// definitions: DDL, Go structs, JSON Schema and Avro schemas.
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go-data-catalog/internal/models"
+)
+
+const (
+	FormatPostgresDDL   = "postgres-ddl"
+	FormatClickHouseDDL = "clickhouse-ddl"
+	FormatGoStruct      = "go-struct"
+	FormatJSONSchema    = "json-schema"
+	FormatAvro          = "avro"
+)
+
+// Formats lists supported output formats.
+var Formats = []string{FormatPostgresDDL, FormatClickHouseDDL, FormatGoStruct, FormatJSONSchema, FormatAvro}
+
+var ErrUnknownFormat = errors.New("unknown render format")
+
+// Result is a rendered definition. Warnings describe anything that could
+// not be mapped exactly, e.g. data types the catalog does not recognize.
+type Result struct {
+	Format      string   `json:"format"`
+	ContentType string   `json:"content_type"`
+	Content     string   `json:"content"`
+	Warnings    []string `json:"warnings"`
+}
+
+type column struct {
+	field models.ArtifactField
+	typ   columnType
+}
+
+// Render produces the definition of artifact in the requested format.
+func Render(format string, artifact *models.Artifact, fields []models.ArtifactField) (*Result, error) {
+	res := &Result{Format: format, Warnings: []string{}}
+	switch artifact.Type {
+	case "table", "view", "dataset":
+	default:
+		res.Warnings = append(res.Warnings, fmt.Sprintf("artifact type %q is not tabular; rendering its fields as columns", artifact.Type))
+	}
+	if len(fields) == 0 {
+		res.Warnings = append(res.Warnings, "artifact has no fields")
+	}
+	cols := make([]column, 0, len(fields))
+	for _, f := range fields {
+		ct := parseType(f.DataType)
+		if ct.Kind == kindUnknown {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("field %q: unknown data type %q, rendered as string", f.FieldName, f.DataType))
+		}
+		if msg := ct.lossy(format); msg != "" {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("field %q: %s", f.FieldName, msg))
+		}
+		cols = append(cols, column{field: f, typ: ct})
+	}
+
+	var err error
+	switch format {
+	case FormatPostgresDDL:
+		res.ContentType = "application/sql"
+		res.Content = renderPostgres(artifact, cols)
+	case FormatClickHouseDDL:
+		res.ContentType = "application/sql"
+		res.Content = renderClickHouse(artifact, cols)
+	case FormatGoStruct:
+		res.ContentType = "text/x-go"
+		res.Content, err = renderGoStruct(artifact, cols)
+	case FormatJSONSchema:
+		res.ContentType = "application/schema+json"
+		res.Content, err = renderJSONSchema(artifact, cols)
+	case FormatAvro:
+		res.ContentType = "application/json"
+		res.Content, err = renderAvro(artifact, cols, &res.Warnings)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+var plainIdent = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+func pgIdent(s string) string {
+	if plainIdent.MatchString(s) {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func chIdent(s string) string {
+	if plainIdent.MatchString(strings.ToLower(s)) {
+		return s
+	}
+	return "`" + strings.ReplaceAll(s, "`", "\\`") + "`"
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func primaryKey(cols []column) []column {
+	var pk []column
+	for _, c := range cols {
+		if c.field.IsPK {
+			pk = append(pk, c)
+		}
+	}
+	return pk
+}
+
+func renderPostgres(a *models.Artifact, cols []column) string {
+	var b strings.Builder
+	table := pgIdent(a.Name)
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n", table)
+	lines := make([]string, 0, len(cols)+1)
+	for _, c := range cols {
+		l := fmt.Sprintf("    %s %s", pgIdent(c.field.FieldName), c.typ.postgres())
+		if c.field.IsPK {
+			l += " NOT NULL"
+		}
+		lines = append(lines, l)
+	}
+	if pk := primaryKey(cols); len(pk) > 0 {
+		names := make([]string, len(pk))
+		for i, c := range pk {
+			names[i] = pgIdent(c.field.FieldName)
+		}
+		lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%s)", strings.Join(names, ", ")))
+	}
+	b.WriteString(strings.Join(lines, ",\n"))
+	b.WriteString("\n);\n")
+	if a.Description != "" {
+		fmt.Fprintf(&b, "\nCOMMENT ON TABLE %s IS %s;\n", table, sqlString(a.Description))
+	}
+	for _, c := range cols {
+		if c.field.Description != "" {
+			fmt.Fprintf(&b, "COMMENT ON COLUMN %s.%s IS %s;\n", table, pgIdent(c.field.FieldName), sqlString(c.field.Description))
+		}
+	}
+	return b.String()
+}
+
+func renderClickHouse(a *models.Artifact, cols []column) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s\n(\n", chIdent(a.Name))
+	lines := make([]string, 0, len(cols))
+	for _, c := range cols {
+		t := c.typ.clickhouse()
+		if !c.field.IsPK && !c.typ.Array {
+			t = "Nullable(" + t + ")"
+		}
+		l := fmt.Sprintf("    %s %s", chIdent(c.field.FieldName), t)
+		if c.field.Description != "" {
+			l += " COMMENT " + sqlString(c.field.Description)
+		}
+		lines = append(lines, l)
+	}
+	b.WriteString(strings.Join(lines, ",\n"))
+	b.WriteString("\n)\nENGINE = MergeTree\n")
+	if pk := primaryKey(cols); len(pk) > 0 {
+		names := make([]string, len(pk))
+		for i, c := range pk {
+			names[i] = chIdent(c.field.FieldName)
+		}
+		fmt.Fprintf(&b, "ORDER BY (%s)", strings.Join(names, ", "))
+	} else {
+		b.WriteString("ORDER BY tuple()")
+	}
+	if a.Description != "" {
+		b.WriteString("\nCOMMENT " + sqlString(a.Description))
+	}
+	b.WriteString(";\n")
+	return b.String()
+}
+
+var initialisms = map[string]string{"id": "ID", "url": "URL", "uuid": "UUID", "api": "API", "json": "JSON", "http": "HTTP", "ip": "IP", "sql": "SQL"}
+
+// goName converts snake_case / kebab-case / spaced names into an exported Go identifier.
+func goName(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	for _, p := range parts {
+		if v, ok := initialisms[strings.ToLower(p)]; ok {
+			b.WriteString(v)
+			continue
+		}
+		r := []rune(p)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	name := b.String()
+	if name == "" {
+		return "X"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// goTag returns the struct tag literal of a field, quoting the names so that
+// any field name yields valid Go.
+func goTag(name string) string {
+	tag := "json:" + strconv.Quote(name) + " db:" + strconv.Quote(name)
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+func renderGoStruct(a *models.Artifact, cols []column) (string, error) {
+	imports := map[string]struct{}{}
+	var body strings.Builder
+	for _, c := range cols {
+		typ, imp := c.typ.golang()
+		if imp != "" {
+			imports[imp] = struct{}{}
+		}
+		if !c.field.IsPK && !c.typ.Array && c.typ.Kind != kindJSON && c.typ.Kind != kindBytes {
+			typ = "*" + typ
+		}
+		if c.field.Description != "" {
+			fmt.Fprintf(&body, "\t// %s\n", strings.ReplaceAll(c.field.Description, "\n", " "))
+		}
+		fmt.Fprintf(&body, "\t%s %s %s\n", goName(c.field.FieldName), typ, goTag(c.field.FieldName))
+	}
+
+	var b strings.Builder
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for p := range imports {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		b.WriteString("import (\n")
+		for _, p := range paths {
+			fmt.Fprintf(&b, "\t%q\n", p)
+		}
+		b.WriteString(")\n\n")
+	}
+	name := goName(a.Name)
+	if a.Description != "" {
+		fmt.Fprintf(&b, "// %s %s\n", name, strings.ReplaceAll(a.Description, "\n", " "))
+	}
+	fmt.Fprintf(&b, "type %s struct {\n%s}\n", name, body.String())
+	// gofmt aligns field types and tags
+	out, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("rendered Go struct does not parse: %w", err)
+	}
+	return string(out), nil
+}
+
+func renderJSONSchema(a *models.Artifact, cols []column) (string, error) {
+	props := map[string]any{}
+	required := []string{}
+	for _, c := range cols {
+		s := c.typ.jsonSchema()
+		if c.field.Description != "" {
+			s["description"] = c.field.Description
+		}
+		if c.field.IsPK {
+			required = append(required, c.field.FieldName)
+		} else if t, ok := s["type"].(string); ok {
+			s["type"] = []string{t, "null"}
+		}
+		props[c.field.FieldName] = s
+	}
+	doc := map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                a.Name,
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+	if a.Description != "" {
+		doc["description"] = a.Description
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	return string(out), err
+}
+
+var avroName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func avroIdent(s string, warnings *[]string) string {
+	if avroName.MatchString(s) {
+		return s
+	}
+	clean := strings.Map(func(r rune) rune {
+		if r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			return r
+		}
+		return '_'
+	}, s)
+	if clean == "" || unicode.IsDigit(rune(clean[0])) {
+		clean = "_" + clean
+	}
+	*warnings = append(*warnings, fmt.Sprintf("name %q is not a valid Avro name, rendered as %q", s, clean))
+	return clean
+}
+
+func renderAvro(a *models.Artifact, cols []column, warnings *[]string) (string, error) {
+	fields := make([]map[string]any, 0, len(cols))
+	used := map[string]bool{}
+	for _, c := range cols {
+		name := avroIdent(c.field.FieldName, warnings)
+		// field names must be unique; sanitizing can make two of them equal
+		if used[name] {
+			unique := name
+			for i := 2; used[unique]; i++ {
+				unique = fmt.Sprintf("%s_%d", name, i)
+			}
+			*warnings = append(*warnings, fmt.Sprintf("field %q: Avro name %q is already taken, rendered as %q", c.field.FieldName, name, unique))
+			name = unique
+		}
+		used[name] = true
+		f := map[string]any{"name": name}
+		if c.field.IsPK {
+			f["type"] = c.typ.avro()
+		} else {
+			f["type"] = []any{"null", c.typ.avro()}
+			f["default"] = nil
+		}
+		if c.field.Description != "" {
+			f["doc"] = c.field.Description
+		}
+		fields = append(fields, f)
+	}
+	doc := map[string]any{
+		"type":   "record",
+		"name":   avroIdent(a.Name, warnings),
+		"fields": fields,
+	}
+	if a.ProjectName != "" {
+		if ns := strings.Trim(strings.Map(func(r rune) rune {
+			if r == '.' || r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+				return unicode.ToLower(r)
+			}
+			return '_'
+		}, a.ProjectName), "._"); ns != "" && !unicode.IsDigit(rune(ns[0])) {
+			doc["namespace"] = ns
+		}
+	}
+	if a.Description != "" {
+		doc["doc"] = a.Description
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	return string(out), err
+}
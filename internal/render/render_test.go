@@ -0,0 +1,88 @@
+package render
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-data-catalog/internal/models"
+)
+
+func TestLossyDecimalsWarn(t *testing.T) {
+	tests := []struct {
+		format, dataType string
+		content          string // expected in the output
+		warn             string // expected in a warning, "" for none
+	}{
+		{FormatPostgresDDL, "numeric", "amount NUMERIC", ""},
+		{FormatClickHouseDDL, "numeric", "Decimal(38, 10)", "without precision"},
+		{FormatAvro, "decimal", `"precision": 38`, "without precision"},
+		{FormatPostgresDDL, "numeric(12,2)", "NUMERIC(12,2)", ""},
+		{FormatClickHouseDDL, "numeric(12)", "Decimal(12, 0)", ""},
+		{FormatPostgresDDL, "money", "NUMERIC(19,2)", "money"},
+		{FormatGoStruct, "money", "*string", "money"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.dataType, func(t *testing.T) {
+			fields := []models.ArtifactField{{FieldName: "amount", DataType: tt.dataType}}
+			res, err := Render(tt.format, &models.Artifact{Name: "payments", Type: "table"}, fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(res.Content, tt.content) {
+				t.Errorf("content lacks %q:\n%s", tt.content, res.Content)
+			}
+			warned := strings.Join(res.Warnings, "\n")
+			if tt.warn == "" && warned != "" {
+				t.Errorf("unexpected warnings: %s", warned)
+			}
+			if tt.warn != "" && !strings.Contains(warned, tt.warn) {
+				t.Errorf("warnings %q lack %q", res.Warnings, tt.warn)
+			}
+		})
+	}
+}
+
+func TestGoStructQuotesTags(t *testing.T) {
+	fields := []models.ArtifactField{
+		{FieldName: `say "hi"`, DataType: "text"},
+		{FieldName: "back`tick", DataType: "int"},
+	}
+	res, err := Render(FormatGoStruct, &models.Artifact{Name: "odd", Type: "table"}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"`json:\"say \\\"hi\\\"\" db:\"say \\\"hi\\\"\"`", `"json:\"back` + "`" + `tick\" db:\"back` + "`" + `tick\""`} {
+		if !strings.Contains(res.Content, want) {
+			t.Errorf("content lacks %s:\n%s", want, res.Content)
+		}
+	}
+}
+
+func TestAvroNameCollisions(t *testing.T) {
+	fields := []models.ArtifactField{
+		{FieldName: "user-id", DataType: "int"},
+		{FieldName: "user id", DataType: "int"},
+		{FieldName: "user_id", DataType: "int"},
+	}
+	res, err := Render(FormatAvro, &models.Artifact{Name: "events", Type: "table"}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Fields []struct{ Name string }
+	}
+	if err := json.Unmarshal([]byte(res.Content), &doc); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range doc.Fields {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "user_id,user_id_2,user_id_3" {
+		t.Errorf("names = %s", got)
+	}
+	if !strings.Contains(strings.Join(res.Warnings, "\n"), "already taken") {
+		t.Errorf("no collision warning in %q", res.Warnings)
+	}
+}
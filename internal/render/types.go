@@ -0,0 +1,315 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// kind is the canonical type a catalog data_type is normalized to before
+// being rendered for a concrete target.
+type kind int
+
+const (
+	kindUnknown kind = iota
+	kindInt16
+	kindInt32
+	kindInt64
+	kindFloat32
+	kindFloat64
+	kindDecimal
+	kindString
+	kindBool
+	kindDate
+	kindTimestamp
+	kindTimestampTZ
+	kindTime
+	kindUUID
+	kindJSON
+	kindBytes
+)
+
+// columnType is a parsed data_type: canonical kind plus optional modifiers
+// such as varchar length or numeric precision/scale.
+type columnType struct {
+	Kind      kind
+	Raw       string
+	Length    int
+	Precision int
+	Scale     int
+	Array     bool
+	// Unconstrained is a decimal without precision, such as a bare numeric;
+	// only PostgreSQL keeps it so, other targets need a precision.
+	Unconstrained bool
+	// Money is a currency amount, rendered as a decimal with two places.
+	Money bool
+}
+
+var kindAliases = map[string]kind{
+	"smallint": kindInt16, "int2": kindInt16, "int16": kindInt16, "smallserial": kindInt16,
+	"int": kindInt32, "integer": kindInt32, "int4": kindInt32, "int32": kindInt32, "serial": kindInt32,
+	"bigint": kindInt64, "int8": kindInt64, "int64": kindInt64, "bigserial": kindInt64, "long": kindInt64,
+	"real": kindFloat32, "float4": kindFloat32, "float32": kindFloat32,
+	"float": kindFloat64, "float8": kindFloat64, "float64": kindFloat64, "double": kindFloat64, "double precision": kindFloat64,
+	"numeric": kindDecimal, "decimal": kindDecimal, "number": kindDecimal, "money": kindDecimal,
+	"text": kindString, "varchar": kindString, "character varying": kindString, "char": kindString,
+	"character": kindString, "string": kindString, "nvarchar": kindString, "citext": kindString,
+	"bool": kindBool, "boolean": kindBool,
+	"date":      kindDate,
+	"timestamp": kindTimestamp, "datetime": kindTimestamp, "timestamp without time zone": kindTimestamp, "datetime64": kindTimestamp,
+	"timestamptz": kindTimestampTZ, "timestamp with time zone": kindTimestampTZ,
+	"time": kindTime, "time without time zone": kindTime,
+	"uuid": kindUUID,
+	"json": kindJSON, "jsonb": kindJSON,
+	"bytea": kindBytes, "blob": kindBytes, "binary": kindBytes, "varbinary": kindBytes, "bytes": kindBytes,
+}
+
+// parseType normalizes a free-form data_type such as "VARCHAR(255)",
+// "numeric(10, 2)" or "text[]". Unrecognized types get kindUnknown.
+func parseType(raw string) columnType {
+	ct := columnType{Raw: raw}
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if strings.HasSuffix(s, "[]") {
+		ct.Array = true
+		s = strings.TrimSpace(strings.TrimSuffix(s, "[]"))
+	}
+	var args []int
+	if i := strings.Index(s, "("); i >= 0 && strings.HasSuffix(s, ")") {
+		for _, p := range strings.Split(s[i+1:len(s)-1], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil {
+				return ct
+			}
+			args = append(args, n)
+		}
+		s = strings.TrimSpace(s[:i])
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	k, ok := kindAliases[s]
+	if !ok {
+		return ct
+	}
+	ct.Kind = k
+	switch k {
+	case kindString, kindBytes:
+		if len(args) > 0 {
+			ct.Length = args[0]
+		}
+	case kindDecimal:
+		switch {
+		case s == "money":
+			// PostgreSQL money is a 64-bit count of cents
+			ct.Money, ct.Precision, ct.Scale = true, 19, 2
+		case len(args) == 0:
+			ct.Unconstrained, ct.Precision, ct.Scale = true, 38, 10
+		default:
+			ct.Precision = args[0]
+			if len(args) > 1 {
+				ct.Scale = args[1]
+			}
+		}
+	}
+	return ct
+}
+
+// lossy describes how rendering the type for format changes its meaning,
+// or returns "" if it does not.
+func (ct columnType) lossy(format string) string {
+	switch {
+	case ct.Money:
+		return fmt.Sprintf("money rendered as a decimal with %d places; currency formatting is lost", ct.Scale)
+	case ct.Unconstrained && format != FormatPostgresDDL && format != FormatGoStruct && format != FormatJSONSchema:
+		return fmt.Sprintf("%s without precision rendered as decimal(%d,%d); digits beyond %d decimal places are rounded", ct.Raw, ct.Precision, ct.Scale, ct.Scale)
+	}
+	return ""
+}
+
+func (ct columnType) postgres() string {
+	var t string
+	switch ct.Kind {
+	case kindInt16:
+		t = "SMALLINT"
+	case kindInt32:
+		t = "INTEGER"
+	case kindInt64:
+		t = "BIGINT"
+	case kindFloat32:
+		t = "REAL"
+	case kindFloat64:
+		t = "DOUBLE PRECISION"
+	case kindDecimal:
+		t = fmt.Sprintf("NUMERIC(%d,%d)", ct.Precision, ct.Scale)
+		if ct.Unconstrained {
+			t = "NUMERIC"
+		}
+	case kindString:
+		t = "TEXT"
+		if ct.Length > 0 {
+			t = fmt.Sprintf("VARCHAR(%d)", ct.Length)
+		}
+	case kindBool:
+		t = "BOOLEAN"
+	case kindDate:
+		t = "DATE"
+	case kindTimestamp:
+		t = "TIMESTAMP"
+	case kindTimestampTZ:
+		t = "TIMESTAMPTZ"
+	case kindTime:
+		t = "TIME"
+	case kindUUID:
+		t = "UUID"
+	case kindJSON:
+		t = "JSONB"
+	case kindBytes:
+		t = "BYTEA"
+	default:
+		t = "TEXT"
+	}
+	if ct.Array {
+		t += "[]"
+	}
+	return t
+}
+
+func (ct columnType) clickhouse() string {
+	var t string
+	switch ct.Kind {
+	case kindInt16:
+		t = "Int16"
+	case kindInt32:
+		t = "Int32"
+	case kindInt64:
+		t = "Int64"
+	case kindFloat32:
+		t = "Float32"
+	case kindFloat64:
+		t = "Float64"
+	case kindDecimal:
+		t = fmt.Sprintf("Decimal(%d, %d)", ct.Precision, ct.Scale)
+	case kindBool:
+		t = "Bool"
+	case kindDate:
+		t = "Date32"
+	case kindTimestamp:
+		t = "DateTime64(6)"
+	case kindTimestampTZ:
+		t = "DateTime64(6, 'UTC')"
+	case kindUUID:
+		t = "UUID"
+	default:
+		// strings, time, json, bytes and unknown types
+		t = "String"
+	}
+	if ct.Array {
+		t = "Array(" + t + ")"
+	}
+	return t
+}
+
+func (ct columnType) golang() (typ string, imp string) {
+	switch ct.Kind {
+	case kindInt16:
+		typ = "int16"
+	case kindInt32:
+		typ = "int32"
+	case kindInt64:
+		typ = "int64"
+	case kindFloat32:
+		typ = "float32"
+	case kindFloat64:
+		typ = "float64"
+	case kindDecimal:
+		// float64 loses precision; string keeps the exact value
+		typ = "string"
+	case kindBool:
+		typ = "bool"
+	case kindDate, kindTimestamp, kindTimestampTZ:
+		typ, imp = "time.Time", "time"
+	case kindJSON:
+		typ, imp = "json.RawMessage", "encoding/json"
+	case kindBytes:
+		typ = "[]byte"
+	default:
+		// strings, time, uuid and unknown types
+		typ = "string"
+	}
+	if ct.Array {
+		typ = "[]" + typ
+	}
+	return typ, imp
+}
+
+// jsonSchema returns the JSON Schema fragment for the type (without nullability).
+func (ct columnType) jsonSchema() map[string]any {
+	var s map[string]any
+	switch ct.Kind {
+	case kindInt16, kindInt32, kindInt64:
+		s = map[string]any{"type": "integer"}
+	case kindFloat32, kindFloat64:
+		s = map[string]any{"type": "number"}
+	case kindDecimal:
+		s = map[string]any{"type": "string", "pattern": `^-?\d+(\.\d+)?$`}
+	case kindBool:
+		s = map[string]any{"type": "boolean"}
+	case kindDate:
+		s = map[string]any{"type": "string", "format": "date"}
+	case kindTimestamp, kindTimestampTZ:
+		s = map[string]any{"type": "string", "format": "date-time"}
+	case kindTime:
+		s = map[string]any{"type": "string", "format": "time"}
+	case kindUUID:
+		s = map[string]any{"type": "string", "format": "uuid"}
+	case kindJSON:
+		s = map[string]any{}
+	case kindBytes:
+		s = map[string]any{"type": "string", "contentEncoding": "base64"}
+	default:
+		s = map[string]any{"type": "string"}
+		if ct.Kind == kindString && ct.Length > 0 {
+			s["maxLength"] = ct.Length
+		}
+	}
+	if ct.Array {
+		return map[string]any{"type": "array", "items": s}
+	}
+	return s
+}
+
+// avro returns the Avro schema for the type (without nullability).
+func (ct columnType) avro() any {
+	var t any
+	switch ct.Kind {
+	case kindInt16, kindInt32:
+		t = "int"
+	case kindInt64:
+		t = "long"
+	case kindFloat32:
+		t = "float"
+	case kindFloat64:
+		t = "double"
+	case kindDecimal:
+		t = map[string]any{"type": "bytes", "logicalType": "decimal", "precision": ct.Precision, "scale": ct.Scale}
+	case kindBool:
+		t = "boolean"
+	case kindDate:
+		t = map[string]any{"type": "int", "logicalType": "date"}
+	case kindTimestamp:
+		t = map[string]any{"type": "long", "logicalType": "local-timestamp-micros"}
+	case kindTimestampTZ:
+		t = map[string]any{"type": "long", "logicalType": "timestamp-micros"}
+	case kindTime:
+		t = map[string]any{"type": "long", "logicalType": "time-micros"}
+	case kindUUID:
+		t = map[string]any{"type": "string", "logicalType": "uuid"}
+	case kindBytes:
+		t = "bytes"
+	default:
+		// strings, json and unknown types
+		t = "string"
+	}
+	if ct.Array {
+		return map[string]any{"type": "array", "items": t}
+	}
+	return t
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
+)
+
+// ErrInvalidAPIToken is returned for unknown, expired or revoked API tokens
+// and tokens of deactivated users.
+var ErrInvalidAPIToken = errors.New("invalid API token")
+
+// APITokenIdentity is who an API token authenticates as.
+type APITokenIdentity struct {
+	TokenID       int
+	UserID        int
+	SystemRole    string
+	Scopes        []string
+	ServiceTeamID int // 0 for personal tokens
+}
+
+type APITokenRepository struct {
+	db *DB
+}
+
+func NewAPITokenRepository(db *DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+const apiTokenColumns = `id, user_id, name, prefix, scopes, expires_at, created_by, created_at, last_used_at, COALESCE(last_used_ip, ''), revoked_at`
+
+func scanAPIToken(row pgx.Row, t *models.APIToken) error {
+	return row.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &t.Scopes, &t.ExpiresAt, &t.CreatedBy, &t.CreatedAt, &t.LastUsedAt, &t.LastUsedIP, &t.RevokedAt)
+}
+
+// Create stores a token by the hash of its secret.
+func (r *APITokenRepository) Create(ctx context.Context, t *models.APIToken, tokenHash string) error {
+	query := `
+		INSERT INTO api_tokens (user_id, name, prefix, token_hash, scopes, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	return r.db.Pool.QueryRow(ctx, query, t.UserID, t.Name, t.Prefix, tokenHash, t.Scopes, t.ExpiresAt, t.CreatedBy).
+		Scan(&t.ID, &t.CreatedAt)
+}
+
+// ListByUser returns all tokens of a user, including revoked and expired ones.
+func (r *APITokenRepository) ListByUser(ctx context.Context, userID int) ([]models.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		var t models.APIToken
+		if err := scanAPIToken(rows, &t); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// Revoke revokes a token of the user. It returns pgx.ErrNoRows if there is
+// no such token.
+func (r *APITokenRepository) Revoke(ctx context.Context, id, userID int) error {
+	query := `UPDATE api_tokens SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1 AND user_id = $2`
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Authenticate resolves a token by hash and records its use.
+func (r *APITokenRepository) Authenticate(ctx context.Context, tokenHash, ip string) (*APITokenIdentity, error) {
+	query := `
+		UPDATE api_tokens t
+		SET last_used_at = NOW(), last_used_ip = $2
+		FROM users u
+		WHERE t.token_hash = $1 AND u.id = t.user_id
+		  AND t.revoked_at IS NULL AND t.expires_at > NOW() AND u.is_active
+		RETURNING t.id, t.user_id, u.system_role, t.scopes, COALESCE(u.service_team_id, 0)
+	`
+	var id APITokenIdentity
+	err := r.db.Pool.QueryRow(ctx, query, tokenHash, ip).Scan(&id.TokenID, &id.UserID, &id.SystemRole, &id.Scopes, &id.ServiceTeamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidAPIToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
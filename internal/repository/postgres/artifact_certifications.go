@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
+)
+
+// ErrNotCertifiable is returned when a draft, deprecated or retired artifact
+// is certified.
+var ErrNotCertifiable = errors.New("only active artifacts can be certified")
+
+const certStateCase = `CASE WHEN ac.review_required_at IS NOT NULL THEN '` + models.CertNeedsReview + `'
+	WHEN ac.expires_at <= NOW() THEN '` + models.CertExpired + `'
+	ELSE '` + models.CertCertified + `' END`
+
+// certificationState selects the certification state of the artifact id
+// given as an SQL expression, empty if it was never certified.
+func certificationState(artifactID string) string {
+	return `COALESCE((SELECT ` + certStateCase + ` FROM artifact_certifications ac WHERE ac.artifact_id = ` + artifactID + `), '')`
+}
+
+const certificationSelect = `
+	SELECT ac.artifact_id, a.name, ` + certStateCase + `, ac.certified_by, COALESCE(u.email, ''),
+	       ac.certified_at, ac.note, ac.expires_at, ac.review_required_at, ac.review_reason
+	FROM artifact_certifications ac
+	JOIN artifacts a ON a.id = ac.artifact_id
+	LEFT JOIN users u ON u.id = ac.certified_by
+`
+
+func scanCertification(row pgx.Row, c *models.Certification) error {
+	return row.Scan(&c.ArtifactID, &c.ArtifactName, &c.State, &c.CertifiedBy, &c.CertifiedByEmail,
+		&c.CertifiedAt, &c.Note, &c.ExpiresAt, &c.ReviewRequiredAt, &c.ReviewReason)
+}
+
+// Certification returns the certification of the team's artifact, or
+// pgx.ErrNoRows if it has none.
+func (r *ArtifactRepository) Certification(ctx context.Context, teamID, artifactID int) (*models.Certification, error) {
+	var c models.Certification
+	if err := scanCertification(r.db.Pool.QueryRow(ctx, certificationSelect+` WHERE ac.artifact_id = $1 AND a.team_id = $2`, artifactID, teamID), &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Certifications lists the certifications of the team's artifacts, those
+// needing attention first, optionally in one state.
+func (r *ArtifactRepository) Certifications(ctx context.Context, teamID int, state string) ([]models.Certification, error) {
+	query := certificationSelect + `
+		WHERE a.team_id = $1 AND ($2 = '' OR ` + certStateCase + ` = $2)
+		ORDER BY ` + certStateCase + ` = '` + models.CertCertified + `', ac.expires_at, a.name
+	`
+	rows, err := r.db.Pool.Query(ctx, query, teamID, state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.Certification{}
+	for rows.Next() {
+		var c models.Certification
+		if err := scanCertification(rows, &c); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// Certify certifies the team's active artifact, or renews its certification,
+// which clears a pending review. It returns pgx.ErrNoRows if the artifact is
+// not the team's and ErrNotCertifiable if it is not active.
+func (r *ArtifactRepository) Certify(ctx context.Context, teamID, artifactID, userID int, note string, expiresAt time.Time) (*models.Certification, error) {
+	query := `
+		INSERT INTO artifact_certifications (artifact_id, certified_by, note, expires_at)
+		SELECT id, $3, $4, $5 FROM artifacts WHERE id = $1 AND team_id = $2 AND status = 'active'
+		ON CONFLICT (artifact_id) DO UPDATE
+		SET certified_by = EXCLUDED.certified_by, certified_at = NOW(), note = EXCLUDED.note,
+		    expires_at = EXCLUDED.expires_at, review_required_at = NULL, review_reason = ''
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, artifactID, teamID, userID, note, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		exists, err := r.Exists(ctx, teamID, artifactID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrNotCertifiable
+		}
+		return nil, pgx.ErrNoRows
+	}
+	return r.Certification(ctx, teamID, artifactID)
+}
+
+// Uncertify removes the certification of the team's artifact. It returns
+// pgx.ErrNoRows if there is none.
+func (r *ArtifactRepository) Uncertify(ctx context.Context, teamID, artifactID int) error {
+	query := `DELETE FROM artifact_certifications ac USING artifacts a WHERE ac.artifact_id = $1 AND a.id = ac.artifact_id AND a.team_id = $2`
+	tag, err := r.db.Pool.Exec(ctx, query, artifactID, teamID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// IsSteward reports whether the user is linked to a data steward of the
+// team's artifact.
+func (r *ArtifactRepository) IsSteward(ctx context.Context, teamID, artifactID, userID int) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM artifact_owners o JOIN contacts c ON c.id = o.contact_id
+			WHERE o.artifact_id = $1 AND o.role = '` + models.OwnerSteward + `' AND c.team_id = $2 AND c.user_id = $3
+		)
+	`
+	var ok bool
+	err := r.db.Pool.QueryRow(ctx, query, artifactID, teamID, userID).Scan(&ok)
+	return ok, err
+}
@@ -109,3 +109,37 @@ func (r *ArtifactFieldRepository) DeleteField(ctx context.Context, id int) error
 	_, err := r.db.Pool.Exec(ctx, `DELETE FROM artifact_fields WHERE id = $1`, id)
 	return err
 }
+
+// GetFieldsByTeamID returns fields of all team artifacts grouped by artifact id
+func (r *ArtifactFieldRepository) GetFieldsByTeamID(ctx context.Context, teamID int) (map[int][]models.ArtifactField, error) {
+	query := `
+		SELECT f.id, f.artifact_id, f.field_name, f.data_type, f.description, f.is_pk, f.created_at
+		FROM artifact_fields f
+		JOIN artifacts a ON a.id = f.artifact_id
+		WHERE a.team_id = $1
+		ORDER BY f.artifact_id, f.id
+	`
+	rows, err := r.db.Pool.Query(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := map[int][]models.ArtifactField{}
+	for rows.Next() {
+		var f models.ArtifactField
+		if err := rows.Scan(
+			&f.ID,
+			&f.ArtifactID,
+			&f.FieldName,
+			&f.DataType,
+			&f.Description,
+			&f.IsPK,
+			&f.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		res[f.ArtifactID] = append(res[f.ArtifactID], f)
+	}
+	return res, rows.Err()
+}
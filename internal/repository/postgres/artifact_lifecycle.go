@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
+)
+
+var (
+	// ErrUnknownReplacement is returned when the replacement is neither an
+	// artifact of the team nor one shared with it.
+	ErrUnknownReplacement = errors.New("replacement artifact not found or not shared with this team")
+	// ErrReplacementInactive is returned when the replacement is itself
+	// deprecated or retired.
+	ErrReplacementInactive = errors.New("the replacement artifact is deprecated or retired")
+	// ErrReasonRequired is returned when an artifact is deprecated without
+	// a reason.
+	ErrReasonRequired = errors.New("a reason is required to deprecate an artifact")
+)
+
+// inUse is the SQL condition on a downstream artifact d that still consumes
+// its upstreams.
+const inUse = `d.status IN ('draft', 'active')`
+
+// SetStatus changes the lifecycle status of the team's artifact and returns
+// it with the previous status. Leaving deprecated and retired clears the
+// deprecation fields. It returns pgx.ErrNoRows if the artifact is not the
+// team's.
+func (r *ArtifactRepository) SetStatus(ctx context.Context, teamID, id int, s models.ArtifactStatus) (*models.Artifact, string, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var cur models.Artifact
+	query := `SELECT status, deprecated_at, replacement_id, deprecation_reason FROM artifacts WHERE id = $1 AND team_id = $2 FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, id, teamID).Scan(&cur.Status, &cur.DeprecatedAt, &cur.ReplacementID, &cur.DeprecationReason); err != nil {
+		return nil, "", err
+	}
+
+	var deprecatedAt *time.Time
+	var replacementID *int
+	reason := ""
+	if s.Status == models.StatusDeprecated || s.Status == models.StatusRetired {
+		deprecatedAt, replacementID, reason = cur.DeprecatedAt, cur.ReplacementID, cur.DeprecationReason
+		if s.DeprecatedAt != nil {
+			deprecatedAt = s.DeprecatedAt
+		}
+		if deprecatedAt == nil {
+			now := time.Now()
+			deprecatedAt = &now
+		}
+		if s.ReplacementID != nil {
+			replacementID = s.ReplacementID
+			var status string
+			query := `SELECT u.status FROM artifacts u WHERE u.id = $1 AND u.id <> $3 AND (u.team_id = $2 OR ` + sharedWith("u.id", "$2") + `)`
+			if err := tx.QueryRow(ctx, query, *replacementID, teamID, id).Scan(&status); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, "", ErrUnknownReplacement
+				}
+				return nil, "", err
+			}
+			if status == models.StatusDeprecated || status == models.StatusRetired {
+				return nil, "", ErrReplacementInactive
+			}
+		}
+		if s.Reason != "" {
+			reason = s.Reason
+		}
+		if s.Status == models.StatusDeprecated && reason == "" {
+			return nil, "", ErrReasonRequired
+		}
+	}
+
+	query = `
+		UPDATE artifacts SET status = $3, deprecated_at = $4, replacement_id = $5, deprecation_reason = $6
+		WHERE id = $1 AND team_id = $2
+	`
+	if _, err := tx.Exec(ctx, query, id, teamID, s.Status, deprecatedAt, replacementID, reason); err != nil {
+		return nil, "", err
+	}
+	// a deprecated artifact is no longer a trusted source
+	if deprecatedAt != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM artifact_certifications WHERE artifact_id = $1`, id); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, "", err
+	}
+	a, err := r.GetArtifactByID(ctx, teamID, id)
+	return a, cur.Status, err
+}
+
+// NotifyDownstream warns the teams whose draft or active artifacts are
+// derived from artifactID, while it is still visible to them: their owners
+// and admins and the users linked to the owners of the downstream artifact.
+// The downstream artifact's name is appended to message. It returns the
+// number of notifications created.
+func (r *NotificationRepository) NotifyDownstream(ctx context.Context, artifactID int, kind, message string) (int64, error) {
+	query := `
+		INSERT INTO notifications (user_id, kind, team_id, subject_id, message)
+		SELECT DISTINCT rcpt.user_id, $2, d.team_id, d.id, $3 || ' Used by ' || d.name || '.'
+		FROM artifact_lineage l
+		JOIN artifacts u ON u.id = l.upstream_artifact_id
+		JOIN artifacts d ON d.id = l.downstream_artifact_id
+		CROSS JOIN LATERAL (
+			SELECT tm.user_id FROM team_members tm JOIN users us ON us.id = tm.user_id
+			WHERE tm.team_id = d.team_id AND tm.role IN ('owner', 'admin') AND tm.status = 'active'
+			  AND us.is_active AND us.service_team_id IS NULL
+			UNION
+			SELECT c.user_id FROM artifact_owners o
+			JOIN contacts c ON c.id = o.contact_id
+			JOIN team_members tm ON tm.team_id = d.team_id AND tm.user_id = c.user_id AND tm.status = 'active'
+			JOIN users us ON us.id = c.user_id
+			WHERE o.artifact_id = d.id AND us.is_active
+		) rcpt
+		WHERE l.upstream_artifact_id = $1 AND ` + inUse + ` AND ` + upstreamAvailable + `
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, artifactID, kind, message)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeprecatedConsumers returns the team's deprecated and retired artifacts
+// that draft and active artifacts are derived from, each with the edges to
+// those consumers. It reads the artifacts along with the edges, in one query.
+func (r *LineageRepository) DeprecatedConsumers(ctx context.Context, teamID int) ([]models.DeprecatedInUse, error) {
+	query := `
+		SELECT u.id, u.name, u.type, u.description, u.project_name, COALESCE(u.developer_id, 0), u.team_id, u.created_at,
+		       u.status, u.deprecated_at, u.replacement_id, u.deprecation_reason, ` + certificationState("u.id") + `,
+		       ` + lineageColumns + lineageFrom + `
+		WHERE u.team_id = $1 AND u.status IN ('deprecated', 'retired') AND ` + inUse + `
+		ORDER BY u.deprecated_at, u.name, u.id, dt.name, d.name
+	`
+	rows, err := r.db.Pool.Query(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.DeprecatedInUse{}
+	for rows.Next() {
+		var a models.Artifact
+		var e models.LineageEdge
+		err := rows.Scan(&a.ID, &a.Name, &a.Type, &a.Description, &a.ProjectName, &a.DeveloperID, &a.TeamID, &a.CreatedAt,
+			&a.Status, &a.DeprecatedAt, &a.ReplacementID, &a.DeprecationReason, &a.Certification,
+			&e.ID, &e.UpstreamID, &e.UpstreamName, &e.UpstreamTeamID, &e.UpstreamTeamName, &e.UpstreamStatus, &e.UpstreamLifecycle,
+			&e.DownstreamID, &e.DownstreamName, &e.DownstreamTeamID, &e.DownstreamTeamName, &e.CreatedBy, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(res); n > 0 && res[n-1].ID == a.ID {
+			res[n-1].Consumers = append(res[n-1].Consumers, e)
+			continue
+		}
+		res = append(res, models.DeprecatedInUse{Artifact: a, Consumers: []models.LineageEdge{e}})
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
+)
+
+// ErrUnknownContact is returned when an owner is not a contact of the team.
+var ErrUnknownContact = errors.New("contact not found in the team")
+
+// firstTechnicalOwner selects the contact that artifacts.developer_id
+// mirrors for the artifact id given as an SQL expression.
+func firstTechnicalOwner(artifactID string) string {
+	return `SELECT contact_id FROM artifact_owners WHERE artifact_id = ` + artifactID + ` AND role = 'technical_owner' ORDER BY position, created_at, contact_id LIMIT 1`
+}
+
+const ownerSelect = `
+	SELECT o.artifact_id, o.contact_id, c.name, c.user_id, o.role, o.created_at
+	FROM artifact_owners o JOIN contacts c ON c.id = o.contact_id
+`
+
+const ownerOrder = ` ORDER BY o.role DESC, o.position, o.created_at, o.contact_id`
+
+// querier is the pool or a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func scanOwner(row pgx.Row, o *models.ArtifactOwner) error {
+	return row.Scan(&o.ArtifactID, &o.ContactID, &o.ContactName, &o.UserID, &o.Role, &o.CreatedAt)
+}
+
+// Owners returns the owners of the team's artifact, technical owners first.
+func (r *ArtifactRepository) Owners(ctx context.Context, teamID, artifactID int) ([]models.ArtifactOwner, error) {
+	return r.owners(ctx, r.db.Pool, ownerSelect+` JOIN artifacts a ON a.id = o.artifact_id WHERE o.artifact_id = $1 AND a.team_id = $2`+ownerOrder, artifactID, teamID)
+}
+
+// OwnersByTeam returns the owners of all artifacts of the team by artifact id.
+func (r *ArtifactRepository) OwnersByTeam(ctx context.Context, teamID int) (map[int][]models.ArtifactOwner, error) {
+	owners, err := r.owners(ctx, r.db.Pool, ownerSelect+` JOIN artifacts a ON a.id = o.artifact_id WHERE a.team_id = $1`+ownerOrder, teamID)
+	if err != nil {
+		return nil, err
+	}
+	res := map[int][]models.ArtifactOwner{}
+	for _, o := range owners {
+		res[o.ArtifactID] = append(res[o.ArtifactID], o)
+	}
+	return res, nil
+}
+
+func (r *ArtifactRepository) owners(ctx context.Context, q querier, query string, args ...any) ([]models.ArtifactOwner, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.ArtifactOwner{}
+	for rows.Next() {
+		var o models.ArtifactOwner
+		if err := scanOwner(rows, &o); err != nil {
+			return nil, err
+		}
+		res = append(res, o)
+	}
+	return res, rows.Err()
+}
+
+// SetOwners replaces the owners of the team's artifact; the order of the
+// list is kept within each role and developer_id follows the first technical
+// owner. It returns pgx.ErrNoRows if the artifact is not the team's and
+// ErrUnknownContact if an owner is not a contact of the team.
+func (r *ArtifactRepository) SetOwners(ctx context.Context, teamID, artifactID int, owners []models.ArtifactOwner) ([]models.ArtifactOwner, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	if err := tx.QueryRow(ctx, `SELECT id FROM artifacts WHERE id = $1 AND team_id = $2 FOR UPDATE`, artifactID, teamID).Scan(&id); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM artifact_owners WHERE artifact_id = $1`, artifactID); err != nil {
+		return nil, err
+	}
+	for i, o := range owners {
+		query := `
+			INSERT INTO artifact_owners (artifact_id, contact_id, role, position)
+			SELECT $1, id, $3, $4 FROM contacts WHERE id = $2 AND team_id = $5
+			ON CONFLICT DO NOTHING
+		`
+		tag, err := tx.Exec(ctx, query, artifactID, o.ContactID, o.Role, i, teamID)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 0 {
+			var inTeam bool
+			if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM contacts WHERE id = $1 AND team_id = $2)`, o.ContactID, teamID).Scan(&inTeam); err != nil {
+				return nil, err
+			}
+			if !inTeam {
+				return nil, ErrUnknownContact
+			}
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE artifacts SET developer_id = (`+firstTechnicalOwner("$1")+`) WHERE id = $1`, artifactID); err != nil {
+		return nil, err
+	}
+	res, err := r.owners(ctx, tx, ownerSelect+` WHERE o.artifact_id = $1`+ownerOrder, artifactID)
+	if err != nil {
+		return nil, err
+	}
+	return res, tx.Commit(ctx)
+}
+
+// ReassignContact moves every ownership of the team's contact fromID to toID,
+// keeping the roles, and returns the number of artifacts concerned. It
+// returns pgx.ErrNoRows if either contact is not the team's.
+func (r *ArtifactRepository) ReassignContact(ctx context.Context, teamID, fromID, toID int) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var found int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM contacts WHERE id IN ($1, $2) AND team_id = $3`, fromID, toID, teamID).Scan(&found); err != nil {
+		return 0, err
+	}
+	if found != 2 {
+		return 0, pgx.ErrNoRows
+	}
+	var n int
+	query := `
+		SELECT COUNT(*) FROM (
+			SELECT artifact_id FROM artifact_owners WHERE contact_id = $1
+			UNION
+			SELECT id FROM artifacts WHERE developer_id = $1 AND team_id = $2
+		) x
+	`
+	if err := tx.QueryRow(ctx, query, fromID, teamID).Scan(&n); err != nil {
+		return 0, err
+	}
+	query = `
+		INSERT INTO artifact_owners (artifact_id, contact_id, role, position, created_at)
+		SELECT artifact_id, $2, role, position, created_at FROM artifact_owners WHERE contact_id = $1
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, fromID, toID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM artifact_owners WHERE contact_id = $1`, fromID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE artifacts SET developer_id = $2 WHERE developer_id = $1 AND team_id = $3`, fromID, toID, teamID); err != nil {
+		return 0, err
+	}
+	return n, tx.Commit(ctx)
+}
+
+// MyArtifacts returns the artifacts owned by contacts linked to the user in
+// the teams the user is an active member of, optionally in one role.
+func (r *ArtifactRepository) MyArtifacts(ctx context.Context, userID int, role string, limit, offset int) ([]models.MyArtifact, error) {
+	query := `
+		SELECT a.id, a.name, a.type, COALESCE(a.description, ''), COALESCE(a.project_name, ''), COALESCE(a.developer_id, 0), a.team_id, a.created_at,
+		       a.status, a.deprecated_at, a.replacement_id, a.deprecation_reason, ` + certificationState("a.id") + `, t.name, array_agg(DISTINCT o.role ORDER BY o.role)
+		FROM artifact_owners o
+		JOIN contacts c ON c.id = o.contact_id
+		JOIN artifacts a ON a.id = o.artifact_id AND a.team_id = c.team_id
+		JOIN teams t ON t.id = a.team_id
+		JOIN team_members tm ON tm.team_id = a.team_id AND tm.user_id = c.user_id AND tm.status = 'active'
+		WHERE c.user_id = $1 AND ($2 = '' OR o.role = $2)
+		GROUP BY a.id, t.name
+		ORDER BY t.name, a.name, a.id
+		LIMIT NULLIF($3, 0) OFFSET $4
+	`
+	rows, err := r.db.Pool.Query(ctx, query, userID, role, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.MyArtifact{}
+	for rows.Next() {
+		var m models.MyArtifact
+		a := &m.Artifact
+		if err := rows.Scan(&a.ID, &a.Name, &a.Type, &a.Description, &a.ProjectName, &a.DeveloperID, &a.TeamID, &a.CreatedAt,
+			&a.Status, &a.DeprecatedAt, &a.ReplacementID, &a.DeprecationReason, &a.Certification, &m.TeamName, &m.Roles); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"go-data-catalog/internal/models"
+)
+
+// ErrAlreadyShared is returned when the artifact already has an active share
+// with the same target.
+var ErrAlreadyShared = errors.New("the artifact is already shared with this team")
+
+const shareColumns = `s.id, s.artifact_id, a.name, s.team_id, s.target_team_id, COALESCE(tt.name, ''), s.created_by, s.created_at, s.revoked_at`
+
+const shareFrom = `
+	FROM artifact_shares s
+	JOIN artifacts a ON a.id = s.artifact_id
+	LEFT JOIN teams tt ON tt.id = s.target_team_id
+`
+
+// sharedWith is an SQL condition: the artifact (an expression) has an active
+// share with the team (an expression) or with all teams of its organization.
+func sharedWith(artifact, team string) string {
+	return `EXISTS (SELECT 1 FROM artifact_shares sw WHERE sw.artifact_id = ` + artifact +
+		` AND sw.revoked_at IS NULL AND (sw.target_team_id = ` + team + ` OR (sw.target_team_id IS NULL AND EXISTS (` +
+		`SELECT 1 FROM teams swo, teams swt WHERE swo.id = sw.team_id AND swt.id = ` + team + ` AND swo.org_id = swt.org_id))))`
+}
+
+// ShareRepository publishes artifacts to other teams.
+type ShareRepository struct {
+	db *DB
+}
+
+func NewShareRepository(db *DB) *ShareRepository { return &ShareRepository{db: db} }
+
+// Create shares an artifact of the team. It returns pgx.ErrNoRows if the team
+// has no such artifact and ErrAlreadyShared for a duplicate.
+func (r *ShareRepository) Create(ctx context.Context, s *models.ArtifactShare) error {
+	query := `
+		INSERT INTO artifact_shares (artifact_id, team_id, target_team_id, created_by)
+		SELECT a.id, a.team_id, $3, $4 FROM artifacts a WHERE a.id = $1 AND a.team_id = $2
+		RETURNING id, created_at
+	`
+	err := r.db.Pool.QueryRow(ctx, query, s.ArtifactID, s.TeamID, s.TargetTeamID, s.CreatedBy).Scan(&s.ID, &s.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrAlreadyShared
+	}
+	if err != nil {
+		return err
+	}
+	return r.db.Pool.QueryRow(ctx, `SELECT `+shareColumns+shareFrom+` WHERE s.id = $1`, s.ID).Scan(shareDest(s)...)
+}
+
+// ListByTeam returns the team's shares, newest first; status is active,
+// revoked or empty for all.
+func (r *ShareRepository) ListByTeam(ctx context.Context, teamID int, status string) ([]models.ArtifactShare, error) {
+	query := `SELECT ` + shareColumns + shareFrom + `
+		WHERE s.team_id = $1
+		  AND ($2 = '' OR ($2 = 'active') = (s.revoked_at IS NULL))
+		ORDER BY s.created_at DESC, s.id DESC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, teamID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.ArtifactShare{}
+	for rows.Next() {
+		var s models.ArtifactShare
+		if err := rows.Scan(shareDest(&s)...); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+// Revoke ends an active share of the team. Lineage edges of other teams to
+// the artifact stay and are reported as unshared. It returns pgx.ErrNoRows if
+// there is no such active share.
+func (r *ShareRepository) Revoke(ctx context.Context, teamID, id int) error {
+	tag, err := r.db.Pool.Exec(ctx, `UPDATE artifact_shares SET revoked_at = NOW() WHERE id = $1 AND team_id = $2 AND revoked_at IS NULL`, id, teamID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ListSharedWith returns artifacts of other teams shared with the team,
+// ordered by team and name.
+func (r *ShareRepository) ListSharedWith(ctx context.Context, teamID int) ([]models.SharedArtifact, error) {
+	query := `
+		SELECT a.id, a.name, a.type, COALESCE(a.description, ''), COALESCE(a.project_name, ''), COALESCE(a.developer_id, 0), a.team_id, a.created_at,
+		       a.status, a.deprecated_at, a.replacement_id, a.deprecation_reason, ` + certificationState("a.id") + `, t.name
+		FROM artifacts a JOIN teams t ON t.id = a.team_id
+		WHERE a.team_id <> $1 AND ` + sharedWith("a.id", "$1") + `
+		ORDER BY t.name, a.name
+	`
+	rows, err := r.db.Pool.Query(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.SharedArtifact{}
+	for rows.Next() {
+		var sa models.SharedArtifact
+		if err := rows.Scan(sharedArtifactDest(&sa)...); err != nil {
+			return nil, err
+		}
+		res = append(res, sa)
+	}
+	return res, rows.Err()
+}
+
+// GetSharedWith returns one artifact of another team shared with the team,
+// or pgx.ErrNoRows.
+func (r *ShareRepository) GetSharedWith(ctx context.Context, teamID, artifactID int) (*models.SharedArtifact, error) {
+	query := `
+		SELECT a.id, a.name, a.type, COALESCE(a.description, ''), COALESCE(a.project_name, ''), COALESCE(a.developer_id, 0), a.team_id, a.created_at,
+		       a.status, a.deprecated_at, a.replacement_id, a.deprecation_reason, ` + certificationState("a.id") + `, t.name
+		FROM artifacts a JOIN teams t ON t.id = a.team_id
+		WHERE a.id = $2 AND a.team_id <> $1 AND ` + sharedWith("a.id", "$1")
+	var sa models.SharedArtifact
+	if err := r.db.Pool.QueryRow(ctx, query, teamID, artifactID).Scan(sharedArtifactDest(&sa)...); err != nil {
+		return nil, err
+	}
+	return &sa, nil
+}
+
+func shareDest(s *models.ArtifactShare) []any {
+	return []any{&s.ID, &s.ArtifactID, &s.ArtifactName, &s.TeamID, &s.TargetTeamID, &s.TargetTeamName, &s.CreatedBy, &s.CreatedAt, &s.RevokedAt}
+}
+
+func sharedArtifactDest(sa *models.SharedArtifact) []any {
+	a := &sa.Artifact
+	return []any{&a.ID, &a.Name, &a.Type, &a.Description, &a.ProjectName, &a.DeveloperID, &a.TeamID, &a.CreatedAt,
+		&a.Status, &a.DeprecatedAt, &a.ReplacementID, &a.DeprecationReason, &a.Certification, &sa.TeamName}
+}
@@ -14,16 +14,24 @@ func NewArtifactRepository(db *DB) *ArtifactRepository{
 }
 
 func (r *ArtifactRepository) GetAllArtifacts(ctx context.Context, teamID int) ([]models.Artifact, error){
+	return r.GetArtifactsPage(ctx, teamID, 0, 0)
+}
+
+// GetArtifactsPage returns one page of team artifacts; limit 0 means no limit
+func (r *ArtifactRepository) GetArtifactsPage(ctx context.Context, teamID, limit, offset int) ([]models.Artifact, error) {
 	query := `
-		SELECT id, name, type, description, project_name, developer_id, team_id, created_at
+		SELECT id, name, type, description, project_name, COALESCE(developer_id, 0), team_id, created_at,
+		       status, deprecated_at, replacement_id, deprecation_reason, ` + certificationState("artifacts.id") + `
 		FROM artifacts
 		WHERE team_id = $1
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
+		LIMIT NULLIF($2, 0) OFFSET $3
 	`
-	rows, err := r.db.Pool.Query(ctx, query, teamID)
+	rows, err := r.db.Pool.Query(ctx, query, teamID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var artifacts []models.Artifact
 	for rows.Next() {
@@ -37,6 +45,11 @@ func (r *ArtifactRepository) GetAllArtifacts(ctx context.Context, teamID int) ([
 			&artifact.DeveloperID,
 			&artifact.TeamID,
 			&artifact.CreatedAt,
+			&artifact.Status,
+			&artifact.DeprecatedAt,
+			&artifact.ReplacementID,
+			&artifact.DeprecationReason,
+			&artifact.Certification,
 		)
 		if err != nil {
 			return nil, err
@@ -47,10 +60,17 @@ func (r *ArtifactRepository) GetAllArtifacts(ctx context.Context, teamID int) ([
 }
 
 func (r *ArtifactRepository) CreateArtifact(ctx context.Context, teamID int, artifact *models.Artifact) error {
+	// the developer becomes a technical owner
 	query := `
-		INSERT INTO artifacts (name, type, description, project_name, developer_id, team_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, team_id, created_at
+		WITH a AS (
+			INSERT INTO artifacts (name, type, description, project_name, developer_id, team_id, status)
+			VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, COALESCE(NULLIF($7, ''), 'active'))
+			RETURNING id, team_id, created_at, developer_id, status
+		), o AS (
+			INSERT INTO artifact_owners (artifact_id, contact_id, role)
+			SELECT a.id, a.developer_id, 'technical_owner' FROM a JOIN contacts c ON c.id = a.developer_id AND c.team_id = a.team_id
+		)
+		SELECT id, team_id, created_at, status FROM a
 	`
 
 	err := r.db.Pool.QueryRow(
@@ -62,14 +82,16 @@ func (r *ArtifactRepository) CreateArtifact(ctx context.Context, teamID int, art
 		artifact.ProjectName,
 		artifact.DeveloperID,
 		teamID,
-	).Scan(&artifact.ID, &artifact.TeamID, &artifact.CreatedAt)
+		artifact.Status,
+	).Scan(&artifact.ID, &artifact.TeamID, &artifact.CreatedAt, &artifact.Status)
 	
 	return err
 }
 
 func (r *ArtifactRepository) GetArtifactByID(ctx context.Context, teamID, id int) (*models.Artifact, error) {
 	query := `
-		SELECT id, name, type, description, project_name, developer_id, team_id, created_at
+		SELECT id, name, type, description, project_name, COALESCE(developer_id, 0), team_id, created_at,
+		       status, deprecated_at, replacement_id, deprecation_reason, ` + certificationState("artifacts.id") + `
 		FROM artifacts
 		WHERE id = $1 AND team_id = $2
 	`
@@ -84,6 +106,11 @@ func (r *ArtifactRepository) GetArtifactByID(ctx context.Context, teamID, id int
 		&artifact.DeveloperID,
 		&artifact.TeamID,
 		&artifact.CreatedAt,
+		&artifact.Status,
+		&artifact.DeprecatedAt,
+		&artifact.ReplacementID,
+		&artifact.DeprecationReason,
+		&artifact.Certification,
 	)
 	
 	if err != nil {
@@ -94,11 +121,24 @@ func (r *ArtifactRepository) GetArtifactByID(ctx context.Context, teamID, id int
 }
 
 func (r *ArtifactRepository) UpdateArtifact(ctx context.Context, teamID, id int, artifact *models.Artifact) error {
+	// developer_id 0 keeps the first technical owner; a new developer
+	// becomes a technical owner
 	query := `
-		UPDATE artifacts 
-		SET name = $3, type = $4, description = $5, project_name = $6, developer_id = $7
-		WHERE id = $1 AND team_id = $2
-		RETURNING team_id, created_at
+		WITH a AS (
+			UPDATE artifacts
+			SET name = $3, type = $4, description = $5, project_name = $6,
+			    developer_id = COALESCE(NULLIF($7, 0), (` + firstTechnicalOwner("$1") + `))
+			WHERE id = $1 AND team_id = $2
+			RETURNING id, team_id, created_at, developer_id
+		), o AS (
+			INSERT INTO artifact_owners (artifact_id, contact_id, role, position)
+			SELECT a.id, a.developer_id, 'technical_owner',
+			       (SELECT COALESCE(MIN(position), 0) - 1 FROM artifact_owners WHERE artifact_id = a.id AND role = 'technical_owner')
+			FROM a JOIN contacts c ON c.id = a.developer_id AND c.team_id = a.team_id
+			WHERE $7 <> 0
+			ON CONFLICT (artifact_id, contact_id, role) DO UPDATE SET position = EXCLUDED.position
+		)
+		SELECT team_id, created_at FROM a
 	`
 	
 	err := r.db.Pool.QueryRow(
@@ -118,7 +158,8 @@ func (r *ArtifactRepository) UpdateArtifact(ctx context.Context, teamID, id int,
 	}
 	
 	artifact.ID = id
-	return nil
+	query = `SELECT COALESCE(developer_id, 0), status, deprecated_at, replacement_id, deprecation_reason, ` + certificationState("artifacts.id") + ` FROM artifacts WHERE id = $1`
+	return r.db.Pool.QueryRow(ctx, query, id).Scan(&artifact.DeveloperID, &artifact.Status, &artifact.DeprecatedAt, &artifact.ReplacementID, &artifact.DeprecationReason, &artifact.Certification)
 }
 
 func (r *ArtifactRepository) DeleteArtifact(ctx context.Context, teamID, id int) error {
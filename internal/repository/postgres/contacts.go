@@ -2,9 +2,48 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"go-data-catalog/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// ErrContactUserTaken is returned when the account is already linked to
+// another contact of the team.
+var ErrContactUserTaken = errors.New("the user is already linked to another contact of the team")
+
+const contactColumns = `c.id, c.name, COALESCE(c.telegram_contact, ''), c.user_id, COALESCE(u.email, ''), c.department, c.availability, c.avatar_url,
+	COALESCE((SELECT json_agg(json_build_object('kind', ch.kind, 'value', ch.value) ORDER BY ch.position, ch.id) FROM contact_channels ch WHERE ch.contact_id = c.id), '[]'),
+	c.team_id, c.created_at`
+
+func scanContact(row pgx.Row, contact *models.Contact) error {
+	return row.Scan(&contact.ID, &contact.Name, &contact.TelegramContact, &contact.UserID, &contact.UserEmail, &contact.Department, &contact.Availability, &contact.AvatarURL, &contact.Channels, &contact.TeamID, &contact.CreatedAt)
+}
+
+// saveChannels replaces the channels of a contact, keeping their order.
+func saveChannels(ctx context.Context, tx pgx.Tx, contactID int, channels []models.ContactChannel) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM contact_channels WHERE contact_id = $1`, contactID); err != nil {
+		return err
+	}
+	for i, ch := range channels {
+		query := `INSERT INTO contact_channels (contact_id, kind, value, position) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+		if _, err := tx.Exec(ctx, query, contactID, ch.Kind, ch.Value, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contactUserTaken maps the unique violation of contacts(team_id, user_id).
+func contactUserTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrContactUserTaken
+	}
+	return err
+}
+
 type ContactRepository struct {
 	db *DB
 }
@@ -14,13 +53,25 @@ func NewContactRepository(db *DB) *ContactRepository {
 }
 
 func (r *ContactRepository) GetAllContacts(ctx context.Context, teamID int) ([]models.Contact, error) {
+	return r.GetContactsPage(ctx, teamID, "", "", 0, 0)
+}
+
+// GetContactsPage returns one page of team contacts; search matches the
+// name, department, availability note, channels and the linked account's
+// email, department matches exactly (ignoring case); limit 0 means no limit
+func (r *ContactRepository) GetContactsPage(ctx context.Context, teamID int, search, department string, limit, offset int) ([]models.Contact, error) {
 	query := `
-		SELECT id, name, telegram_contact, team_id, created_at
-		FROM contacts
-		WHERE team_id = $1
-		ORDER BY created_at DESC
+		SELECT ` + contactColumns + `
+		FROM contacts c LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.team_id = $1
+		  AND ($4 = '' OR c.name ILIKE '%' || $4 || '%' OR c.department ILIKE '%' || $4 || '%'
+		       OR c.availability ILIKE '%' || $4 || '%' OR COALESCE(u.email, '') ILIKE '%' || $4 || '%'
+		       OR EXISTS (SELECT 1 FROM contact_channels ch WHERE ch.contact_id = c.id AND ch.value ILIKE '%' || $4 || '%'))
+		  AND ($5 = '' OR lower(c.department) = lower($5))
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT NULLIF($2, 0) OFFSET $3
 	`
-	rows, err := r.db.Pool.Query(ctx, query, teamID)
+	rows, err := r.db.Pool.Query(ctx, query, teamID, limit, offset, search, department)
 	if err != nil {
 		return nil, err
 	}
@@ -29,13 +80,7 @@ func (r *ContactRepository) GetAllContacts(ctx context.Context, teamID int) ([]m
 	var contacts []models.Contact
 	for rows.Next() {
 		var contact models.Contact
-		err := rows.Scan(
-			&contact.ID,
-			&contact.Name,
-			&contact.TelegramContact,
-			&contact.TeamID,
-			&contact.CreatedAt,
-		)
+		err := scanContact(rows, &contact)
 		if err != nil {
 			return nil, err
 		}
@@ -46,19 +91,13 @@ func (r *ContactRepository) GetAllContacts(ctx context.Context, teamID int) ([]m
 
 func (r *ContactRepository) GetContactByID(ctx context.Context, teamID, id int) (*models.Contact, error) {
 	query := `
-		SELECT id, name, telegram_contact, team_id, created_at
-		FROM contacts
-		WHERE id = $1 AND team_id = $2
+		SELECT ` + contactColumns + `
+		FROM contacts c LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.id = $1 AND c.team_id = $2
 	`
 	
 	var contact models.Contact
-	err := r.db.Pool.QueryRow(ctx, query, id, teamID).Scan(
-		&contact.ID,
-		&contact.Name,
-		&contact.TelegramContact,
-		&contact.TeamID,
-		&contact.CreatedAt,
-	)
+	err := scanContact(r.db.Pool.QueryRow(ctx, query, id, teamID), &contact)
 	
 	if err != nil {
 		return nil, err
@@ -68,46 +107,74 @@ func (r *ContactRepository) GetContactByID(ctx context.Context, teamID, id int)
 }
 
 func (r *ContactRepository) CreateContact(ctx context.Context, teamID int, contact *models.Contact) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO contacts (name, telegram_contact, team_id)
-		VALUES ($1, $2, $3)
-		RETURNING id, team_id, created_at
+		INSERT INTO contacts (name, telegram_contact, user_id, department, availability, avatar_url, team_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, team_id, created_at, COALESCE((SELECT email FROM users WHERE id = user_id), '')
 	`
 	
-	err := r.db.Pool.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		contact.Name,
 		contact.TelegramContact,
+		contact.UserID,
+		contact.Department,
+		contact.Availability,
+		contact.AvatarURL,
 		teamID,
-	).Scan(&contact.ID, &contact.TeamID, &contact.CreatedAt)
-	
-	return err
+	).Scan(&contact.ID, &contact.TeamID, &contact.CreatedAt, &contact.UserEmail)
+	if err != nil {
+		return contactUserTaken(err)
+	}
+	if err := saveChannels(ctx, tx, contact.ID, contact.Channels); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *ContactRepository) UpdateContact(ctx context.Context, teamID, id int, contact *models.Contact) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		UPDATE contacts 
-		SET name = $3, telegram_contact = $4
+		SET name = $3, telegram_contact = $4, user_id = $5, department = $6, availability = $7, avatar_url = $8
 		WHERE id = $1 AND team_id = $2
-		RETURNING team_id, created_at
+		RETURNING team_id, created_at, COALESCE((SELECT email FROM users WHERE id = user_id), '')
 	`
 	
-	err := r.db.Pool.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		id,
 		teamID,
 		contact.Name,
 		contact.TelegramContact,
-	).Scan(&contact.TeamID, &contact.CreatedAt)
+		contact.UserID,
+		contact.Department,
+		contact.Availability,
+		contact.AvatarURL,
+	).Scan(&contact.TeamID, &contact.CreatedAt, &contact.UserEmail)
 	
 	if err != nil {
+		return contactUserTaken(err)
+	}
+	if err := saveChannels(ctx, tx, id, contact.Channels); err != nil {
 		return err
 	}
 	
 	contact.ID = id
-	return nil
+	return tx.Commit(ctx)
 }
 
 func (r *ContactRepository) DeleteContact(ctx context.Context, teamID, id int) error {
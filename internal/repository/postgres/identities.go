@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+)
+
+// IdentityRepository links users to accounts at external identity providers.
+type IdentityRepository struct {
+	db *DB
+}
+
+func NewIdentityRepository(db *DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// FindUserID returns the user linked to provider/subject and records the
+// login. It returns pgx.ErrNoRows if there is no link.
+func (r *IdentityRepository) FindUserID(ctx context.Context, provider, subject string) (int, error) {
+	query := `
+		UPDATE user_identities SET last_login_at = NOW()
+		WHERE provider = $1 AND subject = $2
+		RETURNING user_id
+	`
+	var userID int
+	err := r.db.Pool.QueryRow(ctx, query, provider, subject).Scan(&userID)
+	return userID, err
+}
+
+// LinkedUserID returns the user linked to provider/subject, or pgx.ErrNoRows.
+// Unlike FindUserID it is not a login.
+func (r *IdentityRepository) LinkedUserID(ctx context.Context, provider, subject string) (int, error) {
+	var userID int
+	err := r.db.Pool.QueryRow(ctx, `SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2`, provider, subject).Scan(&userID)
+	return userID, err
+}
+
+func (r *IdentityRepository) Link(ctx context.Context, userID int, provider, subject, email string) error {
+	query := `
+		INSERT INTO user_identities (provider, subject, user_id, email)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, subject) DO UPDATE SET user_id = EXCLUDED.user_id, email = EXCLUDED.email
+	`
+	_, err := r.db.Pool.Exec(ctx, query, provider, subject, userID, email)
+	return err
+}
@@ -2,72 +2,171 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
 	"go-data-catalog/internal/models"
 )
 
+var (
+	// ErrPendingRequest is returned when the user already has a pending
+	// request to the team.
+	ErrPendingRequest = errors.New("you already have a pending request to this team")
+	// ErrRequestProcessed is returned when deciding or cancelling a request
+	// that is no longer pending.
+	ErrRequestProcessed = errors.New("the request was already processed")
+)
+
+const joinRequestColumns = `jr.id, jr.team_id, jr.user_id, jr.status, jr.message, jr.decision_comment, jr.created_at, jr.processed_by, jr.processed_at`
+
 type JoinRequestRepository struct {
 	db *DB
 }
 
 func NewJoinRequestRepository(db *DB) *JoinRequestRepository { return &JoinRequestRepository{db: db} }
 
-func (r *JoinRequestRepository) Create(ctx context.Context, teamID, userID int) (*models.JoinRequest, error) {
-	jr := &models.JoinRequest{TeamID: teamID, UserID: userID, Status: "pending"}
+func (r *JoinRequestRepository) Create(ctx context.Context, teamID, userID int, message string) (*models.JoinRequest, error) {
+	jr := &models.JoinRequest{TeamID: teamID, UserID: userID, Message: message}
 	query := `
-		INSERT INTO join_requests (team_id, user_id, status)
-		VALUES ($1, $2, 'pending')
+		INSERT INTO join_requests (team_id, user_id, status, message)
+		VALUES ($1, $2, 'pending', $3)
 		RETURNING id, status, created_at
 	`
-	if err := r.db.Pool.QueryRow(ctx, query, teamID, userID).Scan(&jr.ID, &jr.Status, &jr.CreatedAt); err != nil {
+	err := r.db.Pool.QueryRow(ctx, query, teamID, userID, message).Scan(&jr.ID, &jr.Status, &jr.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, ErrPendingRequest
+	}
+	if err != nil {
 		return nil, err
 	}
 	return jr, nil
 }
 
 func (r *JoinRequestRepository) GetByID(ctx context.Context, id int) (*models.JoinRequest, error) {
-	query := `SELECT id, team_id, user_id, status, created_at, processed_by, processed_at FROM join_requests WHERE id = $1`
+	query := `SELECT ` + joinRequestColumns + ` FROM join_requests jr WHERE jr.id = $1`
 	var jr models.JoinRequest
-	var pb sql.NullInt32
-	var pa sql.NullTime
-	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&jr.ID, &jr.TeamID, &jr.UserID, &jr.Status, &jr.CreatedAt, &pb, &pa); err != nil {
+	if err := scanJoinRequest(r.db.Pool.QueryRow(ctx, query, id), &jr); err != nil {
 		return nil, err
 	}
-	if pb.Valid { v := int(pb.Int32); jr.ProcessedBy = &v }
-	if pa.Valid { t := pa.Time; jr.ProcessedAt = &t }
 	return &jr, nil
 }
 
+// ListByTeam returns the team's requests with the requesters' email and name,
+// newest first; status may be empty for all.
 func (r *JoinRequestRepository) ListByTeam(ctx context.Context, teamID int, status string) ([]models.JoinRequest, error) {
-	q := `SELECT id, team_id, user_id, status, created_at, processed_by, processed_at FROM join_requests WHERE team_id = $1`
-	args := []any{teamID}
-	if status != "" {
-		q += " AND status = $2"
-		args = append(args, status)
+	query := `
+		SELECT ` + joinRequestColumns + `, u.email, COALESCE(u.name, '')
+		FROM join_requests jr JOIN users u ON u.id = jr.user_id
+		WHERE jr.team_id = $1 AND ($2 = '' OR jr.status = $2)
+		ORDER BY jr.created_at DESC, jr.id DESC
+	`
+	return r.list(ctx, query, func(jr *models.JoinRequest) []any { return []any{&jr.UserEmail, &jr.UserName} }, teamID, status)
+}
+
+// ListForUser returns the user's own requests with team names, newest first;
+// status may be empty for all.
+func (r *JoinRequestRepository) ListForUser(ctx context.Context, userID int, status string) ([]models.JoinRequest, error) {
+	query := `
+		SELECT ` + joinRequestColumns + `, t.name
+		FROM join_requests jr JOIN teams t ON t.id = jr.team_id
+		WHERE jr.user_id = $1 AND ($2 = '' OR jr.status = $2)
+		ORDER BY jr.created_at DESC, jr.id DESC
+	`
+	return r.list(ctx, query, func(jr *models.JoinRequest) []any { return []any{&jr.TeamName} }, userID, status)
+}
+
+// Decide approves or rejects a pending request of the team. Approval adds the
+// requester as a member in the same transaction. It returns pgx.ErrNoRows for
+// an unknown request, ErrRequestProcessed if it is no longer pending and, on
+// approval, ErrMembershipInactive if a team admin deactivated the requester's
+// membership: approving must not quietly reactivate it.
+func (r *JoinRequestRepository) Decide(ctx context.Context, teamID, id, actorID int, status, comment string) (*models.JoinRequest, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	query := `
+		UPDATE join_requests jr SET status = $3, decision_comment = $4, processed_by = $5, processed_at = NOW()
+		WHERE jr.id = $1 AND jr.team_id = $2 AND jr.status = 'pending'
+		RETURNING ` + joinRequestColumns
+	var jr models.JoinRequest
+	err = scanJoinRequest(tx.QueryRow(ctx, query, id, teamID, status, comment, actorID), &jr)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.notPending(ctx, `SELECT EXISTS(SELECT 1 FROM join_requests WHERE id = $1 AND team_id = $2)`, id, teamID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == "approved" {
+		query := `
+			INSERT INTO team_members (team_id, user_id, role, status) VALUES ($1, $2, 'member', 'active')
+			ON CONFLICT (team_id, user_id) DO NOTHING
+		`
+		tag, err := tx.Exec(ctx, query, teamID, jr.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 0 {
+			var status string
+			if err := tx.QueryRow(ctx, `SELECT status FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, jr.UserID).Scan(&status); err != nil {
+				return nil, err
+			}
+			if status == "inactive" {
+				return nil, ErrMembershipInactive
+			}
+		}
+	}
+	return &jr, tx.Commit(ctx)
+}
+
+// Cancel withdraws the user's own pending request. It returns pgx.ErrNoRows
+// for an unknown request and ErrRequestProcessed if it is no longer pending.
+func (r *JoinRequestRepository) Cancel(ctx context.Context, id, userID int) error {
+	query := `UPDATE join_requests SET status = 'cancelled', processed_at = NOW() WHERE id = $1 AND user_id = $2 AND status = 'pending'`
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.notPending(ctx, `SELECT EXISTS(SELECT 1 FROM join_requests WHERE id = $1 AND user_id = $2)`, id, userID)
+	}
+	return nil
+}
+
+// notPending tells an unknown request (pgx.ErrNoRows) from a processed one.
+func (r *JoinRequestRepository) notPending(ctx context.Context, existsQuery string, args ...any) error {
+	var exists bool
+	if err := r.db.Pool.QueryRow(ctx, existsQuery, args...).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrRequestProcessed
+	}
+	return pgx.ErrNoRows
+}
+
+func (r *JoinRequestRepository) list(ctx context.Context, query string, extra func(*models.JoinRequest) []any, args ...any) ([]models.JoinRequest, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
-	q += " ORDER BY created_at DESC"
-	rows, err := r.db.Pool.Query(ctx, q, args...)
-	if err != nil { return nil, err }
 	defer rows.Close()
-	var res []models.JoinRequest
+	res := []models.JoinRequest{}
 	for rows.Next() {
 		var jr models.JoinRequest
-		var pb sql.NullInt32
-		var pa sql.NullTime
-		if err := rows.Scan(&jr.ID, &jr.TeamID, &jr.UserID, &jr.Status, &jr.CreatedAt, &pb, &pa); err != nil { return nil, err }
-		if pb.Valid { v := int(pb.Int32); jr.ProcessedBy = &v }
-		if pa.Valid { t := pa.Time; jr.ProcessedAt = &t }
+		if err := scanJoinRequest(rows, &jr, extra(&jr)...); err != nil {
+			return nil, err
+		}
 		res = append(res, jr)
 	}
-	return res, nil
+	return res, rows.Err()
 }
 
-func (r *JoinRequestRepository) UpdateStatus(ctx context.Context, id int, processedBy int, status string) error {
-	query := `
-		UPDATE join_requests
-		SET status = $2, processed_by = $3, processed_at = NOW()
-		WHERE id = $1
-	`
-	_, err := r.db.Pool.Exec(ctx, query, id, status, processedBy)
-	return err
+func scanJoinRequest(row pgx.Row, jr *models.JoinRequest, extra ...any) error {
+	dest := []any{&jr.ID, &jr.TeamID, &jr.UserID, &jr.Status, &jr.Message, &jr.DecisionComment, &jr.CreatedAt, &jr.ProcessedBy, &jr.ProcessedAt}
+	return row.Scan(append(dest, extra...)...)
 }
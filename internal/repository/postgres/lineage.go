@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"go-data-catalog/internal/models"
+)
+
+// ErrEdgeExists is returned when the lineage edge is already there.
+var ErrEdgeExists = errors.New("the artifact is already linked to this upstream")
+
+// lineageSelect reads edges with the state of the upstream as seen by the
+// downstream team: the live name while it is available, the saved name
+// otherwise.
+var lineageSelect = `SELECT ` + lineageColumns + lineageFrom
+
+// lineageColumns are the edge columns read by scanEdge.
+var lineageColumns = `
+	       l.id, l.upstream_artifact_id,
+	       CASE WHEN ` + upstreamAvailable + ` THEN u.name ELSE l.upstream_name END,
+	       l.upstream_team_id, COALESCE(ut.name, ''),
+	       CASE WHEN u.id IS NULL THEN '` + models.UpstreamDeleted + `'
+	            WHEN ` + upstreamAvailable + ` THEN '` + models.UpstreamAvailable + `'
+	            ELSE '` + models.UpstreamUnshared + `' END,
+	       CASE WHEN ` + upstreamAvailable + ` THEN u.status ELSE '' END,
+	       d.id, d.name, d.team_id, dt.name, l.created_by, l.created_at
+`
+
+var lineageFrom = `
+	FROM artifact_lineage l
+	JOIN artifacts d ON d.id = l.downstream_artifact_id
+	JOIN teams dt ON dt.id = d.team_id
+	LEFT JOIN artifacts u ON u.id = l.upstream_artifact_id
+	LEFT JOIN teams ut ON ut.id = l.upstream_team_id
+`
+
+var upstreamAvailable = `(u.team_id = d.team_id OR ` + sharedWith("u.id", "d.team_id") + `)`
+
+// LineageRepository stores which artifacts are derived from which.
+type LineageRepository struct {
+	db *DB
+}
+
+func NewLineageRepository(db *DB) *LineageRepository { return &LineageRepository{db: db} }
+
+// Add links the team's artifact downstreamID to upstreamID, which must be an
+// artifact of the team or one shared with it. It returns pgx.ErrNoRows if
+// either is not visible to the team and ErrEdgeExists for a duplicate.
+func (r *LineageRepository) Add(ctx context.Context, teamID, downstreamID, upstreamID, actorID int) (*models.LineageEdge, error) {
+	query := `
+		INSERT INTO artifact_lineage (upstream_artifact_id, upstream_team_id, upstream_name, downstream_artifact_id, created_by)
+		SELECT u.id, u.team_id, u.name, d.id, $4
+		FROM artifacts u, artifacts d
+		WHERE u.id = $1 AND d.id = $2 AND d.team_id = $3
+		  AND (u.team_id = $3 OR ` + sharedWith("u.id", "$3") + `)
+		RETURNING id
+	`
+	var id int
+	err := r.db.Pool.QueryRow(ctx, query, upstreamID, downstreamID, teamID, actorID).Scan(&id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, ErrEdgeExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e models.LineageEdge
+	if err := scanEdge(r.db.Pool.QueryRow(ctx, lineageSelect+` WHERE l.id = $1`, id), &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Delete removes an edge into the team's artifact. It returns pgx.ErrNoRows
+// if there is no such edge.
+func (r *LineageRepository) Delete(ctx context.Context, teamID, downstreamID, id int) error {
+	query := `
+		DELETE FROM artifact_lineage l USING artifacts d
+		WHERE l.id = $1 AND l.downstream_artifact_id = $2 AND d.id = l.downstream_artifact_id AND d.team_id = $3
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, downstreamID, teamID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ForArtifact returns the edges into the artifact (its sources) and out of it
+// (artifacts of any team derived from it).
+func (r *LineageRepository) ForArtifact(ctx context.Context, artifactID int) (*models.Lineage, error) {
+	up, err := r.list(ctx, lineageSelect+` WHERE l.downstream_artifact_id = $1 ORDER BY l.id`, artifactID)
+	if err != nil {
+		return nil, err
+	}
+	down, err := r.list(ctx, lineageSelect+` WHERE l.upstream_artifact_id = $1 ORDER BY dt.name, d.name`, artifactID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Lineage{Upstream: up, Downstream: down}, nil
+}
+
+// ByTeam returns the edges into and out of the team's artifacts.
+func (r *LineageRepository) ByTeam(ctx context.Context, teamID int) ([]models.LineageEdge, error) {
+	return r.list(ctx, lineageSelect+` WHERE d.team_id = $1 OR u.team_id = $1 ORDER BY l.id`, teamID)
+}
+
+func (r *LineageRepository) list(ctx context.Context, query string, args ...any) ([]models.LineageEdge, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.LineageEdge{}
+	for rows.Next() {
+		var e models.LineageEdge
+		if err := scanEdge(rows, &e); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+func scanEdge(row pgx.Row, e *models.LineageEdge) error {
+	return row.Scan(&e.ID, &e.UpstreamID, &e.UpstreamName, &e.UpstreamTeamID, &e.UpstreamTeamName, &e.UpstreamStatus, &e.UpstreamLifecycle,
+		&e.DownstreamID, &e.DownstreamName, &e.DownstreamTeamID, &e.DownstreamTeamName, &e.CreatedBy, &e.CreatedAt)
+}
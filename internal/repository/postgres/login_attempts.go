@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+
+	"go-data-catalog/internal/models"
+)
+
+// LoginAttemptRepository stores the login history.
+type LoginAttemptRepository struct {
+	db *DB
+}
+
+func NewLoginAttemptRepository(db *DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) Record(ctx context.Context, a *models.LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (user_id, login, ip, user_agent, method, result)
+		VALUES ($1, LEFT($2, 255), $3, LEFT($4, 255), $5, $6)
+	`
+	_, err := r.db.Pool.Exec(ctx, query, a.UserID, a.Login, a.IP, a.UserAgent, a.Method, a.Result)
+	return err
+}
+
+// ListForUser returns the newest attempts of a user, including failed ones
+// with their email that could not be tied to the account.
+func (r *LoginAttemptRepository) ListForUser(ctx context.Context, userID int, email string, limit, offset int) ([]models.LoginAttempt, error) {
+	query := `
+		SELECT id, user_id, login, ip, user_agent, method, result, created_at
+		FROM login_attempts
+		WHERE user_id = $1 OR (user_id IS NULL AND lower(login) = lower($2))
+		ORDER BY created_at DESC, id DESC
+		LIMIT NULLIF($3, 0) OFFSET $4
+	`
+	rows, err := r.db.Pool.Query(ctx, query, userID, email, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var attempts []models.LoginAttempt
+	for rows.Next() {
+		var a models.LoginAttempt
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Login, &a.IP, &a.UserAgent, &a.Method, &a.Result, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/throttle"
+)
+
+// LoginThrottleRepository is a throttle.Store shared by all server instances.
+type LoginThrottleRepository struct {
+	db     *DB
+	writes atomic.Int64
+}
+
+const throttleSweepEvery = 1000
+
+var _ throttle.Store = (*LoginThrottleRepository)(nil)
+
+func NewLoginThrottleRepository(db *DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+func (r *LoginThrottleRepository) Get(ctx context.Context, key string, window time.Duration) (throttle.Entry, error) {
+	query := `
+		SELECT failures, last_failure_at FROM login_throttle
+		WHERE key = $1 AND last_failure_at > NOW() - make_interval(secs => $2)
+	`
+	var e throttle.Entry
+	err := r.db.Pool.QueryRow(ctx, query, key, window.Seconds()).Scan(&e.Failures, &e.LastFailure)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return throttle.Entry{}, nil
+	}
+	return e, err
+}
+
+func (r *LoginThrottleRepository) Fail(ctx context.Context, key string, window time.Duration) (throttle.Entry, error) {
+	// sweep idle counters now and then; guessing random logins creates many
+	if r.writes.Add(1)%throttleSweepEvery == 0 {
+		if _, err := r.db.Pool.Exec(ctx, `DELETE FROM login_throttle WHERE last_failure_at < NOW() - make_interval(secs => $1)`, window.Seconds()); err != nil {
+			return throttle.Entry{}, err
+		}
+	}
+	query := `
+		INSERT INTO login_throttle (key, failures, last_failure_at) VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttle.last_failure_at > NOW() - make_interval(secs => $2)
+				THEN login_throttle.failures + 1 ELSE 1 END,
+			last_failure_at = NOW()
+		RETURNING failures, last_failure_at
+	`
+	var e throttle.Entry
+	err := r.db.Pool.QueryRow(ctx, query, key, window.Seconds()).Scan(&e.Failures, &e.LastFailure)
+	return e, err
+}
+
+func (r *LoginThrottleRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM login_throttle WHERE key = $1`, key)
+	return err
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
+)
+
+// Notification kinds.
+const (
+	NotifyJoinRequest        = "join_request"
+	NotifyJoinRequestDecided = "join_request_decided"
+	NotifyUpstreamDeprecated = "upstream_deprecated"
+)
+
+// NotificationRepository stores in-app notifications.
+type NotificationRepository struct {
+	db *DB
+}
+
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, userID int, kind string, teamID, subjectID int, message string) error {
+	query := `INSERT INTO notifications (user_id, kind, team_id, subject_id, message) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Pool.Exec(ctx, query, userID, kind, teamID, subjectID, message)
+	return err
+}
+
+// NotifyTeamAdmins notifies every active human owner and admin of the team.
+func (r *NotificationRepository) NotifyTeamAdmins(ctx context.Context, teamID int, kind string, subjectID int, message string) error {
+	query := `
+		INSERT INTO notifications (user_id, kind, team_id, subject_id, message)
+		SELECT tm.user_id, $2, tm.team_id, $3, $4
+		FROM team_members tm JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1 AND tm.role IN ('owner', 'admin') AND tm.status = 'active'
+		  AND u.is_active AND u.service_team_id IS NULL
+	`
+	_, err := r.db.Pool.Exec(ctx, query, teamID, kind, subjectID, message)
+	return err
+}
+
+// ListForUser returns the user's notifications, newest first. limit 0 means
+// no limit.
+func (r *NotificationRepository) ListForUser(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	query := `
+		SELECT id, kind, team_id, subject_id, message, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT NULLIF($3, 0) OFFSET $4
+	`
+	rows, err := r.db.Pool.Query(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.Kind, &n.TeamID, &n.SubjectID, &n.Message, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, n)
+	}
+	return res, rows.Err()
+}
+
+func (r *NotificationRepository) UnreadCount(ctx context.Context, userID int) (int, error) {
+	var n int
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+// MarkRead marks one notification of the user as read. It returns
+// pgx.ErrNoRows if the user has no such notification.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id int) error {
+	tag, err := r.db.Pool.Exec(ctx, `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	return err
+}
@@ -0,0 +1,463 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"go-data-catalog/internal/models"
+)
+
+var (
+	// ErrOrgNameTaken is returned when another organization has the name.
+	ErrOrgNameTaken = errors.New("an organization with this name already exists")
+	// ErrLastOrgAdmin is returned when the change would leave the
+	// organization without an admin.
+	ErrLastOrgAdmin = errors.New("the organization must keep at least one admin")
+	// ErrOwnsTeams is returned when removing a user who owns teams of the
+	// organization.
+	ErrOwnsTeams = errors.New("the user owns teams of this organization; transfer them first")
+	// ErrTypeInUse is returned when removing an artifact type that artifacts
+	// of the organization still use.
+	ErrTypeInUse = errors.New("artifacts of this organization use the type")
+	// ErrAlreadyOrgMember is returned when adding a member again.
+	ErrAlreadyOrgMember = errors.New("the user is already a member of the organization")
+	// ErrTypeExists is returned for a duplicate artifact type.
+	ErrTypeExists = errors.New("the artifact type already exists")
+)
+
+// defaultArtifactTypes start the registry of a new organization.
+var defaultArtifactTypes = []models.ArtifactType{
+	{Name: "table", Description: "таблица БД"},
+	{Name: "view", Description: "представление"},
+	{Name: "procedure", Description: "процедура"},
+	{Name: "function", Description: "функция"},
+	{Name: "index", Description: "индекс"},
+	{Name: "dataset", Description: "датасет"},
+	{Name: "api", Description: "API endpoint"},
+	{Name: "file", Description: "файл"},
+}
+
+const orgColumns = `o.id, o.name, o.description, o.allowed_auth_methods, o.auto_join, o.created_at`
+
+const orgMemberColumns = `m.org_id, m.user_id, u.email, COALESCE(u.name, ''), m.role, u.is_active, m.joined_at`
+
+// OrgRepository stores organizations, their members, settings and artifact
+// type registry.
+type OrgRepository struct {
+	db *DB
+}
+
+func NewOrgRepository(db *DB) *OrgRepository { return &OrgRepository{db: db} }
+
+// Create adds an organization with the default artifact types and makes
+// adminID its admin.
+func (r *OrgRepository) Create(ctx context.Context, o *models.Organization, adminID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO organizations (name, description, allowed_auth_methods, auto_join)
+		VALUES ($1, $2, COALESCE($3, '{password,ldap,oidc}'::text[]), $4)
+		RETURNING id, allowed_auth_methods, created_at
+	`
+	err = tx.QueryRow(ctx, query, o.Name, o.Description, o.AllowedAuthMethods, o.AutoJoin).Scan(&o.ID, &o.AllowedAuthMethods, &o.CreatedAt)
+	if err != nil {
+		return orgNameTaken(err)
+	}
+	for _, t := range defaultArtifactTypes {
+		if _, err := tx.Exec(ctx, `INSERT INTO org_artifact_types (org_id, name, description) VALUES ($1, $2, $3)`, o.ID, t.Name, t.Description); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, 'admin')`, o.ID, adminID); err != nil {
+		return err
+	}
+	o.Role = "admin"
+	return tx.Commit(ctx)
+}
+
+// Get returns the organization or pgx.ErrNoRows.
+func (r *OrgRepository) Get(ctx context.Context, id int) (*models.Organization, error) {
+	var o models.Organization
+	if err := scanOrg(r.db.Pool.QueryRow(ctx, `SELECT `+orgColumns+` FROM organizations o WHERE o.id = $1`, id), &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ListAll returns every organization, by name.
+func (r *OrgRepository) ListAll(ctx context.Context) ([]models.Organization, error) {
+	return r.list(ctx, `SELECT `+orgColumns+`, '' FROM organizations o ORDER BY o.name`)
+}
+
+// ListForUser returns the user's organizations with the user's role.
+func (r *OrgRepository) ListForUser(ctx context.Context, userID int) ([]models.Organization, error) {
+	query := `
+		SELECT ` + orgColumns + `, m.role
+		FROM organizations o JOIN org_members m ON m.org_id = o.id AND m.user_id = $1
+		ORDER BY o.name
+	`
+	return r.list(ctx, query, userID)
+}
+
+// Update changes the name, description and settings.
+func (r *OrgRepository) Update(ctx context.Context, o *models.Organization) error {
+	query := `
+		UPDATE organizations SET name = $2, description = $3, allowed_auth_methods = $4, auto_join = $5
+		WHERE id = $1 RETURNING created_at
+	`
+	err := r.db.Pool.QueryRow(ctx, query, o.ID, o.Name, o.Description, o.AllowedAuthMethods, o.AutoJoin).Scan(&o.CreatedAt)
+	return orgNameTaken(err)
+}
+
+// Role returns the user's role in the organization, or "" for non-members.
+func (r *OrgRepository) Role(ctx context.Context, orgID, userID int) (string, error) {
+	var role string
+	err := r.db.Pool.QueryRow(ctx, `SELECT role FROM org_members WHERE org_id = $1 AND user_id = $2`, orgID, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// IsMember reports whether the user belongs to the organization.
+func (r *OrgRepository) IsMember(ctx context.Context, orgID, userID int) (bool, error) {
+	role, err := r.Role(ctx, orgID, userID)
+	return role != "", err
+}
+
+// AdminOfAll reports whether adminID is an admin of every organization the
+// user belongs to, so that they may manage the account itself.
+func (r *OrgRepository) AdminOfAll(ctx context.Context, adminID, userID int) (bool, error) {
+	query := `
+		SELECT NOT EXISTS (
+			SELECT 1 FROM org_members m
+			WHERE m.user_id = $2 AND NOT EXISTS (
+				SELECT 1 FROM org_members a WHERE a.org_id = m.org_id AND a.user_id = $1 AND a.role = 'admin'
+			)
+		)
+	`
+	var ok bool
+	err := r.db.Pool.QueryRow(ctx, query, adminID, userID).Scan(&ok)
+	return ok, err
+}
+
+// JoinAutoJoin adds a new user to every organization with auto_join set.
+func (r *OrgRepository) JoinAutoJoin(ctx context.Context, userID int) error {
+	query := `
+		INSERT INTO org_members (org_id, user_id)
+		SELECT id, $1 FROM organizations WHERE auto_join
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.Pool.Exec(ctx, query, userID)
+	return err
+}
+
+// AuthMethodAllowed reports whether the user may sign in with the method:
+// users without organizations may, others need one organization allowing it.
+func (r *OrgRepository) AuthMethodAllowed(ctx context.Context, userID int, method string) (bool, error) {
+	query := `
+		SELECT COUNT(*) = 0 OR bool_or($2 = ANY(o.allowed_auth_methods))
+		FROM org_members m JOIN organizations o ON o.id = m.org_id
+		WHERE m.user_id = $1
+	`
+	var ok bool
+	err := r.db.Pool.QueryRow(ctx, query, userID, method).Scan(&ok)
+	return ok, err
+}
+
+// ListMembers returns the members of the organization by email.
+func (r *OrgRepository) ListMembers(ctx context.Context, orgID int) ([]models.OrgMember, error) {
+	query := `SELECT ` + orgMemberColumns + ` FROM org_members m JOIN users u ON u.id = m.user_id WHERE m.org_id = $1 ORDER BY u.email`
+	rows, err := r.db.Pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.OrgMember{}
+	for rows.Next() {
+		var m models.OrgMember
+		if err := scanOrgMember(rows, &m); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+// GetMember returns one member or pgx.ErrNoRows.
+func (r *OrgRepository) GetMember(ctx context.Context, orgID, userID int) (*models.OrgMember, error) {
+	query := `SELECT ` + orgMemberColumns + ` FROM org_members m JOIN users u ON u.id = m.user_id WHERE m.org_id = $1 AND m.user_id = $2`
+	var m models.OrgMember
+	if err := scanOrgMember(r.db.Pool.QueryRow(ctx, query, orgID, userID), &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// AddMember adds the user with the email to the organization. It returns
+// pgx.ErrNoRows for an unknown email or a service account and
+// ErrAlreadyOrgMember for a member.
+func (r *OrgRepository) AddMember(ctx context.Context, orgID int, email, role string) (*models.OrgMember, error) {
+	var userID int
+	err := r.db.Pool.QueryRow(ctx, `SELECT id FROM users WHERE LOWER(email) = LOWER($1) AND service_team_id IS NULL`, email).Scan(&userID)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := r.db.Pool.Exec(ctx, `INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, orgID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrAlreadyOrgMember
+	}
+	return r.GetMember(ctx, orgID, userID)
+}
+
+// MakeAdmin makes the user with the email an admin of the organization,
+// adding them if needed. It returns pgx.ErrNoRows for an unknown email or a
+// service account.
+func (r *OrgRepository) MakeAdmin(ctx context.Context, orgID int, email string) (*models.OrgMember, error) {
+	var userID int
+	err := r.db.Pool.QueryRow(ctx, `SELECT id FROM users WHERE LOWER(email) = LOWER($1) AND service_team_id IS NULL`, email).Scan(&userID)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, 'admin')
+		ON CONFLICT (org_id, user_id) DO UPDATE SET role = 'admin'
+	`
+	if _, err := r.db.Pool.Exec(ctx, query, orgID, userID); err != nil {
+		return nil, err
+	}
+	return r.GetMember(ctx, orgID, userID)
+}
+
+// SetRole changes a member's role. Demoting the last admin returns
+// ErrLastOrgAdmin; an unknown member pgx.ErrNoRows.
+func (r *OrgRepository) SetRole(ctx context.Context, orgID, userID int, role string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := lockAdmins(ctx, tx, orgID, userID, role != "admin"); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `UPDATE org_members SET role = $3 WHERE org_id = $1 AND user_id = $2`, orgID, userID, role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveMember takes the user out of the organization and all its teams. It
+// refuses to remove the last admin (ErrLastOrgAdmin) or an owner of a team of
+// the organization (ErrOwnsTeams).
+func (r *OrgRepository) RemoveMember(ctx context.Context, orgID, userID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := lockAdmins(ctx, tx, orgID, userID, true); err != nil {
+		return err
+	}
+	var owns bool
+	query := `
+		SELECT EXISTS (SELECT 1 FROM team_members tm JOIN teams t ON t.id = tm.team_id
+		               WHERE t.org_id = $1 AND tm.user_id = $2 AND tm.role = 'owner')
+	`
+	if err := tx.QueryRow(ctx, query, orgID, userID).Scan(&owns); err != nil {
+		return err
+	}
+	if owns {
+		return ErrOwnsTeams
+	}
+	query = `DELETE FROM team_members tm USING teams t WHERE t.id = tm.team_id AND t.org_id = $1 AND tm.user_id = $2`
+	if _, err := tx.Exec(ctx, query, orgID, userID); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM org_members WHERE org_id = $1 AND user_id = $2`, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return tx.Commit(ctx)
+}
+
+// lockAdmins locks the organization's admins and, when the user is losing
+// the admin role, returns ErrLastOrgAdmin if they are the only one.
+func lockAdmins(ctx context.Context, tx pgx.Tx, orgID, userID int, losing bool) error {
+	rows, err := tx.Query(ctx, `SELECT user_id FROM org_members WHERE org_id = $1 AND role = 'admin' FOR UPDATE`, orgID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var admins []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		admins = append(admins, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if losing && len(admins) == 1 && admins[0] == userID {
+		return ErrLastOrgAdmin
+	}
+	return nil
+}
+
+// ArtifactTypes returns the registry of the organization by name.
+func (r *OrgRepository) ArtifactTypes(ctx context.Context, orgID int) ([]models.ArtifactType, error) {
+	query := `
+		SELECT at.name, at.description,
+		       (SELECT COUNT(*) FROM artifacts a JOIN teams t ON t.id = a.team_id WHERE t.org_id = at.org_id AND a.type = at.name)
+		FROM org_artifact_types at WHERE at.org_id = $1 ORDER BY at.name
+	`
+	rows, err := r.db.Pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.ArtifactType{}
+	for rows.Next() {
+		var t models.ArtifactType
+		if err := rows.Scan(&t.Name, &t.Description, &t.InUse); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// HasArtifactType reports whether the registry of the organization has the type.
+func (r *OrgRepository) HasArtifactType(ctx context.Context, orgID int, name string) (bool, error) {
+	var ok bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM org_artifact_types WHERE org_id = $1 AND name = $2)`, orgID, name).Scan(&ok)
+	return ok, err
+}
+
+// AddArtifactType adds a type to the registry; a duplicate returns ErrTypeExists.
+func (r *OrgRepository) AddArtifactType(ctx context.Context, orgID int, t models.ArtifactType) error {
+	_, err := r.db.Pool.Exec(ctx, `INSERT INTO org_artifact_types (org_id, name, description) VALUES ($1, $2, $3)`, orgID, t.Name, t.Description)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrTypeExists
+	}
+	return err
+}
+
+// RemoveArtifactType removes an unused type from the registry. It returns
+// ErrTypeInUse while artifacts of the organization have the type and
+// pgx.ErrNoRows for an unknown type.
+func (r *OrgRepository) RemoveArtifactType(ctx context.Context, orgID int, name string) error {
+	query := `
+		DELETE FROM org_artifact_types at
+		WHERE at.org_id = $1 AND at.name = $2
+		  AND NOT EXISTS (SELECT 1 FROM artifacts a JOIN teams t ON t.id = a.team_id WHERE t.org_id = $1 AND a.type = $2)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, orgID, name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		if ok, err := r.HasArtifactType(ctx, orgID, name); err != nil {
+			return err
+		} else if ok {
+			return ErrTypeInUse
+		}
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Usage counts teams, users and catalog content of the organization.
+func (r *OrgRepository) Usage(ctx context.Context, orgID int) (*models.OrgUsage, error) {
+	u := &models.OrgUsage{PerTeam: []models.TeamUsage{}}
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM auth_sessions s WHERE s.user_id = m.user_id AND s.last_used_at > NOW() - INTERVAL '30 days'))
+		FROM org_members m WHERE m.org_id = $1
+	`
+	if err := r.db.Pool.QueryRow(ctx, query, orgID).Scan(&u.Users, &u.ActiveUsers); err != nil {
+		return nil, err
+	}
+	query = `
+		SELECT t.id, t.name, t.archived_at IS NOT NULL,
+		       (SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id AND tm.status = 'active'),
+		       (SELECT COUNT(*) FROM artifacts a WHERE a.team_id = t.id),
+		       (SELECT COUNT(*) FROM artifact_fields f JOIN artifacts a ON a.id = f.artifact_id WHERE a.team_id = t.id),
+		       (SELECT COUNT(*) FROM contacts ct WHERE ct.team_id = t.id),
+		       (SELECT COUNT(*) FROM artifact_shares s WHERE s.team_id = t.id AND s.revoked_at IS NULL),
+		       (SELECT MAX(a.created_at) FROM artifacts a WHERE a.team_id = t.id)
+		FROM teams t WHERE t.org_id = $1
+		ORDER BY t.name
+	`
+	rows, err := r.db.Pool.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t models.TeamUsage
+		if err := rows.Scan(&t.TeamID, &t.TeamName, &t.Archived, &t.Members, &t.Artifacts, &t.Fields, &t.Contacts, &t.Shares, &t.LastArtifactAt); err != nil {
+			return nil, err
+		}
+		u.Teams++
+		u.Artifacts += t.Artifacts
+		u.Fields += t.Fields
+		u.PerTeam = append(u.PerTeam, t)
+	}
+	return u, rows.Err()
+}
+
+func (r *OrgRepository) list(ctx context.Context, query string, args ...any) ([]models.Organization, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.Organization{}
+	for rows.Next() {
+		var o models.Organization
+		if err := scanOrg(rows, &o, &o.Role); err != nil {
+			return nil, err
+		}
+		res = append(res, o)
+	}
+	return res, rows.Err()
+}
+
+func scanOrg(row pgx.Row, o *models.Organization, extra ...any) error {
+	dest := []any{&o.ID, &o.Name, &o.Description, &o.AllowedAuthMethods, &o.AutoJoin, &o.CreatedAt}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func scanOrgMember(row pgx.Row, m *models.OrgMember) error {
+	return row.Scan(&m.OrgID, &m.UserID, &m.Email, &m.Name, &m.Role, &m.IsActive, &m.JoinedAt)
+}
+
+// orgNameTaken maps the unique violation on organizations.name to ErrOrgNameTaken.
+func orgNameTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrOrgNameTaken
+	}
+	return err
+}
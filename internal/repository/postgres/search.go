@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+
+	"go-data-catalog/internal/models"
+)
+
+// Search finds artifacts of the team, and with includeShared those shared
+// with it, whose name, description or fields match q (case-insensitive
+// substring). Name matches come first, then descriptions, then fields;
+// within each, certified artifacts first and deprecated or retired ones
+// last, then the team's own artifacts before shared ones.
+func (r *ArtifactRepository) Search(ctx context.Context, teamID int, q string, includeShared bool, limit int) ([]models.SearchHit, error) {
+	visible := `
+		SELECT a.id, a.name, a.description, a.project_name, a.team_id, a.status, a.team_id <> $3 AS shared,
+		       ` + certificationState("a.id") + ` = '` + models.CertCertified + `' AS certified
+		FROM artifacts a
+		WHERE a.team_id = $3 OR ($4 AND ` + sharedWith("a.id", "$3") + `)
+	`
+	return r.search(ctx, visible, q, limit, teamID, includeShared)
+}
+
+// SearchOrg finds artifacts of the organization's teams the user may read
+// the same way: teams the user is an active member of and public catalogs,
+// or every team with all. Hits outside the user's teams are marked shared
+// and come after the user's own.
+func (r *ArtifactRepository) SearchOrg(ctx context.Context, orgID, userID int, all bool, q string, limit int) ([]models.SearchHit, error) {
+	visible := `
+		SELECT a.id, a.name, a.description, a.project_name, a.team_id, a.status, m.user_id IS NULL AS shared,
+		       ` + certificationState("a.id") + ` = '` + models.CertCertified + `' AS certified
+		FROM artifacts a
+		JOIN teams t ON t.id = a.team_id
+		LEFT JOIN team_members m ON m.team_id = t.id AND m.user_id = $4 AND m.status = 'active'
+		WHERE t.org_id = $3 AND ($5 OR m.user_id IS NOT NULL OR (t.public_catalog AND t.visibility <> 'hidden'))
+	`
+	return r.search(ctx, visible, q, limit, orgID, userID, all)
+}
+
+// search matches q ($1) against the artifacts of the visible query, which
+// gets args from $3 on; $2 is the limit.
+func (r *ArtifactRepository) search(ctx context.Context, visible, q string, limit int, args ...any) ([]models.SearchHit, error) {
+	query := `
+		WITH visible AS (` + visible + `)
+		SELECT v.id, v.name, COALESCE(v.project_name, ''), v.team_id, t.name, v.shared, v.status, v.certified, m.match, m.field
+		FROM visible v
+		JOIN teams t ON t.id = v.team_id
+		CROSS JOIN LATERAL (
+			SELECT 'name' AS match, '' AS field, 1 AS rank WHERE v.name ILIKE '%' || $1 || '%'
+			UNION ALL
+			SELECT 'description', '', 2 WHERE v.name NOT ILIKE '%' || $1 || '%' AND COALESCE(v.description, '') ILIKE '%' || $1 || '%'
+			UNION ALL
+			SELECT 'field', f.field_name, 3 FROM artifact_fields f
+			WHERE f.artifact_id = v.id AND (f.field_name ILIKE '%' || $1 || '%' OR COALESCE(f.description, '') ILIKE '%' || $1 || '%')
+		) m
+		ORDER BY m.rank, v.certified DESC, v.status IN ('deprecated', 'retired'), v.shared, t.name, v.name, m.field
+		LIMIT $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, append([]any{q, limit}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.SearchHit{}
+	for rows.Next() {
+		var h models.SearchHit
+		if err := rows.Scan(&h.ArtifactID, &h.Artifact, &h.ProjectName, &h.TeamID, &h.TeamName, &h.Shared, &h.Status, &h.Certified, &h.Match, &h.Field); err != nil {
+			return nil, err
+		}
+		res = append(res, h)
+	}
+	return res, rows.Err()
+}
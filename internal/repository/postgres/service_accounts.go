@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+
+	"go-data-catalog/internal/models"
+)
+
+type ServiceAccountRepository struct {
+	db *DB
+}
+
+func NewServiceAccountRepository(db *DB) *ServiceAccountRepository {
+	return &ServiceAccountRepository{db: db}
+}
+
+// Create adds a service account to the team with the given role. The account
+// gets a unique placeholder email and no usable password, so it can only
+// authenticate with API tokens.
+func (r *ServiceAccountRepository) Create(ctx context.Context, teamID int, name, role string) (*models.ServiceAccount, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	sa := &models.ServiceAccount{TeamID: teamID, Name: name, Role: role, IsActive: true}
+	query := `
+		INSERT INTO users (email, password_hash, name, system_role, is_active, service_team_id)
+		VALUES ('sa-' || $1::int::text || '-' || md5(random()::text || clock_timestamp()::text) || '@service-accounts.invalid', '!', $2, 'user', TRUE, $1::int)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRow(ctx, query, teamID, name).Scan(&sa.ID, &sa.CreatedAt); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO team_members (team_id, user_id, role, status) VALUES ($1, $2, $3, 'active')`, teamID, sa.ID, role); err != nil {
+		return nil, err
+	}
+	return sa, tx.Commit(ctx)
+}
+
+const serviceAccountQuery = `
+	SELECT u.id, u.service_team_id, COALESCE(u.name, ''), COALESCE(tm.role, ''), u.is_active, u.created_at
+	FROM users u
+	LEFT JOIN team_members tm ON tm.team_id = u.service_team_id AND tm.user_id = u.id
+	WHERE u.service_team_id = $1
+`
+
+func (r *ServiceAccountRepository) ListByTeam(ctx context.Context, teamID int) ([]models.ServiceAccount, error) {
+	rows, err := r.db.Pool.Query(ctx, serviceAccountQuery+` ORDER BY u.created_at`, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	accounts := []models.ServiceAccount{}
+	for rows.Next() {
+		var sa models.ServiceAccount
+		if err := rows.Scan(&sa.ID, &sa.TeamID, &sa.Name, &sa.Role, &sa.IsActive, &sa.CreatedAt); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, sa)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *ServiceAccountRepository) GetByID(ctx context.Context, teamID, id int) (*models.ServiceAccount, error) {
+	var sa models.ServiceAccount
+	err := r.db.Pool.QueryRow(ctx, serviceAccountQuery+` AND u.id = $2`, teamID, id).
+		Scan(&sa.ID, &sa.TeamID, &sa.Name, &sa.Role, &sa.IsActive, &sa.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sa, nil
+}
+
+// Deactivate disables the account and revokes its tokens. The account and
+// its token history are kept.
+func (r *ServiceAccountRepository) Deactivate(ctx context.Context, teamID, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE users SET is_active = FALSE WHERE id = $1 AND service_team_id = $2`, id, teamID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE api_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is
+	// presented again; the whole session is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// HashToken is how refresh and API tokens are stored, so that a leaked table
+// can't be replayed.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type SessionRepository struct {
+	db *DB
+}
+
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create starts a session for the user with its first refresh token.
+func (r *SessionRepository) Create(ctx context.Context, sessionID string, userID int, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `INSERT INTO auth_sessions (id, user_id) VALUES ($1, $2)`, sessionID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`, sessionID, tokenHash, expiresAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Rotate consumes the refresh token with oldHash and stores newHash as its
+// successor. Presenting a consumed token revokes the session.
+func (r *SessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (sessionID string, userID int, err error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT t.id, t.session_id, t.expires_at, t.used_at IS NOT NULL, s.user_id, s.revoked_at IS NOT NULL
+		FROM refresh_tokens t
+		JOIN auth_sessions s ON s.id = t.session_id
+		WHERE t.token_hash = $1
+		FOR UPDATE
+	`
+	var tokenID int
+	var expires time.Time
+	var used, revoked bool
+	err = tx.QueryRow(ctx, query, oldHash).Scan(&tokenID, &sessionID, &expires, &used, &userID, &revoked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", 0, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	if revoked {
+		return "", 0, ErrInvalidRefreshToken
+	}
+	if used {
+		if _, err := tx.Exec(ctx, `UPDATE auth_sessions SET revoked_at = NOW() WHERE id = $1`, sessionID); err != nil {
+			return "", 0, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return "", 0, err
+		}
+		return "", 0, ErrRefreshTokenReused
+	}
+	if time.Now().After(expires) {
+		return "", 0, ErrInvalidRefreshToken
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, tokenID); err != nil {
+		return "", 0, err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`, sessionID, newHash, expiresAt); err != nil {
+		return "", 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE auth_sessions SET last_used_at = NOW() WHERE id = $1`, sessionID); err != nil {
+		return "", 0, err
+	}
+	return sessionID, userID, tx.Commit(ctx)
+}
+
+// Validate reports whether the session is live: not revoked and belonging
+// to an active user. It also returns the user's current system role, so role
+// changes apply without waiting for the access token to expire.
+func (r *SessionRepository) Validate(ctx context.Context, sessionID string) (systemRole string, ok bool, err error) {
+	query := `
+		SELECT u.system_role
+		FROM auth_sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1 AND s.revoked_at IS NULL AND u.is_active
+	`
+	err = r.db.Pool.QueryRow(ctx, query, sessionID).Scan(&systemRole)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return systemRole, true, nil
+}
+
+// Revoke ends one session of the user.
+func (r *SessionRepository) Revoke(ctx context.Context, sessionID string, userID int) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	_, err := r.db.Pool.Exec(ctx, query, sessionID, userID)
+	return err
+}
+
+// RevokeAllForUser ends every session of the user.
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	query := `UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Pool.Exec(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
+)
+
+var (
+	// ErrInvalidInvitation is returned for unknown, expired, revoked or used
+	// up invitations.
+	ErrInvalidInvitation = errors.New("invalid or expired invitation")
+	// ErrInvitationEmail is returned when an email-bound invitation is
+	// accepted by another user.
+	ErrInvitationEmail = errors.New("this invitation is for a different email address")
+	// ErrInvitationUnverified is returned when an email-bound invitation is
+	// accepted before the user confirmed their email.
+	ErrInvitationUnverified = errors.New("confirm your email address before accepting this invitation")
+	// ErrAlreadyMember is returned when the user already belongs to the team.
+	ErrAlreadyMember = errors.New("already a member of this team")
+	// ErrMembershipInactive is returned when a team admin deactivated the
+	// user's membership; only they can reactivate it.
+	ErrMembershipInactive = errors.New("your membership in this team was deactivated by a team admin")
+)
+
+const invitationStatus = `
+	CASE
+		WHEN revoked_at IS NOT NULL THEN 'revoked'
+		WHEN max_uses IS NOT NULL AND use_count >= max_uses THEN 'used'
+		WHEN expires_at <= NOW() THEN 'expired'
+		ELSE 'active'
+	END`
+
+const invitationColumns = `id, team_id, role, email, max_uses, use_count, ` + invitationStatus + `,
+	expires_at, created_by, created_at, revoked_at`
+
+type TeamInvitationRepository struct {
+	db *DB
+}
+
+func NewTeamInvitationRepository(db *DB) *TeamInvitationRepository {
+	return &TeamInvitationRepository{db: db}
+}
+
+// Create stores an invitation under the hash of its link token and fills in
+// the generated fields.
+func (r *TeamInvitationRepository) Create(ctx context.Context, inv *models.TeamInvitation, tokenHash string) error {
+	query := `
+		INSERT INTO team_invitations (team_id, token_hash, role, email, max_uses, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + invitationColumns
+	row := r.db.Pool.QueryRow(ctx, query, inv.TeamID, tokenHash, inv.Role, inv.Email, inv.MaxUses, inv.ExpiresAt, inv.CreatedBy)
+	return scanInvitation(row, inv)
+}
+
+// ListByTeam returns the team's invitations, newest first; status may be
+// empty for all.
+func (r *TeamInvitationRepository) ListByTeam(ctx context.Context, teamID int, status string) ([]models.TeamInvitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM team_invitations
+		WHERE team_id = $1 AND ($2 = '' OR ` + invitationStatus + ` = $2)
+		ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.Pool.Query(ctx, query, teamID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.TeamInvitation{}
+	for rows.Next() {
+		var inv models.TeamInvitation
+		if err := scanInvitation(rows, &inv); err != nil {
+			return nil, err
+		}
+		res = append(res, inv)
+	}
+	return res, rows.Err()
+}
+
+// Revoke makes an invitation of the team unusable. It returns pgx.ErrNoRows
+// if there is no such invitation or it was already revoked.
+func (r *TeamInvitationRepository) Revoke(ctx context.Context, teamID, id int) error {
+	tag, err := r.db.Pool.Exec(ctx, `UPDATE team_invitations SET revoked_at = NOW() WHERE id = $1 AND team_id = $2 AND revoked_at IS NULL`, id, teamID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Accept adds the user to the team with the invitation's role, counts the use
+// and closes the user's pending join requests to the team, all in one
+// transaction.
+func (r *TeamInvitationRepository) Accept(ctx context.Context, tokenHash string, userID int) (*models.TeamInvitation, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var inv models.TeamInvitation
+	row := tx.QueryRow(ctx, `SELECT `+invitationColumns+` FROM team_invitations WHERE token_hash = $1 FOR UPDATE`, tokenHash)
+	if err := scanInvitation(row, &inv); errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidInvitation
+	} else if err != nil {
+		return nil, err
+	}
+	if inv.Status != "active" {
+		return nil, ErrInvalidInvitation
+	}
+	var archived bool
+	if err := tx.QueryRow(ctx, `SELECT archived_at IS NOT NULL FROM teams WHERE id = $1`, inv.TeamID).Scan(&archived); err != nil {
+		return nil, err
+	}
+	if archived {
+		return nil, ErrTeamArchived
+	}
+	if inv.Email != nil {
+		var email string
+		var verified bool
+		if err := tx.QueryRow(ctx, `SELECT email, email_verified_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&email, &verified); err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(email, *inv.Email) {
+			return nil, ErrInvitationEmail
+		}
+		if !verified {
+			return nil, ErrInvitationUnverified
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO team_members (team_id, user_id, role, status) VALUES ($1, $2, $3, 'active')
+		ON CONFLICT (team_id, user_id) DO NOTHING
+	`, inv.TeamID, userID, inv.Role)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		var status string
+		if err := tx.QueryRow(ctx, `SELECT status FROM team_members WHERE team_id = $1 AND user_id = $2`, inv.TeamID, userID).Scan(&status); err != nil {
+			return nil, err
+		}
+		if status == "inactive" {
+			return nil, ErrMembershipInactive
+		}
+		return nil, ErrAlreadyMember
+	}
+	if _, err := tx.Exec(ctx, `UPDATE team_invitations SET use_count = use_count + 1 WHERE id = $1`, inv.ID); err != nil {
+		return nil, err
+	}
+	query := `
+		UPDATE join_requests SET status = 'approved', processed_by = $3, processed_at = NOW()
+		WHERE team_id = $1 AND user_id = $2 AND status = 'pending'
+	`
+	if _, err := tx.Exec(ctx, query, inv.TeamID, userID, inv.CreatedBy); err != nil {
+		return nil, err
+	}
+	inv.UseCount++
+	if inv.MaxUses != nil && inv.UseCount >= *inv.MaxUses {
+		inv.Status = "used"
+	}
+	return &inv, tx.Commit(ctx)
+}
+
+func scanInvitation(row pgx.Row, inv *models.TeamInvitation) error {
+	return row.Scan(&inv.ID, &inv.TeamID, &inv.Role, &inv.Email, &inv.MaxUses, &inv.UseCount, &inv.Status,
+		&inv.ExpiresAt, &inv.CreatedBy, &inv.CreatedAt, &inv.RevokedAt)
+}
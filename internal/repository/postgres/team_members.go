@@ -2,8 +2,17 @@ package postgres
 
 import (
 	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
 )
 
+// ErrLastOwner is returned by changes that would leave a team without an
+// active owner.
+var ErrLastOwner = errors.New("the team must keep at least one owner; transfer ownership first")
+
 type TeamMemberRepository struct {
 	db *DB
 }
@@ -21,8 +30,14 @@ func (r *TeamMemberRepository) AddOrUpdate(ctx context.Context, teamID, userID i
 	return err
 }
 
+// GetUserRole returns the role of an active member. Memberships of
+// deactivated users are kept but grant no access, so they have no role.
 func (r *TeamMemberRepository) GetUserRole(ctx context.Context, teamID, userID int) (string, error) {
-	query := `SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2 AND status = 'active'`
+	query := `
+		SELECT tm.role FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1 AND tm.user_id = $2 AND tm.status = 'active' AND u.is_active
+	`
 	var role string
 	if err := r.db.Pool.QueryRow(ctx, query, teamID, userID).Scan(&role); err != nil {
 		return "", err
@@ -30,10 +45,202 @@ func (r *TeamMemberRepository) GetUserRole(ctx context.Context, teamID, userID i
 	return role, nil
 }
 
+// IsMember reports whether the user is an active member of the team;
+// deactivated users are not.
 func (r *TeamMemberRepository) IsMember(ctx context.Context, teamID, userID int) (bool, error) {
 	var exists bool
-	if err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id=$1 AND user_id=$2 AND status='active')`, teamID, userID).Scan(&exists); err != nil {
+	if err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM team_members tm JOIN users u ON u.id = tm.user_id WHERE tm.team_id=$1 AND tm.user_id=$2 AND tm.status='active' AND u.is_active)`, teamID, userID).Scan(&exists); err != nil {
 		return false, err
 	}
 	return exists, nil
 }
+
+// MissingRequired2FA reports whether the team requires two-factor
+// authentication and the user has not enabled it. Service accounts, which
+// only use API tokens, are exempt.
+func (r *TeamMemberRepository) MissingRequired2FA(ctx context.Context, teamID, userID int) (bool, error) {
+	query := `
+		SELECT t.require_2fa AND u.service_team_id IS NULL AND NOT EXISTS (
+			SELECT 1 FROM user_totp ut WHERE ut.user_id = u.id AND ut.enabled_at IS NOT NULL
+		)
+		FROM teams t, users u
+		WHERE t.id = $1 AND u.id = $2
+	`
+	var missing bool
+	err := r.db.Pool.QueryRow(ctx, query, teamID, userID).Scan(&missing)
+	return missing, err
+}
+
+// EnsureRole makes the user a member with the given role, as granted by an
+// external directory. Owners keep their role, and members deactivated by a
+// team admin stay inactive.
+func (r *TeamMemberRepository) EnsureRole(ctx context.Context, teamID, userID int, role string) error {
+	query := `
+		INSERT INTO team_members (team_id, user_id, role, status)
+		VALUES ($1, $2, $3, 'active')
+		ON CONFLICT (team_id, user_id)
+		DO UPDATE SET role = CASE WHEN team_members.role = 'owner' THEN 'owner' ELSE EXCLUDED.role END
+	`
+	_, err := r.db.Pool.Exec(ctx, query, teamID, userID, role)
+	return err
+}
+
+// ListMembers returns the members of a team with user details, owners first.
+// status filters when not empty.
+func (r *TeamMemberRepository) ListMembers(ctx context.Context, teamID int, status string) ([]models.TeamMemberDetail, error) {
+	query := `
+		SELECT tm.team_id, tm.user_id, tm.role, tm.status, tm.joined_at, u.email, COALESCE(u.name, ''), u.service_team_id IS NOT NULL
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1 AND ($2 = '' OR tm.status = $2)
+		ORDER BY array_position(ARRAY['owner','admin','member','viewer'], tm.role::text), u.email
+	`
+	rows, err := r.db.Pool.Query(ctx, query, teamID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	members := []models.TeamMemberDetail{}
+	for rows.Next() {
+		var m models.TeamMemberDetail
+		if err := rows.Scan(&m.TeamID, &m.UserID, &m.Role, &m.Status, &m.JoinedAt, &m.Email, &m.Name, &m.ServiceAccount); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// GetMember returns a membership in any status, or pgx.ErrNoRows.
+func (r *TeamMemberRepository) GetMember(ctx context.Context, teamID, userID int) (*models.TeamMemberDetail, error) {
+	query := `
+		SELECT tm.team_id, tm.user_id, tm.role, tm.status, tm.joined_at, u.email, COALESCE(u.name, ''), u.service_team_id IS NOT NULL
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1 AND tm.user_id = $2
+	`
+	var m models.TeamMemberDetail
+	err := r.db.Pool.QueryRow(ctx, query, teamID, userID).
+		Scan(&m.TeamID, &m.UserID, &m.Role, &m.Status, &m.JoinedAt, &m.Email, &m.Name, &m.ServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SetRole changes the role of an existing member.
+func (r *TeamMemberRepository) SetRole(ctx context.Context, teamID, userID int, role string) error {
+	return r.change(ctx, teamID, `UPDATE team_members SET role = $3 WHERE team_id = $1 AND user_id = $2`, userID, role)
+}
+
+// SetStatus activates or deactivates a member. Inactive members keep their
+// role but have no access to the team.
+func (r *TeamMemberRepository) SetStatus(ctx context.Context, teamID, userID int, status string) error {
+	return r.change(ctx, teamID, `UPDATE team_members SET status = $3 WHERE team_id = $1 AND user_id = $2`, userID, status)
+}
+
+// Remove deletes a membership.
+func (r *TeamMemberRepository) Remove(ctx context.Context, teamID, userID int) error {
+	return r.change(ctx, teamID, `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, userID)
+}
+
+// TransferOwnership makes an active member the owner; the previous owner
+// stays in the team as admin.
+func (r *TeamMemberRepository) TransferOwnership(ctx context.Context, teamID, fromUserID, toUserID int) error {
+	return r.inTeamTx(ctx, teamID, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE team_members SET role = 'owner' WHERE team_id = $1 AND user_id = $2 AND status = 'active'`, teamID, toUserID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		_, err = tx.Exec(ctx, `UPDATE team_members SET role = 'admin' WHERE team_id = $1 AND user_id = $2 AND role = 'owner'`, teamID, fromUserID)
+		return err
+	})
+}
+
+// ReassignOwner makes an active user, who need not be a member yet, the
+// only owner of the team; previous owners stay as admins. It returns
+// pgx.ErrNoRows if the user is inactive or a service account.
+func (r *TeamMemberRepository) ReassignOwner(ctx context.Context, teamID, userID int) error {
+	return r.inTeamTx(ctx, teamID, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `UPDATE team_members SET role = 'admin' WHERE team_id = $1 AND user_id <> $2 AND role = 'owner'`, teamID, userID); err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO team_members (team_id, user_id, role, status)
+			SELECT $1, id, 'owner', 'active' FROM users WHERE id = $2 AND is_active AND service_team_id IS NULL
+			ON CONFLICT (team_id, user_id)
+			DO UPDATE SET role = 'owner', status = 'active'
+		`
+		tag, err := tx.Exec(ctx, query, teamID, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// change runs a statement on one membership ($1 team, $2 user, then args)
+// and returns pgx.ErrNoRows if there is no such member.
+func (r *TeamMemberRepository) change(ctx context.Context, teamID int, query string, userID int, args ...any) error {
+	return r.inTeamTx(ctx, teamID, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, append([]any{teamID, userID}, args...)...)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// inTeamTx runs fn with the team row locked, so concurrent membership
+// changes of a team are serialized, and commits only if a team that had an
+// active owner still has one afterwards; an owner whose account is
+// deactivated does not count. Teams left without an owner stay manageable
+// until an organization admin reassigns ownership.
+func (r *TeamMemberRepository) inTeamTx(ctx context.Context, teamID int, fn func(pgx.Tx) error) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	var id int
+	if err := tx.QueryRow(ctx, `SELECT id FROM teams WHERE id = $1 FOR UPDATE`, teamID).Scan(&id); err != nil {
+		return err
+	}
+	hadOwner, err := hasActiveOwner(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	hasOwner, err := hasActiveOwner(ctx, tx, teamID)
+	if err != nil {
+		return err
+	}
+	if hadOwner && !hasOwner {
+		return ErrLastOwner
+	}
+	return tx.Commit(ctx)
+}
+
+func hasActiveOwner(ctx context.Context, tx pgx.Tx, teamID int) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM team_members tm
+			JOIN users u ON u.id = tm.user_id
+			WHERE tm.team_id = $1 AND tm.role = 'owner' AND tm.status = 'active' AND u.is_active
+		)
+	`
+	var ok bool
+	err := tx.QueryRow(ctx, query, teamID).Scan(&ok)
+	return ok, err
+}
@@ -0,0 +1,471 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"go-data-catalog/internal/models"
+)
+
+// ErrSnapshotRestored is returned when restoring a snapshot a second time.
+var ErrSnapshotRestored = errors.New("the snapshot was already restored")
+
+const snapshotColumns = `id, team_id, team_name, org_id, deleted_by, created_at, restored_at, restored_team_id`
+
+// TeamSnapshotRepository deletes teams together with a full export of their
+// content and restores them from it.
+type TeamSnapshotRepository struct {
+	db *DB
+}
+
+func NewTeamSnapshotRepository(db *DB) *TeamSnapshotRepository {
+	return &TeamSnapshotRepository{db: db}
+}
+
+// DeleteTeam saves a snapshot of the team and deletes it, cascading to all
+// its data, in one transaction. It returns pgx.ErrNoRows for an unknown team.
+func (r *TeamSnapshotRepository) DeleteTeam(ctx context.Context, teamID, actorID int) (*models.TeamSnapshot, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	data, err := exportTeam(ctx, tx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	s := models.TeamSnapshot{TeamID: teamID, TeamName: data.Team.Name, OrgID: data.Team.OrgID, DeletedBy: &actorID}
+	query := `INSERT INTO team_snapshots (team_id, team_name, org_id, data, deleted_by) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	if err := tx.QueryRow(ctx, query, teamID, data.Team.Name, data.Team.OrgID, raw, actorID).Scan(&s.ID, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM teams WHERE id = $1`, teamID); err != nil {
+		return nil, err
+	}
+	return &s, tx.Commit(ctx)
+}
+
+// List returns snapshots of the organization (0 for all) without their
+// data, newest first; limit 0 means no limit.
+func (r *TeamSnapshotRepository) List(ctx context.Context, orgID, limit, offset int) ([]models.TeamSnapshot, error) {
+	query := `
+		SELECT ` + snapshotColumns + ` FROM team_snapshots
+		WHERE $1 = 0 OR org_id = $1
+		ORDER BY created_at DESC, id DESC LIMIT NULLIF($2, 0) OFFSET $3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, orgID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []models.TeamSnapshot{}
+	for rows.Next() {
+		var s models.TeamSnapshot
+		if err := scanSnapshot(rows, &s); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+// Get returns a snapshot with its data, or pgx.ErrNoRows.
+func (r *TeamSnapshotRepository) Get(ctx context.Context, id int) (*models.TeamSnapshot, error) {
+	var s models.TeamSnapshot
+	var raw []byte
+	row := r.db.Pool.QueryRow(ctx, `SELECT `+snapshotColumns+`, data FROM team_snapshots WHERE id = $1`, id)
+	if err := scanSnapshot(row, &s, &raw); err != nil {
+		return nil, err
+	}
+	s.Data = &models.TeamExport{}
+	if err := json.Unmarshal(raw, s.Data); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Restore recreates the team of a snapshot in its organization under name
+// (empty for the original name) with new ids for the team, contacts, artifacts, fields,
+// shares and lineage edges; certifications keep their expiry and review flag.
+// Memberships of users that no longer exist are skipped; if no active owner
+// is left, actorID becomes the owner. It returns pgx.ErrNoRows for an unknown
+// snapshot, ErrSnapshotRestored and ErrTeamNameTaken.
+func (r *TeamSnapshotRepository) Restore(ctx context.Context, id, actorID int, name string) (*models.Team, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var raw []byte
+	var restored bool
+	var orgID int
+	err = tx.QueryRow(ctx, `SELECT data, restored_at IS NOT NULL, org_id FROM team_snapshots WHERE id = $1 FOR UPDATE`, id).Scan(&raw, &restored, &orgID)
+	if err != nil {
+		return nil, err
+	}
+	if restored {
+		return nil, ErrSnapshotRestored
+	}
+	var data models.TeamExport
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	t := data.Team
+	t.OrgID = orgID
+	if name != "" {
+		t.Name = name
+	}
+	query := `
+		INSERT INTO teams (org_id, name, description, require_2fa, visibility, join_policy, public_catalog, archived_at, created_by, created_at)
+		VALUES ($10, $1, $2, $3, $4, $5, $6, $7, (SELECT id FROM users WHERE id = $8), $9)
+		RETURNING id
+	`
+	err = tx.QueryRow(ctx, query, t.Name, t.Description, t.Require2FA, t.Visibility, t.JoinPolicy, t.PublicCatalog, t.ArchivedAt, t.CreatedBy, t.CreatedAt, t.OrgID).Scan(&t.ID)
+	if err != nil {
+		return nil, nameTaken(err)
+	}
+
+	for _, m := range data.Members {
+		query := `
+			INSERT INTO team_members (team_id, user_id, role, status, joined_at)
+			SELECT $1, id, $3, $4, $5 FROM users WHERE id = $2 AND service_team_id IS NULL
+		`
+		if _, err := tx.Exec(ctx, query, t.ID, m.UserID, m.Role, m.Status, m.JoinedAt); err != nil {
+			return nil, err
+		}
+	}
+	query = `
+		INSERT INTO team_members (team_id, user_id, role, status)
+		SELECT $1, $2, 'owner', 'active'
+		WHERE NOT EXISTS (
+			SELECT 1 FROM team_members tm JOIN users u ON u.id = tm.user_id
+			WHERE tm.team_id = $1 AND tm.role = 'owner' AND tm.status = 'active' AND u.is_active
+		)
+		ON CONFLICT (team_id, user_id) DO UPDATE SET role = 'owner', status = 'active'
+	`
+	if _, err := tx.Exec(ctx, query, t.ID, actorID); err != nil {
+		return nil, err
+	}
+
+	contactIDs := map[int]int{}
+	for _, ct := range data.Contacts {
+		query := `
+			INSERT INTO contacts (name, telegram_contact, user_id, department, availability, avatar_url, team_id, created_at)
+			VALUES ($1, $2, (SELECT id FROM users WHERE id = $5), $6, $7, $8, $3, $4) RETURNING id
+		`
+		var newID int
+		if err := tx.QueryRow(ctx, query, ct.Name, ct.TelegramContact, t.ID, ct.CreatedAt, ct.UserID, ct.Department, ct.Availability, ct.AvatarURL).Scan(&newID); err != nil {
+			return nil, err
+		}
+		contactIDs[ct.ID] = newID
+		// snapshots taken before channels existed only have the Telegram handle
+		if ct.Channels == nil && ct.TelegramContact != "" {
+			ct.Channels = []models.ContactChannel{{Kind: models.ChannelTelegram, Value: ct.TelegramContact}}
+		}
+		if err := saveChannels(ctx, tx, newID, ct.Channels); err != nil {
+			return nil, err
+		}
+	}
+	artifactIDs := map[int]int{}
+	for _, a := range data.Artifacts {
+		var developer *int
+		if id, ok := contactIDs[a.DeveloperID]; ok {
+			developer = &id
+		}
+		query := `
+			INSERT INTO artifacts (name, type, description, project_name, developer_id, team_id, created_at, status, deprecated_at, deprecation_reason)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'active'), $9, $10) RETURNING id
+		`
+		var newID int
+		if err := tx.QueryRow(ctx, query, a.Name, a.Type, a.Description, a.ProjectName, developer, t.ID, a.CreatedAt, a.Status, a.DeprecatedAt, a.DeprecationReason).Scan(&newID); err != nil {
+			return nil, err
+		}
+		artifactIDs[a.ID] = newID
+		// snapshots taken before owners existed only know the developer
+		if data.Owners == nil && developer != nil {
+			data.Owners = append(data.Owners, models.ArtifactOwner{ArtifactID: a.ID, ContactID: a.DeveloperID, Role: models.OwnerTechnical, CreatedAt: a.CreatedAt})
+		}
+	}
+	// replacements within the team point to the restored artifacts, others
+	// are kept while the artifact exists
+	for _, a := range data.Artifacts {
+		if a.ReplacementID == nil {
+			continue
+		}
+		replacement, ok := artifactIDs[*a.ReplacementID]
+		if !ok {
+			replacement = *a.ReplacementID
+		}
+		query := `UPDATE artifacts SET replacement_id = (SELECT id FROM artifacts WHERE id = $2) WHERE id = $1`
+		if _, err := tx.Exec(ctx, query, artifactIDs[a.ID], replacement); err != nil {
+			return nil, err
+		}
+	}
+	for i, o := range data.Owners {
+		query := `
+			INSERT INTO artifact_owners (artifact_id, contact_id, role, position, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		if _, err := tx.Exec(ctx, query, artifactIDs[o.ArtifactID], contactIDs[o.ContactID], o.Role, i, o.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range data.Fields {
+		query := `
+			INSERT INTO artifact_fields (artifact_id, field_name, data_type, description, is_pk, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		if _, err := tx.Exec(ctx, query, artifactIDs[f.ArtifactID], f.FieldName, f.DataType, f.Description, f.IsPK, f.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := restoreShares(ctx, tx, &data, t.ID, artifactIDs); err != nil {
+		return nil, err
+	}
+	if err := restoreLineage(ctx, tx, &data, t.ID, artifactIDs); err != nil {
+		return nil, err
+	}
+	// after the fields, whose insertion would flag the certifications for review
+	for _, c := range data.Certifications {
+		query := `
+			INSERT INTO artifact_certifications (artifact_id, certified_by, certified_at, note, expires_at, review_required_at, review_reason)
+			VALUES ($1, (SELECT id FROM users WHERE id = $2), $3, $4, $5, $6, $7)
+		`
+		if _, err := tx.Exec(ctx, query, artifactIDs[c.ArtifactID], c.CertifiedBy, c.CertifiedAt, c.Note, c.ExpiresAt, c.ReviewRequiredAt, c.ReviewReason); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE team_snapshots SET restored_at = NOW(), restored_team_id = $2 WHERE id = $1`, id, t.ID); err != nil {
+		return nil, err
+	}
+	if err := scanTeam(tx.QueryRow(ctx, `SELECT `+teamColumns+` FROM teams t WHERE t.id = $1`, t.ID), &t); err != nil {
+		return nil, err
+	}
+	return &t, tx.Commit(ctx)
+}
+
+// restoreShares recreates the shares of the team's artifacts, unless their
+// target team is gone, and the shares of other teams' artifacts with the
+// team while those artifacts exist. Revoked shares are kept as well.
+func restoreShares(ctx context.Context, tx pgx.Tx, data *models.TeamExport, teamID int, artifactIDs map[int]int) error {
+	for _, sh := range data.Shares {
+		artifact, team, target := sh.ArtifactID, sh.TeamID, sh.TargetTeamID
+		if sh.TeamID == data.Team.ID {
+			artifact, team = artifactIDs[sh.ArtifactID], teamID
+		} else {
+			target = &teamID
+		}
+		query := `
+			INSERT INTO artifact_shares (artifact_id, team_id, target_team_id, created_by, created_at, revoked_at)
+			SELECT a.id, a.team_id, $3, (SELECT id FROM users WHERE id = $4), $5, $6
+			FROM artifacts a
+			WHERE a.id = $1 AND a.team_id = $2 AND ($3::int IS NULL OR EXISTS (SELECT 1 FROM teams WHERE id = $3))
+			ON CONFLICT DO NOTHING
+		`
+		if _, err := tx.Exec(ctx, query, artifact, team, target, sh.CreatedBy, sh.CreatedAt, sh.RevokedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreLineage recreates the edges into the team's artifacts, keeping
+// upstreams of other teams that still exist, and points edges of other teams
+// whose upstream was deleted with the team back to the restored artifact.
+func restoreLineage(ctx context.Context, tx pgx.Tx, data *models.TeamExport, teamID int, artifactIDs map[int]int) error {
+	for _, e := range data.Lineage {
+		upstream, upstreamTeam := e.UpstreamID, e.UpstreamTeamID
+		if e.UpstreamID != nil {
+			if id, ok := artifactIDs[*e.UpstreamID]; ok {
+				upstream, upstreamTeam = &id, &teamID
+			}
+		}
+		if e.DownstreamTeamID != data.Team.ID {
+			query := `
+				UPDATE artifact_lineage SET upstream_artifact_id = $2, upstream_team_id = $3
+				WHERE id = $1 AND upstream_artifact_id IS NULL
+			`
+			if _, err := tx.Exec(ctx, query, e.ID, upstream, upstreamTeam); err != nil {
+				return err
+			}
+			continue
+		}
+		query := `
+			INSERT INTO artifact_lineage (upstream_artifact_id, upstream_team_id, upstream_name, downstream_artifact_id, created_by, created_at)
+			VALUES ((SELECT id FROM artifacts WHERE id = $1), (SELECT id FROM teams WHERE id = $2), $3, $4, (SELECT id FROM users WHERE id = $5), $6)
+		`
+		if _, err := tx.Exec(ctx, query, upstream, upstreamTeam, e.UpstreamName, artifactIDs[e.DownstreamID], e.CreatedBy, e.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportTeam reads the whole team, locking its row against concurrent
+// changes of the settings.
+func exportTeam(ctx context.Context, tx pgx.Tx, teamID int) (*models.TeamExport, error) {
+	data := &models.TeamExport{Members: []models.TeamMember{}, Contacts: []models.Contact{}, Artifacts: []models.Artifact{}, Fields: []models.ArtifactField{}, Owners: []models.ArtifactOwner{},
+		Shares: []models.ArtifactShare{}, Lineage: []models.LineageEdge{}, Certifications: []models.Certification{}}
+	if err := scanTeam(tx.QueryRow(ctx, `SELECT `+teamColumns+` FROM teams t WHERE t.id = $1 FOR UPDATE`, teamID), &data.Team); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT tm.team_id, tm.user_id, tm.role, tm.status, tm.joined_at
+		FROM team_members tm JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1 AND u.service_team_id IS NULL
+		ORDER BY tm.user_id
+	`
+	err := collect(ctx, tx, query, teamID, func(row pgx.Row) error {
+		var m models.TeamMember
+		if err := row.Scan(&m.TeamID, &m.UserID, &m.Role, &m.Status, &m.JoinedAt); err != nil {
+			return err
+		}
+		data.Members = append(data.Members, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + contactColumns + ` FROM contacts c LEFT JOIN users u ON u.id = c.user_id WHERE c.team_id = $1 ORDER BY c.id`
+	err = collect(ctx, tx, query, teamID, func(row pgx.Row) error {
+		var ct models.Contact
+		if err := scanContact(row, &ct); err != nil {
+			return err
+		}
+		data.Contacts = append(data.Contacts, ct)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT id, name, type, COALESCE(description, ''), COALESCE(project_name, ''), COALESCE(developer_id, 0), team_id, created_at,
+		       status, deprecated_at, replacement_id, deprecation_reason
+		FROM artifacts WHERE team_id = $1 ORDER BY id
+	`
+	err = collect(ctx, tx, query, teamID, func(row pgx.Row) error {
+		var a models.Artifact
+		if err := row.Scan(&a.ID, &a.Name, &a.Type, &a.Description, &a.ProjectName, &a.DeveloperID, &a.TeamID, &a.CreatedAt,
+			&a.Status, &a.DeprecatedAt, &a.ReplacementID, &a.DeprecationReason); err != nil {
+			return err
+		}
+		data.Artifacts = append(data.Artifacts, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT f.id, f.artifact_id, f.field_name, f.data_type, COALESCE(f.description, ''), COALESCE(f.is_pk, FALSE), f.created_at
+		FROM artifact_fields f JOIN artifacts a ON a.id = f.artifact_id
+		WHERE a.team_id = $1 ORDER BY f.id
+	`
+	err = collect(ctx, tx, query, teamID, func(row pgx.Row) error {
+		var f models.ArtifactField
+		if err := row.Scan(&f.ID, &f.ArtifactID, &f.FieldName, &f.DataType, &f.Description, &f.IsPK, &f.CreatedAt); err != nil {
+			return err
+		}
+		data.Fields = append(data.Fields, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = ownerSelect + ` JOIN artifacts a ON a.id = o.artifact_id WHERE a.team_id = $1` + ownerOrder
+	err = collect(ctx, tx, query, teamID, func(row pgx.Row) error {
+		var o models.ArtifactOwner
+		if err := scanOwner(row, &o); err != nil {
+			return err
+		}
+		data.Owners = append(data.Owners, o)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// shares of the team's artifacts and shares with the team, which go away
+	// with it
+	query = `SELECT ` + shareColumns + shareFrom + ` WHERE s.team_id = $1 OR s.target_team_id = $1 ORDER BY s.id`
+	err = collect(ctx, tx, query, teamID, func(row pgx.Row) error {
+		var sh models.ArtifactShare
+		if err := row.Scan(shareDest(&sh)...); err != nil {
+			return err
+		}
+		data.Shares = append(data.Shares, sh)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// edges into the team's artifacts and edges of other teams from them,
+	// which lose their upstream with it
+	query = `
+		SELECT l.id, l.upstream_artifact_id, l.upstream_name, l.upstream_team_id, d.id, d.name, d.team_id, l.created_by, l.created_at
+		FROM artifact_lineage l
+		JOIN artifacts d ON d.id = l.downstream_artifact_id
+		LEFT JOIN artifacts u ON u.id = l.upstream_artifact_id
+		WHERE d.team_id = $1 OR u.team_id = $1
+		ORDER BY l.id
+	`
+	err = collect(ctx, tx, query, teamID, func(row pgx.Row) error {
+		var e models.LineageEdge
+		if err := row.Scan(&e.ID, &e.UpstreamID, &e.UpstreamName, &e.UpstreamTeamID, &e.DownstreamID, &e.DownstreamName, &e.DownstreamTeamID, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return err
+		}
+		data.Lineage = append(data.Lineage, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = certificationSelect + ` WHERE a.team_id = $1 ORDER BY ac.artifact_id`
+	err = collect(ctx, tx, query, teamID, func(row pgx.Row) error {
+		var c models.Certification
+		if err := scanCertification(row, &c); err != nil {
+			return err
+		}
+		data.Certifications = append(data.Certifications, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func collect(ctx context.Context, tx pgx.Tx, query string, teamID int, scan func(pgx.Row) error) error {
+	rows, err := tx.Query(ctx, query, teamID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanSnapshot(row pgx.Row, s *models.TeamSnapshot, extra ...any) error {
+	dest := []any{&s.ID, &s.TeamID, &s.TeamName, &s.OrgID, &s.DeletedBy, &s.CreatedAt, &s.RestoredAt, &s.RestoredTeamID}
+	return row.Scan(append(dest, extra...)...)
+}
@@ -2,9 +2,23 @@ package postgres
 
 import (
 	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
 	"go-data-catalog/internal/models"
 )
 
+var (
+	// ErrTeamNameTaken is returned when another team already has the name.
+	ErrTeamNameTaken = errors.New("a team with this name already exists")
+	// ErrTeamArchived is returned when joining an archived team.
+	ErrTeamArchived = errors.New("this team is archived")
+)
+
+const teamColumns = `t.id, t.org_id, t.name, t.description, t.require_2fa, t.visibility, t.join_policy, t.public_catalog, t.archived_at, t.created_by, t.created_at`
+
 type TeamRepository struct {
 	db *DB
 }
@@ -12,59 +26,131 @@ type TeamRepository struct {
 func NewTeamRepository(db *DB) *TeamRepository { return &TeamRepository{db: db} }
 
 func (r *TeamRepository) CreateTeam(ctx context.Context, t *models.Team) error {
+	if t.Visibility == "" {
+		t.Visibility = "public"
+	}
+	if t.JoinPolicy == "" {
+		t.JoinPolicy = "approval"
+	}
 	query := `
-		INSERT INTO teams (name, description, created_by)
-		VALUES ($1, $2, $3)
+		INSERT INTO teams (org_id, name, description, created_by, visibility, join_policy, public_catalog)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at
 	`
-	return r.db.Pool.QueryRow(ctx, query, t.Name, t.Description, t.CreatedBy).Scan(&t.ID, &t.CreatedAt)
+	err := r.db.Pool.QueryRow(ctx, query, t.OrgID, t.Name, t.Description, t.CreatedBy, t.Visibility, t.JoinPolicy, t.PublicCatalog).Scan(&t.ID, &t.CreatedAt)
+	return nameTaken(err)
 }
 
 func (r *TeamRepository) GetByID(ctx context.Context, id int) (*models.Team, error) {
-	query := `SELECT id, name, description, created_by, created_at FROM teams WHERE id = $1`
+	query := `SELECT ` + teamColumns + ` FROM teams t WHERE t.id = $1`
 	var t models.Team
-	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&t.ID, &t.Name, &t.Description, &t.CreatedBy, &t.CreatedAt); err != nil {
+	if err := scanTeam(r.db.Pool.QueryRow(ctx, query, id), &t); err != nil {
 		return nil, err
 	}
 	return &t, nil
 }
 
-func (r *TeamRepository) Search(ctx context.Context, q string, limit int) ([]models.Team, error) {
-	if limit <= 0 || limit > 50 { limit = 20 }
+// Search finds teams the user may discover in their organizations: public
+// teams by substring of the name, discoverable teams only by their exact
+// name, and any team the user is an active member of. Archived teams are
+// found by their members only.
+func (r *TeamRepository) Search(ctx context.Context, q string, userID, limit int) ([]models.Team, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
 	query := `
-		SELECT id, name, description, created_by, created_at
-		FROM teams
-		WHERE name ILIKE '%' || $1 || '%'
-		ORDER BY name ASC
-		LIMIT $2
+		SELECT ` + teamColumns + `
+		FROM teams t
+		WHERE (t.org_id IN (SELECT org_id FROM org_members WHERE user_id = $2) AND t.archived_at IS NULL AND (
+		          (t.visibility = 'public' AND t.name ILIKE '%' || $1 || '%')
+		       OR (t.visibility = 'discoverable' AND $1 <> '' AND LOWER(t.name) = LOWER($1))))
+		   OR (t.name ILIKE '%' || $1 || '%' AND EXISTS (
+		          SELECT 1 FROM team_members m WHERE m.team_id = t.id AND m.user_id = $2 AND m.status = 'active'))
+		ORDER BY t.name ASC
+		LIMIT $3
 	`
-	rows, err := r.db.Pool.Query(ctx, query, q, limit)
-	if err != nil { return nil, err }
-	defer rows.Close()
-	var res []models.Team
-	for rows.Next() {
-		var t models.Team
-		if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.CreatedBy, &t.CreatedAt); err != nil { return nil, err }
-		res = append(res, t)
-	}
-	return res, nil
+	return r.list(ctx, query, q, userID, limit)
 }
 
 func (r *TeamRepository) ListForUser(ctx context.Context, userID int) ([]models.Team, error) {
 	query := `
-		SELECT t.id, t.name, t.description, t.created_by, t.created_at
+		SELECT ` + teamColumns + `
 		FROM teams t
 		JOIN team_members m ON m.team_id = t.id AND m.user_id = $1 AND m.status = 'active'
 		ORDER BY t.name
 	`
-	rows, err := r.db.Pool.Query(ctx, query, userID)
-	if err != nil { return nil, err }
+	return r.list(ctx, query, userID)
+}
+
+func (r *TeamRepository) SetRequire2FA(ctx context.Context, id int, required bool) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE teams SET require_2fa = $2 WHERE id = $1`, id, required)
+	return err
+}
+
+// Update renames the team and changes its description.
+func (r *TeamRepository) Update(ctx context.Context, id int, name, description string) error {
+	tag, err := r.db.Pool.Exec(ctx, `UPDATE teams SET name = $2, description = $3 WHERE id = $1`, id, name, description)
+	if err != nil {
+		return nameTaken(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// SetArchived archives the team or brings it back.
+func (r *TeamRepository) SetArchived(ctx context.Context, id int, archived bool) error {
+	query := `UPDATE teams SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, NOW()) END WHERE id = $1`
+	tag, err := r.db.Pool.Exec(ctx, query, id, archived)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// SetAccess changes visibility, join policy and the public catalog flag.
+func (r *TeamRepository) SetAccess(ctx context.Context, id int, visibility, joinPolicy string, publicCatalog bool) error {
+	query := `UPDATE teams SET visibility = $2, join_policy = $3, public_catalog = $4 WHERE id = $1`
+	tag, err := r.db.Pool.Exec(ctx, query, id, visibility, joinPolicy, publicCatalog)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *TeamRepository) list(ctx context.Context, query string, args ...any) ([]models.Team, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
-	var res []models.Team
+	res := []models.Team{}
 	for rows.Next() {
 		var t models.Team
-		if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.CreatedBy, &t.CreatedAt); err != nil { return nil, err }
+		if err := scanTeam(rows, &t); err != nil {
+			return nil, err
+		}
 		res = append(res, t)
 	}
-	return res, nil
+	return res, rows.Err()
+}
+
+func scanTeam(row pgx.Row, t *models.Team) error {
+	return row.Scan(&t.ID, &t.OrgID, &t.Name, &t.Description, &t.Require2FA, &t.Visibility, &t.JoinPolicy, &t.PublicCatalog, &t.ArchivedAt, &t.CreatedBy, &t.CreatedAt)
+}
+
+// nameTaken maps the unique violation on teams.name to ErrTeamNameTaken.
+func nameTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrTeamNameTaken
+	}
+	return err
 }
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrTwoFactorEnabled is returned when enrolling a user who already has
+// two-factor authentication enabled.
+var ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+
+// TwoFactorRepository stores TOTP secrets and recovery codes.
+type TwoFactorRepository struct {
+	db *DB
+}
+
+func NewTwoFactorRepository(db *DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// TOTP is the stored TOTP state of a user.
+type TOTP struct {
+	Secret  string
+	Enabled bool
+}
+
+// Get returns pgx.ErrNoRows if the user never started enrollment.
+func (r *TwoFactorRepository) Get(ctx context.Context, userID int) (*TOTP, error) {
+	var t TOTP
+	err := r.db.Pool.QueryRow(ctx, `SELECT secret, enabled_at IS NOT NULL FROM user_totp WHERE user_id = $1`, userID).
+		Scan(&t.Secret, &t.Enabled)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TwoFactorRepository) IsEnabled(ctx context.Context, userID int) (bool, error) {
+	var enabled bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM user_totp WHERE user_id = $1 AND enabled_at IS NOT NULL)`, userID).
+		Scan(&enabled)
+	return enabled, err
+}
+
+// StartEnrollment stores a new unconfirmed secret, replacing any earlier
+// unconfirmed one.
+func (r *TwoFactorRepository) StartEnrollment(ctx context.Context, userID int, secret string) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = NULL, created_at = NOW()
+		WHERE user_totp.enabled_at IS NULL
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// Enable confirms enrollment and stores a fresh set of recovery codes.
+func (r *TwoFactorRepository) Enable(ctx context.Context, userID int, step int64, codeHashes []string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `UPDATE user_totp SET enabled_at = NOW(), last_used_step = $2 WHERE user_id = $1 AND enabled_at IS NULL`, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTwoFactorEnabled
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UseStep records a TOTP time step as used. It returns false if the step
+// (or a later one) was already used, i.e. the code is a replay.
+func (r *TwoFactorRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	query := `
+		UPDATE user_totp SET last_used_step = $2
+		WHERE user_id = $1 AND enabled_at IS NOT NULL AND (last_used_step IS NULL OR last_used_step < $2)
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It returns false if
+// there is no such unused code.
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	query := `UPDATE user_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	tag, err := r.db.Pool.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *TwoFactorRepository) RecoveryCodesLeft(ctx context.Context, userID int) (int, error) {
+	var n int
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+// ReplaceRecoveryCodes invalidates all recovery codes of the user and stores
+// new ones.
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Disable removes the TOTP secret and recovery codes.
+func (r *TwoFactorRepository) Disable(ctx context.Context, userID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
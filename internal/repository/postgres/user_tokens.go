@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Purposes of emailed user tokens.
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+)
+
+// ErrInvalidUserToken is returned for unknown, expired or used tokens.
+var ErrInvalidUserToken = errors.New("invalid or expired link")
+
+// UserTokenRepository stores single-use tokens for email verification and
+// password reset.
+type UserTokenRepository struct {
+	db *DB
+}
+
+func NewUserTokenRepository(db *DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// Create stores a new token and invalidates the user's earlier unused tokens
+// of the same purpose, so only the latest email works.
+func (r *UserTokenRepository) Create(ctx context.Context, userID int, purpose, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`, userID, purpose); err != nil {
+		return err
+	}
+	query := `INSERT INTO user_tokens (token_hash, user_id, purpose, expires_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(ctx, query, tokenHash, userID, purpose, expiresAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UserID returns the owner of a valid token without using it.
+func (r *UserTokenRepository) UserID(ctx context.Context, purpose, tokenHash string) (int, error) {
+	query := `SELECT user_id FROM user_tokens WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()`
+	var userID int
+	err := r.db.Pool.QueryRow(ctx, query, tokenHash, purpose).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrInvalidUserToken
+	}
+	return userID, err
+}
+
+// VerifyEmail uses a verification token and marks the email as verified.
+func (r *UserTokenRepository) VerifyEmail(ctx context.Context, tokenHash string) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	userID, err := consumeUserToken(ctx, tx, TokenVerifyEmail, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1`, userID); err != nil {
+		return 0, err
+	}
+	return userID, tx.Commit(ctx)
+}
+
+// ResetPassword uses a reset token, sets the new password hash and revokes
+// all sessions of the user. Following the emailed link also proves the
+// address, so the email becomes verified.
+func (r *UserTokenRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	userID, err := consumeUserToken(ctx, tx, TokenResetPassword, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	query := `UPDATE users SET password_hash = $2, email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, userID, passwordHash); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE auth_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID); err != nil {
+		return 0, err
+	}
+	return userID, tx.Commit(ctx)
+}
+
+func consumeUserToken(ctx context.Context, tx pgx.Tx, purpose, tokenHash string) (int, error) {
+	query := `
+		UPDATE user_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+	var userID int
+	err := tx.QueryRow(ctx, query, tokenHash, purpose).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrInvalidUserToken
+	}
+	return userID, err
+}
@@ -15,28 +15,92 @@ func NewUserRepository(db *DB) *UserRepository {
 
 func (r *UserRepository) CreateUser(ctx context.Context, u *models.User) error {
 	query := `
-		INSERT INTO users (email, password_hash, name, system_role, is_active)
-		VALUES ($1, $2, $3, COALESCE($4, 'user'), COALESCE($5, TRUE))
+		INSERT INTO users (email, password_hash, name, system_role, is_active, email_verified_at)
+		VALUES ($1, $2, $3, COALESCE($4, 'user'), COALESCE($5, TRUE), CASE WHEN $6 THEN NOW() END)
 		RETURNING id, system_role, is_active, created_at
 	`
-	return r.db.Pool.QueryRow(ctx, query, u.Email, u.PasswordHash, u.Name, u.SystemRole, u.IsActive).
+	return r.db.Pool.QueryRow(ctx, query, u.Email, u.PasswordHash, u.Name, u.SystemRole, u.IsActive, u.EmailVerified).
 		Scan(&u.ID, &u.SystemRole, &u.IsActive, &u.CreatedAt)
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `SELECT id, email, password_hash, name, system_role, is_active, created_at FROM users WHERE email = $1`
+	query := `SELECT id, email, password_hash, name, system_role, is_active, email_verified_at IS NOT NULL, service_team_id, created_at FROM users WHERE email = $1`
 	var u models.User
-	if err := r.db.Pool.QueryRow(ctx, query, email).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.SystemRole, &u.IsActive, &u.CreatedAt); err != nil {
+	if err := r.db.Pool.QueryRow(ctx, query, email).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.SystemRole, &u.IsActive, &u.EmailVerified, &u.ServiceTeamID, &u.CreatedAt); err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
-	query := `SELECT id, email, password_hash, name, system_role, is_active, created_at FROM users WHERE id = $1`
+	query := `SELECT id, email, password_hash, name, system_role, is_active, email_verified_at IS NOT NULL, service_team_id, created_at FROM users WHERE id = $1`
 	var u models.User
-	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.SystemRole, &u.IsActive, &u.CreatedAt); err != nil {
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.Name, &u.SystemRole, &u.IsActive, &u.EmailVerified, &u.ServiceTeamID, &u.CreatedAt); err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
+
+// List returns users ordered by email. orgID limits them to the members of
+// an organization when not 0; search matches email or name; active filters
+// by status when not nil; limit 0 means no limit.
+func (r *UserRepository) List(ctx context.Context, orgID int, search string, active *bool, limit, offset int) ([]models.User, error) {
+	query := `
+		SELECT id, email, name, system_role, is_active, email_verified_at IS NOT NULL, service_team_id, created_at
+		FROM users
+		WHERE ($1 = '' OR email ILIKE '%' || $1 || '%' OR name ILIKE '%' || $1 || '%')
+		  AND ($2::boolean IS NULL OR is_active = $2)
+		  AND ($5 = 0 OR id IN (SELECT user_id FROM org_members WHERE org_id = $5))
+		ORDER BY email
+		LIMIT NULLIF($3, 0) OFFSET $4
+	`
+	rows, err := r.db.Pool.Query(ctx, query, search, active, limit, offset, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Email, &u.Name, &u.SystemRole, &u.IsActive, &u.EmailVerified, &u.ServiceTeamID, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// SetActive activates or deactivates a user and returns the updated row.
+func (r *UserRepository) SetActive(ctx context.Context, id int, active bool) (*models.User, error) {
+	query := `
+		UPDATE users SET is_active = $2 WHERE id = $1
+		RETURNING id, email, name, system_role, is_active, email_verified_at IS NOT NULL, service_team_id, created_at
+	`
+	var u models.User
+	if err := r.db.Pool.QueryRow(ctx, query, id, active).Scan(&u.ID, &u.Email, &u.Name, &u.SystemRole, &u.IsActive, &u.EmailVerified, &u.ServiceTeamID, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// SetSystemRole changes the system role ('user' or 'admin') of a user.
+func (r *UserRepository) SetSystemRole(ctx context.Context, id int, role string) (*models.User, error) {
+	query := `
+		UPDATE users SET system_role = $2 WHERE id = $1
+		RETURNING id, email, name, system_role, is_active, email_verified_at IS NOT NULL, service_team_id, created_at
+	`
+	var u models.User
+	if err := r.db.Pool.QueryRow(ctx, query, id, role).Scan(&u.ID, &u.Email, &u.Name, &u.SystemRole, &u.IsActive, &u.EmailVerified, &u.ServiceTeamID, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ClaimUnverified marks the email of an unverified account as verified when
+// an external provider has proven the address. The password set at sign-up
+// is dropped, since whoever chose it never proved they own the address.
+func (r *UserRepository) ClaimUnverified(ctx context.Context, id int) error {
+	query := `UPDATE users SET email_verified_at = NOW(), password_hash = '!' WHERE id = $1 AND email_verified_at IS NULL`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
@@ -0,0 +1,29 @@
+package sso
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidCredentials is returned by a PasswordAuthenticator when the
+// directory does not know the login or rejects the password.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// PasswordAuthenticator checks a login and password against an external
+// directory. Any error other than ErrInvalidCredentials means the directory
+// could not be asked.
+type PasswordAuthenticator interface {
+	Authenticate(ctx context.Context, login, password string) (*Identity, error)
+}
+
+// PasswordBackend is a directory consulted by password login after local
+// accounts, with its own group mapping and signup policy. Unless TrustEmail
+// is set, a directory account is never linked to an existing local account
+// by email: the user has to link it while logged in.
+type PasswordBackend struct {
+	Name          string
+	Authenticator PasswordAuthenticator
+	Groups        GroupMapping
+	AllowSignup   bool
+	TrustEmail    bool
+}
@@ -0,0 +1,56 @@
+package sso
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// roleRank orders team roles that external groups may grant. Ownership is
+// never granted from a directory.
+var roleRank = map[string]int{"viewer": 1, "member": 2, "admin": 3}
+
+// TeamRole is a membership granted by an external group.
+type TeamRole struct {
+	TeamID int
+	Role   string
+}
+
+// GroupMapping maps external group names to team memberships.
+type GroupMapping map[string][]TeamRole
+
+// ParseGroupMapping parses "group=teamId:role,group2=teamId:role". A group
+// may appear several times to grant access to several teams.
+func ParseGroupMapping(s string) (GroupMapping, error) {
+	m := GroupMapping{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		group, target, ok := strings.Cut(entry, "=")
+		teamStr, role, ok2 := strings.Cut(target, ":")
+		teamID, err := strconv.Atoi(strings.TrimSpace(teamStr))
+		role = strings.TrimSpace(role)
+		if !ok || !ok2 || err != nil || teamID <= 0 || roleRank[role] == 0 {
+			return nil, fmt.Errorf("invalid group mapping %q: want group=teamId:role with role admin, member or viewer", entry)
+		}
+		group = strings.TrimSpace(group)
+		m[group] = append(m[group], TeamRole{TeamID: teamID, Role: role})
+	}
+	return m, nil
+}
+
+// Resolve returns the team memberships granted by groups, keeping the
+// highest role when several groups grant access to the same team.
+func (m GroupMapping) Resolve(groups []string) map[int]string {
+	res := map[int]string{}
+	for _, g := range groups {
+		for _, tr := range m[g] {
+			if roleRank[tr.Role] > roleRank[res[tr.TeamID]] {
+				res[tr.TeamID] = tr.Role
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,146 @@
+package sso
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"go-data-catalog/internal/config"
+)
+
+const ldapTimeout = 10 * time.Second
+
+// LDAP authenticates users against an LDAP or Active Directory server: it
+// looks the user up with the service account, then binds as the user to
+// check the password. Every call uses a fresh connection.
+type LDAP struct {
+	url          string
+	startTLS     bool
+	bindDN       string
+	bindPassword string
+	baseDN       string
+	userFilter   string
+	emailAttr    string
+	nameAttr     string
+	groupAttr    string
+}
+
+// NewLDAP returns nil if LDAP is not configured.
+func NewLDAP(cfg *config.Config) (*LDAP, error) {
+	if cfg.LDAPURL == "" {
+		return nil, nil
+	}
+	if cfg.LDAPBaseDN == "" {
+		return nil, errors.New("LDAP_BASE_DN is required with LDAP_URL")
+	}
+	if !strings.Contains(cfg.LDAPUserFilter, "{login}") {
+		return nil, errors.New("LDAP_USER_FILTER must contain {login}")
+	}
+	return &LDAP{
+		url:          cfg.LDAPURL,
+		startTLS:     cfg.LDAPStartTLS,
+		bindDN:       cfg.LDAPBindDN,
+		bindPassword: cfg.LDAPBindPassword,
+		baseDN:       cfg.LDAPBaseDN,
+		userFilter:   cfg.LDAPUserFilter,
+		emailAttr:    cfg.LDAPEmailAttribute,
+		nameAttr:     cfg.LDAPNameAttribute,
+		groupAttr:    cfg.LDAPGroupAttribute,
+	}, nil
+}
+
+// Provider identifies identities from this server in user_identities.
+func (l *LDAP) Provider() string {
+	return "ldap:" + l.url
+}
+
+func (l *LDAP) Authenticate(ctx context.Context, login, password string) (*Identity, error) {
+	// an empty password would be an unauthenticated bind, which many
+	// servers accept for any DN
+	if login == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if l.bindDN != "" {
+		if err := conn.Bind(l.bindDN, l.bindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind: %w", err)
+		}
+	}
+	filter := strings.ReplaceAll(l.userFilter, "{login}", ldap.EscapeFilter(login))
+	res, err := conn.Search(ldap.NewSearchRequest(
+		l.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false,
+		filter, []string{l.emailAttr, l.nameAttr, l.groupAttr}, nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("ldap search: %w", err)
+	}
+	if res == nil || len(res.Entries) != 1 {
+		// unknown or ambiguous login
+		return nil, ErrInvalidCredentials
+	}
+	entry := res.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap user bind: %w", err)
+	}
+
+	email := entry.GetEqualFoldAttributeValue(l.emailAttr)
+	id := &Identity{
+		Provider:      l.Provider(),
+		Subject:       strings.ToLower(entry.DN),
+		Email:         email,
+		EmailVerified: email != "", // linking by it is up to PasswordBackend.TrustEmail
+		Name:          entry.GetEqualFoldAttributeValue(l.nameAttr),
+	}
+	for _, g := range entry.GetEqualFoldAttributeValues(l.groupAttr) {
+		id.Groups = append(id.Groups, groupName(g))
+	}
+	return id, nil
+}
+
+func (l *LDAP) dial(ctx context.Context) (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: ldapTimeout}
+	conn, err := ldap.DialURL(l.url, ldap.DialWithDialer(dialer))
+	if err != nil {
+		return nil, fmt.Errorf("ldap dial: %w", err)
+	}
+	timeout := ldapTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	conn.SetTimeout(timeout)
+	if l.startTLS {
+		u, _ := url.Parse(l.url)
+		if err := conn.StartTLS(&tls.Config{ServerName: u.Hostname()}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap StartTLS: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// groupName reduces a group DN such as "cn=data-eng,ou=groups,dc=example,dc=com"
+// to its first RDN value ("data-eng"), which is what group mappings use.
+// Values that are not DNs are returned as is.
+func groupName(v string) string {
+	dn, err := ldap.ParseDN(v)
+	if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+		return v
+	}
+	return dn.RDNs[0].Attributes[0].Value
+}
@@ -0,0 +1,120 @@
+package sso
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"go-data-catalog/internal/config"
+	"go-data-catalog/internal/sso/ssotest"
+)
+
+const testBaseDN = "dc=example,dc=com"
+
+// startDirectory runs the mock LDAP server with alice and bob and returns a
+// client configured like the catalog's defaults.
+func startDirectory(t *testing.T) *LDAP {
+	t.Helper()
+	d := ssotest.NewDirectory(testBaseDN, "admin")
+	for _, u := range []string{
+		"alice:secret:alice@example.com:Alice Example:data-eng,analysts",
+		"bob:hunter2:bob@example.com:Bob Example",
+	} {
+		if err := d.AddUser(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go d.Serve(ln)
+	t.Cleanup(func() { ln.Close() })
+
+	l, err := NewLDAP(&config.Config{
+		LDAPURL:            "ldap://" + ln.Addr().String(),
+		LDAPBindDN:         "cn=admin," + testBaseDN,
+		LDAPBindPassword:   "admin",
+		LDAPBaseDN:         testBaseDN,
+		LDAPUserFilter:     "(|(mail={login})(uid={login}))",
+		LDAPEmailAttribute: "mail",
+		LDAPNameAttribute:  "cn",
+		LDAPGroupAttribute: "memberOf",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	l := startDirectory(t)
+	for _, login := range []string{"alice", "alice@example.com", "ALICE@example.com"} {
+		id, err := l.Authenticate(context.Background(), login, "secret")
+		if err != nil {
+			t.Fatalf("%s: %v", login, err)
+		}
+		want := &Identity{
+			Provider:      l.Provider(),
+			Subject:       "uid=alice,ou=people," + testBaseDN,
+			Email:         "alice@example.com",
+			EmailVerified: true,
+			Name:          "Alice Example",
+			Groups:        []string{"data-eng", "analysts"},
+		}
+		if !reflect.DeepEqual(id, want) {
+			t.Errorf("%s: got %+v, want %+v", login, id, want)
+		}
+	}
+}
+
+func TestLDAPRejects(t *testing.T) {
+	l := startDirectory(t)
+	tests := []struct{ name, login, password string }{
+		{"wrong password", "alice", "hunter2"},
+		{"unknown login", "carol", "secret"},
+		{"empty password", "alice", ""},
+		{"filter injection", "*", "secret"},
+		{"service account", "admin", "admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := l.Authenticate(context.Background(), tt.login, tt.password); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("err = %v, want ErrInvalidCredentials", err)
+			}
+		})
+	}
+}
+
+func TestLDAPServiceBindFails(t *testing.T) {
+	l := startDirectory(t)
+	l.bindPassword = "wrong"
+	_, err := l.Authenticate(context.Background(), "alice", "secret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("err = %v, want a directory error", err)
+	}
+}
+
+func TestLDAPGroupMapping(t *testing.T) {
+	l := startDirectory(t)
+	m, err := ParseGroupMapping("data-eng=1:member, analysts=1:viewer, analysts=2:admin, ops=3:admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := l.Authenticate(context.Background(), "alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Resolve(alice.Groups), map[int]string{1: "member", 2: "admin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("alice: got %v, want %v", got, want)
+	}
+	bob, err := l.Authenticate(context.Background(), "bob", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Resolve(bob.Groups); len(got) != 0 {
+		t.Errorf("bob has no groups, got %v", got)
+	}
+}
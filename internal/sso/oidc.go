@@ -0,0 +1,133 @@
+// Package sso implements external identity providers: OpenID Connect single
+// sign-on, LDAP password login and mapping of directory groups to team
+// memberships.
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"go-data-catalog/internal/config"
+)
+
+// Identity is a user as asserted by an external provider.
+type Identity struct {
+	Provider      string // e.g. "oidc:https://idp.example.com"
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// OIDC runs the authorization code flow with PKCE against one provider.
+// Discovery happens on first use, so the server starts even if the provider
+// is temporarily unreachable.
+type OIDC struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	groupsClaim  string
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDC returns nil if OIDC is not configured.
+func NewOIDC(cfg *config.Config) (*OIDC, error) {
+	if cfg.OIDCIssuerURL == "" {
+		return nil, nil
+	}
+	if cfg.OIDCClientID == "" || cfg.OIDCRedirectURL == "" {
+		return nil, errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required with OIDC_ISSUER_URL")
+	}
+	return &OIDC{
+		issuer:       cfg.OIDCIssuerURL,
+		clientID:     cfg.OIDCClientID,
+		clientSecret: cfg.OIDCClientSecret,
+		redirectURL:  cfg.OIDCRedirectURL,
+		scopes:       cfg.OIDCScopes,
+		groupsClaim:  cfg.OIDCGroupsClaim,
+	}, nil
+}
+
+func (o *OIDC) init(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.oauth == nil {
+		provider, err := oidc.NewProvider(ctx, o.issuer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("oidc discovery: %w", err)
+		}
+		o.oauth = &oauth2.Config{
+			ClientID:     o.clientID,
+			ClientSecret: o.clientSecret,
+			RedirectURL:  o.redirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       o.scopes,
+		}
+		o.verifier = provider.Verifier(&oidc.Config{ClientID: o.clientID})
+	}
+	return o.oauth, o.verifier, nil
+}
+
+// AuthCodeURL returns the provider URL to send the browser to.
+func (o *OIDC) AuthCodeURL(ctx context.Context, state, nonce, pkceVerifier string) (string, error) {
+	oauth, _, err := o.init(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(pkceVerifier)), nil
+}
+
+// Exchange redeems the authorization code and verifies the ID token.
+func (o *OIDC) Exchange(ctx context.Context, code, pkceVerifier, nonce string) (*Identity, error) {
+	oauth, verifier, err := o.init(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(pkceVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("oidc code exchange: %w", err)
+	}
+	rawID, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("oidc: no id_token in token response")
+	}
+	idToken, err := verifier.Verify(ctx, rawID)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("oidc: nonce mismatch")
+	}
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	id := &Identity{Provider: "oidc:" + o.issuer, Subject: idToken.Subject}
+	id.Email, _ = claims["email"].(string)
+	id.Name, _ = claims["name"].(string)
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		id.EmailVerified = v
+	case string: // some providers send "true"
+		id.EmailVerified = v == "true"
+	}
+	if groups, ok := claims[o.groupsClaim].([]any); ok {
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	}
+	return id, nil
+}
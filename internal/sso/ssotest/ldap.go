@@ -0,0 +1,241 @@
+package ssotest
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+type entry struct {
+	dn       string
+	password string
+	attrs    map[string][]string // lower-case attribute names
+}
+
+// Directory is a minimal in-memory LDAP server. It understands simple bind,
+// subtree search with and/or/not/equality/presence filters, and unbind;
+// nothing else. Users live under ou=people and carry memberOf values for
+// groups under ou=groups. Passwords are kept and compared in clear text.
+type Directory struct {
+	base string
+
+	mu      sync.RWMutex
+	entries []entry
+}
+
+// NewDirectory returns a directory for base with the service account
+// cn=admin,<base>.
+func NewDirectory(base, bindPassword string) *Directory {
+	d := &Directory{base: base}
+	d.entries = append(d.entries, entry{dn: "cn=admin," + base, password: bindPassword, attrs: map[string][]string{"cn": {"admin"}}})
+	return d
+}
+
+// AddUser adds a user given as uid:password:mail:name[:group1,group2].
+func (d *Directory) AddUser(spec string) error {
+	e, err := d.parseUser(spec)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.entries = append(d.entries, e)
+	d.mu.Unlock()
+	return nil
+}
+
+// Serve answers LDAP requests on ln until it is closed.
+func (d *Directory) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go d.serve(conn)
+	}
+}
+
+func (d *Directory) parseUser(spec string) (entry, error) {
+	parts := strings.SplitN(spec, ":", 5)
+	if len(parts) < 4 {
+		return entry{}, fmt.Errorf("invalid user %q: want uid:password:mail:name[:groups]", spec)
+	}
+	e := entry{
+		dn:       "uid=" + parts[0] + ",ou=people," + d.base,
+		password: parts[1],
+		attrs: map[string][]string{
+			"objectclass": {"inetOrgPerson"},
+			"uid":         {parts[0]},
+			"mail":        {parts[2]},
+			"cn":          {parts[3]},
+		},
+	}
+	if len(parts) == 5 {
+		for _, g := range strings.Split(parts[4], ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				e.attrs["memberof"] = append(e.attrs["memberof"], "cn="+g+",ou=groups,"+d.base)
+			}
+		}
+	}
+	return e, nil
+}
+
+func (d *Directory) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		p, err := ber.ReadPacket(conn)
+		if err != nil || len(p.Children) < 2 {
+			return
+		}
+		id, _ := p.Children[0].Value.(int64)
+		op := p.Children[1]
+		var replies []*ber.Packet
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			replies = []*ber.Packet{d.bind(op)}
+		case ldap.ApplicationSearchRequest:
+			replies = d.search(op)
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationExtendedRequest:
+			replies = []*ber.Packet{result(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "extended operations are not supported")}
+		default:
+			log.Printf("unsupported operation %d", op.Tag)
+			return
+		}
+		for _, r := range replies {
+			msg := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+			msg.AppendChild(r)
+			if _, err := conn.Write(msg.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (d *Directory) bind(op *ber.Packet) *ber.Packet {
+	if len(op.Children) < 3 {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultProtocolError, "malformed bind")
+	}
+	dn := op.Children[1].Data.String()
+	password := op.Children[2].Data.String()
+	if dn == "" && password == "" {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "") // anonymous
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, e := range d.entries {
+		if strings.EqualFold(e.dn, dn) && password != "" && e.password == password {
+			return result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+		}
+	}
+	return result(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+}
+
+func (d *Directory) search(op *ber.Packet) []*ber.Packet {
+	if len(op.Children) < 8 {
+		return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, "malformed search")}
+	}
+	base := strings.ToLower(op.Children[0].Data.String())
+	sizeLimit, _ := op.Children[3].Value.(int64)
+	filter := op.Children[6]
+	var want []string
+	for _, a := range op.Children[7].Children {
+		want = append(want, strings.ToLower(a.Data.String()))
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var replies []*ber.Packet
+	for _, e := range d.entries {
+		if !strings.HasSuffix(strings.ToLower(e.dn), base) || !matches(filter, e) {
+			continue
+		}
+		if sizeLimit > 0 && int64(len(replies)) == sizeLimit {
+			return append(replies, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded, ""))
+		}
+		replies = append(replies, searchEntry(e, want))
+	}
+	return append(replies, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, ""))
+}
+
+func matches(f *ber.Packet, e entry) bool {
+	switch f.Tag {
+	case ldap.FilterAnd:
+		for _, c := range f.Children {
+			if !matches(c, e) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, c := range f.Children {
+			if matches(c, e) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return len(f.Children) == 1 && !matches(f.Children[0], e)
+	case ldap.FilterEqualityMatch:
+		if len(f.Children) != 2 {
+			return false
+		}
+		for _, v := range e.attrs[strings.ToLower(f.Children[0].Data.String())] {
+			if strings.EqualFold(v, f.Children[1].Data.String()) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		attr := strings.ToLower(f.Data.String())
+		return attr == "objectclass" || len(e.attrs[attr]) > 0
+	}
+	return false
+}
+
+func searchEntry(e entry, want []string) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "DN"))
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range e.attrs {
+		if !wanted(want, name) {
+			continue
+		}
+		a := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		a.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "Value"))
+		}
+		a.AppendChild(set)
+		attrs.AppendChild(a)
+	}
+	p.AppendChild(attrs)
+	return p
+}
+
+func wanted(want []string, name string) bool {
+	if len(want) == 0 {
+		return true
+	}
+	for _, w := range want {
+		if w == "*" || w == name {
+			return true
+		}
+	}
+	return false
+}
+
+func result(tag ber.Tag, code uint16, msg string) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, msg, "diagnosticMessage"))
+	return p
+}
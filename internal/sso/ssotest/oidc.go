@@ -0,0 +1,240 @@
+// Package ssotest provides in-memory identity providers for tests and local
+// development: an OpenID Connect provider and an LDAP directory. Do not use
+// them anywhere real: they authenticate anybody as anyone.
+package ssotest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mock-oidc"
+
+type grant struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	email         string
+	name          string
+	groups        []string
+	expires       time.Time
+}
+
+// OIDCProvider is a minimal OpenID Connect provider. It supports discovery,
+// the authorization code flow with PKCE (S256) and RS256 ID tokens. Every
+// login is approved: the login page, or a POST to /authorize, chooses the
+// email, name and groups to assert.
+type OIDCProvider struct {
+	// Issuer is the provider URL as seen by the client. With httptest, set
+	// it to the server URL before the first request.
+	Issuer   string
+	ClientID string
+
+	// Defaults shown on the login page.
+	DefaultEmail, DefaultName, DefaultGroups string
+
+	key     *rsa.PrivateKey
+	handler http.Handler
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+// NewOIDCProvider returns a provider accepting clientID.
+func NewOIDCProvider(issuer, clientID string) (*OIDCProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	p := &OIDCProvider{Issuer: strings.TrimRight(issuer, "/"), ClientID: clientID, key: key, codes: map[string]grant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("GET /authorize", p.loginPage)
+	mux.HandleFunc("POST /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	p.handler = mux
+	return p, nil
+}
+
+func (p *OIDCProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.handler.ServeHTTP(w, r)
+}
+
+func (p *OIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile", "groups"},
+	})
+}
+
+func (p *OIDCProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []any{map[string]string{
+		"kty": "RSA", "use": "sig", "alg": "RS256", "kid": keyID,
+		"n": base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+var loginTmpl = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><head><meta charset="UTF-8"><title>Mock OIDC login</title></head>
+<body style="font-family: sans-serif; max-width: 420px; margin: 40px auto">
+<h2>Mock OIDC login</h2>
+<p>Client <b>{{.ClientID}}</b> asks you to sign in.</p>
+<form method="post">
+{{range $k, $v := .Query}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">
+{{end}}<p><label>Email<br><input name="email" value="{{.Email}}" size="40"></label></p>
+<p><label>Name<br><input name="name" value="{{.Name}}" size="40"></label></p>
+<p><label>Groups (comma-separated)<br><input name="groups" value="{{.Groups}}" size="40"></label></p>
+<button type="submit">Sign in</button>
+</form></body></html>`))
+
+func (p *OIDCProvider) loginPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if msg := p.checkAuthRequest(q); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = loginTmpl.Execute(w, map[string]any{
+		"ClientID": q.Get("client_id"), "Query": q,
+		"Email": p.DefaultEmail, "Name": p.DefaultName, "Groups": p.DefaultGroups,
+	})
+}
+
+func (p *OIDCProvider) checkAuthRequest(q url.Values) string {
+	switch {
+	case q.Get("response_type") != "code":
+		return "unsupported response_type"
+	case q.Get("client_id") != p.ClientID:
+		return "unknown client_id"
+	case q.Get("redirect_uri") == "":
+		return "redirect_uri is required"
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		return "PKCE with S256 is required"
+	}
+	return ""
+}
+
+func (p *OIDCProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f := r.PostForm
+	if msg := p.checkAuthRequest(f); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	var groups []string
+	for _, g := range strings.Split(f.Get("groups"), ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groups = append(groups, g)
+		}
+	}
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = grant{
+		clientID: f.Get("client_id"), redirectURI: f.Get("redirect_uri"), nonce: f.Get("nonce"),
+		codeChallenge: f.Get("code_challenge"), email: f.Get("email"), name: f.Get("name"), groups: groups,
+		expires: time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	target, err := url.Parse(f.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	q := target.Query()
+	q.Set("code", code)
+	q.Set("state", f.Get("state"))
+	target.RawQuery = q.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (p *OIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, _, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+	}
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, found := p.codes[code]
+	delete(p.codes, code) // codes are single-use
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		tokenError(w, "unsupported_grant_type")
+		return
+	case !found || time.Now().After(g.expires) || g.clientID != clientID || g.redirectURI != r.PostForm.Get("redirect_uri"):
+		tokenError(w, "invalid_grant")
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != g.codeChallenge:
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": p.Issuer, "aud": g.clientID, "sub": "mock|" + g.email,
+		"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+		"email": g.email, "email_verified": true, "name": g.name, "groups": g.groups,
+	}
+	if g.nonce != "" {
+		claims["nonce"] = g.nonce
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	t.Header["kid"] = keyID
+	idToken, err := t.SignedString(p.key)
+	if err != nil {
+		tokenError(w, "server_error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(), "token_type": "Bearer", "expires_in": 3600, "id_token": idToken,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
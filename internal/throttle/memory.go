@@ -0,0 +1,61 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pruneEvery is how many writes pass between sweeps of expired entries.
+const pruneEvery = 1000
+
+// MemoryStore keeps counters in process memory. Each instance of the server
+// counts on its own; use the Postgres store when running several.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+	writes  int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]Entry{}}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string, window time.Duration) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entries[key]
+	if time.Since(e.LastFailure) > window {
+		return Entry{}, nil
+	}
+	return e, nil
+}
+
+func (s *MemoryStore) Fail(ctx context.Context, key string, window time.Duration) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.writes++
+	if s.writes%pruneEvery == 0 {
+		for k, e := range s.entries {
+			if now.Sub(e.LastFailure) > window {
+				delete(s.entries, k)
+			}
+		}
+	}
+	e := s.entries[key]
+	if now.Sub(e.LastFailure) > window {
+		e = Entry{}
+	}
+	e.Failures++
+	e.LastFailure = now
+	s.entries[key] = e
+	return e, nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
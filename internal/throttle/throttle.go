@@ -0,0 +1,138 @@
+// Package throttle slows down password guessing. Failed attempts are counted
+// per account and per client IP; after a few free attempts every further
+// failure doubles the wait, and too many failures lock the key for a while.
+//
+// Counters live in a Store: MemoryStore for a single instance, or the
+// Postgres store so that all instances share them.
+package throttle
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-data-catalog/internal/config"
+)
+
+// Entry is the failure counter of one key.
+type Entry struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Store keeps failure counters. Failures older than window are forgotten.
+type Store interface {
+	Get(ctx context.Context, key string, window time.Duration) (Entry, error)
+	// Fail counts a failure and returns the updated entry.
+	Fail(ctx context.Context, key string, window time.Duration) (Entry, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// Policy decides how long a key waits after a number of failures.
+type Policy struct {
+	FreeAttempts    int           // failures without any delay
+	BaseDelay       time.Duration // delay after the first failure past FreeAttempts; doubles each time
+	MaxDelay        time.Duration
+	LockoutAfter    int // failures that lock the key; 0 disables lockout
+	LockoutDuration time.Duration
+}
+
+// New returns a limiter with the LOGIN_* settings of cfg.
+func New(cfg *config.Config, store Store) *Limiter {
+	base := time.Duration(cfg.LoginBackoffBase) * time.Second
+	maxDelay := time.Duration(cfg.LoginBackoffMax) * time.Second
+	lockout := time.Duration(cfg.LoginLockoutDuration) * time.Minute
+	account := Policy{FreeAttempts: cfg.LoginFreeAttempts, BaseDelay: base, MaxDelay: maxDelay, LockoutAfter: cfg.LoginLockoutAttempts, LockoutDuration: lockout}
+	ip := Policy{FreeAttempts: cfg.LoginIPFreeAttempts, BaseDelay: base, MaxDelay: maxDelay, LockoutAfter: cfg.LoginIPLockoutAttempts, LockoutDuration: lockout}
+	return NewLimiter(store, account, ip, time.Duration(cfg.LoginFailureWindow)*time.Minute)
+}
+
+// Delay is the wait after the given number of consecutive failures.
+func (p Policy) Delay(failures int) time.Duration {
+	if p.LockoutAfter > 0 && failures >= p.LockoutAfter {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxDelay)
+}
+
+// Limiter applies an account policy and an IP policy to login attempts.
+type Limiter struct {
+	store   Store
+	account Policy
+	ip      Policy
+	window  time.Duration
+}
+
+// NewLimiter returns a limiter that forgets failures after window of
+// inactivity. The window is at least as long as the lockouts.
+func NewLimiter(store Store, account, ip Policy, window time.Duration) *Limiter {
+	window = max(window, account.LockoutDuration, ip.LockoutDuration, account.MaxDelay, ip.MaxDelay)
+	return &Limiter{store: store, account: account, ip: ip, window: window}
+}
+
+// Wait returns how long the login or the IP must wait before the next
+// attempt; zero means the attempt may proceed.
+func (l *Limiter) Wait(ctx context.Context, login, ip string) (time.Duration, error) {
+	a, err := l.wait(ctx, accountKey(login), l.account)
+	if err != nil {
+		return 0, err
+	}
+	b, err := l.wait(ctx, ipKey(ip), l.ip)
+	return max(a, b), err
+}
+
+// Failed counts a failed attempt against both the login and the IP.
+func (l *Limiter) Failed(ctx context.Context, login, ip string) error {
+	if _, err := l.store.Fail(ctx, accountKey(login), l.window); err != nil {
+		return err
+	}
+	_, err := l.store.Fail(ctx, ipKey(ip), l.window)
+	return err
+}
+
+// Succeeded clears the failures of the login. The IP counter is left alone,
+// so an attacker can't reset it by logging into an account of their own.
+func (l *Limiter) Succeeded(ctx context.Context, login string) error {
+	return l.Unlock(ctx, login)
+}
+
+// Unlock clears the failures and any lockout of the login.
+func (l *Limiter) Unlock(ctx context.Context, login string) error {
+	return l.store.Reset(ctx, accountKey(login))
+}
+
+// LockedUntil returns when the login may try again, or the zero time.
+func (l *Limiter) LockedUntil(ctx context.Context, login string) (time.Time, error) {
+	e, err := l.store.Get(ctx, accountKey(login), l.window)
+	if err != nil || e.Failures == 0 {
+		return time.Time{}, err
+	}
+	until := e.LastFailure.Add(l.account.Delay(e.Failures))
+	if !until.After(time.Now()) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+func (l *Limiter) wait(ctx context.Context, key string, p Policy) (time.Duration, error) {
+	e, err := l.store.Get(ctx, key, l.window)
+	if err != nil || e.Failures == 0 {
+		return 0, err
+	}
+	return max(time.Until(e.LastFailure.Add(p.Delay(e.Failures))), 0), nil
+}
+
+func accountKey(login string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(login))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
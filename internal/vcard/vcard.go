@@ -0,0 +1,93 @@
+// Package vcard writes catalog contacts as vCard 3.0 (RFC 2426) cards that
+// address books and mail clients can import.
+package vcard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"go-data-catalog/internal/models"
+)
+
+// ContentType is the media type of a vCard file.
+const ContentType = "text/vcard; charset=utf-8"
+
+// maxLine is the line length in octets after which lines are folded.
+const maxLine = 75
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`)
+
+// Write writes one card per contact; org is the team name.
+func Write(w io.Writer, org string, contacts []models.Contact) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range contacts {
+		writeCard(bw, org, c)
+	}
+	return bw.Flush()
+}
+
+func writeCard(w *bufio.Writer, org string, c models.Contact) {
+	line := func(name, value string) {
+		writeFolded(w, name+":"+value)
+	}
+	line("BEGIN", "VCARD")
+	line("VERSION", "3.0")
+	line("UID", fmt.Sprintf("go-data-catalog-contact-%d", c.ID))
+	line("FN", escape(c.Name))
+	line("N", ";"+escape(c.Name)+";;;")
+	if c.Department != "" {
+		line("ORG", escape(org)+";"+escape(c.Department))
+	} else {
+		line("ORG", escape(org))
+	}
+	emails := map[string]bool{}
+	for _, ch := range c.Channels {
+		switch ch.Kind {
+		case models.ChannelEmail:
+			emails[strings.ToLower(ch.Value)] = true
+			line("EMAIL;TYPE=INTERNET", escape(ch.Value))
+		case models.ChannelPhone:
+			line("TEL;TYPE=WORK,VOICE", escape(ch.Value))
+		case models.ChannelTelegram:
+			line("X-SOCIALPROFILE;TYPE=telegram", "https://t.me/"+strings.TrimPrefix(ch.Value, "@"))
+		default: // slack, mattermost
+			line("X-SOCIALPROFILE;TYPE="+ch.Kind, escape(ch.Value))
+		}
+	}
+	if c.UserEmail != "" && !emails[strings.ToLower(c.UserEmail)] {
+		line("EMAIL;TYPE=INTERNET", escape(c.UserEmail))
+	}
+	if c.Availability != "" {
+		line("NOTE", escape(c.Availability))
+	}
+	if c.AvatarURL != "" {
+		line("PHOTO;VALUE=URI", c.AvatarURL)
+	}
+	line("REV", c.CreatedAt.UTC().Format("20060102T150405Z"))
+	line("END", "VCARD")
+}
+
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeFolded writes a content line, folding it at maxLine octets without
+// splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLine - 1 // the leading space counts
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,25 @@
+-- Personal access tokens and team service accounts
+
+-- A service account is a user without a password that belongs to one team
+-- (service_team_id); it acts through API tokens and its team_members role.
+ALTER TABLE IF EXISTS users
+  ADD COLUMN IF NOT EXISTS service_team_id INTEGER REFERENCES teams(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_users_service_team_id ON users(service_team_id);
+
+-- API tokens of users and service accounts. Only a SHA-256 hash is stored;
+-- prefix is the start of the token, shown in listings to tell tokens apart.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(64),
+    revoked_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);
@@ -0,0 +1,25 @@
+-- TOTP two-factor authentication
+
+-- enabled_at is NULL while enrollment is not confirmed. last_used_step is the
+-- last accepted 30-second time step; older or equal steps are rejected so a
+-- code cannot be replayed.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled_at TIMESTAMP,
+    last_used_step BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One-time recovery codes; only a SHA-256 hash is stored.
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Team owners may require 2FA from all human members.
+ALTER TABLE IF EXISTS teams
+  ADD COLUMN IF NOT EXISTS require_2fa BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,27 @@
+-- Email verification and password reset
+
+-- Accounts that existed before verification was introduced are treated as
+-- verified, so REQUIRE_EMAIL_VERIFICATION does not lock them out. This runs
+-- only when the column is added, not on re-runs of the migration.
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'users' AND column_name = 'email_verified_at'
+  ) THEN
+    ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+    UPDATE users SET email_verified_at = created_at WHERE service_team_id IS NULL;
+  END IF;
+END $$;
+
+-- Single-use tokens sent by email. Only a SHA-256 hash is stored.
+-- purpose: 'verify_email' or 'reset_password'.
+CREATE TABLE IF NOT EXISTS user_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens(user_id, purpose);
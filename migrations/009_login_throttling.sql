@@ -0,0 +1,26 @@
+-- Brute-force protection for password login
+
+-- Failed-attempt counters shared by all server instances (LOGIN_THROTTLE_STORE=postgres).
+-- key: 'account:<login>' or 'ip:<address>'.
+CREATE TABLE IF NOT EXISTS login_throttle (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    last_failure_at TIMESTAMPTZ NOT NULL
+);
+
+-- Login history. user_id is NULL when the login matched no account.
+-- method: 'password', '2fa' or 'oidc'.
+-- result: 'ok', 'invalid_credentials', 'invalid_code', 'throttled', 'mfa_required',
+--         'deactivated', 'email_not_verified'.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    login VARCHAR(255) NOT NULL,
+    ip VARCHAR(64) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    method VARCHAR(16) NOT NULL,
+    result VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id ON login_attempts(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_login ON login_attempts(lower(login), created_at DESC);
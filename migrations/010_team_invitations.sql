@@ -0,0 +1,19 @@
+-- Team invitations: links that add the holder to a team with a preset role.
+-- Only a SHA-256 hash of the link token is stored.
+-- email: if set, only the user with this (verified) email may accept.
+-- max_uses: NULL for a link anyone may use until it expires; email-bound
+--           invitations are single-use.
+CREATE TABLE IF NOT EXISTS team_invitations (
+    id SERIAL PRIMARY KEY,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin','member','viewer')),
+    email VARCHAR(255),
+    max_uses INTEGER CHECK (max_uses > 0),
+    use_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_team_invitations_team_id ON team_invitations(team_id);
@@ -0,0 +1,35 @@
+-- Join request messages, cancellation and in-app notifications
+
+ALTER TABLE join_requests ADD COLUMN IF NOT EXISTS message TEXT NOT NULL DEFAULT '';
+ALTER TABLE join_requests ADD COLUMN IF NOT EXISTS decision_comment TEXT NOT NULL DEFAULT '';
+
+-- Requesters may cancel their pending requests.
+ALTER TABLE join_requests DROP CONSTRAINT IF EXISTS join_requests_status_check;
+ALTER TABLE join_requests ADD CONSTRAINT join_requests_status_check
+    CHECK (status IN ('pending','approved','rejected','cancelled'));
+
+-- At most one pending request per user and team: keep the newest.
+UPDATE join_requests jr SET status = 'cancelled', processed_at = NOW()
+WHERE jr.status = 'pending' AND EXISTS (
+    SELECT 1 FROM join_requests newer
+    WHERE newer.team_id = jr.team_id AND newer.user_id = jr.user_id
+      AND newer.status = 'pending' AND newer.id > jr.id
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_join_requests_pending
+    ON join_requests(team_id, user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_join_requests_user_id ON join_requests(user_id);
+
+-- In-app notifications.
+-- kind: 'join_request' (to team owners/admins) or 'join_request_decided' (to the requester).
+-- subject_id: id of the related object, e.g. the join request.
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    team_id INTEGER REFERENCES teams(id) ON DELETE CASCADE,
+    subject_id INTEGER,
+    message TEXT NOT NULL,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at DESC);
@@ -0,0 +1,12 @@
+-- Team visibility and join policy
+-- visibility: 'public' (listed in search), 'discoverable' (found only by its
+--             exact name), 'hidden' (not found at all; join by invitation).
+-- join_policy: 'open' (join requests are approved at once), 'approval'
+--              (an owner or admin decides), 'invite_only' (no join requests).
+-- public_catalog: any logged-in user may read the artifacts (public teams only).
+-- Existing teams keep the previous behaviour: public, approval.
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public','discoverable','hidden'));
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS join_policy VARCHAR(20) NOT NULL DEFAULT 'approval'
+    CHECK (join_policy IN ('open','approval','invite_only'));
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS public_catalog BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,19 @@
+-- Team lifecycle: archiving and deletion with a restorable snapshot.
+-- archived_at: an archived team is read-only and not found by search.
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- team_snapshots keep a full export of every deleted team (settings,
+-- memberships, contacts, artifacts and fields) so that a system admin can
+-- restore it. team_id is the id the team had; it has no foreign key because
+-- the team no longer exists.
+CREATE TABLE IF NOT EXISTS team_snapshots (
+    id SERIAL PRIMARY KEY,
+    team_id INTEGER NOT NULL,
+    team_name VARCHAR(255) NOT NULL,
+    data JSONB NOT NULL,
+    deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    restored_at TIMESTAMPTZ,
+    restored_team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_team_snapshots_created_at ON team_snapshots(created_at DESC);
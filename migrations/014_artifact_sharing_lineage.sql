@@ -0,0 +1,37 @@
+-- Cross-team sharing of artifacts and lineage between artifacts.
+-- artifact_shares: the owning team publishes an artifact read-only to one
+-- team (target_team_id) or to every team (target_team_id NULL). Revoked
+-- shares are kept for the record.
+CREATE TABLE IF NOT EXISTS artifact_shares (
+    id SERIAL PRIMARY KEY,
+    artifact_id INTEGER NOT NULL REFERENCES artifacts(id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    target_team_id INTEGER REFERENCES teams(id) ON DELETE CASCADE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMPTZ,
+    CHECK (target_team_id IS NULL OR target_team_id <> team_id)
+);
+CREATE INDEX IF NOT EXISTS idx_artifact_shares_team_id ON artifact_shares(team_id);
+CREATE INDEX IF NOT EXISTS idx_artifact_shares_target ON artifact_shares(target_team_id) WHERE revoked_at IS NULL;
+-- one active share per artifact and target
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_artifact_shares_active
+    ON artifact_shares(artifact_id, COALESCE(target_team_id, 0)) WHERE revoked_at IS NULL;
+
+-- artifact_lineage: downstream_artifact_id is derived from
+-- upstream_artifact_id. The edge belongs to the downstream team. The upstream
+-- name and team are copied so that an edge to an artifact that was deleted
+-- (upstream_artifact_id becomes NULL) or is no longer shared still says what
+-- it pointed to.
+CREATE TABLE IF NOT EXISTS artifact_lineage (
+    id SERIAL PRIMARY KEY,
+    upstream_artifact_id INTEGER REFERENCES artifacts(id) ON DELETE SET NULL,
+    upstream_team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL,
+    upstream_name VARCHAR(255) NOT NULL,
+    downstream_artifact_id INTEGER NOT NULL REFERENCES artifacts(id) ON DELETE CASCADE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (upstream_artifact_id, downstream_artifact_id),
+    CHECK (upstream_artifact_id <> downstream_artifact_id)
+);
+CREATE INDEX IF NOT EXISTS idx_artifact_lineage_downstream ON artifact_lineage(downstream_artifact_id);
@@ -0,0 +1,74 @@
+-- Organizations: a tenant layer above teams.
+-- allowed_auth_methods: sign-in methods the members may use ('password',
+--                       'ldap', 'oidc'); a user may sign in with a method
+--                       allowed by at least one of their organizations.
+-- auto_join: new accounts become members automatically.
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    allowed_auth_methods TEXT[] NOT NULL DEFAULT '{password,ldap,oidc}',
+    auto_join BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Organization memberships; org admins manage the organization, its users
+-- and the snapshots of its deleted teams.
+CREATE TABLE IF NOT EXISTS org_members (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('admin','member')),
+    joined_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_org_members_user_id ON org_members(user_id);
+
+-- Artifact types the teams of an organization may use.
+CREATE TABLE IF NOT EXISTS org_artifact_types (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (org_id, name)
+);
+
+-- Existing data moves into a default organization that everyone joins, with
+-- the system admins as its admins and the previous artifact types.
+INSERT INTO organizations (name, description, auto_join)
+SELECT 'Default', 'Created by the migration to organizations', TRUE
+WHERE NOT EXISTS (SELECT 1 FROM organizations);
+
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS org_id INTEGER REFERENCES organizations(id);
+UPDATE teams SET org_id = (SELECT MIN(id) FROM organizations) WHERE org_id IS NULL;
+ALTER TABLE teams ALTER COLUMN org_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_teams_org_id ON teams(org_id);
+
+INSERT INTO org_members (org_id, user_id, role)
+SELECT (SELECT MIN(id) FROM organizations), id, CASE WHEN system_role = 'admin' THEN 'admin' ELSE 'member' END
+FROM users WHERE service_team_id IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO org_artifact_types (org_id, name, description)
+SELECT (SELECT MIN(id) FROM organizations), v.name, v.description
+FROM (VALUES ('table', 'таблица БД'), ('view', 'представление'), ('procedure', 'процедура'), ('function', 'функция'),
+             ('index', 'индекс'), ('dataset', 'датасет'), ('api', 'API endpoint'), ('file', 'файл')) AS v(name, description)
+ON CONFLICT DO NOTHING;
+
+-- Snapshots are restored into the organization the team belonged to.
+ALTER TABLE team_snapshots ADD COLUMN IF NOT EXISTS org_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE team_snapshots SET org_id = (SELECT MIN(id) FROM organizations) WHERE org_id IS NULL;
+
+-- Team members always belong to the team's organization, however they
+-- joined (request, invitation, SSO group, snapshot restore).
+CREATE OR REPLACE FUNCTION team_member_joins_org() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO org_members (org_id, user_id)
+    SELECT t.org_id, NEW.user_id FROM teams t, users u
+    WHERE t.id = NEW.team_id AND u.id = NEW.user_id AND u.service_team_id IS NULL
+    ON CONFLICT DO NOTHING;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS team_members_join_org ON team_members;
+CREATE TRIGGER team_members_join_org AFTER INSERT ON team_members
+    FOR EACH ROW EXECUTE FUNCTION team_member_joins_org();
@@ -0,0 +1,22 @@
+-- A contact may stand for a user account of the team; each account is linked
+-- to at most one contact per team.
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_contacts_team_user ON contacts(team_id, user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_contacts_user_id ON contacts(user_id) WHERE user_id IS NOT NULL;
+
+-- Artifact owners: contacts responsible for an artifact, one row per role.
+-- artifacts.developer_id is kept for compatibility and mirrors the first
+-- technical owner.
+CREATE TABLE IF NOT EXISTS artifact_owners (
+    artifact_id INTEGER NOT NULL REFERENCES artifacts(id) ON DELETE CASCADE,
+    contact_id INTEGER NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    role VARCHAR(30) NOT NULL CHECK (role IN ('technical_owner','business_owner','data_steward')),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (artifact_id, contact_id, role)
+);
+CREATE INDEX IF NOT EXISTS idx_artifact_owners_contact_id ON artifact_owners(contact_id);
+
+INSERT INTO artifact_owners (artifact_id, contact_id, role)
+SELECT id, developer_id, 'technical_owner' FROM artifacts WHERE developer_id IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,21 @@
+-- Contact directory: department, availability note, avatar and typed
+-- channels. contacts.telegram_contact is kept for compatibility and mirrors
+-- the first Telegram channel.
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS department VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS availability VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(500) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_contacts_team_department ON contacts(team_id, lower(department));
+
+CREATE TABLE IF NOT EXISTS contact_channels (
+    id SERIAL PRIMARY KEY,
+    contact_id INTEGER NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('email','phone','telegram','slack','mattermost')),
+    value VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (contact_id, kind, value)
+);
+CREATE INDEX IF NOT EXISTS idx_contact_channels_contact_id ON contact_channels(contact_id);
+
+INSERT INTO contact_channels (contact_id, kind, value)
+SELECT id, 'telegram', telegram_contact FROM contacts WHERE COALESCE(telegram_contact, '') <> ''
+ON CONFLICT DO NOTHING;
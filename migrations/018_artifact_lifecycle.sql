@@ -0,0 +1,12 @@
+-- Artifact lifecycle: draft -> active -> deprecated -> retired.
+-- deprecated_at, replacement_id and deprecation_reason describe a deprecated
+-- or retired artifact and are empty otherwise. The replacement may belong to
+-- another team that shares it.
+ALTER TABLE artifacts ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('draft','active','deprecated','retired'));
+ALTER TABLE artifacts ADD COLUMN IF NOT EXISTS deprecated_at TIMESTAMPTZ;
+ALTER TABLE artifacts ADD COLUMN IF NOT EXISTS replacement_id INTEGER REFERENCES artifacts(id) ON DELETE SET NULL;
+ALTER TABLE artifacts ADD COLUMN IF NOT EXISTS deprecation_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE artifacts DROP CONSTRAINT IF EXISTS artifacts_replacement_not_self;
+ALTER TABLE artifacts ADD CONSTRAINT artifacts_replacement_not_self CHECK (replacement_id <> id);
+CREATE INDEX IF NOT EXISTS idx_artifacts_status ON artifacts(team_id, status) WHERE status IN ('deprecated','retired');
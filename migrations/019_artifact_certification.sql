@@ -0,0 +1,52 @@
+-- Certification of trusted ("gold") artifacts by a team admin or a data
+-- steward of the artifact, until expires_at. A change of the fields or the
+-- description afterwards sets review_required_at, however it is made; the
+-- artifact counts as certified again only once it is re-certified.
+CREATE TABLE IF NOT EXISTS artifact_certifications (
+    artifact_id INTEGER PRIMARY KEY REFERENCES artifacts(id) ON DELETE CASCADE,
+    certified_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    certified_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    note TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    review_required_at TIMESTAMPTZ,
+    review_reason TEXT NOT NULL DEFAULT ''
+);
+
+-- flag_certification_review flags the certification of an artifact for
+-- review; the first reason is kept.
+CREATE OR REPLACE FUNCTION flag_certification_review(artifact INTEGER, reason TEXT) RETURNS void AS $$
+BEGIN
+    UPDATE artifact_certifications
+    SET review_required_at = NOW(), review_reason = reason
+    WHERE artifact_id = artifact AND review_required_at IS NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION artifact_fields_flag_certification() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM flag_certification_review(NEW.artifact_id, 'field ' || NEW.field_name || ' added');
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM flag_certification_review(OLD.artifact_id, 'field ' || OLD.field_name || ' removed');
+    ELSIF (OLD.field_name, OLD.data_type, OLD.description, OLD.is_pk) IS DISTINCT FROM (NEW.field_name, NEW.data_type, NEW.description, NEW.is_pk) THEN
+        PERFORM flag_certification_review(NEW.artifact_id, 'field ' || OLD.field_name || ' changed');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS artifact_fields_flag_certification ON artifact_fields;
+CREATE TRIGGER artifact_fields_flag_certification AFTER INSERT OR UPDATE OR DELETE ON artifact_fields
+    FOR EACH ROW EXECUTE FUNCTION artifact_fields_flag_certification();
+
+CREATE OR REPLACE FUNCTION artifacts_flag_certification() RETURNS trigger AS $$
+BEGIN
+    PERFORM flag_certification_review(NEW.id, 'description changed');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS artifacts_flag_certification ON artifacts;
+CREATE TRIGGER artifacts_flag_certification AFTER UPDATE OF description ON artifacts
+    FOR EACH ROW WHEN (OLD.description IS DISTINCT FROM NEW.description)
+    EXECUTE FUNCTION artifacts_flag_certification();
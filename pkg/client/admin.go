@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// UserFilter selects users in ListUsers. Active filters by status when set.
+type UserFilter struct {
+	Search string
+	Active *bool
+	ListOptions
+}
+
+// ListAllOrgs returns every organization, with the current user's role
+// where they are a member. Requires the system admin role.
+func (c *Client) ListAllOrgs(ctx context.Context) ([]Organization, error) {
+	var res []Organization
+	err := c.call(ctx, http.MethodGet, "/admin/orgs", nil, &res)
+	return res, err
+}
+
+// AppointOrgAdmin makes the user with the email an admin of the
+// organization, adding them if needed. Requires the system admin role;
+// system admins manage users and teams only as org admins.
+func (c *Client) AppointOrgAdmin(ctx context.Context, orgID int, email string) (*OrgMember, error) {
+	var m OrgMember
+	if err := c.call(ctx, http.MethodPut, fmt.Sprintf("/admin/orgs/%d/admins", orgID), map[string]string{"email": email}, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SetSystemRole sets the system role of a user: "user" or "admin".
+func (c *Client) SetSystemRole(ctx context.Context, id int, role string) (*User, error) {
+	var u User
+	body := map[string]string{"system_role": role}
+	if err := c.call(ctx, http.MethodPut, fmt.Sprintf("/admin/users/%d/role", id), body, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (c *Client) listUsers(ctx context.Context, prefix string, f UserFilter) ([]User, error) {
+	v := url.Values{}
+	if f.Search != "" {
+		v.Set("search", f.Search)
+	}
+	if f.Active != nil {
+		v.Set("active", strconv.FormatBool(*f.Active))
+	}
+	if f.Limit > 0 {
+		v.Set("limit", strconv.Itoa(f.Limit))
+	}
+	if f.Offset > 0 {
+		v.Set("offset", strconv.Itoa(f.Offset))
+	}
+	path := prefix + "/users"
+	if len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+	var res []User
+	err := c.call(ctx, http.MethodGet, path, nil, &res)
+	return res, err
+}
+
+// userCall sends a request without a body to prefix/users/id+suffix.
+func (c *Client) userCall(ctx context.Context, method, prefix string, id int, suffix string) (*User, error) {
+	var u User
+	if err := c.call(ctx, method, fmt.Sprintf("%s/users/%d%s", prefix, id, suffix), nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (c *Client) loginHistory(ctx context.Context, prefix string, id int, opts ListOptions) (*LoginHistory, error) {
+	v := url.Values{}
+	if opts.Limit > 0 {
+		v.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		v.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	path := fmt.Sprintf("%s/users/%d/login-attempts", prefix, id)
+	if len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+	var h LoginHistory
+	if err := c.call(ctx, http.MethodGet, path, nil, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (c *Client) listTeamSnapshots(ctx context.Context, prefix string, opts ListOptions) ([]TeamSnapshot, error) {
+	v := url.Values{}
+	if opts.Limit > 0 {
+		v.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		v.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	path := prefix + "/team-snapshots"
+	if len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+	var res []TeamSnapshot
+	err := c.call(ctx, http.MethodGet, path, nil, &res)
+	return res, err
+}
+
+func (c *Client) getTeamSnapshot(ctx context.Context, prefix string, id int) (*TeamSnapshot, error) {
+	var s TeamSnapshot
+	if err := c.call(ctx, http.MethodGet, fmt.Sprintf("%s/team-snapshots/%d", prefix, id), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (c *Client) restoreTeamSnapshot(ctx context.Context, prefix string, id int, name string) (*Team, error) {
+	var body any
+	if name != "" {
+		body = map[string]string{"name": name}
+	}
+	var t Team
+	if err := c.call(ctx, http.MethodPost, fmt.Sprintf("%s/team-snapshots/%d/restore", prefix, id), body, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name,omitempty"`
+}
+
+// Register creates an account and authenticates the client as the new user.
+func (c *Client) Register(ctx context.Context, req RegisterRequest) (*AuthResponse, error) {
+	var res AuthResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/register", false, req, &res); err != nil {
+		return nil, err
+	}
+	if !res.VerificationRequired {
+		c.setTokens(res.Token, res.RefreshToken)
+	}
+	return &res, nil
+}
+
+// VerifyEmail confirms an email address with the token from the
+// verification email.
+func (c *Client) VerifyEmail(ctx context.Context, token string) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/auth/verify-email", false, map[string]string{"token": token}, nil)
+}
+
+// ResendVerification asks for a new verification email. The server answers
+// the same way for unknown addresses.
+func (c *Client) ResendVerification(ctx context.Context, email string) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/auth/verify-email/resend", false, map[string]string{"email": email}, nil)
+}
+
+// ForgotPassword asks for a password reset email. The server answers the
+// same way for unknown addresses.
+func (c *Client) ForgotPassword(ctx context.Context, email string) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/auth/password/forgot", false, map[string]string{"email": email}, nil)
+}
+
+// ResetPassword sets a new password with the token from the reset email.
+// All sessions of the user are revoked; log in again afterwards.
+func (c *Client) ResetPassword(ctx context.Context, token, password string) error {
+	body := map[string]string{"token": token, "password": password}
+	return c.do(ctx, http.MethodPost, "/api/v1/auth/password/reset", false, body, nil)
+}
+
+// Login authenticates the client. Credentials passed here are not stored;
+// use WithCredentials for automatic re-login. If the account has two-factor
+// authentication enabled, the result has MFARequired set and the client is
+// not authenticated until LoginTwoFactor.
+func (c *Client) Login(ctx context.Context, email, password string) (*AuthResponse, error) {
+	var res AuthResponse
+	body := map[string]string{"email": email, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/login", false, body, &res); err != nil {
+		return nil, err
+	}
+	if !res.MFARequired {
+		c.setTokens(res.Token, res.RefreshToken)
+	}
+	return &res, nil
+}
+
+// LoginTwoFactor finishes a login that returned MFARequired, with a TOTP
+// code or a recovery code.
+func (c *Client) LoginTwoFactor(ctx context.Context, mfaToken, code string) (*AuthResponse, error) {
+	var res AuthResponse
+	body := map[string]string{"mfa_token": mfaToken, "code": code}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/login/2fa", false, body, &res); err != nil {
+		return nil, err
+	}
+	c.setTokens(res.Token, res.RefreshToken)
+	return &res, nil
+}
+
+// Refresh exchanges the current refresh token for a new token pair. A refresh
+// token works once; the server revokes the session if it is reused.
+func (c *Client) Refresh(ctx context.Context) (*AuthResponse, error) {
+	var res AuthResponse
+	body := map[string]string{"refresh_token": c.RefreshToken()}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/refresh", false, body, &res); err != nil {
+		return nil, err
+	}
+	c.setTokens(res.Token, res.RefreshToken)
+	return &res, nil
+}
+
+// Logout revokes the current session on the server and forgets its tokens.
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.call(ctx, http.MethodPost, "/auth/logout", nil, nil); err != nil {
+		return err
+	}
+	c.setTokens("", "")
+	return nil
+}
+
+// LogoutAll revokes every session of the current user, on all devices.
+func (c *Client) LogoutAll(ctx context.Context) error {
+	if err := c.call(ctx, http.MethodPost, "/auth/logout-all", nil, nil); err != nil {
+		return err
+	}
+	c.setTokens("", "")
+	return nil
+}
+
+// LinkDirectory links a directory (LDAP) account to the current user, who
+// proves it with the directory login and password. Afterwards that account
+// logs in to this user.
+func (c *Client) LinkDirectory(ctx context.Context, login, password string) error {
+	return c.call(ctx, http.MethodPost, "/me/identities/ldap", map[string]string{"login": login, "password": password}, nil)
+}
@@ -0,0 +1,321 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultPageSize is the page size used by the Artifacts and Contacts iterators.
+const DefaultPageSize = 100
+
+// ListOptions selects a page of a list endpoint. Zero Limit returns all items.
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+func (o *ListOptions) query() string {
+	if o == nil || (o.Limit == 0 && o.Offset == 0) {
+		return ""
+	}
+	v := url.Values{}
+	if o.Limit > 0 {
+		v.Set("limit", fmt.Sprint(o.Limit))
+	}
+	if o.Offset > 0 {
+		v.Set("offset", fmt.Sprint(o.Offset))
+	}
+	return "?" + v.Encode()
+}
+
+// withQuery adds filter parameters to a path that may already have a query.
+func withQuery(path string, v url.Values) string {
+	if len(v) == 0 {
+		return path
+	}
+	if strings.Contains(path, "?") {
+		return path + "&" + v.Encode()
+	}
+	return path + "?" + v.Encode()
+}
+
+// paginate walks a limit/offset list endpoint page by page.
+func paginate[T any](ctx context.Context, fetch func(context.Context, *ListOptions) ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		opts := &ListOptions{Limit: DefaultPageSize}
+		for {
+			page, err := fetch(ctx, opts)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(page) < opts.Limit {
+				return
+			}
+			opts.Offset += len(page)
+		}
+	}
+}
+
+func teamPath(teamID int, format string, args ...any) string {
+	return fmt.Sprintf("/teams/%d", teamID) + fmt.Sprintf(format, args...)
+}
+
+// ListArtifacts returns the team's artifacts, newest first.
+func (c *Client) ListArtifacts(ctx context.Context, teamID int, opts *ListOptions) ([]Artifact, error) {
+	var res []Artifact
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/artifacts")+opts.query(), nil, &res)
+	return res, err
+}
+
+// Artifacts iterates over all team artifacts, fetching them page by page.
+func (c *Client) Artifacts(ctx context.Context, teamID int) iter.Seq2[Artifact, error] {
+	return paginate(ctx, func(ctx context.Context, o *ListOptions) ([]Artifact, error) {
+		return c.ListArtifacts(ctx, teamID, o)
+	})
+}
+
+func (c *Client) GetArtifact(ctx context.Context, teamID, id int) (*Artifact, error) {
+	var a Artifact
+	if err := c.call(ctx, http.MethodGet, teamPath(teamID, "/artifacts/%d", id), nil, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (c *Client) CreateArtifact(ctx context.Context, teamID int, a Artifact) (*Artifact, error) {
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/artifacts"), a, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (c *Client) UpdateArtifact(ctx context.Context, teamID, id int, a Artifact) (*Artifact, error) {
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/artifacts/%d", id), a, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (c *Client) DeleteArtifact(ctx context.Context, teamID, id int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/artifacts/%d", id), nil, nil)
+}
+
+// RenderArtifact generates a definition of the artifact, see the Format constants.
+func (c *Client) RenderArtifact(ctx context.Context, teamID, id int, format string) (*RenderResult, error) {
+	var res RenderResult
+	if err := c.call(ctx, http.MethodGet, teamPath(teamID, "/artifacts/%d/render?format=%s", id, url.QueryEscape(format)), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) ListFields(ctx context.Context, teamID, artifactID int) ([]ArtifactField, error) {
+	var res []ArtifactField
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/artifacts/%d/fields", artifactID), nil, &res)
+	return res, err
+}
+
+func (c *Client) CreateField(ctx context.Context, teamID, artifactID int, f ArtifactField) (*ArtifactField, error) {
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/artifacts/%d/fields", artifactID), f, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (c *Client) GetField(ctx context.Context, teamID, id int) (*ArtifactField, error) {
+	var f ArtifactField
+	if err := c.call(ctx, http.MethodGet, teamPath(teamID, "/fields/%d", id), nil, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (c *Client) UpdateField(ctx context.Context, teamID, id int, f ArtifactField) (*ArtifactField, error) {
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/fields/%d", id), f, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (c *Client) DeleteField(ctx context.Context, teamID, id int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/fields/%d", id), nil, nil)
+}
+
+// ListContacts returns the team's contacts, newest first.
+func (c *Client) ListContacts(ctx context.Context, teamID int, opts *ListOptions) ([]Contact, error) {
+	var res []Contact
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/contacts")+opts.query(), nil, &res)
+	return res, err
+}
+
+// Contacts iterates over all team contacts, fetching them page by page.
+func (c *Client) Contacts(ctx context.Context, teamID int) iter.Seq2[Contact, error] {
+	return paginate(ctx, func(ctx context.Context, o *ListOptions) ([]Contact, error) {
+		return c.ListContacts(ctx, teamID, o)
+	})
+}
+
+// SearchContacts returns the team's contacts matching q (name, department,
+// availability note, channels or linked email) and, when not empty, the
+// department.
+func (c *Client) SearchContacts(ctx context.Context, teamID int, q, department string, opts *ListOptions) ([]Contact, error) {
+	v := url.Values{}
+	if q != "" {
+		v.Set("q", q)
+	}
+	if department != "" {
+		v.Set("department", department)
+	}
+	var res []Contact
+	err := c.call(ctx, http.MethodGet, withQuery(teamPath(teamID, "/contacts")+opts.query(), v), nil, &res)
+	return res, err
+}
+
+// ContactVCard returns a contact as a vCard 3.0 file.
+func (c *Client) ContactVCard(ctx context.Context, teamID, id int) ([]byte, error) {
+	var data []byte
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/contacts/%d/vcard", id), nil, &data)
+	return data, err
+}
+
+// DirectoryVCard returns all contacts of the team as one vCard 3.0 file.
+func (c *Client) DirectoryVCard(ctx context.Context, teamID int) ([]byte, error) {
+	var data []byte
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/contacts.vcf"), nil, &data)
+	return data, err
+}
+
+func (c *Client) GetContact(ctx context.Context, teamID, id int) (*Contact, error) {
+	var ct Contact
+	if err := c.call(ctx, http.MethodGet, teamPath(teamID, "/contacts/%d", id), nil, &ct); err != nil {
+		return nil, err
+	}
+	return &ct, nil
+}
+
+func (c *Client) CreateContact(ctx context.Context, teamID int, ct Contact) (*Contact, error) {
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/contacts"), ct, &ct); err != nil {
+		return nil, err
+	}
+	return &ct, nil
+}
+
+func (c *Client) UpdateContact(ctx context.Context, teamID, id int, ct Contact) (*Contact, error) {
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/contacts/%d", id), ct, &ct); err != nil {
+		return nil, err
+	}
+	return &ct, nil
+}
+
+func (c *Client) DeleteContact(ctx context.Context, teamID, id int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/contacts/%d", id), nil, nil)
+}
+
+// SetArtifactStatus changes the lifecycle status of an artifact. Deprecating
+// or retiring it notifies the teams of artifacts derived from it.
+func (c *Client) SetArtifactStatus(ctx context.Context, teamID, id int, s ArtifactStatus) (*Artifact, error) {
+	var a Artifact
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/artifacts/%d/status", id), s, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// DeprecatedReport lists the team's deprecated and retired artifacts that
+// draft or active artifacts are still derived from.
+func (c *Client) DeprecatedReport(ctx context.Context, teamID int) ([]DeprecatedInUse, error) {
+	var res []DeprecatedInUse
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/reports/deprecated"), nil, &res)
+	return res, err
+}
+
+// Certification returns the certification of an artifact; an APIError with
+// status 404 means it is not certified.
+func (c *Client) Certification(ctx context.Context, teamID, artifactID int) (*Certification, error) {
+	var cert Certification
+	if err := c.call(ctx, http.MethodGet, teamPath(teamID, "/artifacts/%d/certification", artifactID), nil, &cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// Certify certifies an artifact until expiresAt, or renews its
+// certification. Team admins certify any artifact, other members those they
+// are a data steward of.
+func (c *Client) Certify(ctx context.Context, teamID, artifactID int, note string, expiresAt time.Time) (*Certification, error) {
+	var cert Certification
+	body := map[string]any{"note": note, "expires_at": expiresAt}
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/artifacts/%d/certification", artifactID), body, &cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// Uncertify removes the certification of an artifact.
+func (c *Client) Uncertify(ctx context.Context, teamID, artifactID int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/artifacts/%d/certification", artifactID), nil, nil)
+}
+
+// Certifications lists the certifications of the team's artifacts, those to
+// review or renew first; state filters by state when not empty.
+func (c *Client) Certifications(ctx context.Context, teamID int, state string) ([]Certification, error) {
+	v := url.Values{}
+	if state != "" {
+		v.Set("state", state)
+	}
+	var res []Certification
+	err := c.call(ctx, http.MethodGet, withQuery(teamPath(teamID, "/certifications"), v), nil, &res)
+	return res, err
+}
+
+// ArtifactOwners returns the owners of an artifact, technical owners first.
+func (c *Client) ArtifactOwners(ctx context.Context, teamID, artifactID int) ([]ArtifactOwner, error) {
+	var res []ArtifactOwner
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/artifacts/%d/owners", artifactID), nil, &res)
+	return res, err
+}
+
+// SetArtifactOwners replaces the owners of an artifact. Only ContactID and
+// Role of each owner are sent; the first technical owner becomes the
+// artifact's DeveloperID.
+func (c *Client) SetArtifactOwners(ctx context.Context, teamID, artifactID int, owners []ArtifactOwner) ([]ArtifactOwner, error) {
+	var res []ArtifactOwner
+	body := map[string][]ArtifactOwner{"owners": owners}
+	err := c.call(ctx, http.MethodPut, teamPath(teamID, "/artifacts/%d/owners", artifactID), body, &res)
+	return res, err
+}
+
+// ReassignContact hands every artifact owned by contact id over to another
+// contact of the team and returns the number of artifacts concerned.
+func (c *Client) ReassignContact(ctx context.Context, teamID, id, toContactID int) (int, error) {
+	var res struct {
+		Reassigned int `json:"reassigned"`
+	}
+	body := map[string]int{"to_contact_id": toContactID}
+	err := c.call(ctx, http.MethodPost, teamPath(teamID, "/contacts/%d/reassign", id), body, &res)
+	return res.Reassigned, err
+}
+
+// MyArtifacts returns the artifacts owned by contacts linked to the current
+// user, optionally only in one role.
+func (c *Client) MyArtifacts(ctx context.Context, role string, opts *ListOptions) ([]MyArtifact, error) {
+	v := url.Values{}
+	if role != "" {
+		v.Set("role", role)
+	}
+	var res []MyArtifact
+	err := c.call(ctx, http.MethodGet, withQuery("/me/artifacts"+opts.query(), v), nil, &res)
+	return res, err
+}
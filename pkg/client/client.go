@@ -0,0 +1,252 @@
+// Package client is a Go SDK for the data catalog REST API.
+//
+//	c := client.New("http://catalog:8080", client.WithCredentials(email, password))
+//	a, err := c.CreateArtifact(ctx, teamID, client.Artifact{Name: "users", Type: "table", ProjectName: "DWH"})
+//	for a, err := range c.Artifacts(ctx, teamID) { ... }
+//
+// Requests are authenticated with a bearer token. When the server rejects it
+// the client exchanges its refresh token for a new pair; failing that, with
+// WithCredentials it logs in again. Logging in also happens lazily. Idempotent
+// requests (GET, PUT, DELETE) are retried on 5xx responses and network errors.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+
+	reauthMu sync.Mutex // serializes refreshes: a refresh token works only once
+
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+	email        string
+	password     string
+}
+
+type Option func(*Client)
+
+// WithToken sets a bearer token obtained elsewhere.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithRefreshToken sets a refresh token used to obtain a new access token
+// when the current one expires.
+func WithRefreshToken(token string) Option {
+	return func(c *Client) { c.refreshToken = token }
+}
+
+// WithCredentials makes the client log in on demand and whenever the server
+// rejects the current token.
+func WithCredentials(email, password string) Option {
+	return func(c *Client) { c.email, c.password = email, password }
+}
+
+// WithHTTPClient replaces the default http.Client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithRetries sets how many times a failed idempotent request is retried and
+// the initial backoff, which doubles on each attempt.
+func WithRetries(max int, backoff time.Duration) Option {
+	return func(c *Client) { c.maxRetries, c.backoff = max, backoff }
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		backoff:    200 * time.Millisecond,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Token returns the current bearer token.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// RefreshToken returns the current refresh token. It changes on every
+// refresh, so callers that persist tokens should save it after use.
+func (c *Client) RefreshToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refreshToken
+}
+
+func (c *Client) setTokens(token, refresh string) {
+	c.mu.Lock()
+	c.token, c.refreshToken = token, refresh
+	c.mu.Unlock()
+}
+
+func (c *Client) hasCredentials() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.email != "" && c.password != ""
+}
+
+// reauth replaces the access token stale, by refresh token if there is one
+// and otherwise by logging in with stored credentials. It does nothing if
+// another request has already replaced it.
+func (c *Client) reauth(ctx context.Context, stale string) error {
+	c.reauthMu.Lock()
+	defer c.reauthMu.Unlock()
+	if c.Token() != stale {
+		return nil
+	}
+	if c.RefreshToken() != "" {
+		_, err := c.Refresh(ctx)
+		if err == nil || !c.hasCredentials() {
+			return err
+		}
+	}
+	c.mu.Lock()
+	email, password := c.email, c.password
+	c.mu.Unlock()
+	res, err := c.Login(ctx, email, password)
+	if err == nil && res.MFARequired {
+		return ErrTwoFactorRequired
+	}
+	return err
+}
+
+func (c *Client) canReauth() bool {
+	return c.RefreshToken() != "" || c.hasCredentials()
+}
+
+// call performs an authenticated API request under /api/v1.
+func (c *Client) call(ctx context.Context, method, path string, body, out any) error {
+	if c.Token() == "" && c.canReauth() {
+		if err := c.reauth(ctx, ""); err != nil {
+			return err
+		}
+	}
+	token := c.Token()
+	err := c.do(ctx, method, "/api/v1"+path, true, body, out)
+	if errors.Is(err, ErrUnauthorized) && c.canReauth() {
+		if err := c.reauth(ctx, token); err != nil {
+			return err
+		}
+		err = c.do(ctx, method, "/api/v1"+path, true, body, out)
+	}
+	return err
+}
+
+// do sends one logical request, retrying idempotent methods. out may be a
+// pointer to decode JSON into or a *[]byte to receive the raw body.
+func (c *Client) do(ctx context.Context, method, path string, auth bool, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	retries := 0
+	if method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete {
+		retries = c.maxRetries
+	}
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		err := c.once(ctx, method, path, auth, payload, out)
+		if err == nil || attempt >= retries || !retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	// transport errors, but not a cancelled or expired context
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (c *Client) once(ctx context.Context, method, path string, auth bool, payload []byte, out any) error {
+	var rd io.Reader
+	if payload != nil {
+		rd = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, rd)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if tok := c.Token(); auth && tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		apiErr := &APIError{StatusCode: resp.StatusCode, Method: method, Path: path, Message: strings.TrimSpace(string(data))}
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			apiErr.Message = e.Error
+		}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		}
+		return apiErr
+	}
+	switch o := out.(type) {
+	case nil:
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	case *[]byte:
+		*o, err = io.ReadAll(resp.Body)
+		return err
+	default:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil || len(data) == 0 {
+			return err
+		}
+		return json.Unmarshal(data, out)
+	}
+}
+
+// Health checks GET /health.
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", false, nil, nil)
+}
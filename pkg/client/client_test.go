@@ -0,0 +1,302 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, h http.Handler, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	opts = append([]Option{WithToken("tok"), WithRetries(2, time.Millisecond)}, opts...)
+	return New(srv.URL, opts...)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestArtifactsPaginates(t *testing.T) {
+	const total = 2*DefaultPageSize + 50
+	var offsets []int
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/teams/7/artifacts" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, offset)
+		page := []Artifact{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			page = append(page, Artifact{ID: i + 1})
+		}
+		writeJSON(w, http.StatusOK, page)
+	})
+	c := newTestClient(t, h)
+
+	n := 0
+	for a, err := range c.Artifacts(context.Background(), 7) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+		if a.ID != n {
+			t.Fatalf("artifact %d has id %d", n, a.ID)
+		}
+	}
+	if n != total {
+		t.Fatalf("got %d artifacts, want %d", n, total)
+	}
+	if want := fmt.Sprint([]int{0, 100, 200}); fmt.Sprint(offsets) != want {
+		t.Errorf("offsets = %v, want %s", offsets, want)
+	}
+
+	// breaking out of the loop stops fetching
+	offsets = nil
+	for range c.Artifacts(context.Background(), 7) {
+		break
+	}
+	if len(offsets) != 1 {
+		t.Errorf("fetched %d pages after break, want 1", len(offsets))
+	}
+}
+
+func TestArtifactsYieldsError(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "not a member"})
+	}))
+	var errs []error
+	for _, err := range c.Artifacts(context.Background(), 7) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrForbidden) {
+		t.Fatalf("got %v, want a single ErrForbidden", errs)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		method string
+		call   func(*Client) error
+		want   int32 // attempts
+	}{
+		{http.MethodGet, func(c *Client) error { _, err := c.GetArtifact(context.Background(), 1, 2); return err }, 3},
+		{http.MethodPut, func(c *Client) error { _, err := c.UpdateArtifact(context.Background(), 1, 2, Artifact{}); return err }, 3},
+		{http.MethodDelete, func(c *Client) error { return c.DeleteArtifact(context.Background(), 1, 2) }, 3},
+		{http.MethodPost, func(c *Client) error { _, err := c.CreateArtifact(context.Background(), 1, Artifact{}); return err }, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			var attempts atomic.Int32
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != tt.method {
+					t.Errorf("method = %s, want %s", r.Method, tt.method)
+				}
+				attempts.Add(1)
+				writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "down"})
+			}))
+			if err := tt.call(c); !errors.Is(err, ErrServer) {
+				t.Fatalf("err = %v, want ErrServer", err)
+			}
+			if got := attempts.Load(); got != tt.want {
+				t.Errorf("%d attempts, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, http.StatusOK, Artifact{ID: 2, Name: "users"})
+	}))
+	a, err := c.GetArtifact(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "users" {
+		t.Errorf("name = %q", a.Name)
+	}
+}
+
+func TestNoRetryOn4xx(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "artifact not found"})
+	}))
+	if _, err := c.GetArtifact(context.Background(), 1, 2); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("%d attempts, want 1", got)
+	}
+}
+
+// authServer accepts the bearer token "new" and exchanges the refresh token
+// "r1" for it exactly once, like the real server does.
+type authServer struct {
+	refreshes atomic.Int32
+	logins    atomic.Int32
+	stale     func() // called on each request with a rejected token
+}
+
+func (s *authServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v1/auth/refresh":
+		var body struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.RefreshToken != "r1" || s.refreshes.Add(1) > 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "refresh token reused"})
+			return
+		}
+		writeJSON(w, http.StatusOK, AuthResponse{Token: "new", RefreshToken: "r2"})
+	case "/api/v1/auth/login":
+		s.logins.Add(1)
+		writeJSON(w, http.StatusOK, AuthResponse{Token: "new", RefreshToken: "r2"})
+	default:
+		if r.Header.Get("Authorization") != "Bearer new" {
+			if s.stale != nil {
+				s.stale()
+			}
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "token expired"})
+			return
+		}
+		writeJSON(w, http.StatusOK, Artifact{ID: 2})
+	}
+}
+
+func TestReauthWithRefreshToken(t *testing.T) {
+	s := &authServer{}
+	c := newTestClient(t, s, WithToken("old"), WithRefreshToken("r1"))
+	if _, err := c.GetArtifact(context.Background(), 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if c.Token() != "new" || c.RefreshToken() != "r2" {
+		t.Errorf("tokens = %q, %q", c.Token(), c.RefreshToken())
+	}
+	if s.refreshes.Load() != 1 || s.logins.Load() != 0 {
+		t.Errorf("%d refreshes, %d logins", s.refreshes.Load(), s.logins.Load())
+	}
+}
+
+func TestReauthFallsBackToLogin(t *testing.T) {
+	s := &authServer{}
+	c := newTestClient(t, s, WithToken("old"), WithRefreshToken("revoked"), WithCredentials("a@example.com", "secret"))
+	if _, err := c.GetArtifact(context.Background(), 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if s.logins.Load() != 1 {
+		t.Errorf("%d logins, want 1", s.logins.Load())
+	}
+}
+
+func TestReauthWithoutCredentials(t *testing.T) {
+	c := newTestClient(t, &authServer{}, WithToken("old"))
+	if _, err := c.GetArtifact(context.Background(), 1, 2); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestConcurrentReauthRefreshesOnce(t *testing.T) {
+	const n = 8
+	// Hold the 401 responses until every request has been rejected, so all
+	// of them reauth with the same stale token at once.
+	var rejected atomic.Int32
+	all := make(chan struct{})
+	s := &authServer{}
+	s.stale = func() {
+		k := rejected.Add(1)
+		if k == n {
+			close(all)
+		}
+		if k <= n {
+			select {
+			case <-all:
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}
+	c := newTestClient(t, s, WithToken("old"), WithRefreshToken("r1"))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.GetArtifact(context.Background(), 1, 2)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if got := s.refreshes.Load(); got != 1 {
+		t.Errorf("%d refreshes, want 1", got)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	sentinels := []error{ErrUnauthorized, ErrForbidden, ErrNotFound, ErrBadRequest, ErrTooManyRequests, ErrServer}
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrBadRequest},
+		{http.StatusUnprocessableEntity, ErrBadRequest},
+		{http.StatusTooManyRequests, ErrTooManyRequests},
+		{http.StatusInternalServerError, ErrServer},
+		{http.StatusServiceUnavailable, ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				writeJSON(w, tt.status, map[string]string{"error": "boom"})
+			}), WithRetries(0, 0))
+			_, err := c.GetArtifact(context.Background(), 1, 2)
+			for _, s := range sentinels {
+				if got := errors.Is(err, s); got != (s == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v", s, got)
+				}
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %T, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != "boom" {
+				t.Errorf("got %d %q", apiErr.StatusCode, apiErr.Message)
+			}
+			if apiErr.RetryAfter != 30*time.Second {
+				t.Errorf("RetryAfter = %v", apiErr.RetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrBadRequest   = errors.New("bad request")
+	ErrServer       = errors.New("server error")
+
+	// ErrTooManyRequests is returned while the server throttles logins after
+	// failed attempts; APIError.RetryAfter says how long to wait.
+	ErrTooManyRequests = errors.New("too many requests")
+
+	// ErrTwoFactorRequired is returned by automatic re-login when the
+	// account has two-factor authentication enabled.
+	ErrTwoFactorRequired = errors.New("two-factor authentication required")
+)
+
+// APIError is returned for any non-2xx response. It matches the sentinel
+// errors above with errors.Is, e.g. errors.Is(err, client.ErrNotFound).
+type APIError struct {
+	StatusCode int
+	Message    string
+	Method     string
+	Path       string
+	// RetryAfter is the wait requested by the server with a 429 response.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusConflict || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
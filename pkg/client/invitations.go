@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// InvitationRequest describes a new team invitation. With Email only that
+// user may accept it and the server emails the link; such invitations are
+// single-use. Otherwise MaxUses limits how many people may join with the
+// link: nil means one, 0 means unlimited until expiry. Zero ExpiresInDays
+// means the server default (7 days).
+type InvitationRequest struct {
+	Role          string `json:"role"`
+	Email         string `json:"email,omitempty"`
+	MaxUses       *int   `json:"max_uses,omitempty"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty"`
+}
+
+// CreateInvitation creates an invitation to the team (owner/admin; only the
+// owner invites admins).
+func (c *Client) CreateInvitation(ctx context.Context, teamID int, req InvitationRequest) (*CreatedInvitation, error) {
+	var inv CreatedInvitation
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/invitations"), req, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListInvitations lists the team's invitations; status may be active, used,
+// expired, revoked or empty for all.
+func (c *Client) ListInvitations(ctx context.Context, teamID int, status string) ([]Invitation, error) {
+	path := teamPath(teamID, "/invitations")
+	if status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	var res []Invitation
+	err := c.call(ctx, http.MethodGet, path, nil, &res)
+	return res, err
+}
+
+// RevokeInvitation makes an invitation unusable.
+func (c *Client) RevokeInvitation(ctx context.Context, teamID, id int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/invitations/%d", id), nil, nil)
+}
+
+// AcceptInvitation joins the team of the invitation token.
+func (c *Client) AcceptInvitation(ctx context.Context, token string) (*AcceptedInvitation, error) {
+	var res AcceptedInvitation
+	if err := c.call(ctx, http.MethodPost, "/invitations/accept", map[string]string{"token": token}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Notifications returns the current user's in-app notifications, newest
+// first, optionally only unread ones.
+func (c *Client) Notifications(ctx context.Context, unreadOnly bool, opts ListOptions) (*Notifications, error) {
+	q := url.Values{}
+	if unreadOnly {
+		q.Set("unread", "true")
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		q.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	var res Notifications
+	if err := c.call(ctx, http.MethodGet, "/me/notifications?"+q.Encode(), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) MarkNotificationRead(ctx context.Context, id int) error {
+	return c.call(ctx, http.MethodPost, fmt.Sprintf("/me/notifications/%d/read", id), nil, nil)
+}
+
+func (c *Client) MarkAllNotificationsRead(ctx context.Context) error {
+	return c.call(ctx, http.MethodPost, "/me/notifications/read-all", nil, nil)
+}
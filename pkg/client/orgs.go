@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// OrgSettings are the editable properties of an organization. Nil
+// AllowedAuthMethods allows every sign-in method.
+type OrgSettings struct {
+	Name               string   `json:"name"`
+	Description        string   `json:"description"`
+	AllowedAuthMethods []string `json:"allowed_auth_methods,omitempty"`
+	AutoJoin           bool     `json:"auto_join"`
+}
+
+func orgPath(orgID int, format string, args ...any) string {
+	return fmt.Sprintf("/orgs/%d", orgID) + fmt.Sprintf(format, args...)
+}
+
+// ListOrgs returns the organizations of the current user with their role.
+func (c *Client) ListOrgs(ctx context.Context) ([]Organization, error) {
+	var res []Organization
+	err := c.call(ctx, http.MethodGet, "/orgs", nil, &res)
+	return res, err
+}
+
+// CreateOrg creates an organization with the current user as its admin.
+// Requires the system admin role.
+func (c *Client) CreateOrg(ctx context.Context, s OrgSettings) (*Organization, error) {
+	var o Organization
+	if err := c.call(ctx, http.MethodPost, "/orgs", s, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// GetOrg returns an organization the user belongs to.
+func (c *Client) GetOrg(ctx context.Context, orgID int) (*Organization, error) {
+	var o Organization
+	if err := c.call(ctx, http.MethodGet, orgPath(orgID, ""), nil, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// UpdateOrg replaces the name and settings of an organization (org admin).
+func (c *Client) UpdateOrg(ctx context.Context, orgID int, s OrgSettings) (*Organization, error) {
+	var o Organization
+	if err := c.call(ctx, http.MethodPut, orgPath(orgID, ""), s, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// OrgMembers lists the members of an organization.
+func (c *Client) OrgMembers(ctx context.Context, orgID int) ([]OrgMember, error) {
+	var res []OrgMember
+	err := c.call(ctx, http.MethodGet, orgPath(orgID, "/members"), nil, &res)
+	return res, err
+}
+
+// AddOrgMember adds an existing user by email; role is admin or member
+// (empty for member).
+func (c *Client) AddOrgMember(ctx context.Context, orgID int, email, role string) (*OrgMember, error) {
+	var m OrgMember
+	body := map[string]string{"email": email, "role": role}
+	if err := c.call(ctx, http.MethodPost, orgPath(orgID, "/members"), body, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SetOrgMemberRole changes the role of a member (org admin).
+func (c *Client) SetOrgMemberRole(ctx context.Context, orgID, userID int, role string) (*OrgMember, error) {
+	var m OrgMember
+	body := map[string]string{"role": role}
+	if err := c.call(ctx, http.MethodPut, orgPath(orgID, "/members/%d", userID), body, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// RemoveOrgMember removes a member from the organization and its teams.
+func (c *Client) RemoveOrgMember(ctx context.Context, orgID, userID int) error {
+	return c.call(ctx, http.MethodDelete, orgPath(orgID, "/members/%d", userID), nil, nil)
+}
+
+// ArtifactTypes lists the artifact types of an organization.
+func (c *Client) ArtifactTypes(ctx context.Context, orgID int) ([]ArtifactType, error) {
+	var res []ArtifactType
+	err := c.call(ctx, http.MethodGet, orgPath(orgID, "/artifact-types"), nil, &res)
+	return res, err
+}
+
+// AddArtifactType registers an artifact type (org admin).
+func (c *Client) AddArtifactType(ctx context.Context, orgID int, name, description string) (*ArtifactType, error) {
+	var t ArtifactType
+	body := map[string]string{"name": name, "description": description}
+	if err := c.call(ctx, http.MethodPost, orgPath(orgID, "/artifact-types"), body, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RemoveArtifactType removes an unused artifact type (org admin).
+func (c *Client) RemoveArtifactType(ctx context.Context, orgID int, name string) error {
+	return c.call(ctx, http.MethodDelete, orgPath(orgID, "/artifact-types/%s", url.PathEscape(name)), nil, nil)
+}
+
+// SearchOrg searches artifacts and fields across the teams of an
+// organization the user may read; org admins search every team.
+func (c *Client) SearchOrg(ctx context.Context, orgID int, q string, limit int) ([]SearchHit, error) {
+	v := url.Values{"q": {q}}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	var res []SearchHit
+	err := c.call(ctx, http.MethodGet, orgPath(orgID, "/search?")+v.Encode(), nil, &res)
+	return res, err
+}
+
+// OrgUsage returns usage statistics of the organization (org admin).
+func (c *Client) OrgUsage(ctx context.Context, orgID int) (*OrgUsage, error) {
+	var u OrgUsage
+	if err := c.call(ctx, http.MethodGet, orgPath(orgID, "/usage"), nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ListOrgUsers lists the users of an organization (org admin).
+func (c *Client) ListOrgUsers(ctx context.Context, orgID int, f UserFilter) ([]User, error) {
+	return c.listUsers(ctx, orgPath(orgID, ""), f)
+}
+
+// GetOrgUser returns a user of the organization (org admin).
+func (c *Client) GetOrgUser(ctx context.Context, orgID, id int) (*User, error) {
+	return c.userCall(ctx, http.MethodGet, orgPath(orgID, ""), id, "")
+}
+
+// DeactivateOrgUser blocks the login of a user of the organization and ends
+// all their sessions (org admin of every organization of the user).
+func (c *Client) DeactivateOrgUser(ctx context.Context, orgID, id int) (*User, error) {
+	return c.userCall(ctx, http.MethodPost, orgPath(orgID, ""), id, "/deactivate")
+}
+
+// ActivateOrgUser reactivates a deactivated user of the organization.
+func (c *Client) ActivateOrgUser(ctx context.Context, orgID, id int) (*User, error) {
+	return c.userCall(ctx, http.MethodPost, orgPath(orgID, ""), id, "/activate")
+}
+
+// ResetOrgUserTwoFactor turns off two-factor authentication of a user of the
+// organization who lost their authenticator and recovery codes.
+func (c *Client) ResetOrgUserTwoFactor(ctx context.Context, orgID, id int) error {
+	return c.call(ctx, http.MethodDelete, orgPath(orgID, "/users/%d/2fa", id), nil, nil)
+}
+
+// OrgLoginHistory returns the newest login attempts of a user of the
+// organization and whether failed logins currently lock the account.
+func (c *Client) OrgLoginHistory(ctx context.Context, orgID, id int, opts ListOptions) (*LoginHistory, error) {
+	return c.loginHistory(ctx, orgPath(orgID, ""), id, opts)
+}
+
+// UnlockOrgUser clears the failed logins and any lockout of a user of the
+// organization.
+func (c *Client) UnlockOrgUser(ctx context.Context, orgID, id int) error {
+	return c.call(ctx, http.MethodPost, orgPath(orgID, "/users/%d/unlock", id), nil, nil)
+}
+
+// ListOrgTeamSnapshots lists the snapshots of the organization's deleted
+// teams (org admin).
+func (c *Client) ListOrgTeamSnapshots(ctx context.Context, orgID int, opts ListOptions) ([]TeamSnapshot, error) {
+	return c.listTeamSnapshots(ctx, orgPath(orgID, ""), opts)
+}
+
+// GetOrgTeamSnapshot returns a snapshot of a deleted team of the
+// organization with its data.
+func (c *Client) GetOrgTeamSnapshot(ctx context.Context, orgID, id int) (*TeamSnapshot, error) {
+	return c.getTeamSnapshot(ctx, orgPath(orgID, ""), id)
+}
+
+// RestoreOrgTeamSnapshot recreates a deleted team of the organization; name
+// may be empty to keep the original name. A snapshot can be restored once.
+func (c *Client) RestoreOrgTeamSnapshot(ctx context.Context, orgID, id int, name string) (*Team, error) {
+	return c.restoreTeamSnapshot(ctx, orgPath(orgID, ""), id, name)
+}
+
+// ReassignTeamOwner makes an active user of the organization the owner of
+// one of its teams, e.g. when the owner's account was deactivated; previous
+// owners become admins (org admin).
+func (c *Client) ReassignTeamOwner(ctx context.Context, orgID, teamID, userID int) (*TeamMember, error) {
+	var m TeamMember
+	if err := c.call(ctx, http.MethodPut, orgPath(orgID, "/teams/%d/owner", teamID), map[string]int{"user_id": userID}, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
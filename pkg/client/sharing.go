@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ShareArtifact shares an artifact of the team read-only with another team,
+// or with all teams when targetTeamID is 0 (owner/admin).
+func (c *Client) ShareArtifact(ctx context.Context, teamID, artifactID, targetTeamID int) (*ArtifactShare, error) {
+	body := map[string]any{}
+	if targetTeamID != 0 {
+		body["target_team_id"] = targetTeamID
+	}
+	var s ArtifactShare
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/artifacts/%d/shares", artifactID), body, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListShares lists the team's shares; status may be active, revoked or empty
+// for all.
+func (c *Client) ListShares(ctx context.Context, teamID int, status string) ([]ArtifactShare, error) {
+	path := teamPath(teamID, "/shares")
+	if status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	var res []ArtifactShare
+	err := c.call(ctx, http.MethodGet, path, nil, &res)
+	return res, err
+}
+
+// RevokeShare stops sharing; lineage of other teams to the artifact remains
+// and is reported as unshared.
+func (c *Client) RevokeShare(ctx context.Context, teamID, id int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/shares/%d", id), nil, nil)
+}
+
+// SharedArtifacts lists artifacts of other teams shared with the team.
+func (c *Client) SharedArtifacts(ctx context.Context, teamID int) ([]SharedArtifact, error) {
+	var res []SharedArtifact
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/shared-artifacts"), nil, &res)
+	return res, err
+}
+
+// GetSharedArtifact returns a shared artifact with its fields.
+func (c *Client) GetSharedArtifact(ctx context.Context, teamID, id int) (*SharedArtifact, error) {
+	var sa SharedArtifact
+	if err := c.call(ctx, http.MethodGet, teamPath(teamID, "/shared-artifacts/%d", id), nil, &sa); err != nil {
+		return nil, err
+	}
+	return &sa, nil
+}
+
+// Lineage returns the sources of the team's artifact and what is derived from it.
+func (c *Client) Lineage(ctx context.Context, teamID, artifactID int) (*Lineage, error) {
+	var l Lineage
+	if err := c.call(ctx, http.MethodGet, teamPath(teamID, "/artifacts/%d/lineage", artifactID), nil, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// AddLineage marks the artifact as derived from upstreamID, an artifact of the
+// team or one shared with it.
+func (c *Client) AddLineage(ctx context.Context, teamID, artifactID, upstreamID int) (*LineageEdge, error) {
+	var e LineageEdge
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/artifacts/%d/lineage", artifactID), map[string]int{"upstream_id": upstreamID}, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// DeleteLineage removes an edge into the artifact.
+func (c *Client) DeleteLineage(ctx context.Context, teamID, artifactID, edgeID int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/artifacts/%d/lineage/%d", artifactID, edgeID), nil, nil)
+}
+
+// Search finds artifacts and fields of the team and artifacts shared with it
+// whose names or descriptions contain q. Zero limit means the server default.
+func (c *Client) Search(ctx context.Context, teamID int, q string, limit int) ([]SearchHit, error) {
+	v := url.Values{"q": {q}}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	var res []SearchHit
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/search?")+v.Encode(), nil, &res)
+	return res, err
+}
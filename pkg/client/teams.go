@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SearchTeams finds teams by name (GET /teams?search=).
+func (c *Client) SearchTeams(ctx context.Context, query string) ([]Team, error) {
+	var res []Team
+	err := c.call(ctx, http.MethodGet, "/teams?search="+url.QueryEscape(query), nil, &res)
+	return res, err
+}
+
+// CreateTeam creates a team owned by the current user in their only
+// organization; see CreateOrgTeam.
+func (c *Client) CreateTeam(ctx context.Context, name, description string) (*Team, error) {
+	return c.CreateOrgTeam(ctx, 0, name, description)
+}
+
+// CreateOrgTeam creates a team in the organization; orgID 0 picks the user's
+// only organization.
+func (c *Client) CreateOrgTeam(ctx context.Context, orgID int, name, description string) (*Team, error) {
+	var t Team
+	body := map[string]any{"name": name, "description": description}
+	if orgID != 0 {
+		body["org_id"] = orgID
+	}
+	if err := c.call(ctx, http.MethodPost, "/teams", body, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UpdateTeamSettings replaces the team's visibility, join policy and public
+// catalog flag (owner only).
+func (c *Client) UpdateTeamSettings(ctx context.Context, teamID int, settings TeamSettings) (*Team, error) {
+	var t Team
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/settings"), settings, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RequestJoin asks to join a team; message is shown to the team admins and
+// may be empty. A second pending request to the same team is refused. Open
+// teams approve the request at once; invite-only teams refuse it.
+func (c *Client) RequestJoin(ctx context.Context, teamID int, message string) (*JoinRequest, error) {
+	var jr JoinRequest
+	if err := c.call(ctx, http.MethodPost, fmt.Sprintf("/teams/%d/join", teamID), map[string]string{"message": message}, &jr); err != nil {
+		return nil, err
+	}
+	return &jr, nil
+}
+
+// MyTeams lists teams the current user is an active member of.
+func (c *Client) MyTeams(ctx context.Context) ([]Team, error) {
+	var res []Team
+	err := c.call(ctx, http.MethodGet, "/me/teams", nil, &res)
+	return res, err
+}
+
+// ListJoinRequests lists a team's join requests; status may be empty for all.
+func (c *Client) ListJoinRequests(ctx context.Context, teamID int, status string) ([]JoinRequest, error) {
+	var res []JoinRequest
+	err := c.call(ctx, http.MethodGet, fmt.Sprintf("/teams/%d/requests?status=%s", teamID, url.QueryEscape(status)), nil, &res)
+	return res, err
+}
+
+// ApproveJoinRequest approves a pending request; comment is shown to the
+// requester and may be empty.
+func (c *Client) ApproveJoinRequest(ctx context.Context, teamID, requestID int, comment string) error {
+	return c.call(ctx, http.MethodPost, fmt.Sprintf("/teams/%d/requests/%d/approve", teamID, requestID), map[string]string{"comment": comment}, nil)
+}
+
+// RejectJoinRequest rejects a pending request; comment is shown to the
+// requester and may be empty.
+func (c *Client) RejectJoinRequest(ctx context.Context, teamID, requestID int, comment string) error {
+	return c.call(ctx, http.MethodPost, fmt.Sprintf("/teams/%d/requests/%d/reject", teamID, requestID), map[string]string{"comment": comment}, nil)
+}
+
+// MyJoinRequests lists the current user's join requests; status may be
+// empty for all.
+func (c *Client) MyJoinRequests(ctx context.Context, status string) ([]JoinRequest, error) {
+	var res []JoinRequest
+	err := c.call(ctx, http.MethodGet, "/me/join-requests?status="+url.QueryEscape(status), nil, &res)
+	return res, err
+}
+
+// CancelJoinRequest withdraws the current user's pending join request.
+func (c *Client) CancelJoinRequest(ctx context.Context, requestID int) error {
+	return c.call(ctx, http.MethodPost, fmt.Sprintf("/me/join-requests/%d/cancel", requestID), nil, nil)
+}
+
+// ListMembers lists team members; status may be "active", "inactive" or
+// empty for all.
+func (c *Client) ListMembers(ctx context.Context, teamID int, status string) ([]TeamMember, error) {
+	path := teamPath(teamID, "/members")
+	if status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	var res []TeamMember
+	err := c.call(ctx, http.MethodGet, path, nil, &res)
+	return res, err
+}
+
+// SetMemberRole changes a member's role to admin, member or viewer (team
+// owner/admin; only the owner manages admins).
+func (c *Client) SetMemberRole(ctx context.Context, teamID, userID int, role string) (*TeamMember, error) {
+	var m TeamMember
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/members/%d/role", userID), map[string]string{"role": role}, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SetMemberActive deactivates or reactivates a member (team owner/admin).
+func (c *Client) SetMemberActive(ctx context.Context, teamID, userID int, active bool) (*TeamMember, error) {
+	status := "inactive"
+	if active {
+		status = "active"
+	}
+	var m TeamMember
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/members/%d/status", userID), map[string]string{"status": status}, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// RemoveMember removes a member from the team (team owner/admin).
+func (c *Client) RemoveMember(ctx context.Context, teamID, userID int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/members/%d", userID), nil, nil)
+}
+
+// UpdateTeam renames the team and replaces its description (owner only).
+func (c *Client) UpdateTeam(ctx context.Context, teamID int, name, description string) (*Team, error) {
+	var t Team
+	body := map[string]string{"name": name, "description": description}
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, ""), body, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ArchiveTeam makes the team read-only and hides it from search (owner
+// only); UnarchiveTeam reverses it.
+func (c *Client) ArchiveTeam(ctx context.Context, teamID int) (*Team, error) {
+	var t Team
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/archive"), nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (c *Client) UnarchiveTeam(ctx context.Context, teamID int) (*Team, error) {
+	var t Team
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/unarchive"), nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteTeam deletes the team with all its data (owner only). confirmName
+// must equal the team name. A snapshot is saved first; an org admin can
+// restore it with RestoreOrgTeamSnapshot. Needs an interactive login.
+func (c *Client) DeleteTeam(ctx context.Context, teamID int, confirmName string) (*TeamSnapshot, error) {
+	var s TeamSnapshot
+	if err := c.call(ctx, http.MethodDelete, teamPath(teamID, ""), map[string]string{"confirm_name": confirmName}, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LeaveTeam removes the current user from the team. The last owner must
+// transfer ownership first.
+func (c *Client) LeaveTeam(ctx context.Context, teamID int) error {
+	return c.call(ctx, http.MethodPost, teamPath(teamID, "/leave"), nil, nil)
+}
+
+// TransferOwnership makes another active member the team owner; the current
+// owner becomes an admin.
+func (c *Client) TransferOwnership(ctx context.Context, teamID, userID int) (*TeamMember, error) {
+	var m TeamMember
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/transfer-ownership"), map[string]int{"user_id": userID}, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// MyPermissions returns the current user's role in the team and the actions
+// it allows, by resource.
+func (c *Client) MyPermissions(ctx context.Context, teamID int) (*Permissions, error) {
+	var p Permissions
+	if err := c.call(ctx, http.MethodGet, teamPath(teamID, "/me/permissions"), nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DownloadDocs returns the team's static documentation site as a zip archive.
+func (c *Client) DownloadDocs(ctx context.Context, teamID int) ([]byte, error) {
+	var data []byte
+	err := c.call(ctx, http.MethodGet, fmt.Sprintf("/teams/%d/docs.zip", teamID), nil, &data)
+	return data, err
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// API token scopes.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// TokenRequest describes a new API token. Zero ExpiresInDays means the
+// server default (90 days).
+type TokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+}
+
+// ListTokens lists the current user's personal access tokens. Token and
+// service account management requires a login session: the server refuses
+// it to clients authenticated with an API token.
+func (c *Client) ListTokens(ctx context.Context) ([]APIToken, error) {
+	var res []APIToken
+	err := c.call(ctx, http.MethodGet, "/me/tokens", nil, &res)
+	return res, err
+}
+
+// CreateToken creates a personal access token.
+func (c *Client) CreateToken(ctx context.Context, req TokenRequest) (*CreatedToken, error) {
+	var t CreatedToken
+	if err := c.call(ctx, http.MethodPost, "/me/tokens", req, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RevokeToken revokes a personal access token.
+func (c *Client) RevokeToken(ctx context.Context, id int) error {
+	return c.call(ctx, http.MethodDelete, fmt.Sprintf("/me/tokens/%d", id), nil, nil)
+}
+
+// ListServiceAccounts lists the team's service accounts (owner/admin).
+func (c *Client) ListServiceAccounts(ctx context.Context, teamID int) ([]ServiceAccount, error) {
+	var res []ServiceAccount
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/service-accounts"), nil, &res)
+	return res, err
+}
+
+// CreateServiceAccount creates a service account with a team role: admin,
+// member or viewer.
+func (c *Client) CreateServiceAccount(ctx context.Context, teamID int, name, role string) (*ServiceAccount, error) {
+	var sa ServiceAccount
+	body := map[string]string{"name": name, "role": role}
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/service-accounts"), body, &sa); err != nil {
+		return nil, err
+	}
+	return &sa, nil
+}
+
+// DeactivateServiceAccount disables a service account and revokes its tokens.
+func (c *Client) DeactivateServiceAccount(ctx context.Context, teamID, id int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/service-accounts/%d", id), nil, nil)
+}
+
+func (c *Client) ListServiceAccountTokens(ctx context.Context, teamID, accountID int) ([]APIToken, error) {
+	var res []APIToken
+	err := c.call(ctx, http.MethodGet, teamPath(teamID, "/service-accounts/%d/tokens", accountID), nil, &res)
+	return res, err
+}
+
+func (c *Client) CreateServiceAccountToken(ctx context.Context, teamID, accountID int, req TokenRequest) (*CreatedToken, error) {
+	var t CreatedToken
+	if err := c.call(ctx, http.MethodPost, teamPath(teamID, "/service-accounts/%d/tokens", accountID), req, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (c *Client) RevokeServiceAccountToken(ctx context.Context, teamID, accountID, tokenID int) error {
+	return c.call(ctx, http.MethodDelete, teamPath(teamID, "/service-accounts/%d/tokens/%d", accountID, tokenID), nil, nil)
+}
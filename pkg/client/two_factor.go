@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// TwoFactorStatus is the two-factor authentication state of the current user.
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollment is a new TOTP secret. QRCode is a PNG data URL of
+// OTPAuthURI.
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"`
+}
+
+type recoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorStatus returns whether 2FA is enabled for the current user.
+// Managing 2FA requires a login session, not an API token.
+func (c *Client) TwoFactorStatus(ctx context.Context) (*TwoFactorStatus, error) {
+	var s TwoFactorStatus
+	if err := c.call(ctx, http.MethodGet, "/me/2fa", nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// EnrollTwoFactor generates a new TOTP secret. 2FA is not enabled until
+// ConfirmTwoFactor.
+func (c *Client) EnrollTwoFactor(ctx context.Context) (*TwoFactorEnrollment, error) {
+	var e TwoFactorEnrollment
+	if err := c.call(ctx, http.MethodPost, "/me/2fa/enroll", nil, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ConfirmTwoFactor enables 2FA with a code from the authenticator and
+// returns the one-time recovery codes.
+func (c *Client) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	var res recoveryCodes
+	if err := c.call(ctx, http.MethodPost, "/me/2fa/confirm", map[string]string{"code": code}, &res); err != nil {
+		return nil, err
+	}
+	return res.RecoveryCodes, nil
+}
+
+// DisableTwoFactor turns 2FA off; code is a TOTP or recovery code.
+func (c *Client) DisableTwoFactor(ctx context.Context, code string) error {
+	return c.call(ctx, http.MethodPost, "/me/2fa/disable", map[string]string{"code": code}, nil)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes; code is a TOTP or
+// recovery code.
+func (c *Client) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	var res recoveryCodes
+	if err := c.call(ctx, http.MethodPost, "/me/2fa/recovery-codes", map[string]string{"code": code}, &res); err != nil {
+		return nil, err
+	}
+	return res.RecoveryCodes, nil
+}
+
+// SetTeamRequire2FA makes two-factor authentication mandatory for the
+// team's members (owner only).
+func (c *Client) SetTeamRequire2FA(ctx context.Context, teamID int, required bool) (*Team, error) {
+	var t Team
+	if err := c.call(ctx, http.MethodPut, teamPath(teamID, "/require-2fa"), map[string]bool{"required": required}, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,507 @@
+package client
+
+import "time"
+
+// The types below mirror go-data-catalog/internal/models so that code
+// outside this module can use them. JSON tags must stay in sync.
+
+type Contact struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// TelegramContact is the first Telegram channel. When Channels is nil
+	// it replaces the Telegram channel and other channels are kept.
+	TelegramContact string `json:"telegram_contact"`
+	// UserID links the contact to the account of a team member.
+	UserID       *int             `json:"user_id"`
+	UserEmail    string           `json:"user_email,omitempty"`
+	Department   string           `json:"department"`
+	Availability string           `json:"availability"` // on-call or availability note
+	AvatarURL    string           `json:"avatar_url"`
+	Channels     []ContactChannel `json:"channels"` // nil keeps them, see TelegramContact
+	TeamID       int              `json:"team_id"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+// Contact channel kinds.
+const (
+	ChannelEmail      = "email"
+	ChannelPhone      = "phone"
+	ChannelTelegram   = "telegram"
+	ChannelSlack      = "slack"
+	ChannelMattermost = "mattermost"
+)
+
+// ContactChannel is one way to reach a contact. The server checks the
+// format of Value and adds the leading @ to handles.
+type ContactChannel struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Artifact: Status is draft or active on creation; UpdateArtifact ignores it
+// and the deprecation fields, see SetArtifactStatus.
+type Artifact struct {
+	ID                int        `json:"id"`
+	Name              string     `json:"name"`
+	Type              string     `json:"type"`
+	Description       string     `json:"description"`
+	ProjectName       string     `json:"project_name"`
+	DeveloperID       int        `json:"developer_id,omitempty"` // the first technical owner
+	TeamID            int        `json:"team_id"`
+	Status            string     `json:"status,omitempty"`
+	DeprecatedAt      *time.Time `json:"deprecated_at,omitempty"`
+	ReplacementID     *int       `json:"replacement_id,omitempty"`
+	DeprecationReason string     `json:"deprecation_reason,omitempty"`
+	Certification     string     `json:"certification,omitempty"` // certification state, read-only
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// Artifact lifecycle statuses.
+const (
+	StatusDraft      = "draft"
+	StatusActive     = "active"
+	StatusDeprecated = "deprecated"
+	StatusRetired    = "retired"
+)
+
+// ArtifactStatus changes the lifecycle status of an artifact. Reason is
+// required to deprecate; omitted deprecation fields keep their value and
+// DeprecatedAt defaults to now.
+type ArtifactStatus struct {
+	Status        string     `json:"status"`
+	DeprecatedAt  *time.Time `json:"deprecated_at,omitempty"`
+	ReplacementID *int       `json:"replacement_id,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+}
+
+// Certification states.
+const (
+	CertCertified   = "certified"
+	CertNeedsReview = "needs_review"
+	CertExpired     = "expired"
+)
+
+// Certification marks an artifact as a trusted source until ExpiresAt. A
+// change of its fields or description afterwards sets ReviewRequiredAt and
+// the state becomes needs_review until it is certified again.
+type Certification struct {
+	ArtifactID       int        `json:"artifact_id"`
+	ArtifactName     string     `json:"artifact_name"`
+	State            string     `json:"state"`
+	CertifiedBy      *int       `json:"certified_by"`
+	CertifiedByEmail string     `json:"certified_by_email,omitempty"`
+	CertifiedAt      time.Time  `json:"certified_at"`
+	Note             string     `json:"note"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	ReviewRequiredAt *time.Time `json:"review_required_at"`
+	ReviewReason     string     `json:"review_reason,omitempty"`
+}
+
+// DeprecatedInUse is a deprecated or retired artifact of the team with the
+// lineage edges of the draft and active artifacts still derived from it.
+type DeprecatedInUse struct {
+	Artifact
+	Consumers []LineageEdge `json:"consumers"`
+}
+
+// Owner roles of an artifact.
+const (
+	OwnerTechnical = "technical_owner"
+	OwnerBusiness  = "business_owner"
+	OwnerSteward   = "data_steward"
+)
+
+// ArtifactOwner is a contact responsible for an artifact in a role.
+type ArtifactOwner struct {
+	ArtifactID  int       `json:"artifact_id"`
+	ContactID   int       `json:"contact_id"`
+	ContactName string    `json:"contact_name"`
+	UserID      *int      `json:"user_id,omitempty"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MyArtifact is an artifact owned by the current user with the user's roles.
+type MyArtifact struct {
+	Artifact
+	TeamName string   `json:"team_name"`
+	Roles    []string `json:"roles"`
+}
+
+type ArtifactField struct {
+	ID          int       `json:"id"`
+	ArtifactID  int       `json:"artifact_id"`
+	FieldName   string    `json:"field_name"`
+	DataType    string    `json:"data_type"`
+	Description string    `json:"description"`
+	IsPK        bool      `json:"is_pk"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type User struct {
+	ID         int    `json:"id"`
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	SystemRole string `json:"system_role"`
+	IsActive   bool   `json:"is_active"`
+	// EmailVerified is false until the user follows the emailed link.
+	EmailVerified bool `json:"email_verified"`
+	// ServiceTeamID is set for team service accounts.
+	ServiceTeamID *int      `json:"service_team_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TeamMember is a team membership with the member's user details. Role is
+// owner, admin, member or viewer; Status is active or inactive.
+type TeamMember struct {
+	TeamID         int       `json:"team_id"`
+	UserID         int       `json:"user_id"`
+	Email          string    `json:"email"`
+	Name           string    `json:"name"`
+	Role           string    `json:"role"`
+	Status         string    `json:"status"`
+	ServiceAccount bool      `json:"service_account"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// Permissions lists what the current user may do in a team. Permissions maps
+// a resource (artifacts, fields, contacts, members, join_requests,
+// service_accounts, team) to the allowed actions (read, create, update,
+// delete, manage, leave, transfer).
+type Permissions struct {
+	TeamID      int                 `json:"team_id"`
+	OrgID       int                 `json:"org_id"`
+	Role        string              `json:"role"`
+	Archived    bool                `json:"archived"` // only reading is allowed
+	Permissions map[string][]string `json:"permissions"`
+}
+
+// Can reports whether the action on the resource is allowed.
+func (p *Permissions) Can(resource, action string) bool {
+	for _, a := range p.Permissions[resource] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginAttempt is one entry of a user's login history. Method is password,
+// 2fa or oidc; Result is ok or the reason of the failure.
+type LoginAttempt struct {
+	ID        int       `json:"id"`
+	UserID    *int      `json:"user_id"`
+	Login     string    `json:"login"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Method    string    `json:"method"`
+	Result    string    `json:"result"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginHistory is returned by LoginHistory. LockedUntil is set while failed
+// logins block the account.
+type LoginHistory struct {
+	LockedUntil *time.Time     `json:"locked_until"`
+	Attempts    []LoginAttempt `json:"attempts"`
+}
+
+type Team struct {
+	ID            int        `json:"id"`
+	OrgID         int        `json:"org_id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	Require2FA    bool       `json:"require_2fa"`
+	Visibility    string     `json:"visibility"`
+	JoinPolicy    string     `json:"join_policy"`
+	PublicCatalog bool       `json:"public_catalog"`
+	ArchivedAt    *time.Time `json:"archived_at,omitempty"`
+	CreatedBy     int        `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TeamSnapshot is the export saved when a team is deleted. Data is set only
+// by GetOrgTeamSnapshot.
+type TeamSnapshot struct {
+	ID             int         `json:"id"`
+	TeamID         int         `json:"team_id"`
+	TeamName       string      `json:"team_name"`
+	OrgID          int         `json:"org_id"`
+	DeletedBy      *int        `json:"deleted_by"`
+	CreatedAt      time.Time   `json:"created_at"`
+	RestoredAt     *time.Time  `json:"restored_at,omitempty"`
+	RestoredTeamID *int        `json:"restored_team_id,omitempty"`
+	Data           *TeamExport `json:"data,omitempty"`
+}
+
+type TeamExport struct {
+	Team           Team            `json:"team"`
+	Members        []TeamMember    `json:"members"`
+	Contacts       []Contact       `json:"contacts"`
+	Artifacts      []Artifact      `json:"artifacts"`
+	Fields         []ArtifactField `json:"fields"`
+	Owners         []ArtifactOwner `json:"owners"`
+	Shares         []ArtifactShare `json:"shares"`
+	Lineage        []LineageEdge   `json:"lineage"`
+	Certifications []Certification `json:"certifications"`
+}
+
+// TeamSettings control who finds a team and how people join it. Visibility
+// is public, discoverable (found by its exact name) or hidden; JoinPolicy is
+// open, approval or invite_only. PublicCatalog lets any user read the
+// artifacts of a public team.
+type TeamSettings struct {
+	Visibility    string `json:"visibility"`
+	JoinPolicy    string `json:"join_policy"`
+	PublicCatalog bool   `json:"public_catalog"`
+}
+
+// JoinRequest status is pending, approved, rejected or cancelled. TeamName is
+// set in MyJoinRequests, UserEmail and UserName in ListJoinRequests.
+type JoinRequest struct {
+	ID              int        `json:"id"`
+	TeamID          int        `json:"team_id"`
+	UserID          int        `json:"user_id"`
+	Status          string     `json:"status"`
+	Message         string     `json:"message"`
+	DecisionComment string     `json:"decision_comment"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ProcessedBy     *int       `json:"processed_by"`
+	ProcessedAt     *time.Time `json:"processed_at"`
+	TeamName        string     `json:"team_name,omitempty"`
+	UserEmail       string     `json:"user_email,omitempty"`
+	UserName        string     `json:"user_name,omitempty"`
+}
+
+// Notification is an in-app message. Kind is join_request or
+// join_request_decided; SubjectID is the id of the join request.
+type Notification struct {
+	ID        int        `json:"id"`
+	Kind      string     `json:"kind"`
+	TeamID    *int       `json:"team_id"`
+	SubjectID *int       `json:"subject_id"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Notifications is a page of notifications with the total unread count.
+type Notifications struct {
+	Unread        int            `json:"unread"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// AuthResponse is returned by Register, Login and Refresh. Token is a
+// short-lived access token; RefreshToken is single-use.
+// AuthResponse is a token pair, or, when MFARequired is set, a challenge to
+// finish with LoginTwoFactor; then only MFAToken and ExpiresAt are set.
+type AuthResponse struct {
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+	User         User      `json:"user"`
+	MFARequired  bool      `json:"mfa_required,omitempty"`
+	MFAToken     string    `json:"mfa_token,omitempty"`
+	// VerificationRequired is set by Register when the server requires a
+	// verified email before login; no tokens are issued then.
+	VerificationRequired bool `json:"verification_required,omitempty"`
+}
+
+// RenderResult is a generated artifact definition, see RenderArtifact.
+type RenderResult struct {
+	Format      string   `json:"format"`
+	ContentType string   `json:"content_type"`
+	Content     string   `json:"content"`
+	Warnings    []string `json:"warnings"`
+}
+
+// Render formats accepted by RenderArtifact.
+const (
+	FormatPostgresDDL   = "postgres-ddl"
+	FormatClickHouseDDL = "clickhouse-ddl"
+	FormatGoStruct      = "go-struct"
+	FormatJSONSchema    = "json-schema"
+	FormatAvro          = "avro"
+)
+
+// APIToken describes a personal access token or a service account token.
+type APIToken struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedBy  *int       `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// CreatedToken is a new API token with its secret, which the server never
+// returns again.
+type CreatedToken struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// Invitation adds whoever accepts it to a team with Role. MaxUses is nil for
+// an unlimited link; Status is active, used, expired or revoked.
+type Invitation struct {
+	ID        int        `json:"id"`
+	TeamID    int        `json:"team_id"`
+	Role      string     `json:"role"`
+	Email     *string    `json:"email"`
+	MaxUses   *int       `json:"max_uses"`
+	UseCount  int        `json:"use_count"`
+	Status    string     `json:"status"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedBy *int       `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// CreatedInvitation is a new invitation with its link token, which the server
+// never returns again.
+type CreatedInvitation struct {
+	Invitation
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// AcceptedInvitation is the team joined with an invitation.
+type AcceptedInvitation struct {
+	Team Team   `json:"team"`
+	Role string `json:"role"`
+}
+
+// ServiceAccount is a team-owned non-human account.
+type ServiceAccount struct {
+	ID        int       `json:"id"`
+	TeamID    int       `json:"team_id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ArtifactShare publishes an artifact read-only to another team, or to all
+// teams when TargetTeamID is nil.
+type ArtifactShare struct {
+	ID             int        `json:"id"`
+	ArtifactID     int        `json:"artifact_id"`
+	ArtifactName   string     `json:"artifact_name"`
+	TeamID         int        `json:"team_id"`
+	TargetTeamID   *int       `json:"target_team_id"`
+	TargetTeamName string     `json:"target_team_name,omitempty"`
+	CreatedBy      *int       `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+// SharedArtifact is an artifact of another team shared with the caller's team.
+type SharedArtifact struct {
+	Artifact
+	TeamName string          `json:"team_name"`
+	Fields   []ArtifactField `json:"fields,omitempty"`
+}
+
+// Upstream states of a lineage edge, as seen by the downstream team.
+const (
+	UpstreamAvailable = "available"
+	UpstreamUnshared  = "unshared"
+	UpstreamDeleted   = "deleted"
+)
+
+// LineageEdge says that the downstream artifact is derived from the upstream
+// one. UpstreamID is nil once the upstream artifact is deleted.
+type LineageEdge struct {
+	ID                 int       `json:"id"`
+	UpstreamID         *int      `json:"upstream_id"`
+	UpstreamName       string    `json:"upstream_name"`
+	UpstreamTeamID     *int      `json:"upstream_team_id"`
+	UpstreamTeamName   string    `json:"upstream_team_name"`
+	UpstreamStatus     string    `json:"upstream_status"`
+	UpstreamLifecycle  string    `json:"upstream_lifecycle,omitempty"` // status of an available upstream
+	DownstreamID       int       `json:"downstream_id"`
+	DownstreamName     string    `json:"downstream_name"`
+	DownstreamTeamID   int       `json:"downstream_team_id"`
+	DownstreamTeamName string    `json:"downstream_team_name"`
+	CreatedBy          *int      `json:"created_by"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// Lineage lists the sources of an artifact and the artifacts derived from it.
+type Lineage struct {
+	Upstream   []LineageEdge `json:"upstream"`
+	Downstream []LineageEdge `json:"downstream"`
+}
+
+// SearchHit is an artifact, or a field of it, matching a catalog search.
+type SearchHit struct {
+	ArtifactID  int    `json:"artifact_id"`
+	Artifact    string `json:"artifact"`
+	ProjectName string `json:"project_name"`
+	TeamID      int    `json:"team_id"`
+	TeamName    string `json:"team_name"`
+	Shared      bool   `json:"shared"`
+	Status      string `json:"status"`
+	Certified   bool   `json:"certified"`
+	Match       string `json:"match"`
+	Field       string `json:"field,omitempty"`
+}
+
+// Organization groups teams. Role is the current user's role (admin or
+// member) in ListOrgs.
+type Organization struct {
+	ID                 int       `json:"id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	AllowedAuthMethods []string  `json:"allowed_auth_methods"` // password, ldap, oidc
+	AutoJoin           bool      `json:"auto_join"`
+	Role               string    `json:"role,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// OrgMember is a user's membership in an organization.
+type OrgMember struct {
+	OrgID    int       `json:"org_id"`
+	UserID   int       `json:"user_id"`
+	Email    string    `json:"email"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+	IsActive bool      `json:"is_active"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// ArtifactType is a type the teams of an organization may use.
+type ArtifactType struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InUse       int    `json:"in_use"`
+}
+
+// TeamUsage is one team's row in OrgUsage.
+type TeamUsage struct {
+	TeamID         int        `json:"team_id"`
+	TeamName       string     `json:"team_name"`
+	Archived       bool       `json:"archived"`
+	Members        int        `json:"members"`
+	Artifacts      int        `json:"artifacts"`
+	Fields         int        `json:"fields"`
+	Contacts       int        `json:"contacts"`
+	Shares         int        `json:"shares"`
+	LastArtifactAt *time.Time `json:"last_artifact_at"`
+}
+
+// OrgUsage sums up an organization; ActiveUsers used a session in the last
+// 30 days.
+type OrgUsage struct {
+	Teams       int         `json:"teams"`
+	Users       int         `json:"users"`
+	ActiveUsers int         `json:"active_users"`
+	Artifacts   int         `json:"artifacts"`
+	Fields      int         `json:"fields"`
+	PerTeam     []TeamUsage `json:"per_team"`
+}
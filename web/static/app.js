@@ -5,9 +5,12 @@ const qsa = (sel, root=document) => [...root.querySelectorAll(sel)];
 
 const state = {
   token: localStorage.getItem('token') || '',
+  refreshToken: localStorage.getItem('refreshToken') || '',
   me: null,
   teamId: null,
   teamName: null,
+  orgId: null,
+  perms: {},
 };
 
 function show(id) { qs('#'+id).classList.remove('hidden'); }
@@ -24,7 +27,30 @@ function headers(json=true) {
   return h;
 }
 
-async function api(path, opts={}) {
+function setSession(data) {
+  state.token = data.token; localStorage.setItem('token', state.token);
+  state.refreshToken = data.refresh_token || ''; localStorage.setItem('refreshToken', state.refreshToken);
+}
+
+// refreshSession exchanges the refresh token for a new pair. Concurrent
+// callers share one request: a refresh token can be used only once.
+let refreshing = null;
+function refreshSession() {
+  if (!state.refreshToken) return Promise.resolve(false);
+  if (!refreshing) {
+    refreshing = fetch(API_ROOT + '/auth/refresh', {
+      method: 'POST', headers: {'Content-Type':'application/json'},
+      body: JSON.stringify({refresh_token: state.refreshToken}),
+    }).then(async res => {
+      if (!res.ok) return false;
+      setSession(await res.json());
+      return true;
+    }).catch(() => false).finally(() => { refreshing = null; });
+  }
+  return refreshing;
+}
+
+async function api(path, opts={}, retried=false) {
   const headersIn = opts.headers || {};
   const h = { ...headersIn };
   if (state.token && !('Authorization' in h)) {
@@ -36,9 +62,13 @@ async function api(path, opts={}) {
   }
   const res = await fetch(API_ROOT + path, { ...opts, headers: h });
   if (!res.ok) {
-    if (res.status === 401) {
-      // token missing/invalid -> force logout UI-side
-      logout();
+    if (res.status === 401 && !path.startsWith('/auth/')) {
+      // access token expired -> try the refresh token once, then give up
+      if (!retried && await refreshSession()) {
+        const { Authorization, ...rest } = headersIn;
+        return api(path, { ...opts, headers: rest }, true);
+      }
+      clearSession();
     }
     const text = await res.text();
     throw new Error(text || res.statusText);
@@ -56,14 +86,21 @@ function notifyError(el, msg) {
   } catch { el.textContent = msg; }
 }
 
-function logout() {
-  state.token = '';
-  localStorage.removeItem('token');
+function clearSession() {
+  state.token = ''; state.refreshToken = '';
+  localStorage.removeItem('token'); localStorage.removeItem('refreshToken');
   hide('main-screen');
   show('auth-screen');
 }
 
+async function logout() {
+  if (state.token) await api('/auth/logout', { method:'POST' }).catch(()=>{});
+  clearSession();
+}
+
 async function refreshMyTeams() {
+  await acceptPendingInvite();
+  refreshNotificationCount();
   const list = await api('/me/teams', { headers: headers(false) });
   const el = qs('#teams-list');
   el.innerHTML = '';
@@ -80,17 +117,71 @@ async function refreshMyTeams() {
   });
 }
 
+// can reports whether the current user's team role allows the action
+// (see GET /teams/:teamId/me/permissions).
+function can(resource, action) {
+  return (state.perms[resource] || []).includes(action);
+}
+
+async function loadPermissions() {
+  try {
+    const res = await api(`/teams/${state.teamId}/me/permissions`, { headers: headers(false) });
+    state.perms = res.permissions || {};
+    state.archived = res.archived;
+    state.orgId = res.org_id;
+  } catch (e) { state.perms = {}; state.archived = false; }
+  qs('#team-name').textContent = state.teamName + (state.archived ? ' (в архиве)' : '');
+  qs('#btn-create-artifact').classList.toggle('hidden', !can('artifacts','create'));
+  qs('#btn-create-contact').classList.toggle('hidden', !can('contacts','create'));
+  qs('.tab[data-tab="requests"]').classList.toggle('hidden', !can('join_requests','read'));
+  qs('#btn-invite').classList.toggle('hidden', !can('invitations','manage'));
+  qs('#btn-team-settings').classList.toggle('hidden', !can('team','update') && !can('team','delete'));
+  // guests of a public catalog see artifacts only
+  qs('.tab[data-tab="contacts"]').classList.toggle('hidden', !can('contacts','read'));
+  qs('.tab[data-tab="members"]').classList.toggle('hidden', !can('members','read'));
+}
+
 async function openTeam(id, name) {
   state.teamId = id; state.teamName = name;
   qs('#team-name').textContent = name;
   hide('teams-section');
   show('team-view');
+  qs('#artifact-search').value = '';
+  qs('#contact-search').value = '';
+  hide('artifact-search-results');
   setTab('artifacts');
+  await loadPermissions();
   try { await loadArtifacts(); } catch(e){ console.warn('artifacts load failed', e); }
-  try { await loadContacts(); } catch(e){ console.warn('contacts load failed', e); }
+  if (can('contacts','read')) {
+    try { await loadContacts(); } catch(e){ console.warn('contacts load failed', e); }
+  }
+  if (can('members','read')) {
+    try { await loadMembers(); } catch(e){ console.warn('members load failed', e); }
+  }
+  try { await loadInvitations(); } catch(e){ console.warn('invitations load failed', e); }
   try { await loadRequestsSafe(); } catch(e){ console.warn('requests load failed', e); }
 }
 
+const artifactStatuses = {draft:'черновик', active:'активен', deprecated:'устарел', retired:'выведен из эксплуатации'};
+
+// statusBadge marks artifacts that are not plainly active.
+function statusBadge(status) {
+  return status && status !== 'active' ? ` <span class="badge badge-${status}">${artifactStatuses[status]}</span>` : '';
+}
+
+const certificationStates = {certified:'★ сертифицирован', needs_review:'требует проверки', expired:'сертификация истекла'};
+
+// certBadge marks certified artifacts and those whose certification lapsed.
+function certBadge(state) {
+  return state ? ` <span class="badge badge-${state}">${certificationStates[state]}</span>` : '';
+}
+
+// deprecationNote says since when and why an artifact is deprecated.
+function deprecationNote(a) {
+  if (!a.deprecated_at) return '';
+  return `<p class="deprecation">Устарел с ${a.deprecated_at.slice(0,10)}${a.deprecation_reason ? ': ' + a.deprecation_reason : ''}${a.replacement_id ? ` • замена: ID ${a.replacement_id}` : ''}</p>`;
+}
+
 async function loadArtifacts() {
   let arr = await api(`/teams/${state.teamId}/artifacts`, { headers: headers(false) });
   if (!Array.isArray(arr)) arr = [];
@@ -99,13 +190,19 @@ async function loadArtifacts() {
   arr.forEach(a=>{
     const item = document.createElement('div');
     item.className='list-item';
-    item.innerHTML=`<h3>${a.name} <span class=\"badge\">${a.type}</span></h3>
+    item.innerHTML=`<h3>${a.name} <span class=\"badge\">${a.type}</span>${statusBadge(a.status)}${certBadge(a.certification)}</h3>
+      ${deprecationNote(a)}
       <p>${a.description||''}</p>
       <div class=\"meta\">Проект: ${a.project_name} • ID: ${a.id}</div>
       <div class=\"actions\">\
         <button class=\"btn btn-small\" data-act=\"show-fields\">Поля</button>\
-        <button class=\"btn btn-small\" data-act=\"add-field\">+ Поле</button>\
-        <button class=\"btn btn-small btn-secondary\" data-act=\"delete\">Удалить</button>\
+        ${can('fields','create') ? '<button class=\"btn btn-small\" data-act=\"add-field\">+ Поле</button>' : ''}\
+        ${can('contacts','read') ? '<button class=\"btn btn-small\" data-act=\"owners\">Владельцы</button>' : ''}\
+        ${can('lineage','read') ? '<button class=\"btn btn-small\" data-act=\"lineage\">Происхождение</button>' : ''}\
+        ${can('artifacts','update') ? '<button class=\"btn btn-small\" data-act=\"status\">Статус</button>' : ''}\
+        ${can('certifications','read') ? '<button class=\"btn btn-small\" data-act=\"certify\">Сертификация</button>' : ''}\
+        ${can('shares','manage') ? '<button class=\"btn btn-small\" data-act=\"share\">Поделиться</button>' : ''}\
+        ${can('artifacts','delete') ? '<button class=\"btn btn-small btn-secondary\" data-act=\"delete\">Удалить</button>' : ''}\
       </div>
       <div class=\"fields\" style=\"margin-top:10px; display:none;\"></div>`;
     item.onclick = async (e)=>{
@@ -127,24 +224,361 @@ async function loadArtifacts() {
         await renderFields(item, a.id);
         e.stopPropagation();
       }
+      if (act==='owners') { await openModalOwners(a); e.stopPropagation(); }
+      if (act==='lineage') { await openModalLineage(a); e.stopPropagation(); }
+      if (act==='status') { await openModalStatus(a); e.stopPropagation(); }
+      if (act==='certify') { await openModalCertification(a); e.stopPropagation(); }
+      if (act==='share') { await openModalShare(a); e.stopPropagation(); }
     };
     el.appendChild(item);
   });
+  await loadSharedArtifacts();
+}
+
+// loadSharedArtifacts lists artifacts other teams share with this one;
+// they are read-only here.
+async function loadSharedArtifacts() {
+  const el = qs('#shared-artifacts-list');
+  el.innerHTML = '';
+  let arr = [];
+  if (can('shares','read')) {
+    try { arr = await api(`/teams/${state.teamId}/shared-artifacts`, { headers: headers(false) }); } catch (e) { console.warn('shared artifacts load failed', e); }
+  }
+  qs('#shared-artifacts-title').classList.toggle('hidden', arr.length === 0);
+  arr.forEach(a=>{
+    const item = document.createElement('div');
+    item.className='list-item';
+    item.innerHTML=`<h3>${a.name} <span class="badge">${a.type}</span> <span class="badge">${a.team_name}</span>${statusBadge(a.status)}${certBadge(a.certification)}</h3>
+      ${deprecationNote(a)}
+      <p>${a.description||''}</p>
+      <div class="meta">Проект: ${a.project_name} • ID: ${a.id}</div>
+      <div class="actions"><button class="btn btn-small" data-act="show-fields">Поля</button></div>
+      <div class="fields" style="margin-top:10px; display:none;"></div>`;
+    item.onclick = async (e)=>{
+      if (e.target?.dataset?.act !== 'show-fields') return;
+      const res = await api(`/teams/${state.teamId}/shared-artifacts/${a.id}`, { headers: headers(false) });
+      const box = item.querySelector('.fields');
+      box.style.display = 'block';
+      box.innerHTML = (res.fields||[]).map(f=>`<div style="font-size:13px; padding:6px 0; border-top:1px dashed #ddd;"><b>${f.field_name}</b>: ${f.data_type} ${f.is_pk?'(PK)':''}</div>`).join('') || '<div style="color:#777;">Нет полей</div>';
+    };
+    el.appendChild(item);
+  });
+}
+
+async function searchArtifacts() {
+  const q = qs('#artifact-search').value.trim();
+  const el = qs('#artifact-search-results');
+  if (!q) { el.classList.add('hidden'); return; }
+  try {
+    const hits = await api(`/teams/${state.teamId}/search?q=${encodeURIComponent(q)}`, { headers: headers(false) });
+    const where = {name:'название', description:'описание', field:'поле'};
+    el.innerHTML = hits.length ? hits.map(h=>`<div class="list-item"><h3>${h.artifact}${h.shared ? ` <span class="badge">${h.team_name}</span>` : ''}${statusBadge(h.status)}${h.certified ? certBadge('certified') : ''}</h3>
+      <div class="meta">Проект: ${h.project_name} • ID: ${h.artifact_id} • совпадение: ${where[h.match]}${h.field ? ' ' + h.field : ''}</div></div>`).join('')
+      : '<div class="list-item">Ничего не найдено</div>';
+  } catch (e) { el.innerHTML = `<div class="list-item">${e.message}</div>`; }
+  el.classList.remove('hidden');
+}
+
+async function openModalShare(a) {
+  openModal(`
+    <h3>Поделиться «${a.name}»</h3>
+    <p>Другие команды увидят артефакт и его поля только для чтения.</p>
+    <input id="m-share-team" type="number" min="1" placeholder="ID команды (пусто — все команды)"/>
+    <div class="btn-group"><button id="m-share-save" class="btn">Поделиться</button></div>
+    <div id="m-share-list" class="list"></div>
+    <pre id="m-share-out"></pre>
+  `);
+  const render = async ()=>{
+    const shares = (await api(`/teams/${state.teamId}/shares?status=active`, { headers: headers(false) })).filter(s=>s.artifact_id===a.id);
+    const box = qs('#m-share-list');
+    box.innerHTML = '';
+    shares.forEach(s=>{
+      const row = document.createElement('div');
+      row.className = 'list-item';
+      row.innerHTML = `${s.target_team_id ? `${s.target_team_name} (ID ${s.target_team_id})` : 'Все команды'} <button class="btn btn-small btn-secondary">Отозвать</button>`;
+      row.querySelector('button').onclick = async ()=>{
+        try { await api(`/teams/${state.teamId}/shares/${s.id}`, { method:'DELETE', headers: headers(false) }); } catch (e) { qs('#m-share-out').textContent = e.message; }
+        await render();
+      };
+      box.appendChild(row);
+    });
+  };
+  qs('#m-share-save').onclick = async ()=>{
+    const target = Number(qs('#m-share-team').value);
+    try {
+      await api(`/teams/${state.teamId}/artifacts/${a.id}/shares`, { method:'POST', headers: headers(), body: JSON.stringify(target ? {target_team_id: target} : {}) });
+      qs('#m-share-team').value = '';
+      qs('#m-share-out').textContent = '';
+      await render();
+    } catch (e) { qs('#m-share-out').textContent = e.message; }
+  };
+  await render();
+}
+
+async function openModalLineage(a) {
+  const status = {available:'', unshared:' — больше не доступен (владелец прекратил доступ)', deleted:' — удалён'};
+  const l = await api(`/teams/${state.teamId}/artifacts/${a.id}/lineage`, { headers: headers(false) });
+  let sources = [];
+  if (can('lineage','create')) {
+    const own = await api(`/teams/${state.teamId}/artifacts`, { headers: headers(false) });
+    const shared = can('shares','read') ? await api(`/teams/${state.teamId}/shared-artifacts`, { headers: headers(false) }) : [];
+    sources = own.filter(x=>x.id!==a.id).map(x=>({id:x.id, label:x.name})).concat(shared.map(x=>({id:x.id, label:`${x.name} (${x.team_name})`})));
+  }
+  openModal(`
+    <h3>Происхождение «${a.name}»</h3>
+    <h4>Источники</h4>
+    <div id="m-lin-up" class="list"></div>
+    ${sources.length ? `<select id="m-lin-src">${sources.map(s=>`<option value="${s.id}">${s.label}</option>`).join('')}</select>
+    <div class="btn-group"><button id="m-lin-add" class="btn btn-small">Добавить источник</button></div>` : ''}
+    <h4>Используется в</h4>
+    <div class="list">${l.downstream.map(e=>`<div class="list-item">${e.downstream_name} <span class="badge">${e.downstream_team_name}</span></div>`).join('') || '<div style="color:#777;">Нет</div>'}</div>
+    <pre id="m-lin-out"></pre>
+  `);
+  const up = qs('#m-lin-up');
+  if (l.upstream.length === 0) up.innerHTML = '<div style="color:#777;">Нет</div>';
+  l.upstream.forEach(e=>{
+    const row = document.createElement('div');
+    row.className = 'list-item';
+    row.innerHTML = `${e.upstream_name} <span class="badge">${e.upstream_team_name || '—'}</span>${statusBadge(e.upstream_lifecycle)}${status[e.upstream_status]}
+      ${can('lineage','delete') ? '<button class="btn btn-small btn-secondary">Убрать</button>' : ''}`;
+    const btn = row.querySelector('button');
+    if (btn) btn.onclick = async ()=>{
+      try { await api(`/teams/${state.teamId}/artifacts/${a.id}/lineage/${e.id}`, { method:'DELETE', headers: headers(false) }); await openModalLineage(a); }
+      catch (err) { qs('#m-lin-out').textContent = err.message; }
+    };
+    up.appendChild(row);
+  });
+  if (sources.length) qs('#m-lin-add').onclick = async ()=>{
+    try {
+      await api(`/teams/${state.teamId}/artifacts/${a.id}/lineage`, { method:'POST', headers: headers(), body: JSON.stringify({upstream_id: Number(qs('#m-lin-src').value)}) });
+      await openModalLineage(a);
+    } catch (e) { qs('#m-lin-out').textContent = e.message; }
+  };
+}
+
+// openModalStatus moves an artifact through its lifecycle. Deprecating or
+// retiring it notifies the teams of artifacts derived from it.
+async function openModalStatus(a) {
+  const own = await api(`/teams/${state.teamId}/artifacts`, { headers: headers(false) });
+  const shared = can('shares','read') ? await api(`/teams/${state.teamId}/shared-artifacts`, { headers: headers(false) }).catch(()=>[]) : [];
+  const candidates = own.filter(x=>x.id!==a.id).map(x=>({id:x.id, status:x.status, label:x.name}))
+    .concat(shared.map(x=>({id:x.id, status:x.status, label:`${x.name} (${x.team_name})`})))
+    .filter(x=>x.status==='draft' || x.status==='active');
+  openModal(`
+    <h3>Статус «${a.name}»</h3>
+    <select id="m-st-status">${Object.entries(artifactStatuses).map(([k,v])=>`<option value="${k}" ${k===a.status?'selected':''}>${v}</option>`).join('')}</select>
+    <div id="m-st-depr">
+      <input id="m-st-date" type="date" value="${(a.deprecated_at || new Date().toISOString()).slice(0,10)}"/>
+      <textarea id="m-st-reason" placeholder="Причина (обязательна для «устарел»)">${a.deprecation_reason || ''}</textarea>
+      <select id="m-st-repl">
+        <option value="0">Без замены</option>
+        ${candidates.map(x=>`<option value="${x.id}" ${x.id===a.replacement_id?'selected':''}>${x.label}</option>`).join('')}
+      </select>
+      <p class="meta">Команды, чьи артефакты построены на этом, получат уведомление.</p>
+    </div>
+    <div class="btn-group"><button id="m-st-save" class="btn">Сохранить</button></div>
+    <pre id="m-st-out"></pre>
+  `);
+  const toggle = ()=> qs('#m-st-depr').classList.toggle('hidden', !['deprecated','retired'].includes(qs('#m-st-status').value));
+  qs('#m-st-status').onchange = toggle;
+  toggle();
+  qs('#m-st-save').onclick = async ()=>{
+    const body = { status: qs('#m-st-status').value };
+    if (body.status === 'deprecated' || body.status === 'retired') {
+      const date = qs('#m-st-date').value;
+      if (date) body.deprecated_at = new Date(date).toISOString();
+      body.reason = qs('#m-st-reason').value.trim();
+      const repl = Number(qs('#m-st-repl').value);
+      if (repl) body.replacement_id = repl;
+    }
+    try {
+      await api(`/teams/${state.teamId}/artifacts/${a.id}/status`, { method:'PUT', headers: headers(), body: JSON.stringify(body) });
+    } catch (e) { qs('#m-st-out').textContent = e.message; return; }
+    closeModal();
+    await loadArtifacts();
+  };
+}
+
+// openModalDeprecatedReport lists the team's deprecated artifacts that other
+// artifacts are still built on.
+async function openModalDeprecatedReport() {
+  const items = await api(`/teams/${state.teamId}/reports/deprecated`, { headers: headers(false) });
+  openModal(`
+    <h3>Устаревшие артефакты в использовании</h3>
+    <div class="list">${items.map(a=>`<div class="list-item"><h3>${a.name}${statusBadge(a.status)}</h3>
+      ${deprecationNote(a)}
+      <div class="meta">Используется в: ${a.consumers.map(e=>`${e.downstream_name} (${e.downstream_team_name})`).join(', ')}</div></div>`).join('') || '<div class="list-item">Нет устаревших артефактов, которые ещё используются</div>'}</div>
+  `);
+}
+
+// openModalCertification shows the certification of an artifact. Team admins
+// and its data stewards certify it until a date, renew or remove it.
+async function openModalCertification(a) {
+  const cert = a.certification ? await api(`/teams/${state.teamId}/artifacts/${a.id}/certification`, { headers: headers(false) }) : null;
+  const expires = cert ? cert.expires_at.slice(0,10) : new Date(Date.now() + 180*86400000).toISOString().slice(0,10);
+  openModal(`
+    <h3>Сертификация «${a.name}»</h3>
+    ${cert ? `<div class="list-item">${certBadge(cert.state)}
+      <div class="meta">Сертифицировал: ${cert.certified_by_email || '—'} • ${cert.certified_at.slice(0,10)} • до ${cert.expires_at.slice(0,10)}</div>
+      ${cert.review_reason ? `<p class="deprecation">Требует проверки: ${cert.review_reason}</p>` : ''}
+      <p>${cert.note || ''}</p></div>` : '<p class="meta">Артефакт не сертифицирован.</p>'}
+    ${can('certifications','update') ? `
+      <textarea id="m-cert-note" placeholder="Почему этому источнику можно доверять">${cert ? cert.note : ''}</textarea>
+      <input id="m-cert-expires" type="date" value="${expires}"/>
+      <p class="meta">Сертифицировать могут администраторы команды и стюарды данных артефакта. Изменение полей или описания снимает отметку до повторной проверки.</p>
+      <div class="btn-group">
+        <button id="m-cert-save" class="btn">${cert ? 'Подтвердить' : 'Сертифицировать'}</button>
+        ${cert ? '<button id="m-cert-remove" class="btn btn-secondary">Снять сертификацию</button>' : ''}
+      </div>
+      <pre id="m-cert-out"></pre>` : ''}
+  `);
+  if (!can('certifications','update')) return;
+  qs('#m-cert-save').onclick = async ()=>{
+    const body = { note: qs('#m-cert-note').value.trim(), expires_at: new Date(qs('#m-cert-expires').value).toISOString() };
+    try {
+      await api(`/teams/${state.teamId}/artifacts/${a.id}/certification`, { method:'PUT', headers: headers(), body: JSON.stringify(body) });
+    } catch (e) { notifyError(qs('#m-cert-out'), e.message); return; }
+    closeModal();
+    await loadArtifacts();
+  };
+  if (cert) qs('#m-cert-remove').onclick = async ()=>{
+    try {
+      await api(`/teams/${state.teamId}/artifacts/${a.id}/certification`, { method:'DELETE', headers: headers(false) });
+    } catch (e) { notifyError(qs('#m-cert-out'), e.message); return; }
+    closeModal();
+    await loadArtifacts();
+  };
+}
+
+// openModalCertificationReview lists the certifications to review or renew.
+async function openModalCertificationReview() {
+  const items = await api(`/teams/${state.teamId}/certifications`, { headers: headers(false) });
+  openModal(`
+    <h3>Сертифицированные артефакты</h3>
+    <div class="list">${items.map(c=>`<div class="list-item"><h3>${c.artifact_name}${certBadge(c.state)}</h3>
+      ${c.review_reason ? `<p class="deprecation">${c.review_reason}</p>` : ''}
+      <div class="meta">ID: ${c.artifact_id} • ${c.certified_by_email || '—'} • до ${c.expires_at.slice(0,10)}</div></div>`).join('') || '<div class="list-item">Нет сертифицированных артефактов</div>'}</div>
+  `);
+}
+
+const ownerRoles = {technical_owner:'Технический владелец', business_owner:'Бизнес-владелец', data_steward:'Стюард данных'};
+
+// openModalOwners shows the owners of an artifact; editors replace the list.
+async function openModalOwners(a) {
+  let owners = await api(`/teams/${state.teamId}/artifacts/${a.id}/owners`, { headers: headers(false) });
+  if (!can('artifacts','update')) {
+    openModal(`
+      <h3>Владельцы «${a.name}»</h3>
+      <div class="list">${owners.map(o=>`<div class="list-item">${o.contact_name} <span class="badge">${ownerRoles[o.role]}</span></div>`).join('') || '<div class="list-item">Владельцы не указаны</div>'}</div>
+    `);
+    return;
+  }
+  const contacts = await api(`/teams/${state.teamId}/contacts`, { headers: headers(false) });
+  openModal(`
+    <h3>Владельцы «${a.name}»</h3>
+    <div id="m-own-list" class="list"></div>
+    <select id="m-own-contact">${contacts.map(c=>`<option value="${c.id}">${c.name}</option>`).join('')}</select>
+    <select id="m-own-role">${Object.entries(ownerRoles).map(([v,l])=>`<option value="${v}">${l}</option>`).join('')}</select>
+    <div class="btn-group">
+      <button id="m-own-add" class="btn btn-small btn-secondary">Добавить</button>
+      <button id="m-own-save" class="btn">Сохранить</button>
+    </div>
+    <pre id="m-own-out"></pre>
+  `);
+  const render = ()=>{
+    const box = qs('#m-own-list');
+    box.innerHTML = owners.length ? '' : '<div style="color:#777;">Владельцы не указаны</div>';
+    owners.forEach((o, i)=>{
+      const row = document.createElement('div');
+      row.className = 'list-item';
+      row.innerHTML = `${o.contact_name} <span class="badge">${ownerRoles[o.role]}</span> <button class="btn btn-small btn-secondary">Убрать</button>`;
+      row.querySelector('button').onclick = ()=>{ owners.splice(i, 1); render(); };
+      box.appendChild(row);
+    });
+  };
+  render();
+  qs('#m-own-add').onclick = ()=>{
+    const sel = qs('#m-own-contact');
+    if (!sel.value) return;
+    const o = { contact_id: Number(sel.value), contact_name: sel.selectedOptions[0].textContent, role: qs('#m-own-role').value };
+    if (!owners.some(x=>x.contact_id===o.contact_id && x.role===o.role)) owners.push(o);
+    render();
+  };
+  qs('#m-own-save').onclick = async ()=>{
+    try {
+      owners = await api(`/teams/${state.teamId}/artifacts/${a.id}/owners`, { method:'PUT', headers: headers(), body: JSON.stringify({owners: owners.map(o=>({contact_id:o.contact_id, role:o.role}))}) });
+      closeModal();
+    } catch (e) { qs('#m-own-out').textContent = e.message; }
+  };
+}
+
+// openModalReassign hands every artifact of a departing contact over to
+// another contact.
+function openModalReassign(c, contacts) {
+  openModal(`
+    <h3>Передать артефакты «${c.name}»</h3>
+    <p>Все роли владельца перейдут к выбранному контакту.</p>
+    <select id="m-re-to">${contacts.filter(x=>x.id!==c.id).map(x=>`<option value="${x.id}">${x.name}</option>`).join('')}</select>
+    <div class="btn-group"><button id="m-re-save" class="btn">Передать</button></div>
+    <pre id="m-re-out"></pre>
+  `);
+  qs('#m-re-save').onclick = async ()=>{
+    const to = Number(qs('#m-re-to').value);
+    if (!to) return;
+    try {
+      const res = await api(`/teams/${state.teamId}/contacts/${c.id}/reassign`, { method:'POST', headers: headers(), body: JSON.stringify({to_contact_id: to}) });
+      qs('#m-re-out').textContent = `Передано артефактов: ${res.reassigned}`;
+    } catch (e) { qs('#m-re-out').textContent = e.message; }
+  };
+}
+
+async function openModalMyArtifacts() {
+  const items = await api('/me/artifacts', { headers: headers(false) });
+  openModal(`
+    <h3>Мои артефакты</h3>
+    <p>Артефакты, владельцем которых указан контакт, связанный с вашей учётной записью.</p>
+    <div class="list">${items.map(a=>`<div class="list-item"><h3>${a.name} <span class="badge">${a.team_name}</span></h3>
+      <div class="meta">Проект: ${a.project_name} • ${a.roles.map(r=>ownerRoles[r]).join(', ')}</div></div>`).join('') || '<div class="list-item">Нет артефактов</div>'}</div>
+  `);
+}
+
+const channelKinds = {email:'Email', phone:'Телефон', telegram:'Telegram', slack:'Slack', mattermost:'Mattermost'};
+
+// download saves text received from the API as a file.
+function download(filename, text, type) {
+  const a = document.createElement('a');
+  a.href = URL.createObjectURL(new Blob([text], { type }));
+  a.download = filename;
+  a.click();
+  URL.revokeObjectURL(a.href);
 }
 
 async function loadContacts() {
-  let arr = await api(`/teams/${state.teamId}/contacts`, { headers: headers(false) });
+  const q = qs('#contact-search').value.trim();
+  let arr = await api(`/teams/${state.teamId}/contacts${q ? '?q=' + encodeURIComponent(q) : ''}`, { headers: headers(false) });
   if (!Array.isArray(arr)) arr = [];
   const el = qs('#contacts-list');
   el.innerHTML='';
   arr.forEach(c=>{
     const item = document.createElement('div');
     item.className='list-item';
-    item.innerHTML=`<h3>${c.name}</h3><div class="meta">TG: ${c.telegram_contact||''} • ID: ${c.id}</div>
+    const channels = (c.channels || []).map(ch=>`${channelKinds[ch.kind]}: ${ch.value}`).join(' • ');
+    item.innerHTML=`<h3>${c.avatar_url ? `<img class="avatar" src="${c.avatar_url}" alt=""/> ` : ''}${c.name}${c.department ? ` <span class="badge">${c.department}</span>` : ''}</h3>
+      ${c.availability ? `<p>${c.availability}</p>` : ''}
+      <div class="meta">${channels ? channels + ' • ' : ''}${c.user_email ? 'учётная запись: ' + c.user_email + ' • ' : ''}ID: ${c.id}</div>
       <div class="actions">
-        <button class="btn btn-small btn-secondary" data-act="delete">Удалить</button>
+        <button class="btn btn-small btn-secondary" data-act="vcard">vCard</button>
+        ${can('contacts','update') ? '<button class="btn btn-small" data-act="edit">Изменить</button>' : ''}
+        ${can('artifacts','update') && arr.length > 1 ? '<button class="btn btn-small" data-act="reassign">Передать артефакты</button>' : ''}
+        ${can('contacts','delete') ? '<button class="btn btn-small btn-secondary" data-act="delete">Удалить</button>' : ''}
       </div>`;
     item.onclick = async (e)=>{
+      if (e.target?.dataset?.act==='vcard') {
+        download(`contact-${c.id}.vcf`, await api(`/teams/${state.teamId}/contacts/${c.id}/vcard`, { headers: headers(false) }), 'text/vcard');
+        e.stopPropagation();
+      }
+      if (e.target?.dataset?.act==='edit') { openModalContact(c).catch(err=>alert(err.message)); e.stopPropagation(); }
+      if (e.target?.dataset?.act==='reassign') { openModalReassign(c, arr); e.stopPropagation(); }
       if (e.target?.dataset?.act==='delete') {
         if (confirm('Удалить контакт?')) {
           await api(`/teams/${state.teamId}/contacts/${c.id}`, { method:'DELETE', headers: headers(false) });
@@ -157,6 +591,54 @@ async function loadContacts() {
   });
 }
 
+// myUserID reads the user id from the access token.
+function myUserID() {
+  try { return JSON.parse(atob(state.token.split('.')[1].replace(/-/g,'+').replace(/_/g,'/'))).user_id; }
+  catch (e) { return null; }
+}
+
+async function loadMembers() {
+  const arr = await api(`/teams/${state.teamId}/members`, { headers: headers(false) });
+  const me = arr.find(m=>m.user_id===myUserID());
+  const myRole = me ? me.role : '';
+  const el = qs('#members-list');
+  el.innerHTML='';
+  arr.forEach(m=>{
+    const item = document.createElement('div');
+    item.className='list-item';
+    // owners manage everyone but themselves; admins manage members and viewers
+    const canManage = m.user_id!==myUserID() && !m.service_account && m.role!=='owner' &&
+      (myRole==='owner' || (myRole==='admin' && m.role!=='admin'));
+    const roles = myRole==='owner' ? ['admin','member','viewer'] : ['member','viewer'];
+    item.innerHTML=`<h3>${m.service_account ? m.name + ' (сервисный аккаунт)' : (m.name || m.email)} <span class="badge">${m.role}</span>
+        ${m.status==='inactive' ? '<span class="badge badge-rejected">неактивен</span>' : ''}</h3>
+      <div class="meta">${m.service_account ? '' : m.email + ' • '}ID: ${m.user_id} • с ${new Date(m.joined_at).toLocaleDateString()}</div>
+      ${canManage ? `<div class="actions">
+        <select data-act="role">${roles.map(r=>`<option ${r===m.role?'selected':''}>${r}</option>`).join('')}</select>
+        <button class="btn btn-small btn-secondary" data-act="status">${m.status==='active' ? 'Деактивировать' : 'Активировать'}</button>
+        <button class="btn btn-small btn-secondary" data-act="remove">Исключить</button>
+        ${myRole==='owner' && m.status==='active' ? '<button class="btn btn-small btn-secondary" data-act="transfer">Передать владение</button>' : ''}
+      </div>` : ''}`;
+    const base = `/teams/${state.teamId}/members/${m.user_id}`;
+    const run = async (fn)=>{ try { await fn(); } catch (e) { alert(e.message); } await loadMembers(); };
+    const sel = qs('select', item);
+    if (sel) sel.onchange = ()=> run(()=> api(base + '/role', { method:'PUT', headers: headers(), body: JSON.stringify({role: sel.value}) }));
+    item.onclick = async (e)=>{
+      const act = e.target?.dataset?.act;
+      if (act==='status') {
+        await run(()=> api(base + '/status', { method:'PUT', headers: headers(), body: JSON.stringify({status: m.status==='active' ? 'inactive' : 'active'}) }));
+      }
+      if (act==='remove' && confirm('Исключить участника из команды?')) {
+        await run(()=> api(base, { method:'DELETE', headers: headers(false) }));
+      }
+      if (act==='transfer' && confirm('Передать владение командой? Вы станете администратором.')) {
+        await run(()=> api(`/teams/${state.teamId}/transfer-ownership`, { method:'POST', headers: headers(), body: JSON.stringify({user_id: m.user_id}) }));
+      }
+    };
+    el.appendChild(item);
+  });
+}
+
 async function loadRequestsSafe() {
   // может вернуть 403 для member/viewer — просто скрываем
   try {
@@ -168,7 +650,9 @@ async function loadRequestsSafe() {
       item.className='list-item';
       const badge = r.status==='pending'?'badge-pending':(r.status==='approved'?'badge-approved':'badge-rejected');
       item.innerHTML=`<h3>Заявка #${r.id} <span class="badge ${badge}">${r.status}</span></h3>
-        <div class="meta">От пользователя: ${r.user_id} • ${new Date(r.created_at).toLocaleString()}</div>
+        <div class="meta">От пользователя: ${r.user_email || r.user_id}${r.user_name ? ' (' + r.user_name + ')' : ''} • ${new Date(r.created_at).toLocaleString()}</div>
+        ${r.message ? `<p>${r.message}</p>` : ''}
+        ${r.decision_comment ? `<div class="meta">Комментарий: ${r.decision_comment}</div>` : ''}
         ${r.status==='pending'?'<div class="actions">\
           <button class="btn btn-small" data-act="approve">Одобрить</button>\
           <button class="btn btn-small btn-secondary" data-act="reject">Отклонить</button>\
@@ -176,7 +660,11 @@ async function loadRequestsSafe() {
       item.onclick = async (e)=>{
         const act = e.target?.dataset?.act;
         if (act==='approve' || act==='reject') {
-          await api(`/teams/${state.teamId}/requests/${r.id}/${act}`, { method:'POST', headers: headers(false) });
+          const comment = prompt('Комментарий для пользователя (необязательно)', '');
+          if (comment === null) return;
+          try {
+            await api(`/teams/${state.teamId}/requests/${r.id}/${act}`, { method:'POST', headers: headers(), body: JSON.stringify({comment}) });
+          } catch (err) { alert(err.message); }
           await loadRequestsSafe();
         }
       };
@@ -194,11 +682,15 @@ function openModal(html) {
 }
 function closeModal(){ qs('#modal').classList.add('hidden'); }
 
-function openModalCreateTeam() {
+async function openModalCreateTeam() {
+  const orgs = await api('/orgs', { headers: headers(false) }).catch(()=>[]);
   openModal(`
     <h3>Создать команду</h3>
     <input type="text" id="m-team-name" placeholder="Название"/>
     <textarea id="m-team-desc" placeholder="Описание"></textarea>
+    <select id="m-team-org" class="${orgs.length > 1 ? '' : 'hidden'}">
+      ${orgs.map(o=>`<option value="${o.id}">${o.name}</option>`).join('')}
+    </select>
     <div class="btn-group">
       <button id="m-team-save" class="btn">Создать</button>
     </div>
@@ -207,28 +699,33 @@ function openModalCreateTeam() {
     const name = qs('#m-team-name').value.trim();
     const description = qs('#m-team-desc').value.trim();
     if (!name) return;
-    await api('/teams', { method:'POST', headers: headers(), body: JSON.stringify({name, description}) });
+    const org_id = Number(qs('#m-team-org').value) || undefined;
+    try { await api('/teams', { method:'POST', headers: headers(), body: JSON.stringify({name, description, org_id}) }); } catch (e) { alert(e.message); return; }
     closeModal();
     await refreshMyTeams();
   };
 }
 
-function openModalArtifact() {
+async function openModalArtifact() {
+  // types come from the registry of the team's organization
+  const types = await api(`/orgs/${state.orgId}/artifact-types`, { headers: headers(false) }).catch(()=>[]);
+  const contacts = can('contacts','read') ? await api(`/teams/${state.teamId}/contacts`, { headers: headers(false) }).catch(()=>[]) : [];
   openModal(`
     <h3>Создать артефакт</h3>
     <input id="m-art-name" placeholder="Имя"/>
     <select id="m-art-type">
-      <option value="table">table</option>
-      <option value="view">view</option>
-      <option value="procedure">procedure</option>
-      <option value="function">function</option>
-      <option value="index">index</option>
-      <option value="dataset">dataset</option>
-      <option value="api">api</option>
-      <option value="file">file</option>
+      ${types.map(t=>`<option value="${t.name}" title="${t.description}">${t.name}</option>`).join('')}
     </select>
     <input id="m-art-project" placeholder="Проект"/>
     <textarea id="m-art-desc" placeholder="Описание"></textarea>
+    <select id="m-art-owner">
+      <option value="0">Технический владелец не указан</option>
+      ${contacts.map(c=>`<option value="${c.id}">${c.name}</option>`).join('')}
+    </select>
+    <select id="m-art-status">
+      <option value="active">${artifactStatuses.active}</option>
+      <option value="draft">${artifactStatuses.draft}</option>
+    </select>
     <div class="btn-group">
       <button id="m-art-save" class="btn">Создать</button>
     </div>
@@ -239,7 +736,8 @@ function openModalArtifact() {
       type: qs('#m-art-type').value,
       description: qs('#m-art-desc').value.trim(),
       project_name: qs('#m-art-project').value.trim(),
-      developer_id: 1
+      developer_id: Number(qs('#m-art-owner').value),
+      status: qs('#m-art-status').value
     };
     if (!body.name || !body.project_name) return;
     await api(`/teams/${state.teamId}/artifacts`, { method:'POST', headers: headers(), body: JSON.stringify(body)});
@@ -284,17 +782,47 @@ qs('#m-field-save').onclick = async ()=>{
   };
 }
 
-function openModalContact() {
+// openModalContact creates a contact or, given one, edits it. Channels of
+// each kind are entered comma-separated.
+async function openModalContact(c) {
+  // a contact may stand for the account of an active team member
+  const members = can('members','read') ? await api(`/teams/${state.teamId}/members`, { headers: headers(false) }).catch(()=>[]) : [];
+  const values = kind => (c?.channels || []).filter(ch=>ch.kind===kind).map(ch=>ch.value).join(', ');
   openModal(`
-    <h3>Создать контакт</h3>
+    <h3>${c ? 'Изменить контакт' : 'Создать контакт'}</h3>
     <input id="m-contact-name" placeholder="Имя"/>
-    <input id="m-contact-tg" placeholder="Telegram @username"/>
-    <div class="btn-group"><button id="m-contact-save" class="btn">Создать</button></div>
+    <input id="m-contact-dept" placeholder="Отдел"/>
+    ${Object.entries(channelKinds).map(([k,l])=>`<input id="m-contact-ch-${k}" placeholder="${l}${k==='email' || k==='phone' ? '' : ' @username'} (через запятую)" value="${values(k)}"/>`).join('')}
+    <textarea id="m-contact-avail" placeholder="Доступность, дежурства"></textarea>
+    <input id="m-contact-avatar" placeholder="URL аватара"/>
+    <select id="m-contact-user">
+      <option value="">Без учётной записи</option>
+      ${members.filter(m=>m.status==='active' && !m.service_account).map(m=>`<option value="${m.user_id}">${m.name || m.email} (${m.email})</option>`).join('')}
+    </select>
+    <div class="btn-group"><button id="m-contact-save" class="btn">${c ? 'Сохранить' : 'Создать'}</button></div>
+    <pre id="m-contact-out"></pre>
   `);
+  if (c) {
+    qs('#m-contact-name').value = c.name;
+    qs('#m-contact-dept').value = c.department || '';
+    qs('#m-contact-avail').value = c.availability || '';
+    qs('#m-contact-avatar').value = c.avatar_url || '';
+    qs('#m-contact-user').value = c.user_id || '';
+  }
   qs('#m-contact-save').onclick = async ()=>{
-    const body = { name: qs('#m-contact-name').value.trim(), telegram_contact: qs('#m-contact-tg').value.trim() };
+    const body = {
+      name: qs('#m-contact-name').value.trim(),
+      department: qs('#m-contact-dept').value.trim(),
+      availability: qs('#m-contact-avail').value.trim(),
+      avatar_url: qs('#m-contact-avatar').value.trim(),
+      channels: Object.keys(channelKinds).flatMap(k=>qs(`#m-contact-ch-${k}`).value.split(',').map(v=>v.trim()).filter(Boolean).map(value=>({kind:k, value})))
+    };
+    const user = qs('#m-contact-user').value;
+    if (user) body.user_id = Number(user);
     if (!body.name) return;
-    await api(`/teams/${state.teamId}/contacts`, { method:'POST', headers: headers(), body: JSON.stringify(body)});
+    try {
+      await api(`/teams/${state.teamId}/contacts${c ? '/' + c.id : ''}`, { method: c ? 'PUT' : 'POST', headers: headers(), body: JSON.stringify(body)});
+    } catch (e) { notifyError(qs('#m-contact-out'), e.message); return; }
     closeModal();
     await loadContacts();
   };
@@ -307,8 +835,9 @@ function bindUI() {
     const password = qs('#password').value;
     const err = qs('#auth-error'); err.textContent='';
     try {
-      const data = await api('/auth/login', { method:'POST', headers: {'Content-Type':'application/json'}, body: JSON.stringify({email,password})});
-      state.token = data.token; localStorage.setItem('token', state.token);
+      let data = await api('/auth/login', { method:'POST', headers: {'Content-Type':'application/json'}, body: JSON.stringify({email,password})});
+      if (data.mfa_required) data = await loginSecondFactor(data.mfa_token);
+      setSession(data);
       qs('#user-email').textContent = email;
       hide('auth-screen'); show('main-screen');
       await refreshMyTeams();
@@ -322,17 +851,38 @@ function bindUI() {
     const err = qs('#auth-error'); err.textContent='';
     try {
       const data = await api('/auth/register', { method:'POST', headers: {'Content-Type':'application/json'}, body: JSON.stringify({email,password,name})});
-      state.token = data.token; localStorage.setItem('token', state.token);
+      if (data.verification_required) {
+        err.textContent = 'Мы отправили письмо на ' + email + '. Подтвердите адрес по ссылке из письма и войдите.';
+        return;
+      }
+      setSession(data);
       qs('#user-email').textContent = email;
       hide('auth-screen'); show('main-screen');
       await refreshMyTeams();
     } catch (e) { notifyError(err, e.message); }
   };
 
+  qs('#btn-sso').onclick = ()=>{ location.href = API_ROOT + '/auth/oidc/login'; };
+
+  qs('#btn-forgot').onclick = async ()=>{
+    const email = qs('#email').value.trim();
+    const err = qs('#auth-error'); err.textContent='';
+    if (!email) { err.textContent = 'Введите email'; return; }
+    try {
+      await api('/auth/password/forgot', { method:'POST', headers: {'Content-Type':'application/json'}, body: JSON.stringify({email})});
+      err.textContent = 'Если такой аккаунт существует, на ' + email + ' отправлена ссылка для сброса пароля.';
+    } catch (e) { notifyError(err, e.message); }
+  };
+
   qs('#btn-logout').onclick = logout;
+  qs('#btn-2fa').onclick = ()=> openModalTwoFactor().catch(e=>alert(e.message));
 
   // Teams
   qs('#btn-create-team').onclick = openModalCreateTeam;
+  qs('#btn-my-requests').onclick = openModalMyRequests;
+  qs('#btn-notifications').onclick = openModalNotifications;
+  qs('#btn-orgs').onclick = ()=> openModalOrgs().catch(e=>alert(e.message));
+  qs('#btn-my-artifacts').onclick = ()=> openModalMyArtifacts().catch(e=>alert(e.message));
   qs('#btn-search-teams').onclick = async ()=>{
     const q = qs('#team-search').value.trim();
     if (!q) return;
@@ -341,11 +891,17 @@ function bindUI() {
     res.forEach(t=>{
       const div = document.createElement('div');
       div.className = 'list-item';
-      div.innerHTML = `<h3>${t.name}</h3><p>${t.description||''}</p><div class="actions"><button class="btn btn-small">Запросить доступ</button></div>`;
+      const joinLabel = {open: 'Вступить', approval: 'Запросить доступ'}[t.join_policy];
+      div.innerHTML = `<h3>${t.name}</h3><p>${t.description||''}</p><div class="actions">${joinLabel ? `<button class="btn btn-small">${joinLabel}</button>` : '<span class="meta">Только по приглашению</span>'}</div>`;
       div.onclick = async (e)=>{
         if (e.target.tagName==='BUTTON') {
-          await api(`/teams/${t.id}/join`, { method:'POST', headers: headers(false) });
-          alert('Запрос отправлен');
+          const message = t.join_policy === 'open' ? '' : prompt('Сообщение администраторам команды (необязательно)', '');
+          if (message === null) { e.stopPropagation(); return; }
+          try {
+            await api(`/teams/${t.id}/join`, { method:'POST', headers: headers(), body: JSON.stringify({message}) });
+            if (t.join_policy === 'open') { alert('Вы вступили в команду'); await refreshMyTeams(); }
+            else alert('Запрос отправлен');
+          } catch (err) { alert(err.message); }
           e.stopPropagation();
         } else {
           openTeam(t.id, t.name);
@@ -355,6 +911,18 @@ function bindUI() {
     });
   };
 
+  qs('#btn-invite').onclick = openModalInvite;
+  qs('#btn-team-settings').onclick = ()=> openModalTeamSettings().catch(e=>alert(e.message));
+
+  qs('#btn-leave-team').onclick = async ()=>{
+    if (!confirm('Покинуть команду ' + state.teamName + '?')) return;
+    try {
+      await api(`/teams/${state.teamId}/leave`, { method:'POST', headers: headers(false) });
+      hide('team-view'); show('teams-section');
+      await refreshMyTeams();
+    } catch (e) { alert(e.message); }
+  };
+
   qs('#btn-back-to-teams').onclick = ()=>{
     hide('team-view'); show('teams-section');
   };
@@ -366,18 +934,377 @@ function bindUI() {
 
   // Creates
   qs('#btn-create-artifact').onclick = openModalArtifact;
-  qs('#btn-create-contact').onclick = openModalContact;
+  qs('#btn-search-artifacts').onclick = searchArtifacts;
+  qs('#btn-deprecated-report').onclick = ()=> openModalDeprecatedReport().catch(e=>alert(e.message));
+  qs('#btn-certifications').onclick = ()=> openModalCertificationReview().catch(e=>alert(e.message));
+  qs('#artifact-search').onkeydown = (e)=>{ if (e.key==='Enter') searchArtifacts(); };
+  qs('#btn-create-contact').onclick = ()=> openModalContact().catch(e=>alert(e.message));
+  qs('#contact-search').onkeydown = (e)=>{ if (e.key==='Enter') loadContacts().catch(err=>alert(err.message)); };
+  qs('#btn-export-contacts').onclick = async ()=>{
+    try { download(`team-${state.teamId}-contacts.vcf`, await api(`/teams/${state.teamId}/contacts.vcf`, { headers: headers(false) }), 'text/vcard'); }
+    catch (e) { alert(e.message); }
+  };
 
   // Modal close
   qs('.modal .close').onclick = closeModal;
   qs('#modal').addEventListener('click', (e)=>{ if (e.target.id==='modal') closeModal(); });
 }
 
+// loginSecondFactor finishes a login of an account with 2FA enabled.
+async function loginSecondFactor(mfaToken) {
+  const code = prompt('Код из приложения-аутентификатора или код восстановления');
+  if (!code) throw new Error('Вход отменён');
+  return api('/auth/login/2fa', { method:'POST', headers: {'Content-Type':'application/json'}, body: JSON.stringify({mfa_token: mfaToken, code})});
+}
+
+// consumeSSORedirect picks up the result of an SSO login, which the server
+// passes in the URL fragment, and removes it from the address bar.
+async function consumeSSORedirect() {
+  if (!location.hash) return;
+  const p = new URLSearchParams(location.hash.slice(1));
+  if (!p.has('token') && !p.has('mfa_token') && !p.has('error')) return;
+  history.replaceState(null, '', location.pathname + location.search);
+  try {
+    if (p.has('error')) throw new Error(p.get('error'));
+    const data = p.has('mfa_token') ? await loginSecondFactor(p.get('mfa_token')) : {token: p.get('token'), refresh_token: p.get('refresh_token')};
+    setSession(data);
+  } catch (e) { qs('#auth-error').textContent = e.message; }
+}
+
+// consumeAccountLink handles links from account emails:
+// #verify_email=TOKEN and #reset_password=TOKEN. Invitation links
+// (#invite=TOKEN) are kept until the user is logged in.
+async function consumeAccountLink() {
+  if (!location.hash) return;
+  const p = new URLSearchParams(location.hash.slice(1));
+  if (p.has('invite')) {
+    localStorage.setItem('pendingInvite', p.get('invite'));
+    history.replaceState(null, '', location.pathname + location.search);
+    if (!state.token) qs('#auth-error').textContent = 'Войдите или зарегистрируйтесь, чтобы принять приглашение в команду.';
+    return;
+  }
+  if (!p.has('verify_email') && !p.has('reset_password')) return;
+  history.replaceState(null, '', location.pathname + location.search);
+  const msg = qs('#auth-error');
+  try {
+    if (p.has('verify_email')) {
+      await api('/auth/verify-email', { method:'POST', headers: {'Content-Type':'application/json'}, body: JSON.stringify({token: p.get('verify_email')})});
+      msg.textContent = 'Email подтверждён. Теперь можно войти.';
+      return;
+    }
+    const password = prompt('Новый пароль');
+    if (!password) return;
+    await api('/auth/password/reset', { method:'POST', headers: {'Content-Type':'application/json'}, body: JSON.stringify({token: p.get('reset_password'), password})});
+    clearSession();
+    msg.textContent = 'Пароль изменён. Войдите с новым паролем.';
+  } catch (e) { msg.textContent = e.message; }
+}
+
+async function acceptPendingInvite() {
+  const token = localStorage.getItem('pendingInvite');
+  if (!token) return;
+  localStorage.removeItem('pendingInvite');
+  try {
+    const res = await api('/invitations/accept', { method:'POST', headers: headers(), body: JSON.stringify({token}) });
+    alert(`Вы вступили в команду ${res.team.name} (роль: ${res.role})`);
+  } catch (e) { alert('Приглашение не принято: ' + e.message); }
+}
+
+function openModalInvite() {
+  const roles = state.perms.team?.includes('transfer') ? ['viewer','member','admin'] : ['viewer','member'];
+  openModal(`
+    <h3>Пригласить в команду</h3>
+    <select id="m-inv-role">${roles.map(r=>`<option ${r==='member'?'selected':''}>${r}</option>`).join('')}</select>
+    <input id="m-inv-email" placeholder="Email (необязательно; ссылка придёт письмом)"/>
+    <input id="m-inv-uses" type="number" min="0" value="1" placeholder="Сколько человек может вступить (0 — без ограничений)"/>
+    <input id="m-inv-days" type="number" min="1" max="90" value="7" placeholder="Срок действия, дней"/>
+    <div class="btn-group"><button id="m-inv-save" class="btn">Создать приглашение</button></div>
+    <pre id="m-inv-out"></pre>
+  `);
+  qs('#m-inv-save').onclick = async ()=>{
+    const email = qs('#m-inv-email').value.trim();
+    const body = { role: qs('#m-inv-role').value, expires_in_days: Number(qs('#m-inv-days').value) || 7 };
+    if (email) body.email = email; else body.max_uses = Number(qs('#m-inv-uses').value);
+    try {
+      const res = await api(`/teams/${state.teamId}/invitations`, { method:'POST', headers: headers(), body: JSON.stringify(body) });
+      qs('#m-inv-out').textContent = 'Ссылка (показывается один раз):\n' + res.url;
+      qs('#m-inv-save').disabled = true;
+      await loadInvitations();
+    } catch (e) { qs('#m-inv-out').textContent = e.message; }
+  };
+}
+
+async function openModalTeamSettings() {
+  const teams = await api('/me/teams', { headers: headers(false) });
+  const t = teams.find(x => x.id === state.teamId);
+  if (!t) return;
+  const opt = (values, cur) => Object.entries(values).map(([v, label])=>`<option value="${v}" ${v===cur?'selected':''}>${label}</option>`).join('');
+  openModal(`
+    <h3>Настройки команды</h3>
+    <input id="m-set-name" value="${t.name}" placeholder="Название"/>
+    <textarea id="m-set-desc" placeholder="Описание">${t.description||''}</textarea>
+    <div class="btn-group"><button id="m-set-rename" class="btn btn-small">Сохранить название и описание</button></div>
+    <label>Видимость</label>
+    <select id="m-set-vis">${opt({public:'Публичная — видна в поиске', discoverable:'Находится только по точному названию', hidden:'Скрытая'}, t.visibility)}</select>
+    <label>Вступление</label>
+    <select id="m-set-join">${opt({open:'Свободное', approval:'По заявке', invite_only:'Только по приглашению'}, t.join_policy)}</select>
+    <label><input id="m-set-catalog" type="checkbox" ${t.public_catalog?'checked':''}/> Публичный каталог: все пользователи могут просматривать артефакты</label>
+    <div class="btn-group"><button id="m-set-save" class="btn btn-small">Сохранить доступ</button></div>
+    <div class="btn-group">
+      <button id="m-set-archive" class="btn btn-small btn-secondary">${t.archived_at ? 'Вернуть из архива' : 'В архив'}</button>
+      <button id="m-set-delete" class="btn btn-small btn-secondary">Удалить команду</button>
+    </div>
+    <pre id="m-set-out"></pre>
+  `);
+  const run = async (fn)=>{
+    try { await fn(); } catch (e) { qs('#m-set-out').textContent = e.message; }
+  };
+  qs('#m-set-rename').onclick = ()=> run(async ()=>{
+    const res = await api(`/teams/${state.teamId}`, { method:'PUT', headers: headers(), body: JSON.stringify({name: qs('#m-set-name').value.trim(), description: qs('#m-set-desc').value}) });
+    state.teamName = res.name;
+    await loadPermissions();
+    closeModal();
+  });
+  qs('#m-set-save').onclick = ()=> run(async ()=>{
+    const body = { visibility: qs('#m-set-vis').value, join_policy: qs('#m-set-join').value, public_catalog: qs('#m-set-catalog').checked };
+    await api(`/teams/${state.teamId}/settings`, { method:'PUT', headers: headers(), body: JSON.stringify(body) });
+    closeModal();
+  });
+  qs('#m-set-archive').onclick = ()=> run(async ()=>{
+    if (!t.archived_at && !confirm('Команда станет доступной только для чтения и пропадёт из поиска. Продолжить?')) return;
+    await api(`/teams/${state.teamId}/${t.archived_at ? 'unarchive' : 'archive'}`, { method:'POST', headers: headers(false) });
+    closeModal();
+    await openTeam(state.teamId, state.teamName);
+  });
+  qs('#m-set-delete').onclick = ()=> run(async ()=>{
+    const name = prompt(`Команда будет удалена вместе со всеми артефактами и контактами (копию сможет восстановить системный администратор). Введите название команды «${t.name}» для подтверждения`);
+    if (name === null) return;
+    await api(`/teams/${state.teamId}`, { method:'DELETE', headers: headers(), body: JSON.stringify({confirm_name: name}) });
+    closeModal();
+    hide('team-view'); show('teams-section');
+    await refreshMyTeams();
+  });
+}
+
+async function loadInvitations() {
+  const el = qs('#invitations-list');
+  el.innerHTML = '';
+  if (!can('invitations','read')) return;
+  const arr = await api(`/teams/${state.teamId}/invitations?status=active`, { headers: headers(false) });
+  arr.forEach(inv=>{
+    const item = document.createElement('div');
+    item.className='list-item';
+    item.innerHTML=`<h3>Приглашение #${inv.id} <span class="badge">${inv.role}</span></h3>
+      <div class="meta">${inv.email || 'по ссылке'} • использовано ${inv.use_count}${inv.max_uses ? ' из ' + inv.max_uses : ''} • до ${new Date(inv.expires_at).toLocaleString()}</div>
+      <div class="actions"><button class="btn btn-small btn-secondary" data-act="revoke">Отозвать</button></div>`;
+    item.onclick = async (e)=>{
+      if (e.target?.dataset?.act==='revoke' && confirm('Отозвать приглашение?')) {
+        try { await api(`/teams/${state.teamId}/invitations/${inv.id}`, { method:'DELETE', headers: headers(false) }); } catch (err) { alert(err.message); }
+        await loadInvitations();
+      }
+    };
+    el.appendChild(item);
+  });
+}
+
+async function refreshNotificationCount() {
+  try {
+    const res = await api('/me/notifications?unread=true&limit=1', { headers: headers(false) });
+    qs('#btn-notifications').textContent = res.unread ? `Уведомления (${res.unread})` : 'Уведомления';
+  } catch (e) { console.warn('notifications load failed', e); }
+}
+
+async function openModalNotifications() {
+  const res = await api('/me/notifications?limit=50', { headers: headers(false) });
+  openModal(`
+    <h3>Уведомления</h3>
+    <div class="list">${res.notifications.map(n=>`<div class="list-item">
+      <p>${n.read_at ? '' : '<span class="badge badge-pending">новое</span> '}${n.message}</p>
+      <div class="meta">${new Date(n.created_at).toLocaleString()}</div></div>`).join('') || '<div class="list-item">Уведомлений нет</div>'}</div>
+    <div class="btn-group"><button id="m-notif-read" class="btn">Отметить все прочитанными</button></div>
+  `);
+  qs('#m-notif-read').onclick = async ()=>{
+    await api('/me/notifications/read-all', { method:'POST', headers: headers(false) });
+    closeModal();
+    await refreshNotificationCount();
+  };
+}
+
+async function openModalOrgs() {
+  const orgs = await api('/orgs', { headers: headers(false) });
+  openModal(`
+    <h3>Организации</h3>
+    <div class="list" id="m-orgs">${orgs.map(o=>`<div class="list-item" data-org="${o.id}">
+      <h3>${o.name} <span class="badge">${o.role}</span></h3><p>${o.description||''}</p></div>`).join('') || '<div class="list-item">Вы не состоите в организациях</div>'}</div>
+  `);
+  qs('#m-orgs').onclick = (e)=>{
+    const id = e.target.closest('[data-org]')?.dataset.org;
+    if (id) openModalOrg(orgs.find(o=>o.id==id)).catch(err=>alert(err.message));
+  };
+}
+
+// openModalOrg shows search across the organization's teams; org admins
+// also get usage, settings, members and the artifact type registry.
+async function openModalOrg(org) {
+  const admin = org.role === 'admin';
+  const base = `/orgs/${org.id}`;
+  const [members, types, usage] = await Promise.all([
+    admin ? api(`${base}/members`, { headers: headers(false) }) : [],
+    api(`${base}/artifact-types`, { headers: headers(false) }),
+    admin ? api(`${base}/usage`, { headers: headers(false) }) : null,
+  ]);
+  const methods = ['password','ldap','oidc'];
+  openModal(`
+    <h3>${org.name}</h3>
+    <div class="toolbar">
+      <input id="m-org-q" placeholder="Поиск артефактов во всех командах"/>
+      <button id="m-org-search" class="btn btn-small">Найти</button>
+    </div>
+    <div class="list" id="m-org-hits"></div>
+    ${admin ? `
+    <h4>Использование</h4>
+    <p>${usage.teams} команд, ${usage.users} пользователей (активны за 30 дней: ${usage.active_users}), ${usage.artifacts} артефактов, ${usage.fields} полей</p>
+    <div class="list">${usage.per_team.map(t=>`<div class="list-item"><h3>${t.team_name}${t.archived ? ' (в архиве)' : ''}</h3>
+      <div class="meta">участников: ${t.members} • артефактов: ${t.artifacts} • полей: ${t.fields} • контактов: ${t.contacts} • общий доступ: ${t.shares}${t.last_artifact_at ? ' • последнее изменение: ' + new Date(t.last_artifact_at).toLocaleDateString() : ''}</div></div>`).join('')}</div>
+    <h4>Настройки</h4>
+    <input id="m-org-name" value="${org.name}"/>
+    <textarea id="m-org-desc" placeholder="Описание">${org.description||''}</textarea>
+    <div>Способы входа: ${methods.map(m=>`<label><input type="checkbox" data-method="${m}" ${org.allowed_auth_methods.includes(m)?'checked':''}/> ${m}</label>`).join(' ')}</div>
+    <label><input type="checkbox" id="m-org-autojoin" ${org.auto_join?'checked':''}/> новые пользователи вступают автоматически</label>
+    <div class="btn-group"><button id="m-org-save" class="btn btn-small">Сохранить</button></div>
+    <h4>Участники</h4>
+    <div class="list" id="m-org-members">${members.map(m=>`<div class="list-item">
+      <h3>${m.email} <span class="badge">${m.role}</span>${m.is_active ? '' : ' <span class="badge badge-rejected">неактивен</span>'}</h3>
+      <div class="actions">
+        <button class="btn btn-small btn-secondary" data-role="${m.role==='admin'?'member':'admin'}" data-user="${m.user_id}">${m.role==='admin'?'Сделать участником':'Сделать администратором'}</button>
+        <button class="btn btn-small btn-secondary" data-remove="${m.user_id}">Исключить</button>
+      </div></div>`).join('')}</div>
+    <div class="toolbar"><input id="m-org-email" placeholder="Email пользователя"/><button id="m-org-add" class="btn btn-small">Добавить</button></div>` : ''}
+    <h4>Типы артефактов</h4>
+    <div class="list" id="m-org-types">${types.map(t=>`<div class="list-item"><h3>${t.name}</h3>
+      <div class="meta">${t.description||''} • используется: ${t.in_use}</div>
+      ${admin && !t.in_use ? `<div class="actions"><button class="btn btn-small btn-secondary" data-type="${t.name}">Удалить</button></div>` : ''}</div>`).join('')}</div>
+    ${admin ? `<div class="toolbar"><input id="m-org-type" placeholder="Новый тип"/><input id="m-org-type-desc" placeholder="Описание"/><button id="m-org-type-add" class="btn btn-small">Добавить</button></div>` : ''}
+  `);
+  const reopen = async (updated)=> openModalOrg(updated || org).catch(e=>alert(e.message));
+  const run = async (fn)=>{ try { await fn(); } catch (e) { alert(e.message); return; } await reopen(); };
+  qs('#m-org-search').onclick = async ()=>{
+    const q = qs('#m-org-q').value.trim();
+    if (!q) return;
+    const hits = await api(`${base}/search?q=${encodeURIComponent(q)}`, { headers: headers(false) }).catch(e=>{ alert(e.message); return []; });
+    qs('#m-org-hits').innerHTML = hits.map(h=>`<div class="list-item"><h3>${h.artifact}${h.field ? ' • ' + h.field : ''}</h3>
+      <div class="meta">${h.team_name} • ${h.project_name} • ${h.match}</div></div>`).join('') || '<div class="list-item">Ничего не найдено</div>';
+  };
+  qs('#m-org-types').onclick = (e)=>{
+    const name = e.target?.dataset?.type;
+    if (name && confirm('Удалить тип ' + name + '?')) run(()=> api(`${base}/artifact-types/${encodeURIComponent(name)}`, { method:'DELETE', headers: headers(false) }));
+  };
+  if (!admin) return;
+  qs('#m-org-save').onclick = async ()=>{
+    const body = {
+      name: qs('#m-org-name').value.trim(),
+      description: qs('#m-org-desc').value.trim(),
+      allowed_auth_methods: qsa('[data-method]').filter(el=>el.checked).map(el=>el.dataset.method),
+      auto_join: qs('#m-org-autojoin').checked,
+    };
+    try {
+      const updated = await api(base, { method:'PUT', headers: headers(), body: JSON.stringify(body) });
+      await reopen({ ...updated, role: org.role });
+    } catch (e) { alert(e.message); }
+  };
+  qs('#m-org-members').onclick = (e)=>{
+    const { role, user, remove } = e.target?.dataset || {};
+    if (user) run(()=> api(`${base}/members/${user}`, { method:'PUT', headers: headers(), body: JSON.stringify({role}) }));
+    if (remove && confirm('Исключить пользователя из организации и всех её команд?')) run(()=> api(`${base}/members/${remove}`, { method:'DELETE', headers: headers(false) }));
+  };
+  qs('#m-org-add').onclick = ()=>{
+    const email = qs('#m-org-email').value.trim();
+    if (email) run(()=> api(`${base}/members`, { method:'POST', headers: headers(), body: JSON.stringify({email}) }));
+  };
+  qs('#m-org-type-add').onclick = ()=>{
+    const name = qs('#m-org-type').value.trim();
+    const description = qs('#m-org-type-desc').value.trim();
+    if (name) run(()=> api(`${base}/artifact-types`, { method:'POST', headers: headers(), body: JSON.stringify({name, description}) }));
+  };
+}
+
+async function openModalMyRequests() {
+  const arr = await api('/me/join-requests', { headers: headers(false) });
+  openModal(`
+    <h3>Мои заявки</h3>
+    <div class="list" id="m-my-requests">${arr.map(r=>`<div class="list-item">
+      <h3>${r.team_name} <span class="badge ${r.status==='pending'?'badge-pending':(r.status==='approved'?'badge-approved':'badge-rejected')}">${r.status}</span></h3>
+      <div class="meta">${new Date(r.created_at).toLocaleString()}${r.decision_comment ? ' • ' + r.decision_comment : ''}</div>
+      ${r.status==='pending' ? `<div class="actions"><button class="btn btn-small btn-secondary" data-cancel="${r.id}">Отменить</button></div>` : ''}
+    </div>`).join('') || '<div class="list-item">Заявок нет</div>'}</div>
+  `);
+  qs('#m-my-requests').onclick = async (e)=>{
+    const id = e.target?.dataset?.cancel;
+    if (!id || !confirm('Отменить заявку?')) return;
+    try { await api(`/me/join-requests/${id}/cancel`, { method:'POST', headers: headers(false) }); } catch (err) { alert(err.message); }
+    await openModalMyRequests();
+  };
+}
+
+async function openModalTwoFactor() {
+  const st = await api('/me/2fa', { headers: headers(false) });
+  if (st.enabled) {
+    openModal(`
+      <h3>Двухфакторная аутентификация</h3>
+      <p>Включена. Осталось кодов восстановления: ${st.recovery_codes_left}</p>
+      <input id="m-2fa-code" placeholder="Код из приложения или код восстановления"/>
+      <div class="btn-group">
+        <button id="m-2fa-codes" class="btn btn-secondary">Новые коды восстановления</button>
+        <button id="m-2fa-disable" class="btn">Отключить</button>
+      </div>
+      <pre id="m-2fa-out"></pre>
+    `);
+    const code = ()=> qs('#m-2fa-code').value.trim();
+    qs('#m-2fa-disable').onclick = async ()=>{
+      await api('/me/2fa/disable', { method:'POST', headers: headers(), body: JSON.stringify({code: code()}) });
+      closeModal();
+    };
+    qs('#m-2fa-codes').onclick = async ()=>{
+      const res = await api('/me/2fa/recovery-codes', { method:'POST', headers: headers(), body: JSON.stringify({code: code()}) });
+      qs('#m-2fa-out').textContent = res.recovery_codes.join('\n');
+    };
+    return;
+  }
+  const e = await api('/me/2fa/enroll', { method:'POST', headers: headers(false) });
+  openModal(`
+    <h3>Двухфакторная аутентификация</h3>
+    <p>Отсканируйте QR-код в приложении-аутентификаторе или введите ключ вручную.</p>
+    <img src="${e.qr_code}" alt="QR"/>
+    <p><code>${e.secret}</code></p>
+    <input id="m-2fa-code" placeholder="Код из приложения"/>
+    <div class="btn-group"><button id="m-2fa-confirm" class="btn">Включить</button></div>
+    <pre id="m-2fa-out"></pre>
+  `);
+  qs('#m-2fa-confirm').onclick = async ()=>{
+    const res = await api('/me/2fa/confirm', { method:'POST', headers: headers(), body: JSON.stringify({code: qs('#m-2fa-code').value.trim()}) });
+    qs('#m-2fa-confirm').disabled = true;
+    qs('#m-2fa-out').textContent = 'Сохраните коды восстановления, они больше не будут показаны:\n' + res.recovery_codes.join('\n');
+  };
+}
+
+async function loadProviders() {
+  try {
+    const res = await fetch(API_ROOT + '/auth/providers');
+    if (!res.ok) return;
+    const p = await res.json();
+    if (p.oidc) show('btn-sso');
+    if (p.ldap) { qs('#email').type = 'text'; qs('#email').placeholder = 'Email или логин'; }
+  } catch (e) { /* password login still works */ }
+}
+
 window.addEventListener('DOMContentLoaded', async ()=>{
   bindUI();
+  await consumeAccountLink();
+  await consumeSSORedirect();
+  loadProviders();
   if (state.token) {
     // try to load
     show('main-screen'); hide('auth-screen');
-    await refreshMyTeams().catch(()=>logout());
+    await refreshMyTeams().catch(()=>clearSession());
   }
 });